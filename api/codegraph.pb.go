@@ -0,0 +1,1273 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.31.0
+// source: api/codegraph.proto
+
+package codebase_syncer
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IndexEntry_Kind int32
+
+const (
+	IndexEntry_UNKNOWN            IndexEntry_Kind = 0
+	IndexEntry_FILE_ELEMENT_TABLE IndexEntry_Kind = 1
+	IndexEntry_SYMBOL_OCCURRENCE  IndexEntry_Kind = 2
+)
+
+// Enum value maps for IndexEntry_Kind.
+var (
+	IndexEntry_Kind_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "FILE_ELEMENT_TABLE",
+		2: "SYMBOL_OCCURRENCE",
+	}
+	IndexEntry_Kind_value = map[string]int32{
+		"UNKNOWN":            0,
+		"FILE_ELEMENT_TABLE": 1,
+		"SYMBOL_OCCURRENCE":  2,
+	}
+)
+
+func (x IndexEntry_Kind) Enum() *IndexEntry_Kind {
+	p := new(IndexEntry_Kind)
+	*p = x
+	return p
+}
+
+func (x IndexEntry_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IndexEntry_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_codegraph_proto_enumTypes[0].Descriptor()
+}
+
+func (IndexEntry_Kind) Type() protoreflect.EnumType {
+	return &file_api_codegraph_proto_enumTypes[0]
+}
+
+func (x IndexEntry_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IndexEntry_Kind.Descriptor instead.
+func (IndexEntry_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{11, 0}
+}
+
+// Source position (1-based)
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartLine     int32                  `protobuf:"varint,1,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`       // Start line
+	StartColumn   int32                  `protobuf:"varint,2,opt,name=start_column,json=startColumn,proto3" json:"start_column,omitempty"` // Start column
+	EndLine       int32                  `protobuf:"varint,3,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`             // End line
+	EndColumn     int32                  `protobuf:"varint,4,opt,name=end_column,json=endColumn,proto3" json:"end_column,omitempty"`       // End column
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_api_codegraph_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Position) GetStartLine() int32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *Position) GetStartColumn() int32 {
+	if x != nil {
+		return x.StartColumn
+	}
+	return 0
+}
+
+func (x *Position) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *Position) GetEndColumn() int32 {
+	if x != nil {
+		return x.EndColumn
+	}
+	return 0
+}
+
+// Search definition request
+type SearchDefinitionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`             // Client ID
+	CodebasePath  string                 `protobuf:"bytes,2,opt,name=codebase_path,json=codebasePath,proto3" json:"codebase_path,omitempty"` // Codebase absolute path
+	FilePath      string                 `protobuf:"bytes,3,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`             // File path
+	StartLine     int32                  `protobuf:"varint,4,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`         // Start line
+	EndLine       int32                  `protobuf:"varint,5,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`               // End line
+	CodeSnippet   string                 `protobuf:"bytes,6,opt,name=code_snippet,json=codeSnippet,proto3" json:"code_snippet,omitempty"`    // Code snippet
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchDefinitionRequest) Reset() {
+	*x = SearchDefinitionRequest{}
+	mi := &file_api_codegraph_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchDefinitionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchDefinitionRequest) ProtoMessage() {}
+
+func (x *SearchDefinitionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchDefinitionRequest.ProtoReflect.Descriptor instead.
+func (*SearchDefinitionRequest) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchDefinitionRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *SearchDefinitionRequest) GetCodebasePath() string {
+	if x != nil {
+		return x.CodebasePath
+	}
+	return ""
+}
+
+func (x *SearchDefinitionRequest) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *SearchDefinitionRequest) GetStartLine() int32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *SearchDefinitionRequest) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *SearchDefinitionRequest) GetCodeSnippet() string {
+	if x != nil {
+		return x.CodeSnippet
+	}
+	return ""
+}
+
+// Definition information
+type DefinitionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FilePath      string                 `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"` // File path
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                         // Symbol name
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                         // Element type
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`                   // Code content
+	Position      *Position              `protobuf:"bytes,5,opt,name=position,proto3" json:"position,omitempty"`                 // Position
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DefinitionInfo) Reset() {
+	*x = DefinitionInfo{}
+	mi := &file_api_codegraph_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DefinitionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefinitionInfo) ProtoMessage() {}
+
+func (x *DefinitionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefinitionInfo.ProtoReflect.Descriptor instead.
+func (*DefinitionInfo) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DefinitionInfo) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *DefinitionInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DefinitionInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DefinitionInfo) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *DefinitionInfo) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+// Search definition response
+type SearchDefinitionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          []*DefinitionInfo      `protobuf:"bytes,1,rep,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchDefinitionResponse) Reset() {
+	*x = SearchDefinitionResponse{}
+	mi := &file_api_codegraph_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchDefinitionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchDefinitionResponse) ProtoMessage() {}
+
+func (x *SearchDefinitionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchDefinitionResponse.ProtoReflect.Descriptor instead.
+func (*SearchDefinitionResponse) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchDefinitionResponse) GetList() []*DefinitionInfo {
+	if x != nil {
+		return x.List
+	}
+	return nil
+}
+
+// Search reference request
+type SearchReferenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`             // Client ID
+	CodebasePath  string                 `protobuf:"bytes,2,opt,name=codebase_path,json=codebasePath,proto3" json:"codebase_path,omitempty"` // Codebase absolute path
+	FilePath      string                 `protobuf:"bytes,3,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`             // File path
+	StartLine     int32                  `protobuf:"varint,4,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`         // Start line
+	EndLine       int32                  `protobuf:"varint,5,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`               // End line
+	SymbolName    string                 `protobuf:"bytes,6,opt,name=symbol_name,json=symbolName,proto3" json:"symbol_name,omitempty"`       // Symbol name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchReferenceRequest) Reset() {
+	*x = SearchReferenceRequest{}
+	mi := &file_api_codegraph_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchReferenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchReferenceRequest) ProtoMessage() {}
+
+func (x *SearchReferenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchReferenceRequest.ProtoReflect.Descriptor instead.
+func (*SearchReferenceRequest) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchReferenceRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *SearchReferenceRequest) GetCodebasePath() string {
+	if x != nil {
+		return x.CodebasePath
+	}
+	return ""
+}
+
+func (x *SearchReferenceRequest) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *SearchReferenceRequest) GetStartLine() int32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *SearchReferenceRequest) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *SearchReferenceRequest) GetSymbolName() string {
+	if x != nil {
+		return x.SymbolName
+	}
+	return ""
+}
+
+// Relation node of reference tree
+type RelationNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FilePath      string                 `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`       // File path
+	SymbolName    string                 `protobuf:"bytes,2,opt,name=symbol_name,json=symbolName,proto3" json:"symbol_name,omitempty"` // Symbol name
+	Position      *Position              `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`                       // Position
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`                         // Code content
+	NodeType      string                 `protobuf:"bytes,5,opt,name=node_type,json=nodeType,proto3" json:"node_type,omitempty"`       // Node type
+	Children      []*RelationNode        `protobuf:"bytes,6,rep,name=children,proto3" json:"children,omitempty"`                       // Child nodes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelationNode) Reset() {
+	*x = RelationNode{}
+	mi := &file_api_codegraph_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationNode) ProtoMessage() {}
+
+func (x *RelationNode) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationNode.ProtoReflect.Descriptor instead.
+func (*RelationNode) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RelationNode) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *RelationNode) GetSymbolName() string {
+	if x != nil {
+		return x.SymbolName
+	}
+	return ""
+}
+
+func (x *RelationNode) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *RelationNode) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *RelationNode) GetNodeType() string {
+	if x != nil {
+		return x.NodeType
+	}
+	return ""
+}
+
+func (x *RelationNode) GetChildren() []*RelationNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+// Search reference response
+type SearchReferenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          []*RelationNode        `protobuf:"bytes,1,rep,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchReferenceResponse) Reset() {
+	*x = SearchReferenceResponse{}
+	mi := &file_api_codegraph_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchReferenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchReferenceResponse) ProtoMessage() {}
+
+func (x *SearchReferenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchReferenceResponse.ProtoReflect.Descriptor instead.
+func (*SearchReferenceResponse) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchReferenceResponse) GetList() []*RelationNode {
+	if x != nil {
+		return x.List
+	}
+	return nil
+}
+
+// Get file structure request
+type GetFileStructureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`             // Client ID
+	CodebasePath  string                 `protobuf:"bytes,2,opt,name=codebase_path,json=codebasePath,proto3" json:"codebase_path,omitempty"` // Codebase absolute path
+	FilePath      string                 `protobuf:"bytes,3,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`             // File path
+	Types         []string               `protobuf:"bytes,4,rep,name=types,proto3" json:"types,omitempty"`                                   // Element types to keep
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileStructureRequest) Reset() {
+	*x = GetFileStructureRequest{}
+	mi := &file_api_codegraph_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileStructureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileStructureRequest) ProtoMessage() {}
+
+func (x *GetFileStructureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileStructureRequest.ProtoReflect.Descriptor instead.
+func (*GetFileStructureRequest) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetFileStructureRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *GetFileStructureRequest) GetCodebasePath() string {
+	if x != nil {
+		return x.CodebasePath
+	}
+	return ""
+}
+
+func (x *GetFileStructureRequest) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *GetFileStructureRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// File structure information
+type FileStructureInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`         // Element type
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`         // Symbol name
+	Position      *Position              `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"` // Position
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`   // Code content
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileStructureInfo) Reset() {
+	*x = FileStructureInfo{}
+	mi := &file_api_codegraph_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileStructureInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileStructureInfo) ProtoMessage() {}
+
+func (x *FileStructureInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileStructureInfo.ProtoReflect.Descriptor instead.
+func (*FileStructureInfo) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FileStructureInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FileStructureInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileStructureInfo) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *FileStructureInfo) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+// Get file structure response
+type GetFileStructureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          []*FileStructureInfo   `protobuf:"bytes,1,rep,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileStructureResponse) Reset() {
+	*x = GetFileStructureResponse{}
+	mi := &file_api_codegraph_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileStructureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileStructureResponse) ProtoMessage() {}
+
+func (x *GetFileStructureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileStructureResponse.ProtoReflect.Descriptor instead.
+func (*GetFileStructureResponse) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetFileStructureResponse) GetList() []*FileStructureInfo {
+	if x != nil {
+		return x.List
+	}
+	return nil
+}
+
+// Export index request
+type ExportIndexRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`             // Client ID
+	CodebasePath  string                 `protobuf:"bytes,2,opt,name=codebase_path,json=codebasePath,proto3" json:"codebase_path,omitempty"` // Codebase absolute path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportIndexRequest) Reset() {
+	*x = ExportIndexRequest{}
+	mi := &file_api_codegraph_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportIndexRequest) ProtoMessage() {}
+
+func (x *ExportIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportIndexRequest.ProtoReflect.Descriptor instead.
+func (*ExportIndexRequest) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExportIndexRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ExportIndexRequest) GetCodebasePath() string {
+	if x != nil {
+		return x.CodebasePath
+	}
+	return ""
+}
+
+// Export index entry, value is codegraphpb.FileElementTable or codegraphpb.SymbolOccurrence in protobuf wire format
+type IndexEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          IndexEntry_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=codebase_syncer.IndexEntry_Kind" json:"kind,omitempty"` // Entry kind
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                                         // Storage key
+	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`                                     // Encoded value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexEntry) Reset() {
+	*x = IndexEntry{}
+	mi := &file_api_codegraph_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexEntry) ProtoMessage() {}
+
+func (x *IndexEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexEntry.ProtoReflect.Descriptor instead.
+func (*IndexEntry) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *IndexEntry) GetKind() IndexEntry_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return IndexEntry_UNKNOWN
+}
+
+func (x *IndexEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *IndexEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// Watch index status request
+type WatchIndexStatusRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ClientId       string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`                    // Client ID
+	Workspace      string                 `protobuf:"bytes,2,opt,name=workspace,proto3" json:"workspace,omitempty"`                                  // Workspace path
+	IntervalMillis int32                  `protobuf:"varint,3,opt,name=interval_millis,json=intervalMillis,proto3" json:"interval_millis,omitempty"` // Check interval, 0 means default
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WatchIndexStatusRequest) Reset() {
+	*x = WatchIndexStatusRequest{}
+	mi := &file_api_codegraph_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchIndexStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchIndexStatusRequest) ProtoMessage() {}
+
+func (x *WatchIndexStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchIndexStatusRequest.ProtoReflect.Descriptor instead.
+func (*WatchIndexStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchIndexStatusRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *WatchIndexStatusRequest) GetWorkspace() string {
+	if x != nil {
+		return x.Workspace
+	}
+	return ""
+}
+
+func (x *WatchIndexStatusRequest) GetIntervalMillis() int32 {
+	if x != nil {
+		return x.IntervalMillis
+	}
+	return 0
+}
+
+// Index status
+type IndexStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                                  // Status: pending,running,success,failed
+	Process       float32                `protobuf:"fixed32,2,opt,name=process,proto3" json:"process,omitempty"`                              // Progress
+	TotalFiles    int32                  `protobuf:"varint,3,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`       // Total files
+	TotalSucceed  int32                  `protobuf:"varint,4,opt,name=total_succeed,json=totalSucceed,proto3" json:"total_succeed,omitempty"` // Succeed files
+	TotalFailed   int32                  `protobuf:"varint,5,opt,name=total_failed,json=totalFailed,proto3" json:"total_failed,omitempty"`    // Failed files
+	TotalChunks   int32                  `protobuf:"varint,6,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`    // Total chunks (embedding only)
+	FailedReason  string                 `protobuf:"bytes,7,opt,name=failed_reason,json=failedReason,proto3" json:"failed_reason,omitempty"`  // Failed reason
+	FailedFiles   []string               `protobuf:"bytes,8,rep,name=failed_files,json=failedFiles,proto3" json:"failed_files,omitempty"`     // Failed files
+	ProcessTs     int64                  `protobuf:"varint,9,opt,name=process_ts,json=processTs,proto3" json:"process_ts,omitempty"`          // Process timestamp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexStatus) Reset() {
+	*x = IndexStatus{}
+	mi := &file_api_codegraph_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexStatus) ProtoMessage() {}
+
+func (x *IndexStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexStatus.ProtoReflect.Descriptor instead.
+func (*IndexStatus) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *IndexStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *IndexStatus) GetProcess() float32 {
+	if x != nil {
+		return x.Process
+	}
+	return 0
+}
+
+func (x *IndexStatus) GetTotalFiles() int32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *IndexStatus) GetTotalSucceed() int32 {
+	if x != nil {
+		return x.TotalSucceed
+	}
+	return 0
+}
+
+func (x *IndexStatus) GetTotalFailed() int32 {
+	if x != nil {
+		return x.TotalFailed
+	}
+	return 0
+}
+
+func (x *IndexStatus) GetTotalChunks() int32 {
+	if x != nil {
+		return x.TotalChunks
+	}
+	return 0
+}
+
+func (x *IndexStatus) GetFailedReason() string {
+	if x != nil {
+		return x.FailedReason
+	}
+	return ""
+}
+
+func (x *IndexStatus) GetFailedFiles() []string {
+	if x != nil {
+		return x.FailedFiles
+	}
+	return nil
+}
+
+func (x *IndexStatus) GetProcessTs() int64 {
+	if x != nil {
+		return x.ProcessTs
+	}
+	return 0
+}
+
+// Index status event, sent when status changes
+type IndexStatusEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workspace     string                 `protobuf:"bytes,1,opt,name=workspace,proto3" json:"workspace,omitempty"` // Workspace path
+	Embedding     *IndexStatus           `protobuf:"bytes,2,opt,name=embedding,proto3" json:"embedding,omitempty"` // Embedding index status
+	Codegraph     *IndexStatus           `protobuf:"bytes,3,opt,name=codegraph,proto3" json:"codegraph,omitempty"` // Codegraph index status
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexStatusEvent) Reset() {
+	*x = IndexStatusEvent{}
+	mi := &file_api_codegraph_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexStatusEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexStatusEvent) ProtoMessage() {}
+
+func (x *IndexStatusEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_codegraph_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexStatusEvent.ProtoReflect.Descriptor instead.
+func (*IndexStatusEvent) Descriptor() ([]byte, []int) {
+	return file_api_codegraph_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *IndexStatusEvent) GetWorkspace() string {
+	if x != nil {
+		return x.Workspace
+	}
+	return ""
+}
+
+func (x *IndexStatusEvent) GetEmbedding() *IndexStatus {
+	if x != nil {
+		return x.Embedding
+	}
+	return nil
+}
+
+func (x *IndexStatusEvent) GetCodegraph() *IndexStatus {
+	if x != nil {
+		return x.Codegraph
+	}
+	return nil
+}
+
+var File_api_codegraph_proto protoreflect.FileDescriptor
+
+const file_api_codegraph_proto_rawDesc = "" +
+	"\n" +
+	"\x13api/codegraph.proto\x12\x0fcodebase_syncer\"\x86\x01\n" +
+	"\bPosition\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x01 \x01(\x05R\tstartLine\x12!\n" +
+	"\fstart_column\x18\x02 \x01(\x05R\vstartColumn\x12\x19\n" +
+	"\bend_line\x18\x03 \x01(\x05R\aendLine\x12\x1d\n" +
+	"\n" +
+	"end_column\x18\x04 \x01(\x05R\tendColumn\"\xd5\x01\n" +
+	"\x17SearchDefinitionRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12#\n" +
+	"\rcodebase_path\x18\x02 \x01(\tR\fcodebasePath\x12\x1b\n" +
+	"\tfile_path\x18\x03 \x01(\tR\bfilePath\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x04 \x01(\x05R\tstartLine\x12\x19\n" +
+	"\bend_line\x18\x05 \x01(\x05R\aendLine\x12!\n" +
+	"\fcode_snippet\x18\x06 \x01(\tR\vcodeSnippet\"\xa6\x01\n" +
+	"\x0eDefinitionInfo\x12\x1b\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x125\n" +
+	"\bposition\x18\x05 \x01(\v2\x19.codebase_syncer.PositionR\bposition\"O\n" +
+	"\x18SearchDefinitionResponse\x123\n" +
+	"\x04list\x18\x01 \x03(\v2\x1f.codebase_syncer.DefinitionInfoR\x04list\"\xd2\x01\n" +
+	"\x16SearchReferenceRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12#\n" +
+	"\rcodebase_path\x18\x02 \x01(\tR\fcodebasePath\x12\x1b\n" +
+	"\tfile_path\x18\x03 \x01(\tR\bfilePath\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x04 \x01(\x05R\tstartLine\x12\x19\n" +
+	"\bend_line\x18\x05 \x01(\x05R\aendLine\x12\x1f\n" +
+	"\vsymbol_name\x18\x06 \x01(\tR\n" +
+	"symbolName\"\xf5\x01\n" +
+	"\fRelationNode\x12\x1b\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x1f\n" +
+	"\vsymbol_name\x18\x02 \x01(\tR\n" +
+	"symbolName\x125\n" +
+	"\bposition\x18\x03 \x01(\v2\x19.codebase_syncer.PositionR\bposition\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1b\n" +
+	"\tnode_type\x18\x05 \x01(\tR\bnodeType\x129\n" +
+	"\bchildren\x18\x06 \x03(\v2\x1d.codebase_syncer.RelationNodeR\bchildren\"L\n" +
+	"\x17SearchReferenceResponse\x121\n" +
+	"\x04list\x18\x01 \x03(\v2\x1d.codebase_syncer.RelationNodeR\x04list\"\x8e\x01\n" +
+	"\x17GetFileStructureRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12#\n" +
+	"\rcodebase_path\x18\x02 \x01(\tR\fcodebasePath\x12\x1b\n" +
+	"\tfile_path\x18\x03 \x01(\tR\bfilePath\x12\x14\n" +
+	"\x05types\x18\x04 \x03(\tR\x05types\"\x8c\x01\n" +
+	"\x11FileStructureInfo\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x125\n" +
+	"\bposition\x18\x03 \x01(\v2\x19.codebase_syncer.PositionR\bposition\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\"R\n" +
+	"\x18GetFileStructureResponse\x126\n" +
+	"\x04list\x18\x01 \x03(\v2\".codebase_syncer.FileStructureInfoR\x04list\"V\n" +
+	"\x12ExportIndexRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12#\n" +
+	"\rcodebase_path\x18\x02 \x01(\tR\fcodebasePath\"\xae\x01\n" +
+	"\n" +
+	"IndexEntry\x124\n" +
+	"\x04kind\x18\x01 \x01(\x0e2 .codebase_syncer.IndexEntry.KindR\x04kind\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\"B\n" +
+	"\x04Kind\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\x16\n" +
+	"\x12FILE_ELEMENT_TABLE\x10\x01\x12\x15\n" +
+	"\x11SYMBOL_OCCURRENCE\x10\x02\"}\n" +
+	"\x17WatchIndexStatusRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x1c\n" +
+	"\tworkspace\x18\x02 \x01(\tR\tworkspace\x12'\n" +
+	"\x0finterval_millis\x18\x03 \x01(\x05R\x0eintervalMillis\"\xb2\x02\n" +
+	"\vIndexStatus\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\aprocess\x18\x02 \x01(\x02R\aprocess\x12\x1f\n" +
+	"\vtotal_files\x18\x03 \x01(\x05R\n" +
+	"totalFiles\x12#\n" +
+	"\rtotal_succeed\x18\x04 \x01(\x05R\ftotalSucceed\x12!\n" +
+	"\ftotal_failed\x18\x05 \x01(\x05R\vtotalFailed\x12!\n" +
+	"\ftotal_chunks\x18\x06 \x01(\x05R\vtotalChunks\x12#\n" +
+	"\rfailed_reason\x18\a \x01(\tR\ffailedReason\x12!\n" +
+	"\ffailed_files\x18\b \x03(\tR\vfailedFiles\x12\x1d\n" +
+	"\n" +
+	"process_ts\x18\t \x01(\x03R\tprocessTs\"\xa8\x01\n" +
+	"\x10IndexStatusEvent\x12\x1c\n" +
+	"\tworkspace\x18\x01 \x01(\tR\tworkspace\x12:\n" +
+	"\tembedding\x18\x02 \x01(\v2\x1c.codebase_syncer.IndexStatusR\tembedding\x12:\n" +
+	"\tcodegraph\x18\x03 \x01(\v2\x1c.codebase_syncer.IndexStatusR\tcodegraph2\x80\x04\n" +
+	"\x10CodeGraphService\x12g\n" +
+	"\x10SearchDefinition\x12(.codebase_syncer.SearchDefinitionRequest\x1a).codebase_syncer.SearchDefinitionResponse\x12d\n" +
+	"\x0fSearchReference\x12'.codebase_syncer.SearchReferenceRequest\x1a(.codebase_syncer.SearchReferenceResponse\x12g\n" +
+	"\x10GetFileStructure\x12(.codebase_syncer.GetFileStructureRequest\x1a).codebase_syncer.GetFileStructureResponse\x12Q\n" +
+	"\vExportIndex\x12#.codebase_syncer.ExportIndexRequest\x1a\x1b.codebase_syncer.IndexEntry0\x01\x12a\n" +
+	"\x10WatchIndexStatus\x12(.codebase_syncer.WatchIndexStatusRequest\x1a!.codebase_syncer.IndexStatusEvent0\x01B\x14Z\x12./;codebase_syncerb\x06proto3"
+
+var (
+	file_api_codegraph_proto_rawDescOnce sync.Once
+	file_api_codegraph_proto_rawDescData []byte
+)
+
+func file_api_codegraph_proto_rawDescGZIP() []byte {
+	file_api_codegraph_proto_rawDescOnce.Do(func() {
+		file_api_codegraph_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_codegraph_proto_rawDesc), len(file_api_codegraph_proto_rawDesc)))
+	})
+	return file_api_codegraph_proto_rawDescData
+}
+
+var file_api_codegraph_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_codegraph_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_api_codegraph_proto_goTypes = []any{
+	(IndexEntry_Kind)(0),             // 0: codebase_syncer.IndexEntry.Kind
+	(*Position)(nil),                 // 1: codebase_syncer.Position
+	(*SearchDefinitionRequest)(nil),  // 2: codebase_syncer.SearchDefinitionRequest
+	(*DefinitionInfo)(nil),           // 3: codebase_syncer.DefinitionInfo
+	(*SearchDefinitionResponse)(nil), // 4: codebase_syncer.SearchDefinitionResponse
+	(*SearchReferenceRequest)(nil),   // 5: codebase_syncer.SearchReferenceRequest
+	(*RelationNode)(nil),             // 6: codebase_syncer.RelationNode
+	(*SearchReferenceResponse)(nil),  // 7: codebase_syncer.SearchReferenceResponse
+	(*GetFileStructureRequest)(nil),  // 8: codebase_syncer.GetFileStructureRequest
+	(*FileStructureInfo)(nil),        // 9: codebase_syncer.FileStructureInfo
+	(*GetFileStructureResponse)(nil), // 10: codebase_syncer.GetFileStructureResponse
+	(*ExportIndexRequest)(nil),       // 11: codebase_syncer.ExportIndexRequest
+	(*IndexEntry)(nil),               // 12: codebase_syncer.IndexEntry
+	(*WatchIndexStatusRequest)(nil),  // 13: codebase_syncer.WatchIndexStatusRequest
+	(*IndexStatus)(nil),              // 14: codebase_syncer.IndexStatus
+	(*IndexStatusEvent)(nil),         // 15: codebase_syncer.IndexStatusEvent
+}
+var file_api_codegraph_proto_depIdxs = []int32{
+	1,  // 0: codebase_syncer.DefinitionInfo.position:type_name -> codebase_syncer.Position
+	3,  // 1: codebase_syncer.SearchDefinitionResponse.list:type_name -> codebase_syncer.DefinitionInfo
+	1,  // 2: codebase_syncer.RelationNode.position:type_name -> codebase_syncer.Position
+	6,  // 3: codebase_syncer.RelationNode.children:type_name -> codebase_syncer.RelationNode
+	6,  // 4: codebase_syncer.SearchReferenceResponse.list:type_name -> codebase_syncer.RelationNode
+	1,  // 5: codebase_syncer.FileStructureInfo.position:type_name -> codebase_syncer.Position
+	9,  // 6: codebase_syncer.GetFileStructureResponse.list:type_name -> codebase_syncer.FileStructureInfo
+	0,  // 7: codebase_syncer.IndexEntry.kind:type_name -> codebase_syncer.IndexEntry.Kind
+	14, // 8: codebase_syncer.IndexStatusEvent.embedding:type_name -> codebase_syncer.IndexStatus
+	14, // 9: codebase_syncer.IndexStatusEvent.codegraph:type_name -> codebase_syncer.IndexStatus
+	2,  // 10: codebase_syncer.CodeGraphService.SearchDefinition:input_type -> codebase_syncer.SearchDefinitionRequest
+	5,  // 11: codebase_syncer.CodeGraphService.SearchReference:input_type -> codebase_syncer.SearchReferenceRequest
+	8,  // 12: codebase_syncer.CodeGraphService.GetFileStructure:input_type -> codebase_syncer.GetFileStructureRequest
+	11, // 13: codebase_syncer.CodeGraphService.ExportIndex:input_type -> codebase_syncer.ExportIndexRequest
+	13, // 14: codebase_syncer.CodeGraphService.WatchIndexStatus:input_type -> codebase_syncer.WatchIndexStatusRequest
+	4,  // 15: codebase_syncer.CodeGraphService.SearchDefinition:output_type -> codebase_syncer.SearchDefinitionResponse
+	7,  // 16: codebase_syncer.CodeGraphService.SearchReference:output_type -> codebase_syncer.SearchReferenceResponse
+	10, // 17: codebase_syncer.CodeGraphService.GetFileStructure:output_type -> codebase_syncer.GetFileStructureResponse
+	12, // 18: codebase_syncer.CodeGraphService.ExportIndex:output_type -> codebase_syncer.IndexEntry
+	15, // 19: codebase_syncer.CodeGraphService.WatchIndexStatus:output_type -> codebase_syncer.IndexStatusEvent
+	15, // [15:20] is the sub-list for method output_type
+	10, // [10:15] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_api_codegraph_proto_init() }
+func file_api_codegraph_proto_init() {
+	if File_api_codegraph_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_codegraph_proto_rawDesc), len(file_api_codegraph_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_codegraph_proto_goTypes,
+		DependencyIndexes: file_api_codegraph_proto_depIdxs,
+		EnumInfos:         file_api_codegraph_proto_enumTypes,
+		MessageInfos:      file_api_codegraph_proto_msgTypes,
+	}.Build()
+	File_api_codegraph_proto = out.File
+	file_api_codegraph_proto_goTypes = nil
+	file_api_codegraph_proto_depIdxs = nil
+}
@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.31.0
+// source: api/codegraph.proto
+
+package codebase_syncer
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CodeGraphService_SearchDefinition_FullMethodName = "/codebase_syncer.CodeGraphService/SearchDefinition"
+	CodeGraphService_SearchReference_FullMethodName  = "/codebase_syncer.CodeGraphService/SearchReference"
+	CodeGraphService_GetFileStructure_FullMethodName = "/codebase_syncer.CodeGraphService/GetFileStructure"
+	CodeGraphService_ExportIndex_FullMethodName      = "/codebase_syncer.CodeGraphService/ExportIndex"
+	CodeGraphService_WatchIndexStatus_FullMethodName = "/codebase_syncer.CodeGraphService/WatchIndexStatus"
+)
+
+// CodeGraphServiceClient is the client API for CodeGraphService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Code graph query service definition
+type CodeGraphServiceClient interface {
+	// Search symbol definitions
+	SearchDefinition(ctx context.Context, in *SearchDefinitionRequest, opts ...grpc.CallOption) (*SearchDefinitionResponse, error)
+	// Search symbol references
+	SearchReference(ctx context.Context, in *SearchReferenceRequest, opts ...grpc.CallOption) (*SearchReferenceResponse, error)
+	// Get file structure
+	GetFileStructure(ctx context.Context, in *GetFileStructureRequest, opts ...grpc.CallOption) (*GetFileStructureResponse, error)
+	// Export index entries of codebase
+	ExportIndex(ctx context.Context, in *ExportIndexRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IndexEntry], error)
+	// Watch index status changes of workspace
+	WatchIndexStatus(ctx context.Context, in *WatchIndexStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IndexStatusEvent], error)
+}
+
+type codeGraphServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCodeGraphServiceClient(cc grpc.ClientConnInterface) CodeGraphServiceClient {
+	return &codeGraphServiceClient{cc}
+}
+
+func (c *codeGraphServiceClient) SearchDefinition(ctx context.Context, in *SearchDefinitionRequest, opts ...grpc.CallOption) (*SearchDefinitionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchDefinitionResponse)
+	err := c.cc.Invoke(ctx, CodeGraphService_SearchDefinition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeGraphServiceClient) SearchReference(ctx context.Context, in *SearchReferenceRequest, opts ...grpc.CallOption) (*SearchReferenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchReferenceResponse)
+	err := c.cc.Invoke(ctx, CodeGraphService_SearchReference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeGraphServiceClient) GetFileStructure(ctx context.Context, in *GetFileStructureRequest, opts ...grpc.CallOption) (*GetFileStructureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFileStructureResponse)
+	err := c.cc.Invoke(ctx, CodeGraphService_GetFileStructure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeGraphServiceClient) ExportIndex(ctx context.Context, in *ExportIndexRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IndexEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CodeGraphService_ServiceDesc.Streams[0], CodeGraphService_ExportIndex_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportIndexRequest, IndexEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CodeGraphService_ExportIndexClient = grpc.ServerStreamingClient[IndexEntry]
+
+func (c *codeGraphServiceClient) WatchIndexStatus(ctx context.Context, in *WatchIndexStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IndexStatusEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CodeGraphService_ServiceDesc.Streams[1], CodeGraphService_WatchIndexStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchIndexStatusRequest, IndexStatusEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CodeGraphService_WatchIndexStatusClient = grpc.ServerStreamingClient[IndexStatusEvent]
+
+// CodeGraphServiceServer is the server API for CodeGraphService service.
+// All implementations must embed UnimplementedCodeGraphServiceServer
+// for forward compatibility.
+//
+// Code graph query service definition
+type CodeGraphServiceServer interface {
+	// Search symbol definitions
+	SearchDefinition(context.Context, *SearchDefinitionRequest) (*SearchDefinitionResponse, error)
+	// Search symbol references
+	SearchReference(context.Context, *SearchReferenceRequest) (*SearchReferenceResponse, error)
+	// Get file structure
+	GetFileStructure(context.Context, *GetFileStructureRequest) (*GetFileStructureResponse, error)
+	// Export index entries of codebase
+	ExportIndex(*ExportIndexRequest, grpc.ServerStreamingServer[IndexEntry]) error
+	// Watch index status changes of workspace
+	WatchIndexStatus(*WatchIndexStatusRequest, grpc.ServerStreamingServer[IndexStatusEvent]) error
+	mustEmbedUnimplementedCodeGraphServiceServer()
+}
+
+// UnimplementedCodeGraphServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCodeGraphServiceServer struct{}
+
+func (UnimplementedCodeGraphServiceServer) SearchDefinition(context.Context, *SearchDefinitionRequest) (*SearchDefinitionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchDefinition not implemented")
+}
+func (UnimplementedCodeGraphServiceServer) SearchReference(context.Context, *SearchReferenceRequest) (*SearchReferenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchReference not implemented")
+}
+func (UnimplementedCodeGraphServiceServer) GetFileStructure(context.Context, *GetFileStructureRequest) (*GetFileStructureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileStructure not implemented")
+}
+func (UnimplementedCodeGraphServiceServer) ExportIndex(*ExportIndexRequest, grpc.ServerStreamingServer[IndexEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportIndex not implemented")
+}
+func (UnimplementedCodeGraphServiceServer) WatchIndexStatus(*WatchIndexStatusRequest, grpc.ServerStreamingServer[IndexStatusEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchIndexStatus not implemented")
+}
+func (UnimplementedCodeGraphServiceServer) mustEmbedUnimplementedCodeGraphServiceServer() {}
+func (UnimplementedCodeGraphServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeCodeGraphServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CodeGraphServiceServer will
+// result in compilation errors.
+type UnsafeCodeGraphServiceServer interface {
+	mustEmbedUnimplementedCodeGraphServiceServer()
+}
+
+func RegisterCodeGraphServiceServer(s grpc.ServiceRegistrar, srv CodeGraphServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCodeGraphServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CodeGraphService_ServiceDesc, srv)
+}
+
+func _CodeGraphService_SearchDefinition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchDefinitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeGraphServiceServer).SearchDefinition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeGraphService_SearchDefinition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeGraphServiceServer).SearchDefinition(ctx, req.(*SearchDefinitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeGraphService_SearchReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchReferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeGraphServiceServer).SearchReference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeGraphService_SearchReference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeGraphServiceServer).SearchReference(ctx, req.(*SearchReferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeGraphService_GetFileStructure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileStructureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeGraphServiceServer).GetFileStructure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeGraphService_GetFileStructure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeGraphServiceServer).GetFileStructure(ctx, req.(*GetFileStructureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeGraphService_ExportIndex_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportIndexRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CodeGraphServiceServer).ExportIndex(m, &grpc.GenericServerStream[ExportIndexRequest, IndexEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CodeGraphService_ExportIndexServer = grpc.ServerStreamingServer[IndexEntry]
+
+func _CodeGraphService_WatchIndexStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchIndexStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CodeGraphServiceServer).WatchIndexStatus(m, &grpc.GenericServerStream[WatchIndexStatusRequest, IndexStatusEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CodeGraphService_WatchIndexStatusServer = grpc.ServerStreamingServer[IndexStatusEvent]
+
+// CodeGraphService_ServiceDesc is the grpc.ServiceDesc for CodeGraphService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CodeGraphService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "codebase_syncer.CodeGraphService",
+	HandlerType: (*CodeGraphServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchDefinition",
+			Handler:    _CodeGraphService_SearchDefinition_Handler,
+		},
+		{
+			MethodName: "SearchReference",
+			Handler:    _CodeGraphService_SearchReference_Handler,
+		},
+		{
+			MethodName: "GetFileStructure",
+			Handler:    _CodeGraphService_GetFileStructure_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportIndex",
+			Handler:       _CodeGraphService_ExportIndex_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchIndexStatus",
+			Handler:       _CodeGraphService_WatchIndexStatus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/codegraph.proto",
+}
@@ -8,17 +8,17 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"runtime"
-
-	// "net"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"os/signal"
+	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
-	// api "codebase-indexer/api"
+	api "codebase-indexer/api"
 	"codebase-indexer/internal/config"
 	"codebase-indexer/internal/daemon"
 	"codebase-indexer/internal/database"
@@ -32,9 +32,12 @@ import (
 	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
 	"codebase-indexer/pkg/codegraph/parser"
 	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/textindex"
+	"codebase-indexer/pkg/codegraph/vector"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
-	// "google.golang.org/grpc"
+
+	"google.golang.org/grpc"
 )
 
 var (
@@ -57,7 +60,7 @@ func main() {
 
 	// Parse command line arguments
 	appName := flag.String("appname", "codebase-indexer", "app name")
-	// grpcServer := flag.String("grpc", "localhost:51353", "gRPC server address")
+	grpcServer := flag.String("grpc", "localhost:51353", "gRPC server address")
 	httpServer := flag.String("http", "localhost:11380", "HTTP server address")
 	logLevel := flag.String("loglevel", "info", "log level (debug, info, warn, error)")
 	enableSwagger := flag.Bool("swagger", false, "enable swagger documentation")
@@ -80,6 +83,7 @@ func main() {
 	clientConfig := config.GetClientConfig()
 	clientConfig.Pprof.Enabled = *enablePprof
 	clientConfig.Pprof.Address = *pprofAddr
+	applyEmbeddingEnv(&clientConfig.Embedding)
 	config.SetClientConfig(clientConfig)
 	// syncConfig
 	authInfo := config.GetAuthInfo()
@@ -148,6 +152,43 @@ func main() {
 		}
 	}(codegraphStore)
 
+	// 创建全文索引
+	textIndex, err := textindex.NewIndex(utils.TextIndexDir, appLogger)
+	if err != nil {
+		appLogger.Fatal("failed to initialize text index: %v", err)
+		return
+	}
+	defer func(textIndex *textindex.Index) {
+		if err := textIndex.Close(); err != nil {
+			appLogger.Error("failed to close text index: %v", err)
+		}
+	}(textIndex)
+
+	// 创建本地向量索引，配置了向量化服务时启用
+	var vectorStore *vector.Store
+	if embeddingConfig := config.GetClientConfig().Embedding; embeddingConfig.Provider != types.EmptyString {
+		provider, err := vector.NewProvider(vector.ProviderConfig{
+			Provider:   embeddingConfig.Provider,
+			BaseURL:    embeddingConfig.BaseURL,
+			Model:      embeddingConfig.Model,
+			ApiKey:     embeddingConfig.ApiKey,
+			Dimensions: embeddingConfig.Dimensions,
+			BatchSize:  embeddingConfig.BatchSize,
+		})
+		if err == nil {
+			vectorStore, err = vector.NewStore(utils.EmbeddingDir, provider, appLogger)
+		}
+		if err != nil {
+			appLogger.Error("failed to initialize vector store, local semantic search disabled: %v", err)
+		} else {
+			defer func(vectorStore *vector.Store) {
+				if err := vectorStore.Close(); err != nil {
+					appLogger.Error("failed to close vector store: %v", err)
+				}
+			}(vectorStore)
+		}
+	}
+
 	// 创建工作区读取器
 	workspaceReader := workspace.NewWorkSpaceReader(appLogger)
 
@@ -160,11 +201,13 @@ func main() {
 	dependencyAnalyzer := analyzer.NewDependencyAnalyzer(appLogger, packageClassifier, workspaceReader, codegraphStore)
 
 	indexer := service.NewCodeIndexer(scanRepo, sourceFileParser, dependencyAnalyzer, workspaceReader, codegraphStore,
-		workspaceRepo, service.IndexerConfig{VisitPattern: workspace.DefaultVisitPattern}, appLogger)
+		textIndex, vectorStore, workspaceRepo, service.IndexerConfig{VisitPattern: workspace.DefaultVisitPattern}, appLogger)
 
 	codegraphProcessor := service.NewCodegraphProcessor(workspaceReader, indexer, workspaceRepo, eventRepo, appLogger)
 	codebaseService := service.NewCodebaseService(storageManager, appLogger, workspaceReader, workspaceRepo, definition.NewDefinitionParser(), indexer)
+	contextService := service.NewContextService(storageManager, workspaceReader, indexer, definition.NewDefinitionParser(), service.DefaultTokenEstimator, appLogger)
 	extensionService := service.NewExtensionService(storageManager, syncRepo, scanRepo, workspaceRepo, eventRepo, codebaseEmbeddingRepo, codebaseService, fileScanService, appLogger)
+	fileWatchService := service.NewFileWatchService(extensionService, fileScanService, scanRepo, storageManager, eventRepo, appLogger)
 
 	// Initialize job layer
 	fileScanJob := job.NewFileScanJob(fileScanService, storageManager, syncRepo, appLogger, 5*time.Minute)
@@ -172,19 +215,22 @@ func main() {
 	statusCheckerJob := job.NewStatusCheckerJob(embeddingStatusService, storageManager, syncRepo, appLogger, 5*time.Second)
 	eventCleanerJob := job.NewEventCleanerJob(eventRepo, appLogger)
 	indexCleanJob := job.NewIndexCleanJob(appLogger, indexer, workspaceRepo)
+	fileWatchJob := job.NewFileWatchJob(fileWatchService, fileScanService, storageManager, appLogger, 10*time.Second)
 	// Initialize handler layer
-	// grpcHandler := handler.NewGRPCHandler(syncRepo, scanRepo, storageManager, schedulerService, appLogger)
+	grpcHandler := handler.NewGRPCHandler(syncRepo, scanRepo, storageManager, schedulerService, appLogger)
+	codegraphHandler := handler.NewCodeGraphHandler(codebaseService, extensionService, appLogger)
 	extensionHandler := handler.NewExtensionHandler(extensionService, appLogger)
-	backendHandler := handler.NewBackendHandler(codebaseService, appLogger)
+	backendHandler := handler.NewBackendHandler(codebaseService, contextService, appLogger)
 
 	// Initialize gRPC server
-	// lis, err := net.Listen("tcp", *grpcServer)
-	// if err != nil {
-	// 	appLogger.Fatal("failed to listen: %v", err)
-	// 	return
-	// }
-	// s := grpc.NewServer()
-	// api.RegisterSyncServiceServer(s, grpcHandler)
+	lis, err := net.Listen("tcp", *grpcServer)
+	if err != nil {
+		appLogger.Fatal("failed to listen: %v", err)
+		return
+	}
+	s := grpc.NewServer()
+	api.RegisterSyncServiceServer(s, grpcHandler)
+	api.RegisterCodeGraphServiceServer(s, codegraphHandler)
 
 	// Initialize HTTP server
 	httpServerInstance := server.NewServer(extensionHandler, backendHandler, appLogger)
@@ -194,9 +240,8 @@ func main() {
 	}
 
 	// Start daemonProcess process
-	// daemonProcess := daemonProcess.NewDaemon(syncScheduler, s, lis, httpSync, fileScanner, storageManager, appLogger)
-	daemonProcess := daemon.NewDaemon(schedulerService, syncRepo, scanRepo, storageManager, appLogger,
-		fileScanJob, eventProcessorJob, statusCheckerJob, indexCleanJob, eventCleanerJob)
+	daemonProcess := daemon.NewDaemon(schedulerService, s, lis, syncRepo, scanRepo, storageManager, appLogger,
+		fileScanJob, eventProcessorJob, statusCheckerJob, indexCleanJob, eventCleanerJob, fileWatchJob)
 	go daemonProcess.Start()
 
 	// Start pprof server if enabled
@@ -321,6 +366,13 @@ func initDir(appName string) error {
 	}
 	fmt.Printf("index directory: %s\n", indexPath)
 
+	// Initialize text index directory
+	textIndexPath, err := utils.GetCacheTextIndexDir(cachePath)
+	if err != nil {
+		return fmt.Errorf("failed to get text index directory: %v", err)
+	}
+	fmt.Printf("text index directory: %s\n", textIndexPath)
+
 	// Initialize cache db directory
 	cacheDbPath, err := utils.GetCacheDbDir(cachePath)
 	if err != nil {
@@ -352,6 +404,27 @@ func initDir(appName string) error {
 	return nil
 }
 
+// applyEmbeddingEnv overrides local embedding configuration from environment variables
+func applyEmbeddingEnv(cfg *config.ConfigEmbedding) {
+	if val, ok := os.LookupEnv("EMBEDDING_PROVIDER"); ok {
+		cfg.Provider = val
+	}
+	if val, ok := os.LookupEnv("EMBEDDING_BASE_URL"); ok {
+		cfg.BaseURL = val
+	}
+	if val, ok := os.LookupEnv("EMBEDDING_MODEL"); ok {
+		cfg.Model = val
+	}
+	if val, ok := os.LookupEnv("EMBEDDING_API_KEY"); ok {
+		cfg.ApiKey = val
+	}
+	if val, ok := os.LookupEnv("EMBEDDING_DIMENSIONS"); ok {
+		if dimensions, err := strconv.Atoi(val); err == nil && dimensions > 0 {
+			cfg.Dimensions = dimensions
+		}
+	}
+}
+
 // initConfig initializes configuration
 func initConfig(appName string) error {
 	// Set app info
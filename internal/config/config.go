@@ -32,11 +32,22 @@ type ConfigPprof struct {
 	Address string `json:"address"`
 }
 
+// Local embedding provider configuration
+type ConfigEmbedding struct {
+	Provider   string `json:"provider"` // openai or fake, empty disables local semantic search
+	BaseURL    string `json:"baseURL"`  // OpenAI-compatible endpoint, e.g. http://localhost:11434/v1
+	Model      string `json:"model"`
+	ApiKey     string `json:"apiKey"`
+	Dimensions int    `json:"dimensions"`
+	BatchSize  int    `json:"batchSize"`
+}
+
 // Client configuration file structure
 type ClientConfig struct {
-	Server ConfigServer `json:"server"`
-	Sync   ConfigSync   `json:"sync"`
-	Pprof  ConfigPprof  `json:"pprof"`
+	Server    ConfigServer    `json:"server"`
+	Sync      ConfigSync      `json:"sync"`
+	Pprof     ConfigPprof     `json:"pprof"`
+	Embedding ConfigEmbedding `json:"embedding"`
 }
 
 var DefaultConfigServer = ConfigServer{
@@ -75,6 +86,9 @@ var DefaultFileIgnorePatterns = []string{
 	"*.doc", "*.docx", "*.xls", "*.xlsx", "*.ppt", "*.pptx",
 	"*.rtf", "*.psd", "*.pbix",
 	"*.odt", "*.ods", "*.odp", // OpenDocument formats
+	// Lockfiles and minified bundles
+	"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock", "poetry.lock", "composer.lock",
+	"*.min.js", "*.min.css",
 }
 
 var DefaultFolderIgnorePatterns = []string{
@@ -108,11 +122,17 @@ var DefaultConfigPprof = ConfigPprof{
 	Address: "localhost:6060", // Default pprof address
 }
 
+// Default local embedding configuration
+var DefaultConfigEmbedding = ConfigEmbedding{
+	BatchSize: 16, // Default texts per embedding request
+}
+
 // Default client configuration
 var DefaultClientConfig = ClientConfig{
-	Server: DefaultConfigServer,
-	Sync:   DefaultConfigSync,
-	Pprof:  DefaultConfigPprof,
+	Server:    DefaultConfigServer,
+	Sync:      DefaultConfigSync,
+	Pprof:     DefaultConfigPprof,
+	Embedding: DefaultConfigEmbedding,
 }
 
 // Global client configuration
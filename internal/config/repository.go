@@ -34,6 +34,13 @@ type CodebaseConfig struct {
 	HashTree     map[string]string `json:"hashTree"`
 	LastSync     time.Time         `json:"lastSync"`
 	RegisterTime time.Time         `json:"registerTime"`
+	Watch        *WatchConfig      `json:"watch,omitempty"`
+}
+
+// WatchConfig 工作区文件监听配置，未配置时不监听，需显式开启
+type WatchConfig struct {
+	Enabled    bool `json:"enabled"`
+	DebounceMs int  `json:"debounceMs"` // 事件合并的静默时长，文件不再变化后再生成事件
 }
 
 // Codebase embedding config
@@ -54,4 +61,7 @@ type IgnoreConfig struct {
 	IncludeRules []string
 	MaxFileCount int
 	MaxFileSize  int
+	// IncludesUnder reports whether include patterns of .codebase-indexer.yaml may match paths
+	// under an ignored dir (relative to the codebase), so that the dir is walked instead of skipped
+	IncludesUnder func(relDir string) bool
 }
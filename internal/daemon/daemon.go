@@ -3,23 +3,23 @@ package daemon
 
 import (
 	"context"
-	"time"
-
-	// "net"
+	"net"
 	"sync"
+	"time"
 
 	"codebase-indexer/internal/config"
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/internal/service"
 	"codebase-indexer/internal/utils"
 	"codebase-indexer/pkg/logger"
-	// "google.golang.org/grpc"
+
+	"google.golang.org/grpc"
 )
 
 type Daemon struct {
-	scheduler *service.Scheduler
-	// grpcServer  *grpc.Server
-	// grpcListen  net.Listener
+	scheduler   *service.Scheduler
+	grpcServer  *grpc.Server
+	grpcListen  net.Listener
 	httpSync    repository.SyncInterface
 	fileScanner repository.ScannerInterface
 	storage     repository.StorageInterface
@@ -36,17 +36,14 @@ type Daemon struct {
 	jobs []Job
 }
 
-// func NewDaemon(scheduler *scheduler.Scheduler, grpcServer *grpc.Server, grpcListen net.Listener,
-//
-//	httpSync syncer.SyncInterface, fileScanner scanner.ScannerInterface, storage storage.SotrageInterface, logger logger.Logger) *Daemon {
-func NewDaemon(scheduler *service.Scheduler, httpSync repository.SyncInterface,
+func NewDaemon(scheduler *service.Scheduler, grpcServer *grpc.Server, grpcListen net.Listener, httpSync repository.SyncInterface,
 	fileScanner repository.ScannerInterface, storage repository.StorageInterface, logger logger.Logger,
 	jobs ...Job) *Daemon {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Daemon{
-		scheduler: scheduler,
-		// grpcServer:  grpcServer,
-		// grpcListen:  grpcListen,
+		scheduler:   scheduler,
+		grpcServer:  grpcServer,
+		grpcListen:  grpcListen,
 		httpSync:    httpSync,
 		fileScanner: fileScanner,
 		storage:     storage,
@@ -68,13 +65,15 @@ func (d *Daemon) Start() {
 	}
 
 	// Start gRPC server
-	// go func() {
-	// 	d.logger.Info("starting gRPC server, listening on: %s", d.grpcListen.Addr().String())
-	// 	if err := d.grpcServer.Serve(d.grpcListen); err != nil {
-	// 		d.logger.Fatal("gRPC server failed to serve: %v", err)
-	// 		return
-	// 	}
-	// }()
+	if d.grpcServer != nil && d.grpcListen != nil {
+		go func() {
+			d.logger.Info("starting gRPC server, listening on: %s", d.grpcListen.Addr().String())
+			if err := d.grpcServer.Serve(d.grpcListen); err != nil {
+				d.logger.Fatal("gRPC server failed to serve: %v", err)
+				return
+			}
+		}()
+	}
 
 	// Start sync task
 	// d.wg.Add(1)
@@ -286,9 +285,9 @@ func (d *Daemon) Stop() {
 	utils.CleanUploadTmpDir()
 	d.logger.Info("temp directory cleaned up")
 	d.wg.Wait()
-	// if d.grpcServer != nil {
-	// 	d.grpcServer.GracefulStop()
-	// 	d.logger.Info("gRPC service stopped")
-	// }
+	if d.grpcServer != nil {
+		d.grpcServer.GracefulStop()
+		d.logger.Info("gRPC service stopped")
+	}
 	d.logger.Info("daemon process stopped")
 }
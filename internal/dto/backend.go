@@ -1,7 +1,11 @@
 // internal/dto/backend.go - 后端API请求和响应数据结构定义
 package dto
 
-import "codebase-indexer/pkg/codegraph/types"
+import (
+	"codebase-indexer/pkg/codegraph/repomap"
+	"codebase-indexer/pkg/codegraph/textindex"
+	"codebase-indexer/pkg/codegraph/types"
+)
 
 // SearchReferenceRequest 关系检索请求
 type SearchReferenceRequest struct {
@@ -11,6 +15,9 @@ type SearchReferenceRequest struct {
 	StartLine    int    `form:"startLine,omitempty"`
 	EndLine      int    `form:"endLine,omitempty"`
 	SymbolName   string `form:"symbolName,omitempty"`
+	Limit        int    `form:"limit,omitempty"`                                       // 每页引用数，与 cursor 均未指定时不分页
+	Cursor       string `form:"cursor,omitempty"`                                      // 上一页返回的 nextCursor
+	Stream       string `form:"stream,omitempty" binding:"omitempty,oneof=ndjson sse"` // 流式输出模式
 }
 
 // RelationNode 关系节点
@@ -31,17 +38,22 @@ type Position struct {
 }
 
 type ReferenceData struct {
-	List []*types.RelationNode `json:"list"`
+	List       []*types.RelationNode `json:"list"`
+	NextCursor string                `json:"nextCursor,omitempty"`
 }
 
 // SearchDefinitionRequest 获取定义请求
 type SearchDefinitionRequest struct {
-	ClientId     string `form:"clientId" binding:"required"`
-	CodebasePath string `form:"codebasePath" binding:"required"`
-	FilePath     string `form:"filePath" binding:"required"`
-	StartLine    int    `form:"startLine,omitempty"`
-	EndLine      int    `form:"endLine,omitempty"`
-	CodeSnippet  string `form:"codeSnippet,omitempty"`
+	ClientId      string  `form:"clientId" binding:"required"`
+	CodebasePath  string  `form:"codebasePath" binding:"required"`
+	FilePath      string  `form:"filePath" binding:"required"`
+	StartLine     int     `form:"startLine,omitempty"`
+	EndLine       int     `form:"endLine,omitempty"`
+	CodeSnippet   string  `form:"codeSnippet,omitempty"`
+	MinConfidence float64 `form:"minConfidence,omitempty" binding:"omitempty,min=0,max=1"` // 过滤置信度低于该值的候选
+	Limit         int     `form:"limit,omitempty"`
+	Cursor        string  `form:"cursor,omitempty"`
+	Stream        string  `form:"stream,omitempty" binding:"omitempty,oneof=ndjson sse"`
 }
 
 type ReadCodeSnippetsRequest struct {
@@ -69,15 +81,120 @@ type CodeSnippet struct {
 
 // DefinitionInfo 定义信息
 type DefinitionInfo struct {
+	FilePath   string   `json:"filePath"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Content    string   `json:"content,omitempty"`
+	Position   Position `json:"position"`
+	Doc        string   `json:"doc,omitempty"`
+	Signature  string   `json:"signature,omitempty"`
+	Decorators []string `json:"decorators,omitempty"`
+	Container  string   `json:"container,omitempty"`
+	Confidence float64  `json:"confidence"`          // 候选定义的置信度，0~1，按其降序排列
+	Reason     string   `json:"reason,omitempty"`    // 置信度依据
+	Ambiguous  bool     `json:"ambiguous,omitempty"` // 存在多个同样匹配的重载
+}
+
+type DefinitionData struct {
+	List       []*DefinitionInfo `json:"list"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+// HoverRequest 获取光标处符号信息请求，行列从 1 开始，列按字符计
+type HoverRequest struct {
+	ClientId     string `form:"clientId" binding:"required"`
+	CodebasePath string `form:"codebasePath" binding:"required"`
+	FilePath     string `form:"filePath" binding:"required"`
+	Line         int    `form:"line" binding:"required,min=1"`
+	Column       int    `form:"column" binding:"required,min=1"`
+}
+
+// HoverData 光标处的符号及其定义，Definition 为最可能的定义，其余同名候选放在 Candidates
+type HoverData struct {
+	Name       string            `json:"name"`
+	Position   Position          `json:"position"`
+	Definition *DefinitionInfo   `json:"definition,omitempty"`
+	Candidates []*DefinitionInfo `json:"candidates,omitempty"`
+}
+
+const (
+	BatchQueryDefinition = "definition"
+	BatchQueryReference  = "reference"
+	BatchQueryStructure  = "structure"
+)
+
+// SearchBatchRequest 批量检索请求，多个检索共享项目解析及索引读取，并发执行
+type SearchBatchRequest struct {
+	ClientId     string        `json:"clientId" binding:"required"`
+	CodebasePath string        `json:"codebasePath" binding:"required"`
+	Queries      []*BatchQuery `json:"queries" binding:"required,min=1,dive"`
+}
+
+// BatchQuery 单个检索，参数与 /search/definition、/search/reference、/files/structure 一致
+type BatchQuery struct {
+	Id            string   `json:"id,omitempty"` // 调用方自定义标识，原样返回
+	Type          string   `json:"type" binding:"required,oneof=definition reference structure"`
+	FilePath      string   `json:"filePath" binding:"required"`
+	StartLine     int      `json:"startLine,omitempty"`
+	EndLine       int      `json:"endLine,omitempty"`
+	SymbolName    string   `json:"symbolName,omitempty"`
+	CodeSnippet   string   `json:"codeSnippet,omitempty"`
+	Types         []string `json:"types,omitempty"`
+	MinConfidence float64  `json:"minConfidence,omitempty" binding:"omitempty,min=0,max=1"`
+}
+
+// BatchQueryResult 单个检索的结果，按请求顺序返回；Data 与对应单项接口的 data 相同，失败时只返回 Error
+type BatchQueryResult struct {
+	Id    string      `json:"id,omitempty"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// SearchBatchData 批量检索结果
+type SearchBatchData struct {
+	Results []*BatchQueryResult `json:"results"`
+}
+
+// RenameRequest 重命名请求，行列从 1 开始（列按字符计），指向需要重命名的标识符
+type RenameRequest struct {
+	ClientId     string `json:"clientId" binding:"required"`
+	CodebasePath string `json:"codebasePath" binding:"required"`
+	FilePath     string `json:"filePath" binding:"required"`
+	Line         int    `json:"line" binding:"required,min=1"`
+	Column       int    `json:"column" binding:"required,min=1"`
+	NewName      string `json:"newName" binding:"required"`
+}
+
+// TextEditInfo 单处文本替换
+type TextEditInfo struct {
+	Position Position `json:"position"`
+	NewText  string   `json:"newText"`
+}
+
+// FileEdit 单个文件的文本替换，按位置排序
+type FileEdit struct {
+	FilePath string          `json:"filePath"`
+	Edits    []*TextEditInfo `json:"edits"`
+}
+
+// RenameConflictInfo 重命名冲突：ambiguous 存在未被重命名的同名定义，exists 新名称已被定义，shadowing 新名称在文件中已被使用，
+// unresolved 同名引用无法确定是否指向该定义，未被重命名
+type RenameConflictInfo struct {
+	Kind     string   `json:"kind"`
 	FilePath string   `json:"filePath"`
 	Name     string   `json:"name"`
-	Type     string   `json:"type"`
-	Content  string   `json:"content,omitempty"`
 	Position Position `json:"position"`
+	Message  string   `json:"message"`
 }
 
-type DefinitionData struct {
-	List []*DefinitionInfo `json:"list"`
+// RenameData 重命名的工作区编辑及冲突报告，不会修改文件
+type RenameData struct {
+	Name       string                `json:"name"`
+	NewName    string                `json:"newName"`
+	Definition *DefinitionInfo       `json:"definition,omitempty"`
+	Changes    []*FileEdit           `json:"changes"`
+	Conflicts  []*RenameConflictInfo `json:"conflicts,omitempty"`
 }
 
 // GetFileContentRequest 获取文件内容请求
@@ -96,6 +213,8 @@ type GetCodebaseDirectoryRequest struct {
 	Depth        int    `form:"depth,omitempty"`
 	IncludeFiles bool   `form:"includeFiles,omitempty"`
 	SubDir       string `form:"subDir,omitempty"`
+	Limit        int    `form:"limit,omitempty"` // 每页节点数，按先序遍历分页，保留所在目录
+	Cursor       string `form:"cursor,omitempty"`
 }
 
 // DirectoryNode 目录节点
@@ -114,6 +233,7 @@ type DirectoryData struct {
 	TotalFiles    int               `json:"-"`
 	TotalSize     int64             `json:"-"`
 	DirectoryTree []*types.TreeNode `json:"directoryTree"`
+	NextCursor    string            `json:"nextCursor,omitempty"`
 }
 
 // GetFileStructureRequest 获取文件结构请求
@@ -126,16 +246,41 @@ type GetFileStructureRequest struct {
 
 // FileStructureInfo 文件结构信息
 type FileStructureInfo struct {
-	Type     string   `json:"type"`
-	Name     string   `json:"name"`
-	Position Position `json:"position"`
-	Content  string   `json:"content,omitempty"`
+	Type       string   `json:"type"`
+	Name       string   `json:"name"`
+	Position   Position `json:"position"`
+	Content    string   `json:"content,omitempty"`
+	Doc        string   `json:"doc,omitempty"`
+	Signature  string   `json:"signature,omitempty"`
+	Decorators []string `json:"decorators,omitempty"`
 }
 
 type FileStructureData struct {
 	List []*FileStructureInfo `json:"list"`
 }
 
+// UpdateBufferRequest 推送编辑器中未保存文档的内容，文档每次修改时版本号递增
+type UpdateBufferRequest struct {
+	ClientId     string `json:"clientId" binding:"required"`
+	CodebasePath string `json:"codebasePath" binding:"required"`
+	FilePath     string `json:"filePath" binding:"required"`
+	Version      int64  `json:"version" binding:"min=0"`
+	Content      string `json:"content"`
+}
+
+// CloseBufferRequest 关闭文档，丢弃其未保存的内容
+type CloseBufferRequest struct {
+	ClientId     string `form:"clientId" binding:"required"`
+	CodebasePath string `form:"codebasePath" binding:"required"`
+	FilePath     string `form:"filePath" binding:"required"`
+}
+
+// BufferData 覆盖层中文档的版本号，大于请求的版本号时表示请求已过期被忽略
+type BufferData struct {
+	FilePath string `json:"filePath"`
+	Version  int64  `json:"version"`
+}
+
 // GetIndexSummaryRequest 获取索引情况请求
 type GetIndexSummaryRequest struct {
 	ClientId     string `form:"clientId" binding:"required"`
@@ -194,3 +339,177 @@ const (
 	Codegraph = "codegraph"
 	All       = "all"
 )
+
+// AssembleContextRequest 组装上下文请求
+type AssembleContextRequest struct {
+	ClientId     string `json:"clientId" binding:"required"`
+	CodebasePath string `json:"codebasePath" binding:"required"`
+	FilePath     string `json:"filePath" binding:"required"`
+	StartLine    int    `json:"startLine" binding:"required,min=1"`
+	EndLine      int    `json:"endLine,omitempty"`
+	TokenBudget  int    `json:"tokenBudget,omitempty"`
+}
+
+// ContextSnippet 上下文片段
+type ContextSnippet struct {
+	Kind      string  `json:"kind"` // enclosing/definition/caller/outline
+	FilePath  string  `json:"filePath"`
+	Name      string  `json:"name,omitempty"`
+	StartLine int     `json:"startLine"`
+	EndLine   int     `json:"endLine"`
+	Content   string  `json:"content"`
+	Score     float64 `json:"score"`
+	Tokens    int     `json:"tokens"`
+	Truncated bool    `json:"truncated,omitempty"`
+}
+
+type ContextData struct {
+	List        []*ContextSnippet `json:"list"`
+	TokenBudget int               `json:"tokenBudget"`
+	UsedTokens  int               `json:"usedTokens"`
+	Dropped     int               `json:"dropped"`
+}
+
+// SearchTextRequest 全文检索请求
+type SearchTextRequest struct {
+	ClientId     string   `form:"clientId" binding:"required"`
+	CodebasePath string   `form:"codebasePath" binding:"required"`
+	Query        string   `form:"query" binding:"required"`
+	Limit        int      `form:"limit,omitempty"`
+	IncludePaths []string `form:"includePaths,omitempty"` // 相对代码库的 glob，如 src/**/*.go
+	ExcludePaths []string `form:"excludePaths,omitempty"`
+	MaxSnippets  int      `form:"maxSnippets,omitempty"` // 每个文件返回的命中行数
+}
+
+// TextSearchResult 全文检索命中的文件
+type TextSearchResult struct {
+	FilePath     string               `json:"filePath"`
+	Score        float64              `json:"score"`
+	MatchedTerms []string             `json:"matchedTerms"`
+	Snippets     []*textindex.Snippet `json:"snippets"`
+}
+
+// TextSearchData 全文检索结果
+type TextSearchData struct {
+	List []*TextSearchResult `json:"list"`
+}
+
+// SearchSemanticRequest 本地语义检索请求
+type SearchSemanticRequest struct {
+	ClientId     string   `form:"clientId" binding:"required"`
+	CodebasePath string   `form:"codebasePath" binding:"required"`
+	Query        string   `form:"query" binding:"required"`
+	Limit        int      `form:"limit,omitempty"`
+	Hybrid       bool     `form:"hybrid,omitempty"` // 与符号名匹配结果融合
+	IncludePaths []string `form:"includePaths,omitempty"`
+	ExcludePaths []string `form:"excludePaths,omitempty"`
+}
+
+// SemanticSearchResult 语义检索命中的代码块
+type SemanticSearchResult struct {
+	FilePath   string   `json:"filePath"`
+	Language   string   `json:"language"`
+	SymbolPath string   `json:"symbolPath"`
+	Kind       string   `json:"kind"`
+	Position   Position `json:"position"`
+	Score      float64  `json:"score"`
+	Content    string   `json:"content"`
+}
+
+// SemanticSearchData 语义检索结果
+type SemanticSearchData struct {
+	List []*SemanticSearchResult `json:"list"`
+}
+
+// SearchStructuralRequest 结构化检索请求
+type SearchStructuralRequest struct {
+	ClientId     string   `json:"clientId" binding:"required"`
+	CodebasePath string   `json:"codebasePath" binding:"required"`
+	Language     string   `json:"language" binding:"required"`
+	Query        string   `json:"query" binding:"required"` // tree-sitter 查询语句
+	Limit        int      `json:"limit,omitempty"`
+	IncludePaths []string `json:"includePaths,omitempty"`
+	ExcludePaths []string `json:"excludePaths,omitempty"`
+}
+
+const (
+	StructuralEventCapture = "capture"
+	StructuralEventError   = "error"
+	StructuralEventDone    = "done"
+)
+
+// StructuralCapture 结构化检索命中的捕获，按行流式输出
+type StructuralCapture struct {
+	Type      string   `json:"type"`
+	FilePath  string   `json:"filePath"`
+	Capture   string   `json:"capture"`
+	Pattern   uint     `json:"pattern"`
+	Match     int      `json:"match"` // 文件内的匹配序号，同一次匹配的捕获相同
+	Position  Position `json:"position"`
+	Text      string   `json:"text"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// StructuralSearchError 输出开始后发生的错误
+type StructuralSearchError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// StructuralSearchDone 结构化检索结束时输出的统计
+type StructuralSearchDone struct {
+	Type      string `json:"type"`
+	Files     int    `json:"files"`
+	Matched   int    `json:"matched"`
+	Captures  int    `json:"captures"`
+	Truncated bool   `json:"truncated"`
+}
+
+const (
+	StreamEventDefinition = "definition"
+	StreamEventReference  = "reference"
+	StreamEventError      = "error"
+	StreamEventDone       = "done"
+)
+
+// RelationStreamEvent 流式输出的引用检索结果，引用通过 Definition 关联到根定义的序号
+type RelationStreamEvent struct {
+	Type       string `json:"type"`
+	Definition int    `json:"definition"`
+	*types.RelationNode
+}
+
+// DefinitionStreamEvent 流式输出的定义
+type DefinitionStreamEvent struct {
+	Type string `json:"type"`
+	*DefinitionInfo
+}
+
+// StreamError 输出开始后发生的错误
+type StreamError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// StreamDone 流式输出结束
+type StreamDone struct {
+	Type       string `json:"type"`
+	Count      int    `json:"count"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// GetRepoMapRequest 获取仓库地图请求
+type GetRepoMapRequest struct {
+	ClientId     string   `form:"clientId" binding:"required"`
+	CodebasePath string   `form:"codebasePath" binding:"required"`
+	MaxTokens    int      `form:"maxTokens,omitempty"`
+	EditedFiles  []string `form:"editedFiles,omitempty"` // 当前编辑的文件，排名向其倾斜
+}
+
+// RepoMapData 仓库地图：按符号重要性排序的定义签名
+type RepoMapData struct {
+	Content    string                      `json:"content"`
+	Tokens     int                         `json:"tokens"`
+	TotalFiles int                         `json:"totalFiles"`
+	List       []*repomap.RankedDefinition `json:"list"`
+}
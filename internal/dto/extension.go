@@ -1,6 +1,8 @@
 // internal/dto/extension.go - Extension API DTOs
 package dto
 
+import "codebase-indexer/pkg/codegraph/types"
+
 // RegisterSyncRequest represents the request for registering sync service
 // @Description 注册同步服务的请求参数
 type RegisterSyncRequest struct {
@@ -339,12 +341,33 @@ type IndexStatus struct {
 
 	FailedFiles []string `json:"failedFiles,omitempty"`
 
+	// 超出文件数上限未索引的文件数
+	// example: 10
+	TotalDropped int `json:"totalDropped,omitempty"`
+
+	// 未索引的文件，按优先级排序，最多 200 个
+	DroppedFiles []string `json:"droppedFiles,omitempty"`
+
 	ProcessTs int64 `json:"processTs"`
 }
 
 type IndexStatusData struct {
 	Embedding IndexStatus `json:"embedding"`
 	Codegraph IndexStatus `json:"codegraph"`
+	// 后台索引资源调节器状态
+	Governor *types.GovernorState `json:"governor,omitempty"`
+	// 工作区配置文件 .codebase-indexer.yaml 的状态，没有配置文件时为空
+	Config *ConfigStatus `json:"config,omitempty"`
+}
+
+// ConfigStatus represents the status of the workspace config file
+// @Description 工作区配置文件状态
+type ConfigStatus struct {
+	// 配置文件路径
+	// example: g:\projects\codebase-indexer\.codebase-indexer.yaml
+	Path string `json:"path"`
+	// 校验错误，错误的配置项不生效
+	Errors []string `json:"errors,omitempty"`
 }
 
 // IndexStatusResponse represents the response for querying index status
@@ -402,3 +425,40 @@ type IndexSwitchResponse struct {
 	// example: true
 	Data bool `json:"data"`
 }
+
+// WatchConfigRequest represents the request for configuring the workspace file watcher
+// @Description 工作区文件监听配置的请求参数
+type WatchConfigRequest struct {
+	// 工作空间路径
+	// required: true
+	// example: G:\projects\codebase-indexer
+	Workspace string `json:"workspace" binding:"required"`
+
+	// 是否开启监听，未配置的工作区默认不监听
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// 事件合并的静默时长（毫秒），不大于0时使用默认值500
+	// example: 500
+	DebounceMs int `json:"debounceMs"`
+}
+
+// WatchConfigResponse represents the response for configuring the workspace file watcher
+// @Description 工作区文件监听配置的响应数据
+type WatchConfigResponse struct {
+	// 响应代码
+	// example: 0
+	Code string `json:"code"`
+
+	// 是否成功
+	// example: true
+	Success bool `json:"success"`
+
+	// 响应消息
+	// example: ok
+	Message string `json:"message"`
+
+	// 是否开启监听
+	// example: true
+	Data bool `json:"data"`
+}
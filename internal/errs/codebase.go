@@ -10,12 +10,34 @@ var ErrUnSupportedLanguage = response.NewError("codebase-indexer.unsupported_lan
 var ErrIndexDisabled = response.NewError("codebase-indexer.index_disabled", "index is disabled")
 var ErrRecordNotFound = errors.New("record not found")
 
+const CodeInvalidQuery = "codebase-indexer.invalid_query"
+
 var errorInvalidParamFmt = "invalid request params: %s %v"
 var errorRecordNotFoundFmt = "%s not found by %s"
 var errorMissingParamFmt = "missing required param: %s"
 
+// ParamError 请求参数校验失败，调用方据此区分参数错误与内部错误
+type ParamError struct {
+	msg string
+}
+
+func (e *ParamError) Error() string {
+	return e.msg
+}
+
+// NewParamError 按格式创建参数错误
+func NewParamError(format string, args ...interface{}) error {
+	return &ParamError{msg: fmt.Sprintf(format, args...)}
+}
+
+// IsParamError 是否为请求参数错误
+func IsParamError(err error) bool {
+	var paramErr *ParamError
+	return errors.As(err, &paramErr)
+}
+
 func NewInvalidParamErr(name string, value interface{}) error {
-	return fmt.Errorf(errorInvalidParamFmt, name, value)
+	return NewParamError(errorInvalidParamFmt, name, value)
 }
 
 func NewRecordNotFoundErr(name string, value interface{}) error {
@@ -23,5 +45,5 @@ func NewRecordNotFoundErr(name string, value interface{}) error {
 }
 
 func NewMissingParamError(name string) error {
-	return fmt.Errorf(errorMissingParamFmt, name)
+	return NewParamError(errorMissingParamFmt, name)
 }
@@ -2,12 +2,15 @@
 package handler
 
 import (
+	"codebase-indexer/pkg/codegraph/structural"
 	"codebase-indexer/pkg/response"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/errs"
 	"codebase-indexer/internal/service"
 	"codebase-indexer/pkg/logger"
 )
@@ -15,13 +18,15 @@ import (
 // BackendHandler 实现BackendHandler接口的HTTP处理器
 type BackendHandler struct {
 	codebaseService service.CodebaseService
+	contextService  service.ContextService
 	logger          logger.Logger
 }
 
 // NewBackendHandler 创建新的后端处理器
-func NewBackendHandler(codebaseService service.CodebaseService, logger logger.Logger) *BackendHandler {
+func NewBackendHandler(codebaseService service.CodebaseService, contextService service.ContextService, logger logger.Logger) *BackendHandler {
 	return &BackendHandler{
 		codebaseService: codebaseService,
+		contextService:  contextService,
 		logger:          logger,
 	}
 }
@@ -44,6 +49,9 @@ func NewBackendHandler(codebaseService service.CodebaseService, logger logger.Lo
 // @Param symbolName query string false "符号名"
 // @Param includeContent query bool false "是否需要返回代码内容"
 // @Param maxLayer query int false "最大图层数"
+// @Param limit query int false "每页引用数，与 cursor 均未指定时不分页"
+// @Param cursor query string false "上一页返回的 nextCursor"
+// @Param stream query string false "流式输出模式：ndjson 或 sse"
 // @Success 200 {object} SearchRelationResponse "成功"
 // @Failure 400 {object} SearchRelationResponse "请求参数错误"
 // @Failure 500 {object} SearchRelationResponse "服务器内部错误"
@@ -58,6 +66,14 @@ func (h *BackendHandler) SearchReference(c *gin.Context) {
 
 	h.logger.Info("relation search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
 
+	if req.Stream != "" {
+		if err := h.codebaseService.StreamReference(c, &req); err != nil {
+			h.logger.Error("stream reference err:%v", err)
+			response.Error(c, http.StatusBadRequest, err)
+		}
+		return
+	}
+
 	relations, err := h.codebaseService.QueryReference(c, &req)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, err)
@@ -78,6 +94,10 @@ func (h *BackendHandler) SearchReference(c *gin.Context) {
 // @Param startLine query int false "开始行号"
 // @Param endLine query int false "结束行号"
 // @Param codeSnippet query string false "代码片段"
+// @Param minConfidence query number false "最低置信度，0~1"
+// @Param limit query int false "每页定义数，与 cursor 均未指定时不分页"
+// @Param cursor query string false "上一页返回的 nextCursor"
+// @Param stream query string false "流式输出模式：ndjson 或 sse"
 // @Success 200 {object} SearchDefinitionResponse "成功"
 // @Failure 400 {object} SearchDefinitionResponse "请求参数错误"
 // @Failure 500 {object} SearchDefinitionResponse "服务器内部错误"
@@ -92,6 +112,14 @@ func (h *BackendHandler) SearchDefinition(c *gin.Context) {
 
 	h.logger.Info("definition search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
 
+	if req.Stream != "" {
+		if err := h.codebaseService.StreamDefinition(c, &req); err != nil {
+			h.logger.Error("stream definition err:%v", err)
+			response.Error(c, http.StatusBadRequest, err)
+		}
+		return
+	}
+
 	definitions, err := h.codebaseService.QueryDefinition(c, &req)
 	if err != nil {
 		h.logger.Error("search definition err:%v", err)
@@ -101,6 +129,157 @@ func (h *BackendHandler) SearchDefinition(c *gin.Context) {
 	response.OkJson(c, definitions)
 }
 
+// Hover 获取光标处符号信息
+// @Summary 获取光标处符号信息
+// @Description 识别光标处的标识符，解析其定义，返回签名、类型、所属容器、注释及定义位置
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "代码库绝对路径"
+// @Param filePath query string true "文件绝对路径"
+// @Param line query int true "行号，从1开始"
+// @Param column query int true "列号，从1开始"
+// @Success 200 {object} dto.HoverData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/search/hover [get]
+func (h *BackendHandler) Hover(c *gin.Context) {
+	var req dto.HoverRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("hover request: ClientId=%s, Workspace=%s, FilePath=%s, Line=%d, Column=%d",
+		req.ClientId, req.CodebasePath, req.FilePath, req.Line, req.Column)
+
+	data, err := h.codebaseService.Hover(c, &req)
+	if err != nil {
+		h.logger.Error("hover err:%v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// SearchBatch 批量检索
+// @Summary 批量检索
+// @Description 批量检索定义、引用及文件结构，共享项目解析及索引读取并发执行，每个检索单独返回结果或错误
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param request body dto.SearchBatchRequest true "批量检索请求"
+// @Success 200 {object} dto.SearchBatchData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/search/batch [post]
+func (h *BackendHandler) SearchBatch(c *gin.Context) {
+	var req dto.SearchBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("batch search request: ClientId=%s, Workspace=%s, Queries=%d", req.ClientId, req.CodebasePath, len(req.Queries))
+
+	data, err := h.codebaseService.SearchBatch(c, &req)
+	if err != nil {
+		h.logger.Error("batch search err:%v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// Rename 重命名符号
+// @Summary 重命名符号
+// @Description 根据光标处的符号计算项目内所有定义及引用处的文本替换，并报告重名、遮蔽等冲突，不会修改文件
+// @Tags refactor
+// @Accept json
+// @Produce json
+// @Param request body dto.RenameRequest true "重命名请求"
+// @Success 200 {object} dto.RenameData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/refactor/rename [post]
+func (h *BackendHandler) Rename(c *gin.Context) {
+	var req dto.RenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("rename request: ClientId=%s, Workspace=%s, FilePath=%s, Line=%d, Column=%d, NewName=%s",
+		req.ClientId, req.CodebasePath, req.FilePath, req.Line, req.Column, req.NewName)
+
+	data, err := h.codebaseService.Rename(c, &req)
+	if err != nil {
+		h.logger.Error("rename err:%v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// UpdateBuffer 推送未保存文档
+// @Summary 推送未保存文档
+// @Description 推送编辑器中已打开文档的未保存内容及版本号，解析结果作为覆盖层，定义、引用及文件结构查询优先于磁盘上的索引使用，保存或关闭文档后丢弃
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateBufferRequest true "未保存文档"
+// @Success 200 {object} dto.BufferData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/files/buffer [post]
+func (h *BackendHandler) UpdateBuffer(c *gin.Context) {
+	var req dto.UpdateBufferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("update buffer request: ClientId=%s, Workspace=%s, FilePath=%s, Version=%d",
+		req.ClientId, req.CodebasePath, req.FilePath, req.Version)
+
+	data, err := h.codebaseService.UpdateBuffer(c, &req)
+	if err != nil {
+		h.logger.Error("update buffer err:%v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// CloseBuffer 关闭文档
+// @Summary 关闭文档
+// @Description 关闭文档时丢弃其未保存内容的覆盖层
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "代码库绝对路径"
+// @Param filePath query string true "文件绝对路径"
+// @Success 200 "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/files/buffer [delete]
+func (h *BackendHandler) CloseBuffer(c *gin.Context) {
+	var req dto.CloseBufferRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.codebaseService.CloseBuffer(c, &req); err != nil {
+		h.logger.Error("close buffer err:%v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.Ok(c)
+}
+
 // GetFileContent 获取源文件内容接口
 // @Summary 获取文件内容
 // @Description 获取源文件内容，以二进制流形式返回
@@ -142,10 +321,12 @@ func (h *BackendHandler) GetFileContent(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param clientId query string true "用户机器ID"
-// @Param codebasePath query string true "项目绝对路径"
+// @Param codebasePath query string true "代码库绝对路径"
 // @Param depth query int false "递归深度"
 // @Param includeFiles query bool false "是否包含文件"
 // @Param subDir query string false "子目录"
+// @Param limit query int false "每页节点数，按先序遍历分页"
+// @Param cursor query string false "上一页返回的 nextCursor"
 // @Success 200 {object} GetCodebaseDirectoryResponse "成功"
 // @Failure 400 {object} GetCodebaseDirectoryResponse "请求参数错误"
 // @Failure 500 {object} GetCodebaseDirectoryResponse "服务器内部错误"
@@ -174,6 +355,142 @@ func (h *BackendHandler) GetCodebaseDirectory(c *gin.Context) {
 	response.OkJson(c, tree)
 }
 
+// GetRepoMap 获取仓库地图
+// @Summary 获取仓库地图
+// @Description 基于引用图的PageRank排名，在token上限内返回各文件中最重要的定义签名
+// @Tags codebases
+// @Accept json
+// @Produce json
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "代码库绝对路径"
+// @Param maxTokens query int false "最大token数"
+// @Param editedFiles query []string false "当前编辑的文件"
+// @Success 200 {object} dto.RepoMapData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/codebases/repomap [get]
+func (h *BackendHandler) GetRepoMap(c *gin.Context) {
+	var req dto.GetRepoMapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("get repo map request: ClientId=%s, Workspace=%s, MaxTokens=%d", req.ClientId, req.CodebasePath, req.MaxTokens)
+
+	data, err := h.codebaseService.GetRepoMap(c, &req)
+	if err != nil {
+		h.logger.Error("get repo map err: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// SearchText 全文检索
+// @Summary 全文检索
+// @Description 在本地倒排索引中检索标识符、注释和字符串，按BM25排序并返回高亮片段
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "代码库绝对路径"
+// @Param query query string true "检索内容"
+// @Param limit query int false "返回文件数"
+// @Param includePaths query []string false "包含的路径glob"
+// @Param excludePaths query []string false "排除的路径glob"
+// @Param maxSnippets query int false "每个文件的片段数"
+// @Success 200 {object} dto.TextSearchData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/search/text [get]
+func (h *BackendHandler) SearchText(c *gin.Context) {
+	var req dto.SearchTextRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("search text request: ClientId=%s, Workspace=%s, Query=%s", req.ClientId, req.CodebasePath, req.Query)
+
+	data, err := h.codebaseService.SearchText(c, &req)
+	if err != nil {
+		h.logger.Error("search text err: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// SearchSemantic 本地语义检索
+// @Summary 本地语义检索
+// @Description 使用本地配置的向量化服务检索代码块，可选与符号名匹配结果融合排序
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "代码库绝对路径"
+// @Param query query string true "检索内容"
+// @Param limit query int false "返回数量"
+// @Param hybrid query bool false "是否融合符号名匹配"
+// @Param includePaths query []string false "包含的路径glob"
+// @Param excludePaths query []string false "排除的路径glob"
+// @Success 200 {object} dto.SemanticSearchData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/search/semantic [get]
+func (h *BackendHandler) SearchSemantic(c *gin.Context) {
+	var req dto.SearchSemanticRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("search semantic request: ClientId=%s, Workspace=%s, Query=%s, Hybrid=%v",
+		req.ClientId, req.CodebasePath, req.Query, req.Hybrid)
+
+	data, err := h.codebaseService.SearchSemantic(c, &req)
+	if err != nil {
+		h.logger.Error("search semantic err: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// SearchStructural 结构化检索
+// @Summary 结构化检索
+// @Description 在工作区指定语言的文件上执行 tree-sitter 查询，以 NDJSON 流式返回捕获及其位置和文本，最后一行为统计
+// @Tags search
+// @Accept json
+// @Produce application/x-ndjson
+// @Param request body dto.SearchStructuralRequest true "检索参数"
+// @Success 200 {object} dto.StructuralCapture "成功"
+// @Failure 400 {object} structural.QueryError "请求参数错误或查询语句错误"
+// @Router /codebase-indexer/api/v1/search/structural [post]
+func (h *BackendHandler) SearchStructural(c *gin.Context) {
+	var req dto.SearchStructuralRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("search structural request: ClientId=%s, Workspace=%s, Language=%s",
+		req.ClientId, req.CodebasePath, req.Language)
+
+	err := h.codebaseService.SearchStructural(c, &req)
+	if err != nil {
+		h.logger.Error("search structural err: %v", err)
+		var queryErr *structural.QueryError
+		if errors.As(err, &queryErr) {
+			response.ErrorWithData(c, http.StatusBadRequest, response.NewError(errs.CodeInvalidQuery, queryErr.Error()), queryErr)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err)
+	}
+}
+
 // GetFileStructure 获取单个代码文件结构
 // @Summary 获取文件结构
 // @Description 获取单个代码文件的结构信息
@@ -181,7 +498,7 @@ func (h *BackendHandler) GetCodebaseDirectory(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param clientId query string true "用户机器ID"
-// @Param codebasePath query string true "项目绝对路径"
+// @Param codebasePath query string true "代码库绝对路径"
 // @Param filePath query string true "文件相对路径"
 // @Param types query []string false "类型列表"
 // @Success 200 {object} GetFileStructureResponse "成功"
@@ -214,7 +531,7 @@ func (h *BackendHandler) GetFileStructure(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param clientId query string true "用户机器ID"
-// @Param codebasePath query string true "项目绝对路径"
+// @Param codebasePath query string true "代码库绝对路径"
 // @Success 200 {object} GetIndexSummaryResponse "成功"
 // @Failure 400 {object} GetIndexSummaryResponse "请求参数错误"
 // @Failure 500 {object} GetIndexSummaryResponse "服务器内部错误"
@@ -284,3 +601,33 @@ func (h *BackendHandler) ReadCodeSnippets(c *gin.Context) {
 	}
 	response.OkJson(c, list)
 }
+
+// AssembleContext 组装代码上下文
+// @Summary 组装上下文
+// @Description 根据文件及光标范围收集所在定义、使用符号的定义、调用方及依赖文件大纲，排序去重后按token预算打包
+// @Tags context
+// @Accept json
+// @Produce json
+// @Param request body dto.AssembleContextRequest true "组装上下文请求"
+// @Success 200 {object} dto.ContextData "成功"
+// @Failure 400 "请求参数错误"
+// @Router /codebase-indexer/api/v1/context/assemble [post]
+func (h *BackendHandler) AssembleContext(c *gin.Context) {
+	var req dto.AssembleContextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("assemble context request: ClientId=%s, Workspace=%s, FilePath=%s, TokenBudget=%d",
+		req.ClientId, req.CodebasePath, req.FilePath, req.TokenBudget)
+
+	data, err := h.contextService.AssembleContext(c, &req)
+	if err != nil {
+		h.logger.Error("assemble context err: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, data)
+}
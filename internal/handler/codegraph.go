@@ -0,0 +1,263 @@
+// handler/codegraph.go - gRPC code graph query service handler
+package handler
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	api "codebase-indexer/api"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+)
+
+const (
+	defaultWatchIndexStatusInterval = time.Second
+	minWatchIndexStatusInterval     = 200 * time.Millisecond
+)
+
+// CodeGraphHandler handles gRPC code graph query services
+type CodeGraphHandler struct {
+	codebaseService  service.CodebaseService
+	extensionService service.ExtensionService
+	logger           logger.Logger
+	api.UnimplementedCodeGraphServiceServer
+}
+
+// NewCodeGraphHandler creates a new gRPC code graph handler
+func NewCodeGraphHandler(codebaseService service.CodebaseService, extensionService service.ExtensionService, logger logger.Logger) *CodeGraphHandler {
+	return &CodeGraphHandler{
+		codebaseService:  codebaseService,
+		extensionService: extensionService,
+		logger:           logger,
+	}
+}
+
+// SearchDefinition searches symbol definitions
+func (h *CodeGraphHandler) SearchDefinition(ctx context.Context, req *api.SearchDefinitionRequest) (*api.SearchDefinitionResponse, error) {
+	h.logger.Info("grpc definition search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+	data, err := h.codebaseService.QueryDefinition(ctx, &dto.SearchDefinitionRequest{
+		ClientId:     req.ClientId,
+		CodebasePath: req.CodebasePath,
+		FilePath:     req.FilePath,
+		StartLine:    int(req.StartLine),
+		EndLine:      int(req.EndLine),
+		CodeSnippet:  req.CodeSnippet,
+	})
+	if err != nil {
+		h.logger.Error("grpc search definition err: %v", err)
+		return nil, toStatusError(err)
+	}
+	resp := &api.SearchDefinitionResponse{}
+	for _, d := range data.List {
+		resp.List = append(resp.List, &api.DefinitionInfo{
+			FilePath: d.FilePath,
+			Name:     d.Name,
+			Type:     d.Type,
+			Content:  d.Content,
+			Position: toAPIPosition(d.Position),
+		})
+	}
+	return resp, nil
+}
+
+// SearchReference searches symbol references
+func (h *CodeGraphHandler) SearchReference(ctx context.Context, req *api.SearchReferenceRequest) (*api.SearchReferenceResponse, error) {
+	h.logger.Info("grpc reference search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+	data, err := h.codebaseService.QueryReference(ctx, &dto.SearchReferenceRequest{
+		ClientId:     req.ClientId,
+		CodebasePath: req.CodebasePath,
+		FilePath:     req.FilePath,
+		StartLine:    int(req.StartLine),
+		EndLine:      int(req.EndLine),
+		SymbolName:   req.SymbolName,
+	})
+	if err != nil {
+		h.logger.Error("grpc search reference err: %v", err)
+		return nil, toStatusError(err)
+	}
+	return &api.SearchReferenceResponse{List: toAPIRelationNodes(data.List)}, nil
+}
+
+// GetFileStructure gets definitions of a single file
+func (h *CodeGraphHandler) GetFileStructure(ctx context.Context, req *api.GetFileStructureRequest) (*api.GetFileStructureResponse, error) {
+	h.logger.Info("grpc file structure request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+	data, err := h.codebaseService.ParseFileDefinitions(ctx, &dto.GetFileStructureRequest{
+		ClientId:     req.ClientId,
+		CodebasePath: req.CodebasePath,
+		FilePath:     req.FilePath,
+		Types:        req.Types,
+	})
+	if err != nil {
+		h.logger.Error("grpc get file structure err: %v", err)
+		return nil, toStatusError(err)
+	}
+	resp := &api.GetFileStructureResponse{}
+	for _, d := range data.List {
+		resp.List = append(resp.List, &api.FileStructureInfo{
+			Type:     d.Type,
+			Name:     d.Name,
+			Content:  d.Content,
+			Position: toAPIPosition(d.Position),
+		})
+	}
+	return resp, nil
+}
+
+// ExportIndex streams all index entries of codebase
+func (h *CodeGraphHandler) ExportIndex(req *api.ExportIndexRequest, stream api.CodeGraphService_ExportIndexServer) error {
+	h.logger.Info("grpc export index request: ClientId=%s, Workspace=%s", req.ClientId, req.CodebasePath)
+	if req.CodebasePath == types.EmptyString {
+		return status.Error(codes.InvalidArgument, "missing required param: codebasePath")
+	}
+	err := h.codebaseService.WalkIndex(stream.Context(), req.CodebasePath, func(key string, value proto.Message) error {
+		bytes, err := proto.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&api.IndexEntry{
+			Kind:  toIndexEntryKind(value),
+			Key:   key,
+			Value: bytes,
+		})
+	})
+	if err != nil {
+		h.logger.Error("grpc export index err: %v", err)
+		return toStatusError(err)
+	}
+	return nil
+}
+
+// WatchIndexStatus streams index status of workspace whenever it changes
+func (h *CodeGraphHandler) WatchIndexStatus(req *api.WatchIndexStatusRequest, stream api.CodeGraphService_WatchIndexStatusServer) error {
+	h.logger.Info("grpc watch index status request: ClientId=%s, Workspace=%s", req.ClientId, req.Workspace)
+	if req.Workspace == types.EmptyString {
+		return status.Error(codes.InvalidArgument, "missing required param: workspace")
+	}
+	interval := defaultWatchIndexStatusInterval
+	if req.IntervalMillis > 0 {
+		interval = max(time.Duration(req.IntervalMillis)*time.Millisecond, minWatchIndexStatusInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx := stream.Context()
+	var last *api.IndexStatusEvent
+	for {
+		resp, err := h.extensionService.GetIndexStatus(ctx, req.Workspace)
+		if err != nil {
+			h.logger.Error("grpc get index status err: %v", err)
+			return toStatusError(err)
+		}
+		event := &api.IndexStatusEvent{
+			Workspace: req.Workspace,
+			Embedding: toAPIIndexStatus(resp.Data.Embedding),
+			Codegraph: toAPIIndexStatus(resp.Data.Codegraph),
+		}
+		if last == nil || !proto.Equal(last, event) {
+			if err = stream.Send(event); err != nil {
+				return err
+			}
+			last = event
+		}
+
+		select {
+		case <-ctx.Done():
+			h.logger.Info("grpc watch index status stopped: Workspace=%s", req.Workspace)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// toStatusError 参数校验错误为 InvalidArgument，文件、工作区不存在为 NotFound，索引关闭为 FailedPrecondition，
+// 存储等其余错误为 Internal
+func toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errs.IsParamError(err), errors.Is(err, errs.ErrUnSupportedLanguage), lang.IsUnSupportedFileError(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errs.ErrRecordNotFound), errors.Is(err, workspace.ErrPathNotExists),
+		errors.Is(err, fs.ErrNotExist), errors.Is(err, store.ErrKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errs.ErrIndexDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toAPIPosition(p dto.Position) *api.Position {
+	return &api.Position{
+		StartLine:   int32(p.StartLine),
+		StartColumn: int32(p.StartColumn),
+		EndLine:     int32(p.EndLine),
+		EndColumn:   int32(p.EndColumn),
+	}
+}
+
+func toAPIRelationNodes(nodes []*types.RelationNode) []*api.RelationNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	res := make([]*api.RelationNode, 0, len(nodes))
+	for _, n := range nodes {
+		res = append(res, &api.RelationNode{
+			FilePath:   n.FilePath,
+			SymbolName: n.SymbolName,
+			Content:    n.Content,
+			NodeType:   n.NodeType,
+			Position: &api.Position{
+				StartLine:   int32(n.Position.StartLine),
+				StartColumn: int32(n.Position.StartColumn),
+				EndLine:     int32(n.Position.EndLine),
+				EndColumn:   int32(n.Position.EndColumn),
+			},
+			Children: toAPIRelationNodes(n.Children),
+		})
+	}
+	return res
+}
+
+func toIndexEntryKind(value proto.Message) api.IndexEntry_Kind {
+	switch value.(type) {
+	case *codegraphpb.FileElementTable:
+		return api.IndexEntry_FILE_ELEMENT_TABLE
+	case *codegraphpb.SymbolOccurrence:
+		return api.IndexEntry_SYMBOL_OCCURRENCE
+	default:
+		return api.IndexEntry_UNKNOWN
+	}
+}
+
+func toAPIIndexStatus(s dto.IndexStatus) *api.IndexStatus {
+	return &api.IndexStatus{
+		Status:       s.Status,
+		Process:      s.Process,
+		TotalFiles:   int32(s.TotalFiles),
+		TotalSucceed: int32(s.TotalSucceed),
+		TotalFailed:  int32(s.TotalFailed),
+		TotalChunks:  int32(s.TotalChunks),
+		FailedReason: s.FailedReason,
+		FailedFiles:  s.FailedFiles,
+		ProcessTs:    s.ProcessTs,
+	}
+}
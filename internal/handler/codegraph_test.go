@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "codebase-indexer/api"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/test/mocks"
+)
+
+type stubCodebaseService struct {
+	service.CodebaseService
+	definitions *dto.DefinitionData
+	references  *dto.ReferenceData
+	err         error
+}
+
+func (s *stubCodebaseService) QueryDefinition(ctx context.Context, req *dto.SearchDefinitionRequest) (*dto.DefinitionData, error) {
+	return s.definitions, s.err
+}
+
+func (s *stubCodebaseService) QueryReference(ctx context.Context, req *dto.SearchReferenceRequest) (*dto.ReferenceData, error) {
+	return s.references, s.err
+}
+
+func TestCodeGraphHandler_SearchDefinition(t *testing.T) {
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	codebaseService := &stubCodebaseService{
+		definitions: &dto.DefinitionData{List: []*dto.DefinitionInfo{
+			{FilePath: "/tmp/a.go", Name: "foo", Type: "function", Position: dto.Position{StartLine: 1, StartColumn: 1, EndLine: 3, EndColumn: 2}},
+		}},
+	}
+	h := NewCodeGraphHandler(codebaseService, nil, mockLogger)
+
+	resp, err := h.SearchDefinition(context.Background(), &api.SearchDefinitionRequest{CodebasePath: "/tmp", FilePath: "/tmp/a.go"})
+	assert.NoError(t, err)
+	assert.Len(t, resp.List, 1)
+	assert.Equal(t, "foo", resp.List[0].Name)
+	assert.Equal(t, int32(3), resp.List[0].Position.EndLine)
+
+	// 只有参数错误为 InvalidArgument
+	for _, tt := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{err: errs.NewMissingParamError("filePath"), code: codes.InvalidArgument},
+		{err: errs.ErrUnSupportedLanguage, code: codes.InvalidArgument},
+		{err: errs.ErrIndexDisabled, code: codes.FailedPrecondition},
+		{err: fmt.Errorf("read file: %w", workspace.ErrPathNotExists), code: codes.NotFound},
+		{err: errors.New("leveldb: closed"), code: codes.Internal},
+	} {
+		codebaseService.err = tt.err
+		_, err = h.SearchDefinition(context.Background(), &api.SearchDefinitionRequest{})
+		assert.Equal(t, tt.code, status.Code(err), tt.err.Error())
+	}
+}
+
+func TestCodeGraphHandler_SearchReference(t *testing.T) {
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	codebaseService := &stubCodebaseService{
+		references: &dto.ReferenceData{List: []*types.RelationNode{
+			{
+				FilePath:   "/tmp/a.go",
+				SymbolName: "foo",
+				NodeType:   "definition",
+				Children:   []*types.RelationNode{{FilePath: "/tmp/b.go", SymbolName: "foo", NodeType: "reference"}},
+			},
+		}},
+	}
+	h := NewCodeGraphHandler(codebaseService, nil, mockLogger)
+
+	resp, err := h.SearchReference(context.Background(), &api.SearchReferenceRequest{CodebasePath: "/tmp", FilePath: "/tmp/a.go"})
+	assert.NoError(t, err)
+	assert.Len(t, resp.List, 1)
+	assert.Len(t, resp.List[0].Children, 1)
+	assert.Equal(t, "/tmp/b.go", resp.List[0].Children[0].FilePath)
+}
+
+type stubExtensionService struct {
+	service.ExtensionService
+	err error
+}
+
+func (s *stubExtensionService) GetIndexStatus(ctx context.Context, workspacePath string) (*dto.IndexStatusResponse, error) {
+	return nil, s.err
+}
+
+type stubWatchIndexStatusStream struct {
+	api.CodeGraphService_WatchIndexStatusServer
+	ctx context.Context
+}
+
+func (s *stubWatchIndexStatusStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestCodeGraphHandler_WatchIndexStatus(t *testing.T) {
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	// 未知的工作区为 NotFound
+	extensionService := &stubExtensionService{
+		err: fmt.Errorf("failed to get workspace: %w", fmt.Errorf("workspace not found: /tmp: %w", errs.ErrRecordNotFound)),
+	}
+	h := NewCodeGraphHandler(nil, extensionService, mockLogger)
+	err := h.WatchIndexStatus(&api.WatchIndexStatusRequest{Workspace: "/tmp"},
+		&stubWatchIndexStatusStream{ctx: context.Background()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	err = h.WatchIndexStatus(&api.WatchIndexStatusRequest{}, &stubWatchIndexStatusStream{ctx: context.Background()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
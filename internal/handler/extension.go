@@ -563,6 +563,53 @@ func (h *ExtensionHandler) SwitchIndex(c *gin.Context) {
 	})
 }
 
+// UpdateWatchConfig handles workspace file watcher configuration via REST API
+// @Summary 工作区文件监听配置
+// @Description 开启或关闭工作区的文件监听，设置事件合并的静默时长
+// @Tags index
+// @Accept json
+// @Produce json
+// @Param request body WatchConfigRequest true "文件监听配置请求"
+// @Success 200 {object} WatchConfigResponse "操作成功"
+// @Failure 400 {object} WatchConfigResponse "请求参数错误"
+// @Failure 500 {object} WatchConfigResponse "服务器内部错误"
+// @Router /codebase-indexer/api/v1/watch [post]
+func (h *ExtensionHandler) UpdateWatchConfig(c *gin.Context) {
+	var req dto.WatchConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		c.JSON(http.StatusBadRequest, dto.WatchConfigResponse{
+			Code:    "400",
+			Success: false,
+			Message: "invalid request format",
+		})
+		return
+	}
+
+	h.logger.Info("watch config request: Workspace=%s, Enabled=%v, DebounceMs=%d", req.Workspace, req.Enabled, req.DebounceMs)
+
+	err := h.extensionService.UpdateWatchConfig(c.Request.Context(), req.Workspace, &config.WatchConfig{
+		Enabled:    req.Enabled,
+		DebounceMs: req.DebounceMs,
+	})
+	if err != nil {
+		h.logger.Error("failed to update watch config: %v", err)
+		c.JSON(http.StatusInternalServerError, dto.WatchConfigResponse{
+			Code:    "500",
+			Success: false,
+			Message: "failed to update watch config",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.WatchConfigResponse{
+		Code:    "0",
+		Success: true,
+		Message: "ok",
+		Data:    req.Enabled,
+	})
+}
+
 // UpdateSyncConfig handles sync configuration update via REST API
 // @Summary 更新同步配置
 // @Description 从Header中获取参数更新同步配置
@@ -0,0 +1,86 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/logger"
+)
+
+// FileWatchJob 文件监听任务，定期将监听的工作区与活跃工作区及其监听配置同步
+type FileWatchJob struct {
+	watcher  service.FileWatchService
+	scanner  service.FileScanService
+	storage  repository.StorageInterface
+	logger   logger.Logger
+	interval time.Duration
+}
+
+// NewFileWatchJob 创建文件监听任务
+func NewFileWatchJob(
+	watcher service.FileWatchService,
+	scanner service.FileScanService,
+	storage repository.StorageInterface,
+	logger logger.Logger,
+	interval time.Duration,
+) *FileWatchJob {
+	return &FileWatchJob{
+		watcher:  watcher,
+		scanner:  scanner,
+		storage:  storage,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Start 启动文件监听任务
+func (j *FileWatchJob) Start(ctx context.Context) {
+	j.logger.Info("starting file watch job with interval: %v", j.interval)
+	j.syncWorkspaces(ctx)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				j.logger.Error("recovered from panic in file watch job: %v", r)
+			}
+		}()
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				j.watcher.Stop()
+				j.logger.Info("file watch task stopped")
+				return
+			case <-ticker.C:
+				j.syncWorkspaces(ctx)
+			}
+		}
+	}()
+}
+
+// syncWorkspaces 监听活跃工作区，索引关闭时停止所有监听
+func (j *FileWatchJob) syncWorkspaces(ctx context.Context) {
+	codebaseEnv := j.storage.GetCodebaseEnv()
+	if codebaseEnv == nil {
+		codebaseEnv = &config.CodebaseEnv{
+			Switch: dto.SwitchOn,
+		}
+	}
+	if codebaseEnv.Switch == dto.SwitchOff {
+		j.watcher.Sync(ctx, nil)
+		return
+	}
+
+	workspaces, err := j.scanner.ScanActiveWorkspaces()
+	if err != nil {
+		j.logger.Error("failed to scan active workspaces: %v", err)
+		return
+	}
+	j.watcher.Sync(ctx, workspaces)
+}
@@ -17,6 +17,7 @@ import (
 	"codebase-indexer/internal/utils"
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
 
 	gitignore "github.com/sabhiram/go-gitignore"
@@ -37,12 +38,21 @@ type ScannerInterface interface {
 	CalculateFileChangesWithoutDelete(local, remote map[string]string) []*utils.FileStatus
 	LoadIgnoreConfig(codebasePath string) *config.IgnoreConfig
 	CheckIgnoreFile(ignoreConfig *config.IgnoreConfig, codebasePath string, fileInfo *types.FileInfo) (bool, error)
+	DroppedFiles(codebasePath string) (int, []string)
 }
 
 type FileScanner struct {
 	scannerConfig *config.ScannerConfig
 	logger        logger.Logger
 	rwMutex       sync.RWMutex
+	droppedMutex  sync.RWMutex
+	dropped       map[string]droppedFiles // files dropped by the last codebase scan, keyed by codebase path
+}
+
+// droppedFiles files left out because the codebase exceeds MaxFileCount
+type droppedFiles struct {
+	total int
+	files []string
 }
 
 func NewFileScanner(logger logger.Logger) ScannerInterface {
@@ -92,11 +102,14 @@ func (s *FileScanner) GetScannerConfig() *config.ScannerConfig {
 
 // LoadIgnoreConfig loads the ignore config
 func (s *FileScanner) LoadIgnoreConfig(codebasePath string) *config.IgnoreConfig {
+	maxFileCount, maxFileSizeKB := s.workspaceLimits(codebasePath)
+	wsConfig, _ := workspace.LoadConfig(codebasePath)
 	return &config.IgnoreConfig{
-		IgnoreRules:  s.LoadIgnoreRules(codebasePath),
-		IncludeRules: s.LoadIncludeFiles(),
-		MaxFileCount: s.scannerConfig.MaxFileCount,
-		MaxFileSize:  s.scannerConfig.MaxFileSizeKB,
+		IgnoreRules:   s.LoadIgnoreRules(codebasePath),
+		IncludeRules:  s.includeFiles(codebasePath),
+		MaxFileCount:  maxFileCount,
+		MaxFileSize:   maxFileSizeKB,
+		IncludesUnder: wsConfig.IncludesUnder,
 	}
 }
 
@@ -119,11 +132,15 @@ func (s *FileScanner) CheckIgnoreFile(ignoreConfig *config.IgnoreConfig, codebas
 		return false, fmt.Errorf("failed to get relative path: %v", err)
 	}
 
-	// If directory, append "/" and skip size check
+	// If directory, append "/" and skip size check. Ignored dirs holding include patterns
+	// of .codebase-indexer.yaml are kept, so that the files inside are checked one by one
 	checkPath := relPath
 	if fileInfo.IsDir {
 		checkPath = relPath + "/"
 		if ignoreRules.MatchesPath(checkPath) {
+			if ignoreConfig.IncludesUnder != nil && ignoreConfig.IncludesUnder(relPath) {
+				return false, nil
+			}
 			s.logger.Debug("ignore file found: %s in codebase %s", checkPath, codebasePath)
 			return true, nil
 		}
@@ -172,6 +189,12 @@ func (s *FileScanner) LoadIgnoreRules(codebasePath string) *gitignore.GitIgnore
 		currentIgnoreRules = append(currentIgnoreRules, coignoreRules...)
 	}
 
+	// Merge .codebase-indexer.yaml include/exclude rules last, so that include overrides the rules above
+	wsConfig, _ := workspace.LoadConfig(codebasePath)
+	if configRules := wsConfig.IgnoreLines(); len(configRules) > 0 {
+		currentIgnoreRules = append(currentIgnoreRules, configRules...)
+	}
+
 	// Remove duplicate rules
 	uniqueRules := utils.UniqueStringSlice(currentIgnoreRules)
 	// 转义
@@ -286,21 +309,58 @@ func (s *FileScanner) LoadIncludeFiles() []string {
 	return includeFiles
 }
 
+// includeFiles returns the file extensions to include for the codebase, limited to the languages
+// enabled in its .codebase-indexer.yaml
+func (s *FileScanner) includeFiles(codebasePath string) []string {
+	wsConfig, _ := workspace.LoadConfig(codebasePath)
+	if wsConfig == nil || len(wsConfig.Languages) == 0 {
+		return s.LoadIncludeFiles()
+	}
+	includeFiles := append([]string{}, s.scannerConfig.FileIncludePatterns...)
+	for _, l := range lang.GetTreeSitterParsers() {
+		if wsConfig.LanguageEnabled(l.Language) {
+			includeFiles = append(includeFiles, l.SupportedExts...)
+		}
+	}
+	return includeFiles
+}
+
+// workspaceLimits returns the max file count and max file size (KB) for the codebase,
+// limits in its .codebase-indexer.yaml take precedence over the scanner config
+func (s *FileScanner) workspaceLimits(codebasePath string) (int, int) {
+	maxFileCount, maxFileSizeKB := s.scannerConfig.MaxFileCount, s.scannerConfig.MaxFileSizeKB
+	wsConfig, _ := workspace.LoadConfig(codebasePath)
+	if wsConfig == nil {
+		return maxFileCount, maxFileSizeKB
+	}
+	if wsConfig.Limits.MaxFiles > 0 {
+		maxFileCount = wsConfig.Limits.MaxFiles
+	}
+	if wsConfig.Limits.MaxFileSizeKB > 0 {
+		maxFileSizeKB = wsConfig.Limits.MaxFileSizeKB
+	}
+	return maxFileCount, maxFileSizeKB
+}
+
 // ScanCodebase scans codebase directory and generates hash tree
 func (s *FileScanner) ScanCodebase(codebasePath string) (map[string]string, error) {
 	s.logger.Info("starting codebase scan: %s", codebasePath)
 	startTime := time.Now()
 
 	hashTree := make(map[string]string)
+	modTimes := make(map[string]int64)
 	var filesScanned int
+	maxFileCount, maxFileSizeKB := s.workspaceLimits(codebasePath)
+	// Keep walking past the limit to collect candidates, files are selected by priority afterwards
+	maxCandidates := maxFileCount * utils.FileCandidateFactor
 
 	// fileIgnore := s.LoadFileIgnoreRules(codebasePath)
 	// folderIgnore := s.LoadFolderIgnoreRules(codebasePath)
 	ignore := s.LoadIgnoreRules(codebasePath)
-	fileInclude := s.LoadIncludeFiles()
+	fileInclude := s.includeFiles(codebasePath)
 	fileIncludeMap := utils.StringSlice2Map(fileInclude)
+	wsConfig, _ := workspace.LoadConfig(codebasePath)
 
-	maxFileSizeKB := s.scannerConfig.MaxFileSizeKB
 	maxFileSize := int64(maxFileSizeKB * 1024)
 	err := filepath.WalkDir(codebasePath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -317,8 +377,8 @@ func (s *FileScanner) ScanCodebase(codebasePath string) (map[string]string, erro
 
 		if d.IsDir() {
 			// For directories, check if we should skip entire dir
-			// Don't skip root dir (relPath=".") due to ".*" rules
-			if relPath != "." && ignore != nil && ignore.MatchesPath(relPath+"/") {
+			// Don't skip root dir (relPath=".") due to ".*" rules, nor dirs holding include patterns
+			if relPath != "." && ignore != nil && ignore.MatchesPath(relPath+"/") && !wsConfig.IncludesUnder(relPath) {
 				s.logger.Debug("skipping ignored directory: %s", relPath)
 				return fs.SkipDir
 			}
@@ -360,11 +420,12 @@ func (s *FileScanner) ScanCodebase(codebasePath string) (map[string]string, erro
 		}
 
 		filesScanned++
-		if filesScanned > s.scannerConfig.MaxFileCount {
+		if filesScanned > maxCandidates {
 			return fmt.Errorf("reached maximum file count limit: %d", filesScanned)
 		}
 
 		hashTree[relPath] = strconv.FormatInt(hash, 10)
+		modTimes[relPath] = info.ModTime().Unix()
 
 		return nil
 	})
@@ -373,10 +434,11 @@ func (s *FileScanner) ScanCodebase(codebasePath string) (map[string]string, erro
 		// 检查是否是达到文件数上限的错误
 		if err.Error() == fmt.Sprintf("reached maximum file count limit: %d", filesScanned) {
 			s.logger.Warn("reached maximum file count limit: %d, stopping scan, time taken: %v", filesScanned, time.Since(startTime))
-			return hashTree, nil
+			return s.selectFiles(codebasePath, hashTree, modTimes, maxFileCount), nil
 		}
 		return nil, fmt.Errorf("failed to scan codebase: %v", err)
 	}
+	hashTree = s.selectFiles(codebasePath, hashTree, modTimes, maxFileCount)
 
 	s.logger.Info("codebase scan completed, %d files scanned, time taken: %v",
 		filesScanned, time.Since(startTime))
@@ -384,6 +446,45 @@ func (s *FileScanner) ScanCodebase(codebasePath string) (map[string]string, erro
 	return hashTree, nil
 }
 
+// selectFiles keeps maxFileCount files by priority when the codebase has more, and records the dropped ones
+func (s *FileScanner) selectFiles(codebasePath string, hashTree map[string]string, modTimes map[string]int64,
+	maxFileCount int) map[string]string {
+	s.droppedMutex.Lock()
+	defer s.droppedMutex.Unlock()
+	if len(hashTree) <= maxFileCount {
+		delete(s.dropped, codebasePath)
+		return hashTree
+	}
+	candidates := make([]*utils.FileCandidate, 0, len(hashTree))
+	for relPath := range hashTree {
+		candidates = append(candidates, &utils.FileCandidate{Path: relPath, ModTime: modTimes[relPath]})
+	}
+	kept, dropped := utils.SelectFiles(candidates, maxFileCount, time.Now())
+	selected := make(map[string]string, len(kept))
+	for _, c := range kept {
+		selected[c.Path] = hashTree[c.Path]
+	}
+	files := utils.DroppedPaths(dropped)
+	for n, relPath := range files {
+		files[n] = filepath.Join(codebasePath, relPath)
+	}
+	if s.dropped == nil {
+		s.dropped = make(map[string]droppedFiles)
+	}
+	s.dropped[codebasePath] = droppedFiles{total: len(dropped), files: files}
+	s.logger.Warn("codebase %s has %d files, exceeds max file count %d, dropped %d files",
+		codebasePath, len(hashTree), maxFileCount, len(dropped))
+	return selected
+}
+
+// DroppedFiles returns the number of files dropped by the last codebase or directory scan and the top dropped paths
+func (s *FileScanner) DroppedFiles(codebasePath string) (int, []string) {
+	s.droppedMutex.RLock()
+	defer s.droppedMutex.RUnlock()
+	report := s.dropped[codebasePath]
+	return report.total, report.files
+}
+
 // ScanFilePaths scans file paths and generates hash tree
 func (s *FileScanner) ScanFilePaths(codebasePath string, filePaths []string) (map[string]string, error) {
 	s.logger.Info("starting file paths scan for codebase: %s", codebasePath)
@@ -431,15 +532,19 @@ func (s *FileScanner) ScanDirectory(codebasePath, dirPath string) (map[string]st
 	startTime := time.Now()
 
 	hashTree := make(map[string]string)
+	modTimes := make(map[string]int64)
 	var filesScanned int
 
 	// fileIgnore := s.LoadFileIgnoreRules(codebasePath)
 	// folderIgnore := s.LoadFolderIgnoreRules(codebasePath)
 	ignore := s.LoadIgnoreRules(codebasePath)
-	fileInclude := s.LoadIncludeFiles()
+	fileInclude := s.includeFiles(codebasePath)
 	fileIncludeMap := utils.StringSlice2Map(fileInclude)
+	wsConfig, _ := workspace.LoadConfig(codebasePath)
 
-	maxFileSizeKB := s.scannerConfig.MaxFileSizeKB
+	maxFileCount, maxFileSizeKB := s.workspaceLimits(codebasePath)
+	// Keep walking past the limit to collect candidates, files are selected by priority afterwards
+	maxCandidates := maxFileCount * utils.FileCandidateFactor
 	maxFileSize := int64(maxFileSizeKB * 1024)
 	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -456,8 +561,8 @@ func (s *FileScanner) ScanDirectory(codebasePath, dirPath string) (map[string]st
 
 		if d.IsDir() {
 			// For directories, check if we should skip entire dir
-			// Don't skip root dir (relPath=".") due to ".*" rules
-			if relPath != "." && ignore != nil && ignore.MatchesPath(relPath+"/") {
+			// Don't skip root dir (relPath=".") due to ".*" rules, nor dirs holding include patterns
+			if relPath != "." && ignore != nil && ignore.MatchesPath(relPath+"/") && !wsConfig.IncludesUnder(relPath) {
 				s.logger.Debug("skipping ignored directory: %s", relPath)
 				return fs.SkipDir
 			}
@@ -482,9 +587,11 @@ func (s *FileScanner) ScanDirectory(codebasePath, dirPath string) (map[string]st
 			return nil
 		}
 
+		// Verify file extension is supported
 		if len(fileIncludeMap) > 0 {
-			if _, ok := fileIncludeMap[relPath]; !ok {
-				s.logger.Debug("skipping file not included: %s", relPath)
+			fileExt := filepath.Ext(path)
+			if _, ok := fileIncludeMap[fileExt]; !ok {
+				s.logger.Debug("skipping file with unsupported extension: %s", relPath)
 				return nil
 			}
 		}
@@ -497,11 +604,12 @@ func (s *FileScanner) ScanDirectory(codebasePath, dirPath string) (map[string]st
 		}
 
 		filesScanned++
-		if filesScanned > s.scannerConfig.MaxFileCount {
+		if filesScanned > maxCandidates {
 			return fmt.Errorf("reached maximum file count limit: %d", filesScanned)
 		}
 
 		hashTree[relPath] = strconv.FormatInt(hash, 10)
+		modTimes[relPath] = info.ModTime().Unix()
 
 		return nil
 	})
@@ -510,10 +618,14 @@ func (s *FileScanner) ScanDirectory(codebasePath, dirPath string) (map[string]st
 		// 检查是否是达到文件数上限的错误
 		if err.Error() == fmt.Sprintf("reached maximum file count limit: %d", filesScanned) {
 			s.logger.Warn("reached maximum file count limit: %d, stopping scan, time taken: %v", filesScanned, time.Since(startTime))
-			return hashTree, nil
+			return s.selectFiles(codebasePath, hashTree, modTimes, maxFileCount), nil
 		}
 		return nil, fmt.Errorf("failed to scan directory: %v", err)
 	}
+	// Only report drops here, a directory within the limit says nothing about the rest of the codebase
+	if len(hashTree) > maxFileCount {
+		hashTree = s.selectFiles(codebasePath, hashTree, modTimes, maxFileCount)
+	}
 
 	s.logger.Info("directory scan completed, %d files scanned, time taken: %v",
 		filesScanned, time.Since(startTime))
@@ -528,9 +640,9 @@ func (s *FileScanner) ScanFile(codebasePath, filePath string) (string, error) {
 
 	// fileIgnore := s.LoadFileIgnoreRules(codebasePath)
 	ignore := s.LoadIgnoreRules(codebasePath)
-	fileInclude := s.LoadIncludeFiles()
+	fileInclude := s.includeFiles(codebasePath)
 	fileIncludeMap := utils.StringSlice2Map(fileInclude)
-	maxFileSizeKB := s.scannerConfig.MaxFileSizeKB
+	_, maxFileSizeKB := s.workspaceLimits(codebasePath)
 	maxFileSize := int64(maxFileSizeKB * 1024)
 	relPath, err := filepath.Rel(codebasePath, filePath)
 	if err != nil {
@@ -3,6 +3,7 @@ package repository
 import (
 	"codebase-indexer/internal/config"
 	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/test/mocks"
 	"os"
 	"path/filepath"
@@ -279,3 +280,95 @@ func TestCalculateFileChanges(t *testing.T) {
 		assert.Equal(t, utils.FILE_STATUS_DELETED, deleted.Status)
 	})
 }
+
+func TestScanCodebase_IncludeInIgnoredDir(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	cfg := *scannerConfig
+	cfg.MaxFileCount = 100
+	fs := &FileScanner{scannerConfig: &cfg, logger: logger}
+
+	tempDir := t.TempDir()
+	for _, f := range []string{"main.go", "vendor/internal/a.go", "vendor/other/b.go"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("package a\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".codebase-indexer.yaml"),
+		[]byte("include: [\"vendor/internal/\"]\n"), 0644))
+
+	// 被忽略的 vendor 目录中 include 的子目录仍被扫描
+	hashTree, err := fs.ScanCodebase(tempDir)
+	require.NoError(t, err)
+	assert.Contains(t, hashTree, "main.go")
+	assert.Contains(t, hashTree, filepath.Join("vendor", "internal", "a.go"))
+	assert.NotContains(t, hashTree, filepath.Join("vendor", "other", "b.go"))
+
+	ignoreConfig := fs.LoadIgnoreConfig(tempDir)
+	skip, err := fs.CheckIgnoreFile(ignoreConfig, tempDir, &types.FileInfo{Path: filepath.Join(tempDir, "vendor"), IsDir: true})
+	require.NoError(t, err)
+	assert.False(t, skip)
+	skip, err = fs.CheckIgnoreFile(ignoreConfig, tempDir, &types.FileInfo{Path: filepath.Join(tempDir, "vendor", "other"), IsDir: true})
+	require.NoError(t, err)
+	assert.True(t, skip)
+}
+
+func TestScanCodebase_FileIncludeKeepsDirsPruned(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	cfg := *scannerConfig
+	cfg.MaxFileCount = 100
+	fs := &FileScanner{scannerConfig: &cfg, logger: logger}
+
+	tempDir := t.TempDir()
+	for _, f := range []string{"main.go", "api.pb.go", "node_modules/dep/dep.pb.go"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("package a\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".codebase-indexer.yaml"),
+		[]byte("include: [\"*.pb.go\"]\n"), 0644))
+
+	// 不含目录的 include 规则不会让被忽略的 node_modules 被遍历
+	hashTree, err := fs.ScanCodebase(tempDir)
+	require.NoError(t, err)
+	assert.Contains(t, hashTree, "main.go")
+	assert.Contains(t, hashTree, "api.pb.go")
+	assert.NotContains(t, hashTree, filepath.Join("node_modules", "dep", "dep.pb.go"))
+
+	ignoreConfig := fs.LoadIgnoreConfig(tempDir)
+	skip, err := fs.CheckIgnoreFile(ignoreConfig, tempDir, &types.FileInfo{Path: filepath.Join(tempDir, "node_modules"), IsDir: true})
+	require.NoError(t, err)
+	assert.True(t, skip)
+}
+
+func TestScanDirectory_SelectsByPriority(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	cfg := *scannerConfig
+	fs := &FileScanner{scannerConfig: &cfg, logger: logger}
+
+	tempDir := t.TempDir()
+	// 按遍历顺序测试文件在前
+	for _, f := range []string{"pkg/a_test.go", "pkg/b_test.go", "pkg/main.go"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("package a\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".codebase-indexer.yaml"),
+		[]byte("limits:\n  maxFiles: 2\n"), 0644))
+
+	hashTree, err := fs.ScanDirectory(tempDir, filepath.Join(tempDir, "pkg"))
+	require.NoError(t, err)
+	assert.Len(t, hashTree, 2)
+	assert.Contains(t, hashTree, filepath.Join("pkg", "main.go"))
+	total, dropped := fs.DroppedFiles(tempDir)
+	assert.Equal(t, 1, total)
+	assert.Len(t, dropped, 1)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"codebase-indexer/internal/database"
+	"codebase-indexer/internal/errs"
 	"codebase-indexer/internal/model"
 	"codebase-indexer/pkg/logger"
 )
@@ -118,7 +119,7 @@ func (r *workspaceRepository) GetWorkspaceByPath(path string) (*model.Workspace,
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("workspace not found: %s", path)
+			return nil, fmt.Errorf("workspace not found: %s: %w", path, errs.ErrRecordNotFound)
 		}
 		r.logger.Error("Failed to get workspace by path: %v", err)
 		return nil, fmt.Errorf("failed to get workspace by path: %w", err)
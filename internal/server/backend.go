@@ -15,12 +15,22 @@ func SetupBackendRoutes(router *gin.Engine, backendHandler *handler.BackendHandl
 	{
 		api.GET("/search/reference", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchReference)
 		api.GET("/search/definition", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchDefinition)
+		api.GET("/search/hover", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.Hover)
+		api.GET("/search/text", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchText)
+		api.GET("/search/semantic", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchSemantic)
+		api.POST("/search/structural", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchStructural)
+		api.POST("/search/batch", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchBatch)
 		api.GET("/files/content", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileContent)
 		api.POST("/snippets/read", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.ReadCodeSnippets)
 		api.GET("/codebases/directory", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetCodebaseDirectory)
+		api.GET("/codebases/repomap", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetRepoMap)
 		api.GET("/files/structure", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileStructure)
+		api.POST("/files/buffer", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.UpdateBuffer)
+		api.DELETE("/files/buffer", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.CloseBuffer)
 		api.GET("/index/summary", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetIndexSummary)
 		api.GET("/index/export", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.ExportIndex)
+		api.POST("/context/assemble", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.AssembleContext)
+		api.POST("/refactor/rename", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.Rename)
 		api.DELETE("/index", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.DeleteIndex)
 	}
 }
@@ -18,5 +18,6 @@ func SetupExtensionRoutes(router *gin.Engine, extensionHandler *handler.Extensio
 		api.POST("/index", HeaderConfigMiddleware(logger), ExtensionRateLimitMiddleware(logger), extensionHandler.TriggerIndex)
 		api.GET("/index/status", HeaderConfigMiddleware(logger), ExtensionRateLimitMiddleware(logger), extensionHandler.GetIndexStatus)
 		api.GET("/switch", HeaderConfigMiddleware(logger), ExtensionRateLimitMiddleware(logger), extensionHandler.SwitchIndex)
+		api.POST("/watch", HeaderConfigMiddleware(logger), ExtensionRateLimitMiddleware(logger), extensionHandler.UpdateWatchConfig)
 	}
 }
@@ -8,9 +8,13 @@ import (
 	"codebase-indexer/pkg/codegraph/definition"
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/repomap"
 	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/structural"
+	"codebase-indexer/pkg/codegraph/textindex"
 	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/vector"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/response"
 	"context"
@@ -20,9 +24,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
 
 	"codebase-indexer/internal/config"
 	"codebase-indexer/pkg/logger"
@@ -51,15 +58,57 @@ type CodebaseService interface {
 	// QueryDefinition 查询代码定义（支持按行号或代码片段检索）
 	QueryDefinition(ctx context.Context, req *dto.SearchDefinitionRequest) (*dto.DefinitionData, error)
 
+	// StreamDefinition 以 NDJSON 或 SSE 流式输出定义
+	StreamDefinition(c *gin.Context, req *dto.SearchDefinitionRequest) error
+
+	// Hover 获取光标处符号的签名、类型、所属容器、注释及定义位置
+	Hover(ctx context.Context, req *dto.HoverRequest) (*dto.HoverData, error)
+
+	// SearchBatch 批量检索定义、引用及文件结构
+	SearchBatch(ctx context.Context, req *dto.SearchBatchRequest) (*dto.SearchBatchData, error)
+
+	// Rename 计算重命名的工作区编辑及冲突报告
+	Rename(ctx context.Context, req *dto.RenameRequest) (*dto.RenameData, error)
+
+	// UpdateBuffer 推送编辑器中未保存文档的内容，定义、引用及文件结构查询优先使用
+	UpdateBuffer(ctx context.Context, req *dto.UpdateBufferRequest) (*dto.BufferData, error)
+
+	// CloseBuffer 关闭文档，丢弃其未保存的内容
+	CloseBuffer(ctx context.Context, req *dto.CloseBufferRequest) error
+
+	// GetGovernorState 获取后台索引资源调节器的状态
+	GetGovernorState() *types.GovernorState
+
+	// GetDroppedFiles 获取工作区超出文件数上限未建代码图索引的文件数及文件
+	GetDroppedFiles(workspacePath string) (int, []string)
+
 	// QueryReference 查询代码间的关系（如调用、引用等）
 	QueryReference(ctx context.Context, req *dto.SearchReferenceRequest) (*dto.ReferenceData, error)
 
+	// StreamReference 随索引遍历以 NDJSON 或 SSE 流式输出引用
+	StreamReference(c *gin.Context, req *dto.SearchReferenceRequest) error
+
 	// Summarize 获取代码库索引摘要信息
 	Summarize(ctx context.Context, req *dto.GetIndexSummaryRequest) (*dto.IndexSummary, error)
 
 	// DeleteIndex 删除代码库的索引（支持按类型删除）
 	DeleteIndex(ctx context.Context, req *dto.DeleteIndexRequest) error
 	ExportIndex(c *gin.Context, d *dto.ExportIndexRequest) error
+
+	// GetRepoMap 生成仓库地图，按符号中心度展示最重要的定义签名
+	GetRepoMap(ctx context.Context, req *dto.GetRepoMapRequest) (*dto.RepoMapData, error)
+
+	// SearchText 本地全文检索，返回命中文件及高亮片段
+	SearchText(ctx context.Context, req *dto.SearchTextRequest) (*dto.TextSearchData, error)
+
+	// SearchSemantic 本地语义检索，可与符号名匹配结果融合
+	SearchSemantic(ctx context.Context, req *dto.SearchSemanticRequest) (*dto.SemanticSearchData, error)
+
+	// SearchStructural 结构化检索，以 NDJSON 流式输出捕获
+	SearchStructural(c *gin.Context, req *dto.SearchStructuralRequest) error
+
+	// WalkIndex 遍历代码库的索引条目
+	WalkIndex(ctx context.Context, codebasePath string, walkFn func(key string, value proto.Message) error) error
 	ReadCodeSnippets(c *gin.Context, d *dto.ReadCodeSnippetsRequest) (*dto.CodeSnippetsData, error)
 }
 
@@ -68,6 +117,12 @@ const maxLineLimit = 500
 const definitionFillContentNodeLimit = 100
 const DefaultMaxCodeSnippetLines = 500
 const DefaultMaxCodeSnippets = 200
+const maxRepoMapTokens = 16384
+const maxTextSearchLimit = 200
+const defaultStructuralSearchLimit = 1000
+const maxStructuralSearchLimit = 10000
+const maxBatchQueries = 100
+const maxBatchConcurrency = 8
 
 // NewCodebaseService 创建新的代码库服务
 func NewCodebaseService(
@@ -99,7 +154,7 @@ type codebaseService struct {
 func (s *codebaseService) checkPath(ctx context.Context, workspacePath string, filePaths []string) error {
 	for _, filePath := range filePaths {
 		if filePath != types.EmptyString && !utils.IsSubdir(workspacePath, filePath) {
-			return fmt.Errorf("cannot access path %s which not in workspace %s", filePath, workspacePath)
+			return errs.NewParamError("cannot access path %s which not in workspace %s", filePath, workspacePath)
 		}
 	}
 	_, err := s.workspaceRepository.GetWorkspaceByPath(workspacePath)
@@ -107,44 +162,55 @@ func (s *codebaseService) checkPath(ctx context.Context, workspacePath string, f
 }
 
 func (s *codebaseService) ExportIndex(c *gin.Context, d *dto.ExportIndexRequest) error {
-	projects := s.workspaceReader.FindProjects(c, d.CodebasePath, false, workspace.DefaultVisitPattern)
-	if len(projects) == 0 {
-		return fmt.Errorf("can not find project in workspace %s", d.CodebasePath)
-	}
 	downloader := response.NewDownloader(c, fmt.Sprintf("%s-index.json", d.CodebasePath))
 	defer downloader.Finish()
+	return s.WalkIndex(c, d.CodebasePath, func(key string, value proto.Message) error {
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		// 客户端断开时停止遍历
+		return downloader.Write(append(bytes, '\n'))
+	})
+}
+
+// WalkIndex 遍历代码库所有项目的索引，value 为 FileElementTable 或 SymbolOccurrence
+func (s *codebaseService) WalkIndex(ctx context.Context, codebasePath string, walkFn func(key string, value proto.Message) error) error {
+	projects := s.workspaceReader.FindProjects(ctx, codebasePath, false, workspace.DefaultVisitPattern)
+	if len(projects) == 0 {
+		return fmt.Errorf("can not find project in workspace %s", codebasePath)
+	}
 	for _, project := range projects {
-		summary, _ := s.indexer.GetSummary(c, d.CodebasePath)
-		s.logger.Debug("workspace %s has %d indexes", d.CodebasePath, summary.TotalFiles)
-		iter := s.indexer.IndexIter(c, project.Uuid)
-		for iter.Next() {
-			key := iter.Key()
-			value := iter.Value()
-			if store.IsElementPathKey(key) {
-				var fileTable codegraphpb.FileElementTable
-				if err := store.UnmarshalValue(value, &fileTable); err != nil {
-					return err
-				} else {
-					bytes, err := json.Marshal(&fileTable)
-					if err == nil {
-						_ = downloader.Write(bytes)
-						_ = downloader.Write([]byte("\n"))
-					}
-				}
-			} else if store.IsSymbolNameKey(key) {
-				var sym codegraphpb.SymbolOccurrence
-				if err := store.UnmarshalValue(value, &sym); err != nil {
-					return err
-				} else {
-					bytes, err := json.Marshal(&sym)
-					if err == nil {
-						_ = downloader.Write(bytes)
-						_ = downloader.Write([]byte("\n"))
-					}
-				}
-			}
+		summary, _ := s.indexer.GetSummary(ctx, codebasePath)
+		if summary != nil {
+			s.logger.Debug("workspace %s has %d indexes", codebasePath, summary.TotalFiles)
+		}
+		if err := s.walkProjectIndex(ctx, project.Uuid, walkFn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *codebaseService) walkProjectIndex(ctx context.Context, projectUuid string, walkFn func(key string, value proto.Message) error) error {
+	iter := s.indexer.IndexIter(ctx, projectUuid)
+	defer iter.Close()
+	for iter.Next() {
+		key := iter.Key()
+		var value proto.Message
+		if store.IsElementPathKey(key) {
+			value = &codegraphpb.FileElementTable{}
+		} else if store.IsSymbolNameKey(key) {
+			value = &codegraphpb.SymbolOccurrence{}
+		} else {
+			continue
+		}
+		if err := store.UnmarshalValue(iter.Value(), value); err != nil {
+			return err
+		}
+		if err := walkFn(key, value); err != nil {
+			return err
 		}
-		_ = iter.Close()
 	}
 	return nil
 }
@@ -297,6 +363,11 @@ func (l *codebaseService) GetCodebaseDirectoryTree(ctx context.Context, req *dto
 		MaxDepth: req.Depth,
 	}
 
+	page, err := parsePagination(req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	nodes, err := l.workspaceReader.Tree(ctx, req.CodebasePath, req.SubDir, treeOpts)
 	if err != nil {
 		l.logger.Error("failed to get directory tree: %v", err)
@@ -310,16 +381,60 @@ func (l *codebaseService) GetCodebaseDirectoryTree(ctx context.Context, req *dto
 		countFilesAndSize(nodes, &totalFiles, &totalSize, req.IncludeFiles)
 	}
 
+	var nextCursor string
+	if page != nil {
+		index := 0
+		pageNodes := pageTree(nodes, page, &index)
+		nextCursor = page.nextCursor(countTreeNodes(nodes))
+		nodes = pageNodes
+	}
+
 	resp = &dto.DirectoryData{
 		RootPath:      req.CodebasePath,
 		TotalFiles:    totalFiles,
 		TotalSize:     totalSize,
 		DirectoryTree: nodes,
+		NextCursor:    nextCursor,
 	}
 
 	return resp, nil
 }
 
+// pageTree 按先序遍历截取当前页的节点，保留其所在目录以维持树结构，index 为已遍历的节点数
+func pageTree(nodes []*types.TreeNode, page *pagination, index *int) []*types.TreeNode {
+	var res []*types.TreeNode
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if *index >= page.Offset+page.Limit {
+			break
+		}
+		current := *index
+		*index++
+		children := pageTree(node.Children, page, index)
+		if current < page.Offset && len(children) == 0 {
+			continue
+		}
+		paged := *node
+		paged.Children = children
+		res = append(res, &paged)
+	}
+	return res
+}
+
+// countTreeNodes 统计目录树节点总数
+func countTreeNodes(nodes []*types.TreeNode) int {
+	total := 0
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		total += 1 + countTreeNodes(node.Children)
+	}
+	return total
+}
+
 // countFilesAndSize 统计文件数量和总大小
 func countFilesAndSize(nodes []*types.TreeNode, totalFiles *int, totalSize *int64, includeFiles bool) {
 	if len(nodes) == 0 {
@@ -346,10 +461,13 @@ func countFilesAndSize(nodes []*types.TreeNode, totalFiles *int, totalSize *int6
 
 func (l *codebaseService) ParseFileDefinitions(ctx context.Context, req *dto.GetFileStructureRequest) (resp *dto.FileStructureData, err error) {
 	filePath := req.FilePath
-	bytes, err := l.workspaceReader.ReadFile(ctx, req.FilePath, types.ReadOptions{EndLine: maxReadLine})
-
-	if err != nil {
-		return nil, err
+	// 未保存的文档优先
+	bytes, ok := l.indexer.GetBuffer(ctx, req.CodebasePath, filePath)
+	if !ok {
+		bytes, err = l.workspaceReader.ReadFile(ctx, req.FilePath, types.ReadOptions{EndLine: maxReadLine})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	parsed, err := l.fileDefinitionParser.Parse(ctx, &types.SourceFile{
@@ -362,16 +480,117 @@ func (l *codebaseService) ParseFileDefinitions(ctx context.Context, req *dto.Get
 	resp = new(dto.FileStructureData)
 	for _, d := range parsed.Definitions {
 		resp.List = append(resp.List, &dto.FileStructureInfo{
-			Name:     d.Name,
-			Type:     d.Type,
-			Position: dto.ToPosition(d.Range),
-			Content:  string(d.Content),
+			Name:       d.Name,
+			Type:       d.Type,
+			Position:   dto.ToPosition(d.Range),
+			Content:    string(d.Content),
+			Doc:        d.Doc,
+			Signature:  d.Signature,
+			Decorators: d.Decorators,
 		})
 	}
 	return resp, nil
 }
 
 func (l *codebaseService) QueryDefinition(ctx context.Context, req *dto.SearchDefinitionRequest) (resp *dto.DefinitionData, err error) {
+	opts, err := l.definitionOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	page, err := parsePagination(req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := l.indexer.QueryDefinitions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeLimit := definitionFillContentNodeLimit
+	var nextCursor string
+	if page != nil {
+		// 分页时按置信度及位置排序，保证多次请求的顺序一致
+		sortDefinitions(nodes)
+		start, end := page.window(len(nodes))
+		nextCursor = page.nextCursor(len(nodes))
+		nodes, nodeLimit = nodes[start:end], page.Limit
+	}
+
+	// 填充content，控制层数和节点数
+	definitions, err := l.convert2DefinitionInfo(ctx, nodes, nodeLimit)
+	if err != nil {
+		l.logger.Error("fill definition query contents err:%v", err)
+	}
+
+	return &dto.DefinitionData{List: definitions, NextCursor: nextCursor}, nil
+}
+
+// StreamDefinition 流式输出定义，每个定义读取内容后立即输出
+func (l *codebaseService) StreamDefinition(c *gin.Context, req *dto.SearchDefinitionRequest) error {
+	opts, err := l.definitionOptions(req)
+	if err != nil {
+		return err
+	}
+	page, err := parsePagination(req.Limit, req.Cursor)
+	if err != nil {
+		return err
+	}
+	writer, err := response.NewEventWriter(c, req.Stream)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := l.indexer.QueryDefinitions(c, opts)
+	if err != nil {
+		return err
+	}
+	var nextCursor string
+	if page != nil {
+		sortDefinitions(nodes)
+		start, end := page.window(len(nodes))
+		nextCursor = page.nextCursor(len(nodes))
+		nodes = nodes[start:end]
+	}
+	for _, node := range nodes {
+		if err = utils.CheckContextCanceled(c); err != nil {
+			break
+		}
+		if err = writer.WriteEvent(dto.StreamEventDefinition, &dto.DefinitionStreamEvent{
+			Type:           dto.StreamEventDefinition,
+			DefinitionInfo: l.definitionInfoWithContent(c, node),
+		}); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		if !writer.Started() {
+			return err
+		}
+		l.logger.Error("stream definition err: %v", err)
+		return writer.WriteEvent(dto.StreamEventError, &dto.StreamError{Type: dto.StreamEventError, Message: err.Error()})
+	}
+	return writer.WriteEvent(dto.StreamEventDone, &dto.StreamDone{Type: dto.StreamEventDone, Count: len(nodes), NextCursor: nextCursor})
+}
+
+// sortDefinitions 按置信度降序，再按文件路径及位置排序
+func sortDefinitions(nodes []*types.Definition) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Confidence != nodes[j].Confidence {
+			return nodes[i].Confidence > nodes[j].Confidence
+		}
+		if nodes[i].Path != nodes[j].Path {
+			return nodes[i].Path < nodes[j].Path
+		}
+		if len(nodes[i].Range) == 0 || len(nodes[j].Range) == 0 {
+			return len(nodes[i].Range) < len(nodes[j].Range)
+		}
+		return comparePosition(nodes[i].Range, nodes[j].Range) < 0
+	})
+}
+
+// definitionOptions 校验定义检索参数
+func (l *codebaseService) definitionOptions(req *dto.SearchDefinitionRequest) (*types.QueryDefinitionOptions, error) {
 	// 参数验证
 	// 支持三种检索方式：（FilePaths 必传）
 	// 1. 根据行号
@@ -398,37 +617,246 @@ func (l *codebaseService) QueryDefinition(ctx context.Context, req *dto.SearchDe
 	}
 
 	if req.FilePath == types.EmptyString {
-		return nil, fmt.Errorf("missing param: filePath")
+		return nil, errs.NewParamError("missing param: filePath")
 	}
 	if req.CodebasePath == types.EmptyString {
-		return nil, fmt.Errorf("missing param: codebasePath")
+		return nil, errs.NewParamError("missing param: codebasePath")
 	}
 	if !filepath.IsAbs(req.FilePath) {
-		return nil, fmt.Errorf("param filePath must be absolute path")
+		return nil, errs.NewParamError("param filePath must be absolute path")
 	}
-	_, err = lang.InferLanguage(req.FilePath)
-	if err != nil {
+	if _, err := lang.InferLanguage(req.FilePath); err != nil {
 		return nil, errs.ErrUnSupportedLanguage
 	}
 
-	nodes, err := l.indexer.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
-		Workspace:   req.CodebasePath,
-		StartLine:   req.StartLine,
-		EndLine:     req.EndLine,
-		FilePath:    req.FilePath,
-		CodeSnippet: []byte(req.CodeSnippet),
+	return &types.QueryDefinitionOptions{
+		Workspace:     req.CodebasePath,
+		StartLine:     req.StartLine,
+		EndLine:       req.EndLine,
+		FilePath:      req.FilePath,
+		CodeSnippet:   []byte(req.CodeSnippet),
+		MinConfidence: req.MinConfidence,
+	}, nil
+}
+
+func toDefinitionInfo(node *types.Definition) *dto.DefinitionInfo {
+	return &dto.DefinitionInfo{
+		FilePath:   node.Path,
+		Name:       node.Name,
+		Type:       node.Type,
+		Position:   dto.ToPosition(node.Range),
+		Doc:        node.Doc,
+		Signature:  node.Signature,
+		Decorators: node.Decorators,
+		Container:  node.Container,
+		Confidence: node.Confidence,
+		Reason:     node.Reason,
+		Ambiguous:  node.Ambiguous,
+	}
+}
+
+func (l *codebaseService) Hover(ctx context.Context, req *dto.HoverRequest) (*dto.HoverData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if !filepath.IsAbs(req.FilePath) {
+		return nil, errs.NewParamError("param filePath must be absolute path")
+	}
+	if err := l.checkPath(ctx, req.CodebasePath, []string{req.FilePath}); err != nil {
+		return nil, err
+	}
+	if _, err := lang.InferLanguage(req.FilePath); err != nil {
+		return nil, errs.ErrUnSupportedLanguage
+	}
+
+	hover, err := l.indexer.QueryHover(ctx, &types.QueryHoverOptions{
+		Workspace: req.CodebasePath,
+		FilePath:  req.FilePath,
+		Line:      req.Line,
+		Column:    req.Column,
 	})
 	if err != nil {
 		return nil, err
 	}
+	data := &dto.HoverData{
+		Name:     hover.Name,
+		Position: dto.ToPosition(hover.Range),
+	}
+	for i, d := range hover.Definitions {
+		if i == 0 {
+			data.Definition = toDefinitionInfo(d)
+			continue
+		}
+		data.Candidates = append(data.Candidates, toDefinitionInfo(d))
+	}
+	return data, nil
+}
 
-	// 填充content，控制层数和节点数
-	definitions, err := l.convert2DefinitionInfo(ctx, nodes, definitionFillContentNodeLimit)
+func (l *codebaseService) SearchBatch(ctx context.Context, req *dto.SearchBatchRequest) (*dto.SearchBatchData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if len(req.Queries) > maxBatchQueries {
+		return nil, errs.NewParamError("too many queries: %d, max %d", len(req.Queries), maxBatchQueries)
+	}
+
+	// 同一请求内的检索共享项目解析及已解码的 FileElementTable
+	ctx = withQuerySession(ctx)
+	results := make([]*dto.BatchQueryResult, len(req.Queries))
+	queries := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(maxBatchConcurrency, len(req.Queries)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queries {
+				results[idx] = l.searchBatchItem(ctx, req.CodebasePath, req.Queries[idx])
+			}
+		}()
+	}
+	for idx := range req.Queries {
+		queries <- idx
+	}
+	close(queries)
+	wg.Wait()
+	return &dto.SearchBatchData{Results: results}, nil
+}
+
+func (l *codebaseService) searchBatchItem(ctx context.Context, codebasePath string, query *dto.BatchQuery) *dto.BatchQueryResult {
+	result := &dto.BatchQueryResult{Id: query.Id, Type: query.Type}
+	var data interface{}
+	var err error
+	switch query.Type {
+	case dto.BatchQueryDefinition:
+		data, err = l.QueryDefinition(ctx, &dto.SearchDefinitionRequest{
+			CodebasePath:  codebasePath,
+			FilePath:      query.FilePath,
+			StartLine:     query.StartLine,
+			EndLine:       query.EndLine,
+			CodeSnippet:   query.CodeSnippet,
+			MinConfidence: query.MinConfidence,
+		})
+	case dto.BatchQueryReference:
+		data, err = l.QueryReference(ctx, &dto.SearchReferenceRequest{
+			CodebasePath: codebasePath,
+			FilePath:     query.FilePath,
+			StartLine:    query.StartLine,
+			EndLine:      query.EndLine,
+			SymbolName:   query.SymbolName,
+		})
+	case dto.BatchQueryStructure:
+		if err = l.checkPath(ctx, codebasePath, []string{query.FilePath}); err == nil {
+			data, err = l.ParseFileDefinitions(ctx, &dto.GetFileStructureRequest{
+				CodebasePath: codebasePath,
+				FilePath:     query.FilePath,
+				Types:        query.Types,
+			})
+		}
+	default:
+		err = fmt.Errorf("unsupported query type %s", query.Type)
+	}
 	if err != nil {
-		l.logger.Error("fill definition query contents err:%v", err)
+		l.logger.Debug("batch query %s %s err:%v", query.Type, query.FilePath, err)
+		result.Error = err.Error()
+		return result
+	}
+	result.Data = data
+	return result
+}
+
+// UpdateBuffer 解析未保存文档的内容并保存到覆盖层，版本号不大于已有版本的请求被忽略
+func (l *codebaseService) UpdateBuffer(ctx context.Context, req *dto.UpdateBufferRequest) (*dto.BufferData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if !filepath.IsAbs(req.FilePath) {
+		return nil, errs.NewParamError("param filePath must be absolute path")
+	}
+	if err := l.checkPath(ctx, req.CodebasePath, []string{req.FilePath}); err != nil {
+		return nil, err
+	}
+	if _, err := lang.InferLanguage(req.FilePath); err != nil {
+		return nil, errs.ErrUnSupportedLanguage
+	}
+
+	version, err := l.indexer.UpdateBuffer(ctx, req.CodebasePath, req.FilePath, req.Version, []byte(req.Content))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.BufferData{FilePath: req.FilePath, Version: version}, nil
+}
+
+// CloseBuffer 丢弃文档的覆盖层，之后的查询以索引为准
+func (l *codebaseService) CloseBuffer(ctx context.Context, req *dto.CloseBufferRequest) error {
+	if !filepath.IsAbs(req.FilePath) {
+		return errs.NewParamError("param filePath must be absolute path")
+	}
+	if err := l.checkPath(ctx, req.CodebasePath, []string{req.FilePath}); err != nil {
+		return err
 	}
+	return l.indexer.CloseBuffer(ctx, req.CodebasePath, req.FilePath)
+}
+
+// GetGovernorState 后台索引当前的并发、暂停状态及采样的系统负载
+func (l *codebaseService) GetGovernorState() *types.GovernorState {
+	return l.indexer.GetGovernorState()
+}
 
-	return &dto.DefinitionData{List: definitions}, nil
+// GetDroppedFiles 工作区文件数超过上限时按优先级丢弃的文件
+func (l *codebaseService) GetDroppedFiles(workspacePath string) (int, []string) {
+	return l.indexer.GetDroppedFiles(workspacePath)
+}
+
+func (l *codebaseService) Rename(ctx context.Context, req *dto.RenameRequest) (*dto.RenameData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if !filepath.IsAbs(req.FilePath) {
+		return nil, errs.NewParamError("param filePath must be absolute path")
+	}
+	if err := l.checkPath(ctx, req.CodebasePath, []string{req.FilePath}); err != nil {
+		return nil, err
+	}
+	if _, err := lang.InferLanguage(req.FilePath); err != nil {
+		return nil, errs.ErrUnSupportedLanguage
+	}
+
+	res, err := l.indexer.QueryRename(ctx, &types.QueryRenameOptions{
+		Workspace: req.CodebasePath,
+		FilePath:  req.FilePath,
+		Line:      req.Line,
+		Column:    req.Column,
+		NewName:   req.NewName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &dto.RenameData{
+		Name:       res.Name,
+		NewName:    res.NewName,
+		Definition: toDefinitionInfo(res.Definition),
+		Changes:    make([]*dto.FileEdit, 0, len(res.Edits)),
+	}
+	for path, edits := range res.Edits {
+		fileEdit := &dto.FileEdit{FilePath: path, Edits: make([]*dto.TextEditInfo, 0, len(edits))}
+		for _, e := range edits {
+			fileEdit.Edits = append(fileEdit.Edits, &dto.TextEditInfo{Position: dto.ToPosition(e.Range), NewText: e.NewText})
+		}
+		data.Changes = append(data.Changes, fileEdit)
+	}
+	sort.Slice(data.Changes, func(i, j int) bool {
+		return data.Changes[i].FilePath < data.Changes[j].FilePath
+	})
+	for _, c := range res.Conflicts {
+		data.Conflicts = append(data.Conflicts, &dto.RenameConflictInfo{
+			Kind:     c.Kind,
+			FilePath: c.Path,
+			Name:     c.Name,
+			Position: dto.ToPosition(c.Range),
+			Message:  c.Message,
+		})
+	}
+	return data, nil
 }
 
 func (l *codebaseService) convert2DefinitionInfo(ctx context.Context, nodes []*types.Definition, nodeLimit int) ([]*dto.DefinitionInfo, error) {
@@ -442,36 +870,160 @@ func (l *codebaseService) convert2DefinitionInfo(ctx context.Context, nodes []*t
 		if i >= nodeLimit {
 			break
 		}
-		position := dto.ToPosition(node.Range)
-		// 读取文件内容
-		content, err := l.workspaceReader.ReadFile(ctx, node.Path, types.ReadOptions{
-			StartLine: position.StartLine,
-			EndLine:   position.EndLine,
-		})
-		def := &dto.DefinitionInfo{
-			FilePath: node.Path,
-			Name:     node.Name,
-			Type:     node.Type,
-			Position: position,
-		}
-		definitions = append(definitions, def)
-
-		if err != nil {
-			l.logger.Error("read file content failed: %v", err)
-			continue
-		}
-		// 设置节点内容
-		def.Content = string(content)
+		definitions = append(definitions, l.definitionInfoWithContent(ctx, node))
 	}
 
 	return definitions, nil
 }
 
+// definitionInfoWithContent 转换定义并读取其内容
+func (l *codebaseService) definitionInfoWithContent(ctx context.Context, node *types.Definition) *dto.DefinitionInfo {
+	position := dto.ToPosition(node.Range)
+	// 读取文件内容
+	content, err := l.workspaceReader.ReadFile(ctx, node.Path, types.ReadOptions{
+		StartLine: position.StartLine,
+		EndLine:   position.EndLine,
+	})
+	def := toDefinitionInfo(node)
+	if err != nil {
+		l.logger.Error("read file content failed: %v", err)
+		return def
+	}
+	// 设置节点内容
+	def.Content = string(content)
+	return def
+}
+
 const relationFillContentLayerLimit = 2
 const relationFillContentLayerNodeLimit = 10
 
 func (l *codebaseService) QueryReference(ctx context.Context, req *dto.SearchReferenceRequest) (resp *dto.ReferenceData, err error) {
+	opts, err := l.referenceOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	page, err := parsePagination(req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*types.RelationNode
+	var nextCursor string
+	if page == nil {
+		nodes, err = l.indexer.QueryReferences(ctx, opts)
+	} else {
+		nodes, nextCursor, err = l.queryReferencePage(ctx, opts, page)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 填充content，控制层数和节点数
+	if err = l.fillContent(ctx, nodes, relationFillContentLayerLimit, relationFillContentLayerNodeLimit); err != nil {
+		l.logger.Error("fill graph query contents err:%v", err)
+	}
+
+	return &dto.ReferenceData{
+		List:       nodes,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// queryReferencePage 按索引遍历顺序分页引用，根定义每页都返回，取到下一页的第一个引用时停止遍历
+func (l *codebaseService) queryReferencePage(ctx context.Context, opts *types.QueryReferenceOptions, page *pagination) (
+	[]*types.RelationNode, string, error) {
+	var nodes []*types.RelationNode
+	var nextCursor string
+	count := 0
+	err := l.indexer.WalkReferences(ctx, opts, func(definition *types.RelationNode, reference *types.RelationNode) error {
+		if reference == nil {
+			nodes = append(nodes, definition)
+			return nil
+		}
+		if count >= page.Offset+page.Limit {
+			nextCursor = page.nextCursor(count + 1)
+			return errStopWalk
+		}
+		if count >= page.Offset {
+			definition.Children = append(definition.Children, reference)
+		}
+		count++
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, types.EmptyString, err
+	}
+	return nodes, nextCursor, nil
+}
+
+// StreamReference 随索引遍历流式输出引用：先输出根定义，再逐个输出引用
+func (l *codebaseService) StreamReference(c *gin.Context, req *dto.SearchReferenceRequest) error {
+	opts, err := l.referenceOptions(req)
+	if err != nil {
+		return err
+	}
+	page, err := parsePagination(req.Limit, req.Cursor)
+	if err != nil {
+		return err
+	}
+	writer, err := response.NewEventWriter(c, req.Stream)
+	if err != nil {
+		return err
+	}
+
+	definitionIndexes := make(map[*types.RelationNode]int)
+	childCounts := make(map[*types.RelationNode]int)
+	var nextCursor string
+	count, written := 0, 0
+	err = l.indexer.WalkReferences(c, opts, func(definition *types.RelationNode, reference *types.RelationNode) error {
+		if reference == nil {
+			idx := len(definitionIndexes)
+			definitionIndexes[definition] = idx
+			if idx < relationFillContentLayerNodeLimit {
+				l.readNodeContent(c, definition)
+			}
+			return writer.WriteEvent(dto.StreamEventDefinition, &dto.RelationStreamEvent{
+				Type:         dto.StreamEventDefinition,
+				Definition:   idx,
+				RelationNode: definition,
+			})
+		}
+		if page != nil {
+			if count >= page.Offset+page.Limit {
+				nextCursor = page.nextCursor(count + 1)
+				return errStopWalk
+			}
+			if count < page.Offset {
+				count++
+				return nil
+			}
+		}
+		count++
+		idx := definitionIndexes[definition]
+		// 与非流式接口一致，只为前几个定义的前几个引用填充内容
+		if idx < relationFillContentLayerNodeLimit && childCounts[definition] < relationFillContentLayerNodeLimit {
+			l.readNodeContent(c, reference)
+		}
+		childCounts[definition]++
+		written++
+		return writer.WriteEvent(dto.StreamEventReference, &dto.RelationStreamEvent{
+			Type:         dto.StreamEventReference,
+			Definition:   idx,
+			RelationNode: reference,
+		})
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		if !writer.Started() {
+			return err
+		}
+		l.logger.Error("stream reference err: %v", err)
+		return writer.WriteEvent(dto.StreamEventError, &dto.StreamError{Type: dto.StreamEventError, Message: err.Error()})
+	}
+	return writer.WriteEvent(dto.StreamEventDone, &dto.StreamDone{Type: dto.StreamEventDone, Count: written, NextCursor: nextCursor})
+}
 
+// referenceOptions 校验引用检索参数
+func (l *codebaseService) referenceOptions(req *dto.SearchReferenceRequest) (*types.QueryReferenceOptions, error) {
 	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
 		return nil, errs.ErrIndexDisabled
 	}
@@ -485,27 +1037,15 @@ func (l *codebaseService) QueryReference(ctx context.Context, req *dto.SearchRef
 	}
 
 	if !filepath.IsAbs(req.FilePath) {
-		return nil, fmt.Errorf("param filePath must be absolute path")
+		return nil, errs.NewParamError("param filePath must be absolute path")
 	}
 
-	nodes, err := l.indexer.QueryReferences(ctx, &types.QueryReferenceOptions{
+	return &types.QueryReferenceOptions{
 		Workspace:  req.CodebasePath,
 		FilePath:   req.FilePath,
 		StartLine:  req.StartLine,
 		EndLine:    req.EndLine,
 		SymbolName: req.SymbolName,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// 填充content，控制层数和节点数
-	if err = l.fillContent(ctx, nodes, relationFillContentLayerLimit, relationFillContentLayerNodeLimit); err != nil {
-		l.logger.Error("fill graph query contents err:%v", err)
-	}
-
-	return &dto.ReferenceData{
-		List: nodes,
 	}, nil
 }
 
@@ -521,19 +1061,10 @@ func (l *codebaseService) fillContent(ctx context.Context, nodes []*types.Relati
 		}
 
 		// 读取文件内容
-		content, err := l.workspaceReader.ReadFile(ctx, node.FilePath, types.ReadOptions{
-			StartLine: node.Position.StartLine,
-			EndLine:   node.Position.EndLine,
-		})
-
-		if err != nil {
-			l.logger.Error("read file content failed: %v", err)
+		if !l.readNodeContent(ctx, node) {
 			continue
 		}
 
-		// 设置节点内容
-		node.Content = string(content)
-
 		// 如果还没有达到层级限制且有子节点，递归处理子节点
 		if layerLimit > 1 && len(node.Children) > 0 {
 			if err := l.fillContent(ctx, node.Children, layerLimit-1, layerNodeLimit); err != nil {
@@ -545,6 +1076,21 @@ func (l *codebaseService) fillContent(ctx context.Context, nodes []*types.Relati
 	return nil
 }
 
+// readNodeContent 读取节点内容，失败时返回 false
+func (l *codebaseService) readNodeContent(ctx context.Context, node *types.RelationNode) bool {
+	content, err := l.workspaceReader.ReadFile(ctx, node.FilePath, types.ReadOptions{
+		StartLine: node.Position.StartLine,
+		EndLine:   node.Position.EndLine,
+	})
+	if err != nil {
+		l.logger.Error("read file content failed: %v", err)
+		return false
+	}
+	// 设置节点内容
+	node.Content = string(content)
+	return true
+}
+
 func (l *codebaseService) Summarize(ctx context.Context, req *dto.GetIndexSummaryRequest) (*dto.IndexSummary, error) {
 
 	// 从存储获取数量
@@ -589,6 +1135,210 @@ func (l *codebaseService) DeleteIndex(ctx context.Context, req *dto.DeleteIndexR
 	return nil
 }
 
+func (l *codebaseService) GetRepoMap(ctx context.Context, req *dto.GetRepoMapRequest) (*dto.RepoMapData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if req.CodebasePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("codebasePath")
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = repomap.DefaultMaxTokens
+	}
+	if req.MaxTokens > maxRepoMapTokens {
+		req.MaxTokens = maxRepoMapTokens
+	}
+	for _, f := range req.EditedFiles {
+		if !filepath.IsAbs(f) {
+			return nil, errs.NewParamError("param editedFiles must be absolute path")
+		}
+	}
+
+	generator := repomap.NewGenerator()
+	err := l.WalkIndex(ctx, req.CodebasePath, func(key string, value proto.Message) error {
+		if table, ok := value.(*codegraphpb.FileElementTable); ok {
+			generator.Add(table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := generator.Generate(repomap.Options{
+		MaxTokens:       req.MaxTokens,
+		Personalization: req.EditedFiles,
+		BasePath:        req.CodebasePath,
+		TokenCounter:    DefaultTokenEstimator.EstimateTokens,
+	})
+	return &dto.RepoMapData{
+		Content:    res.Content,
+		Tokens:     res.Tokens,
+		TotalFiles: res.TotalFiles,
+		List:       res.Definitions,
+	}, nil
+}
+
+func (l *codebaseService) SearchText(ctx context.Context, req *dto.SearchTextRequest) (*dto.TextSearchData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if req.CodebasePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("codebasePath")
+	}
+	if strings.TrimSpace(req.Query) == types.EmptyString {
+		return nil, errs.NewMissingParamError("query")
+	}
+	if req.Limit <= 0 {
+		req.Limit = textindex.DefaultSearchLimit
+	}
+	if req.Limit > maxTextSearchLimit {
+		req.Limit = maxTextSearchLimit
+	}
+
+	hits, err := l.indexer.QueryText(ctx, &types.QueryTextOptions{
+		Workspace:    req.CodebasePath,
+		Query:        req.Query,
+		Limit:        req.Limit,
+		IncludePaths: req.IncludePaths,
+		ExcludePaths: req.ExcludePaths,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	terms := textindex.QueryTerms(req.Query)
+	list := make([]*dto.TextSearchResult, 0, len(hits))
+	for _, h := range hits {
+		result := &dto.TextSearchResult{
+			FilePath:     h.Path,
+			Score:        h.Score,
+			MatchedTerms: h.MatchedTerms,
+		}
+		content, err := l.workspaceReader.ReadFile(ctx, h.Path, types.ReadOptions{})
+		if err != nil {
+			// 文件已删除但索引未更新，仍返回结果
+			l.logger.Debug("search text read file %s err:%v", h.Path, err)
+		} else {
+			result.Snippets = textindex.Highlight(content, terms, req.MaxSnippets)
+		}
+		list = append(list, result)
+	}
+	return &dto.TextSearchData{List: list}, nil
+}
+
+func (l *codebaseService) SearchSemantic(ctx context.Context, req *dto.SearchSemanticRequest) (*dto.SemanticSearchData, error) {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if req.CodebasePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("codebasePath")
+	}
+	if strings.TrimSpace(req.Query) == types.EmptyString {
+		return nil, errs.NewMissingParamError("query")
+	}
+	if req.Limit <= 0 {
+		req.Limit = vector.DefaultSearchLimit
+	}
+	if req.Limit > maxTextSearchLimit {
+		req.Limit = maxTextSearchLimit
+	}
+
+	hits, err := l.indexer.QuerySemantic(ctx, &types.QuerySemanticOptions{
+		Workspace:    req.CodebasePath,
+		Query:        req.Query,
+		Limit:        req.Limit,
+		Hybrid:       req.Hybrid,
+		IncludePaths: req.IncludePaths,
+		ExcludePaths: req.ExcludePaths,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一文件只读取一次
+	contents := make(map[string][]byte)
+	list := make([]*dto.SemanticSearchResult, 0, len(hits))
+	for _, h := range hits {
+		c := h.Chunk
+		result := &dto.SemanticSearchResult{
+			FilePath:   c.FilePath,
+			Language:   c.Language,
+			SymbolPath: c.SymbolPath,
+			Kind:       c.Kind,
+			Position:   dto.ToPosition(c.Range),
+			Score:      h.Score,
+		}
+		content, ok := contents[c.FilePath]
+		if !ok {
+			content, err = l.workspaceReader.ReadFile(ctx, c.FilePath, types.ReadOptions{})
+			if err != nil {
+				l.logger.Debug("search semantic read file %s err:%v", c.FilePath, err)
+			}
+			contents[c.FilePath] = content
+		}
+		if c.EndByte <= uint(len(content)) && c.StartByte < c.EndByte {
+			result.Content = string(content[c.StartByte:c.EndByte])
+		}
+		list = append(list, result)
+	}
+	return &dto.SemanticSearchData{List: list}, nil
+}
+
+// SearchStructural 执行结构化检索并逐行输出捕获，最后输出统计。
+// 输出开始前的错误（如查询语法错误）直接返回，开始后的错误以 error 行输出
+func (l *codebaseService) SearchStructural(c *gin.Context, req *dto.SearchStructuralRequest) error {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return errs.ErrIndexDisabled
+	}
+	if strings.TrimSpace(req.Query) == types.EmptyString {
+		return errs.NewMissingParamError("query")
+	}
+	if err := l.checkPath(c, req.CodebasePath, nil); err != nil {
+		return err
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultStructuralSearchLimit
+	}
+	if req.Limit > maxStructuralSearchLimit {
+		req.Limit = maxStructuralSearchLimit
+	}
+
+	writer := response.NewJsonLinesWriter(c)
+	stats, err := l.indexer.QueryStructural(c, &types.QueryStructuralOptions{
+		Workspace:    req.CodebasePath,
+		Language:     req.Language,
+		Query:        req.Query,
+		Limit:        req.Limit,
+		IncludePaths: req.IncludePaths,
+		ExcludePaths: req.ExcludePaths,
+	}, func(capture *structural.Capture) error {
+		return writer.Write(&dto.StructuralCapture{
+			Type:      dto.StructuralEventCapture,
+			FilePath:  capture.FilePath,
+			Capture:   capture.Name,
+			Pattern:   capture.Pattern,
+			Match:     capture.Match,
+			Position:  dto.ToPosition(capture.Range),
+			Text:      capture.Text,
+			Truncated: capture.Truncated,
+		})
+	})
+	if err != nil {
+		if !writer.Started() {
+			return err
+		}
+		l.logger.Error("search structural err: %v", err)
+		return writer.Write(&dto.StructuralSearchError{Type: dto.StructuralEventError, Message: err.Error()})
+	}
+	return writer.Write(&dto.StructuralSearchDone{
+		Type:      dto.StructuralEventDone,
+		Files:     stats.Files,
+		Matched:   stats.Matched,
+		Captures:  stats.Captures,
+		Truncated: stats.Truncated,
+	})
+}
+
 func convertStatus(status int) string {
 	var indexStatus string
 	switch status {
@@ -156,6 +156,11 @@ func (c *CodegraphProcessor) ProcessRebuildWorkspaceEvent(ctx context.Context, e
 }
 
 func (c *CodegraphProcessor) ProcessOpenWorkspaceEvent(ctx context.Context, event *model.Event) error {
+	return c.processOpenWorkspace(ctx, event, false)
+}
+
+// processOpenWorkspace 构建工作区索引，resumed 为让出或中断后继续的构建，保留已有进度
+func (c *CodegraphProcessor) processOpenWorkspace(ctx context.Context, event *model.Event, resumed bool) error {
 	// TODO 增加比对逻辑，如果构建过索引，进行比对。
 	fileInfo, err := c.workspaceReader.Stat(event.WorkspacePath)
 	if errors.Is(err, workspace.ErrPathNotExists) {
@@ -176,18 +181,24 @@ func (c *CodegraphProcessor) ProcessOpenWorkspaceEvent(ctx context.Context, even
 		return nil
 	}
 
-	// 更新进度为0，成功后再更新总进度。
-	err = c.workspaceRepo.UpdateCodegraphInfo(event.WorkspacePath, 0, time.Now().Unix())
-	if err != nil {
-		c.logger.Error("codegraph failed to process open_workspace event event, workspace %s reset successful file num failed, err:%v",
-			event.WorkspacePath, err)
-		if err = c.updateEventStatusFinally(event, err); err != nil {
-			return fmt.Errorf("codegraph update open_workspace event err: %w", err)
+	// 更新进度为0，成功后再更新总进度。继续的构建保留已有进度
+	if !resumed {
+		err = c.workspaceRepo.UpdateCodegraphInfo(event.WorkspacePath, 0, time.Now().Unix())
+		if err != nil {
+			c.logger.Error("codegraph failed to process open_workspace event event, workspace %s reset successful file num failed, err:%v",
+				event.WorkspacePath, err)
+			if err = c.updateEventStatusFinally(event, err); err != nil {
+				return fmt.Errorf("codegraph update open_workspace event err: %w", err)
+			}
+			return err
 		}
-		return err
 	}
 	// todo open_workspace过程中会更新进度，其余事件结束更新进度。
 	_, err = c.indexer.IndexWorkspace(ctx, event.WorkspacePath)
+	if errors.Is(context.Cause(ctx), errIndexYielded) {
+		// 让出给文件事件，保持构建中状态，下次按时间戳过滤已索引的文件继续
+		return errIndexYielded
+	}
 	if err = c.updateEventStatusFinally(event, err); err != nil {
 		return fmt.Errorf("codegraph update modify event %d err: %w", event.ID, err)
 	}
@@ -195,139 +206,131 @@ func (c *CodegraphProcessor) ProcessOpenWorkspaceEvent(ctx context.Context, even
 	return nil
 }
 
-// ProcessEvents 处理事件记录
+// ProcessEvents 按优先级处理各工作区的事件：打开的文件、最近修改的文件及活跃工作区优先，工作区的打开、重建最后。
+// 同一工作区的文件事件静默一段时间后合并成一批处理。工作区构建期间有新的文件事件时让出，本轮结束，下一轮先处理文件事件，之后继续构建
 func (c *CodegraphProcessor) ProcessEvents(ctx context.Context, workspacePaths []string) error {
-
-	codegraphStatuses := []int{
-		model.CodegraphStatusInit,
-	}
-	// 重新构建事件
-	rebuildEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeRebuildWorkspace}, workspacePaths, 10,
-		false, nil, codegraphStatuses)
-
+	// 构建中的工作区事件为之前让出或进程退出时中断的构建，继续处理
+	workspaceEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces(workspaceEventTypes, workspacePaths,
+		queueWorkspaceLimit, false, nil, []int{model.CodegraphStatusInit, model.CodegraphStatusBuilding})
 	if err != nil {
-		c.logger.Error("failed to get rebuild_workspace events: %v", err)
-		return fmt.Errorf("failed to get rebuild_workspace events: %w", err)
+		c.logger.Error("failed to get workspace events: %v", err)
+		return fmt.Errorf("failed to get workspace events: %w", err)
 	}
 
-	// 处理重新构建事件
-	for _, event := range rebuildEvents {
-		// 转换路径
-		c.convertWorkspaceFilePathToAbs(event)
-		c.logger.Info("codegraph start to process rebuild_workspace event: %s", event.WorkspacePath)
-		err = c.ProcessRebuildWorkspaceEvent(ctx, event)
-		if err != nil {
-			c.logger.Error("failed to process rebuild_workspace event for codegraph: %v", err)
-			continue
-		}
-		c.logger.Info("codegraph process rebuild_workspace event successfully: %s", event.WorkspacePath)
-	}
-
-	// 打开工作区事件
-	openEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeOpenWorkspace}, workspacePaths, 10,
-		false, nil, codegraphStatuses)
-
+	fileEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+		queueFetchLimit, false, nil, []int{model.CodegraphStatusInit})
 	if err != nil {
-		c.logger.Error("failed to get open_workspace events: %v", err)
-		return fmt.Errorf("failed to get open_workspace events: %w", err)
+		c.logger.Error("failed to get file events: %v", err)
+		return fmt.Errorf("failed to get file events: %w", err)
 	}
 
-	// 处理打开工作区事件
-	for _, event := range openEvents {
-		c.convertWorkspaceFilePathToAbs(event)
-		c.logger.Info("codegraph start to process open_workspace event: %s", event.WorkspacePath)
-		err = c.ProcessOpenWorkspaceEvent(ctx, event)
-		if err != nil {
-			c.logger.Error("failed to process open_workspace event for codegraph: %v", err)
-			continue
+	// 按工作区合并文件事件，仍在持续变化的工作区等待下一轮
+	pending := readyFileEvents(fileEvents, time.Now())
+	events := workspaceEvents
+	for _, event := range fileEvents {
+		if _, ok := pending[event.WorkspacePath]; ok {
+			events = append(events, event)
 		}
-		c.logger.Info("codegraph process open_workspace event successfully: %s", event.WorkspacePath)
 	}
-
-	// 处理添加文件事件
-	addEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeAddFile}, workspacePaths, 10,
-		false, nil, codegraphStatuses)
-
-	if err != nil {
-		c.logger.Error("failed to get add file events: %v", err)
-		return fmt.Errorf("failed to get add file events: %w", err)
+	if len(events) == 0 {
+		return nil
 	}
-
-	// 处理添加文件事件
-	for _, event := range addEvents {
+	for _, event := range events {
+		// 转换路径
 		c.convertWorkspaceFilePathToAbs(event)
-		c.logger.Info("codegraph start to process add_file event: %s", event.SourceFilePath)
-		err = c.ProcessAddFileEvent(ctx, event)
-		if err != nil {
-			c.logger.Error("failed to process add file event for codegraph: %v", err)
+	}
+	hints := newQueueHints(c.indexer.GetOpenBuffers(), workspacePaths, events)
+	queue := newIndexQueue(events, hints)
+	for queue.Len() > 0 {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		item := queue.pop()
+		if !isFileEvent(item.event) {
+			err = c.processWorkspaceEvent(ctx, item.event, workspacePaths)
+			if errors.Is(err, errIndexYielded) {
+				c.logger.Info("codegraph %s event yielded to interactive events: %s", item.event.EventType,
+					item.event.WorkspacePath)
+				return nil
+			}
 			continue
 		}
-		c.logger.Info("codegraph process add_file event successfully: %s", event.SourceFilePath)
-	}
-
-	// 处理修改文件事件
-	modifyEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeModifyFile}, workspacePaths, 10,
-		false, nil, codegraphStatuses)
-
-	if err != nil {
-		c.logger.Error("failed to get modify file events: %v", err)
-		return fmt.Errorf("failed to get modify file events: %w", err)
-	}
-
-	for _, event := range modifyEvents {
-		c.convertWorkspaceFilePathToAbs(event)
-		c.logger.Info("codegraph start to process modify_file event: %s", event.SourceFilePath)
-		err = c.ProcessModifyFileEvent(ctx, event)
-		if err != nil {
-			c.logger.Error("failed to process modify file event for codegraph: %v", err)
+		// 工作区的文件事件在其中优先级最高的事件出队时一起处理
+		batch, ok := pending[item.event.WorkspacePath]
+		if !ok {
 			continue
 		}
-		c.logger.Info("codegraph process modify_file event successfully: %s", event.SourceFilePath)
-	}
-
-	// 处理删除文件事件
-	deleteEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeDeleteFile}, workspacePaths, 10,
-		false, nil, codegraphStatuses)
-
-	if err != nil {
-		c.logger.Error("failed to get delete file events: %v", err)
-		return fmt.Errorf("failed to get delete file events: %w", err)
+		delete(pending, item.event.WorkspacePath)
+		if err = c.processFileEvents(ctx, item.event.WorkspacePath, batch); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	for _, event := range deleteEvents {
-		c.convertWorkspaceFilePathToAbs(event)
-		c.logger.Info("codegraph start to process delete_file event: %s", event.SourceFilePath)
-		err = c.ProcessDeleteFileEvent(ctx, event)
-		if err != nil {
-			c.logger.Error("failed to process delete file event for codegraph: %v", err)
-			continue
+// processWorkspaceEvent 处理工作区的打开、重建事件，标记为构建中，有新的文件事件时让出。
+// 重建在删除索引完成后才标记为构建中，删除期间让出或中断时事件保持初始状态，下次重新删除
+func (c *CodegraphProcessor) processWorkspaceEvent(ctx context.Context, event *model.Event, workspacePaths []string) error {
+	resumed := event.CodegraphStatus == model.CodegraphStatusBuilding
+	yieldCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go c.yieldOnFileEvents(yieldCtx, cancel, workspacePaths)
+
+	c.logger.Info("codegraph start to process %s event: %s, resumed: %v", event.EventType, event.WorkspacePath, resumed)
+	if event.EventType == model.EventTypeRebuildWorkspace && !resumed {
+		if err := c.indexer.RemoveAllIndexes(yieldCtx, event.WorkspacePath); err != nil {
+			if errors.Is(context.Cause(yieldCtx), errIndexYielded) {
+				return errIndexYielded
+			}
+			c.logger.Error("failed to remove indexes for %s event: %v", event.EventType, err)
+			if err = c.updateEventStatusFinally(event, err); err != nil {
+				return fmt.Errorf("codegraph update rebuild_workspace event %d err: %w", event.ID, err)
+			}
+			return err
 		}
-		c.logger.Info("codegraph process delete_file event successfully: %s", event.SourceFilePath)
 	}
-
-	// 处理重命名事件
-	renameEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeRenameFile}, workspacePaths, 10,
-		false, nil, codegraphStatuses)
-
+	if err := c.eventRepo.UpdateEvent(&model.Event{ID: event.ID, CodegraphStatus: model.CodegraphStatusBuilding}); err != nil {
+		c.logger.Error("codegraph update %s event %d to building err: %v", event.EventType, event.ID, err)
+		return err
+	}
+	// 中断的重建已删除过索引，继续构建
+	err := c.processOpenWorkspace(yieldCtx, event, resumed)
 	if err != nil {
-		c.logger.Error("failed to get rename file events: %v", err)
-		return fmt.Errorf("failed to get rename file events: %w", err)
+		if !errors.Is(err, errIndexYielded) {
+			c.logger.Error("failed to process %s event for codegraph: %v", event.EventType, err)
+		}
+		return err
 	}
+	c.logger.Info("codegraph process %s event successfully: %s", event.EventType, event.WorkspacePath)
+	return nil
+}
 
-	for _, event := range renameEvents {
-		c.convertWorkspaceFilePathToAbs(event)
-		c.logger.Info("codegraph start to process rename_file event: source %s target %s",
-			event.SourceFilePath, event.TargetFilePath)
-		err = c.ProcessRenameFileEvent(ctx, event)
-		if err != nil {
-			c.logger.Error("failed to process rename file event for codegraph: %v", err)
-			continue
+// yieldOnFileEvents 工作区构建期间定期检查是否有合并完成、可以处理的文件事件，有则取消构建，仍在合并等待中的事件不让出。
+// 构建至少运行 queueMinBuildSlice 后才会让出，持续少量的文件事件不会使冷构建一直无法推进
+func (c *CodegraphProcessor) yieldOnFileEvents(ctx context.Context, cancel context.CancelCauseFunc, workspacePaths []string) {
+	ticker := time.NewTicker(queueYieldCheckInterval)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if time.Since(start) < queueMinBuildSlice {
+				continue
+			}
+			events, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+				queueFetchLimit, false, nil, []int{model.CodegraphStatusInit})
+			if err != nil {
+				c.logger.Debug("codegraph check pending file events err: %v", err)
+				continue
+			}
+			if len(readyFileEvents(events, time.Now())) > 0 {
+				cancel(errIndexYielded)
+				return
+			}
 		}
-		c.logger.Info("codegraph process rename_file event successfully: source %s target %s",
-			event.SourceFilePath, event.TargetFilePath)
-
 	}
-	return nil
 }
 
 func (c *CodegraphProcessor) updateEventStatusFinally(event *model.Event, err error) error {
@@ -9,9 +9,11 @@ import (
 	"errors"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestCodegraphProcessor_ProcessActiveWorkspaces(t *testing.T) {
@@ -1091,3 +1093,105 @@ func TestCodegraphProcessor_convertFilePathToAbs(t *testing.T) {
 		})
 	}
 }
+
+func TestCodegraphProcessor_ProcessEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWorkspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	mockWorkspaceReader := mocks.NewMockWorkspaceReader(ctrl)
+	mockIndexer := mocks.NewMockIndexer(ctrl)
+	mockEventRepo := mocks.NewMockEventRepository(ctrl)
+
+	processor := &CodegraphProcessor{
+		workspaceRepo:   mockWorkspaceRepo,
+		logger:          mockLogger,
+		workspaceReader: mockWorkspaceReader,
+		indexer:         mockIndexer,
+		eventRepo:       mockEventRepo,
+	}
+	workspacePaths := []string{"/a", "/b"}
+	old := time.Now().Add(-time.Hour)
+
+	t.Run("打开的文件优先，中断的重建继续构建", func(t *testing.T) {
+		workspaceEvents := []*model.Event{
+			{ID: 1, WorkspacePath: "/a", EventType: model.EventTypeRebuildWorkspace,
+				CodegraphStatus: model.CodegraphStatusBuilding, CreatedAt: old},
+		}
+		fileEvents := []*model.Event{
+			{ID: 2, WorkspacePath: "/a", EventType: model.EventTypeAddFile, SourceFilePath: "/a/x.go", CreatedAt: old},
+			{ID: 3, WorkspacePath: "/b", EventType: model.EventTypeModifyFile, SourceFilePath: "/b/y.go", CreatedAt: old},
+		}
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(workspaceEventTypes, workspacePaths,
+			queueWorkspaceLimit, false, nil, gomock.Any()).Return(workspaceEvents, nil)
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+			queueFetchLimit, false, nil, gomock.Any()).Return(fileEvents, nil)
+		mockIndexer.EXPECT().GetOpenBuffers().Return(map[string]time.Time{"/b/y.go": time.Now()})
+		mockWorkspaceReader.EXPECT().FindProjects(gomock.Any(), gomock.Any(), false, gomock.Any()).Return(nil).Times(2)
+		mockWorkspaceReader.EXPECT().Stat(gomock.Any()).Return(&types.FileInfo{}, nil).Times(2)
+		mockWorkspaceReader.EXPECT().Stat("/a").Return(&types.FileInfo{IsDir: true}, nil)
+		// 继续的构建保留已有进度，不重置为 0
+		mockEventRepo.EXPECT().UpdateEvent(gomock.Any()).Return(nil).Times(4)
+		gomock.InOrder(
+			mockIndexer.EXPECT().IndexFiles(gomock.Any(), "/b", []string{"/b/y.go"}).Return(nil),
+			mockIndexer.EXPECT().IndexFiles(gomock.Any(), "/a", []string{"/a/x.go"}).Return(nil),
+			// 已删除过索引，不再删除
+			mockIndexer.EXPECT().IndexWorkspace(gomock.Any(), "/a").Return(&types.IndexTaskMetrics{}, nil),
+		)
+
+		assert.NoError(t, processor.ProcessEvents(context.Background(), workspacePaths))
+	})
+
+	minBuildSlice := queueMinBuildSlice
+	queueMinBuildSlice = 0
+	defer func() { queueMinBuildSlice = minBuildSlice }()
+
+	t.Run("有新的文件事件时让出", func(t *testing.T) {
+		workspaceEvents := []*model.Event{
+			{ID: 4, WorkspacePath: "/a", EventType: model.EventTypeOpenWorkspace,
+				CodegraphStatus: model.CodegraphStatusInit, CreatedAt: old},
+		}
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(workspaceEventTypes, workspacePaths,
+			queueWorkspaceLimit, false, nil, gomock.Any()).Return(workspaceEvents, nil)
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+			queueFetchLimit, false, nil, gomock.Any()).Return(nil, nil)
+		mockIndexer.EXPECT().GetOpenBuffers().Return(nil)
+		// 标记为构建中，让出后不更新为失败
+		mockEventRepo.EXPECT().UpdateEvent(&model.Event{ID: 4, CodegraphStatus: model.CodegraphStatusBuilding}).Return(nil)
+		mockWorkspaceReader.EXPECT().Stat("/a").Return(&types.FileInfo{IsDir: true}, nil)
+		mockWorkspaceRepo.EXPECT().UpdateCodegraphInfo("/a", 0, gomock.Any()).Return(nil)
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+			queueFetchLimit, false, nil, gomock.Any()).Return([]*model.Event{{ID: 5}}, nil)
+		mockIndexer.EXPECT().IndexWorkspace(gomock.Any(), "/a").DoAndReturn(
+			func(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error) {
+				<-ctx.Done()
+				return &types.IndexTaskMetrics{}, ctx.Err()
+			})
+
+		assert.NoError(t, processor.ProcessEvents(context.Background(), workspacePaths))
+	})
+	t.Run("删除索引期间让出时保持初始状态", func(t *testing.T) {
+		workspaceEvents := []*model.Event{
+			{ID: 6, WorkspacePath: "/a", EventType: model.EventTypeRebuildWorkspace,
+				CodegraphStatus: model.CodegraphStatusInit, CreatedAt: old},
+		}
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(workspaceEventTypes, workspacePaths,
+			queueWorkspaceLimit, false, nil, gomock.Any()).Return(workspaceEvents, nil)
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+			queueFetchLimit, false, nil, gomock.Any()).Return(nil, nil)
+		mockIndexer.EXPECT().GetOpenBuffers().Return(nil)
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, workspacePaths,
+			queueFetchLimit, false, nil, gomock.Any()).Return([]*model.Event{{ID: 7}}, nil)
+		// 未标记为构建中，下一轮重新删除
+		mockIndexer.EXPECT().RemoveAllIndexes(gomock.Any(), "/a").DoAndReturn(
+			func(ctx context.Context, workspacePath string) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+		assert.NoError(t, processor.ProcessEvents(context.Background(), workspacePaths))
+	})
+}
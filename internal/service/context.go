@@ -0,0 +1,497 @@
+package service
+
+import (
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/pkg/codegraph/definition"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 上下文片段类型
+const (
+	ContextKindEnclosing  = "enclosing"
+	ContextKindDefinition = "definition"
+	ContextKindCaller     = "caller"
+	ContextKindOutline    = "outline"
+)
+
+const (
+	defaultContextTokenBudget = 4000
+	maxContextTokenBudget     = 128000
+	contextDefinitionLimit    = 30
+	contextCallerLimit        = 10
+	contextOutlineFileLimit   = 5
+	contextWindowLines        = 3
+	contextMinTruncateTokens  = 32
+)
+
+// 各类片段的基础分，依次为：所在定义 > 使用的符号定义 > 调用方 > 依赖文件大纲
+const (
+	enclosingBaseScore  = 1.0
+	definitionBaseScore = 0.8
+	callerBaseScore     = 0.6
+	outlineBaseScore    = 0.4
+)
+
+// TokenEstimator 估算文本占用的 token 数，可替换为具体模型的分词器
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
+// TokenEstimatorFunc 函数适配 TokenEstimator
+type TokenEstimatorFunc func(text string) int
+
+func (f TokenEstimatorFunc) EstimateTokens(text string) int {
+	return f(text)
+}
+
+// DefaultTokenEstimator 粗略估算：ASCII 字符按 4 字节 1 个 token，其余字符每个 1 个 token
+var DefaultTokenEstimator TokenEstimator = TokenEstimatorFunc(func(text string) int {
+	ascii, others := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			others++
+		}
+	}
+	return (ascii+3)/4 + others
+})
+
+// ContextService 为 LLM 提示词组装代码上下文
+type ContextService interface {
+	// AssembleContext 根据文件及光标范围收集相关代码片段，排序去重后按 token 预算打包
+	AssembleContext(ctx context.Context, req *dto.AssembleContextRequest) (*dto.ContextData, error)
+}
+
+// NewContextService 创建上下文组装服务，estimator 为空时使用 DefaultTokenEstimator
+func NewContextService(
+	manager repository.StorageInterface,
+	workspaceReader workspace.WorkspaceReader,
+	indexer Indexer,
+	fileDefinitionParser *definition.DefParser,
+	estimator TokenEstimator,
+	logger logger.Logger) ContextService {
+	if estimator == nil {
+		estimator = DefaultTokenEstimator
+	}
+	return &contextService{
+		manager:              manager,
+		workspaceReader:      workspaceReader,
+		indexer:              indexer,
+		fileDefinitionParser: fileDefinitionParser,
+		estimator:            estimator,
+		logger:               logger,
+	}
+}
+
+type contextService struct {
+	manager              repository.StorageInterface
+	workspaceReader      workspace.WorkspaceReader
+	indexer              Indexer
+	fileDefinitionParser *definition.DefParser
+	estimator            TokenEstimator
+	logger               logger.Logger
+}
+
+// parsedFile 解析后的文件，用于定位包含某行的定义
+type parsedFile struct {
+	lines       []string
+	definitions []*types.Definition
+}
+
+func (s *contextService) AssembleContext(ctx context.Context, req *dto.AssembleContextRequest) (*dto.ContextData, error) {
+	if s.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return nil, errs.ErrIndexDisabled
+	}
+	if !filepath.IsAbs(req.FilePath) {
+		return nil, errs.NewParamError("param filePath must be absolute path")
+	}
+	if _, err := lang.InferLanguage(req.FilePath); err != nil {
+		return nil, errs.ErrUnSupportedLanguage
+	}
+	if req.EndLine < req.StartLine {
+		req.EndLine = req.StartLine
+	}
+	if req.EndLine-req.StartLine > maxLineLimit {
+		req.EndLine = req.StartLine + maxLineLimit
+	}
+	budget := req.TokenBudget
+	if budget <= 0 {
+		budget = defaultContextTokenBudget
+	}
+	if budget > maxContextTokenBudget {
+		budget = maxContextTokenBudget
+	}
+
+	files := make(map[string]*parsedFile)
+	current, err := s.parseFile(ctx, req.FilePath, files)
+	if err != nil {
+		return nil, err
+	}
+	enclosing := s.enclosingSnippet(req, current)
+
+	candidates := []*dto.ContextSnippet{enclosing}
+	definitions := s.collectDefinitions(ctx, req, enclosing)
+	candidates = append(candidates, definitions...)
+	candidates = append(candidates, s.collectCallers(ctx, req, enclosing, files)...)
+	candidates = append(candidates, s.collectOutlines(ctx, req, definitions, files)...)
+
+	ranked := dedupContextSnippets(rankContextSnippets(candidates))
+	return packContextSnippets(ranked, budget, s.estimator), nil
+}
+
+// enclosingSnippet 光标所在的最内层定义，找不到时取光标附近的行
+func (s *contextService) enclosingSnippet(req *dto.AssembleContextRequest, file *parsedFile) *dto.ContextSnippet {
+	if def := innermostDefinition(file.definitions, int32(req.StartLine-1), int32(req.EndLine-1)); def != nil {
+		return &dto.ContextSnippet{
+			Kind:      ContextKindEnclosing,
+			FilePath:  req.FilePath,
+			Name:      def.Name,
+			StartLine: int(def.Range[0]) + 1,
+			EndLine:   int(def.Range[2]) + 1,
+			Content:   string(def.Content),
+			Score:     enclosingBaseScore,
+		}
+	}
+	return windowSnippet(ContextKindEnclosing, req.FilePath, file.lines,
+		req.StartLine-contextWindowLines, req.EndLine+contextWindowLines, enclosingBaseScore)
+}
+
+// collectDefinitions 所在定义中使用到的符号的定义，多次使用的符号得分更高
+func (s *contextService) collectDefinitions(ctx context.Context, req *dto.AssembleContextRequest,
+	enclosing *dto.ContextSnippet) []*dto.ContextSnippet {
+	defs, err := s.indexer.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+		Workspace: req.CodebasePath,
+		FilePath:  req.FilePath,
+		StartLine: enclosing.StartLine,
+		EndLine:   enclosing.EndLine,
+	})
+	if err != nil {
+		s.logger.Error("assemble context query definitions err:%v", err)
+		return nil
+	}
+	found := make(map[string]*dto.ContextSnippet)
+	var snippets []*dto.ContextSnippet
+	for _, d := range defs {
+		if len(d.Range) != 4 {
+			continue
+		}
+		position := dto.ToPosition(d.Range)
+		// 所在定义本身以及其内部的定义
+		if d.Path == req.FilePath && position.StartLine >= enclosing.StartLine && position.EndLine <= enclosing.EndLine {
+			continue
+		}
+		key := symbolMapKey(d.Path, d.Range)
+		if exist, ok := found[key]; ok {
+			exist.Score += 0.02
+			continue
+		}
+		if len(snippets) >= contextDefinitionLimit {
+			continue
+		}
+		content, err := s.workspaceReader.ReadFile(ctx, d.Path, types.ReadOptions{
+			StartLine: position.StartLine,
+			EndLine:   position.EndLine,
+		})
+		if err != nil {
+			s.logger.Error("read file content failed: %v", err)
+			continue
+		}
+		score := definitionBaseScore
+		if d.Path == req.FilePath {
+			score += 0.05
+		}
+		snippet := &dto.ContextSnippet{
+			Kind:      ContextKindDefinition,
+			FilePath:  d.Path,
+			Name:      d.Name,
+			StartLine: position.StartLine,
+			EndLine:   position.EndLine,
+			Content:   string(content),
+			Score:     score,
+		}
+		found[key] = snippet
+		snippets = append(snippets, snippet)
+	}
+	return snippets
+}
+
+// collectCallers 调用所在定义的代码，取调用处所在的定义
+func (s *contextService) collectCallers(ctx context.Context, req *dto.AssembleContextRequest,
+	enclosing *dto.ContextSnippet, files map[string]*parsedFile) []*dto.ContextSnippet {
+	roots, err := s.indexer.QueryReferences(ctx, &types.QueryReferenceOptions{
+		Workspace: req.CodebasePath,
+		FilePath:  req.FilePath,
+		StartLine: enclosing.StartLine,
+		EndLine:   enclosing.EndLine,
+	})
+	if err != nil {
+		s.logger.Error("assemble context query references err:%v", err)
+		return nil
+	}
+	found := make(map[string]*dto.ContextSnippet)
+	var snippets []*dto.ContextSnippet
+	for _, root := range roots {
+		for _, ref := range root.Children {
+			line := ref.Position.StartLine
+			// 自身递归调用
+			if ref.FilePath == req.FilePath && line >= enclosing.StartLine && line <= enclosing.EndLine {
+				continue
+			}
+			file, err := s.parseFile(ctx, ref.FilePath, files)
+			if err != nil {
+				s.logger.Debug("assemble context parse caller file %s err:%v", ref.FilePath, err)
+				continue
+			}
+			var snippet *dto.ContextSnippet
+			if def := innermostDefinition(file.definitions, int32(line-1), int32(line-1)); def != nil {
+				snippet = &dto.ContextSnippet{
+					Kind:      ContextKindCaller,
+					FilePath:  ref.FilePath,
+					Name:      def.Name,
+					StartLine: int(def.Range[0]) + 1,
+					EndLine:   int(def.Range[2]) + 1,
+					Content:   string(def.Content),
+					Score:     callerBaseScore,
+				}
+			} else {
+				snippet = windowSnippet(ContextKindCaller, ref.FilePath, file.lines,
+					line-contextWindowLines, line+contextWindowLines, callerBaseScore)
+			}
+			key := fmt.Sprintf("%s-%d-%d", snippet.FilePath, snippet.StartLine, snippet.EndLine)
+			if exist, ok := found[key]; ok {
+				exist.Score += 0.02
+				continue
+			}
+			if len(snippets) >= contextCallerLimit {
+				continue
+			}
+			found[key] = snippet
+			snippets = append(snippets, snippet)
+		}
+	}
+	return snippets
+}
+
+// collectOutlines 依赖文件（当前文件的导入解析到的文件）的大纲，按导入匹配数排序；已有定义入选的文件不重复给出大纲
+func (s *contextService) collectOutlines(ctx context.Context, req *dto.AssembleContextRequest,
+	definitions []*dto.ContextSnippet, files map[string]*parsedFile) []*dto.ContextSnippet {
+	imported, err := s.indexer.QueryImportedFiles(ctx, req.CodebasePath, req.FilePath)
+	if err != nil {
+		s.logger.Error("assemble context query imported files err:%v", err)
+		return nil
+	}
+	selected := make(map[string]bool)
+	for _, d := range definitions {
+		selected[d.FilePath] = true
+	}
+	var paths []string
+	for _, p := range imported {
+		if p == req.FilePath || selected[p] {
+			continue
+		}
+		paths = append(paths, p)
+		if len(paths) >= contextOutlineFileLimit {
+			break
+		}
+	}
+
+	var snippets []*dto.ContextSnippet
+	for rank, p := range paths {
+		file, err := s.parseFile(ctx, p, files)
+		if err != nil || len(file.definitions) == 0 {
+			continue
+		}
+		var sb strings.Builder
+		for _, d := range file.definitions {
+			signature := strings.TrimSpace(strings.SplitN(string(d.Content), "\n", 2)[0])
+			if signature == types.EmptyString {
+				signature = d.Name
+			}
+			sb.WriteString(fmt.Sprintf("%d: %s\n", d.Range[0]+1, signature))
+		}
+		snippets = append(snippets, &dto.ContextSnippet{
+			Kind:      ContextKindOutline,
+			FilePath:  p,
+			StartLine: 1,
+			EndLine:   len(file.lines),
+			Content:   sb.String(),
+			Score:     outlineBaseScore + 0.02*float64(len(paths)-rank),
+		})
+	}
+	return snippets
+}
+
+// parseFile 读取并解析文件中的定义，结果在单次请求内缓存
+func (s *contextService) parseFile(ctx context.Context, filePath string, files map[string]*parsedFile) (*parsedFile, error) {
+	if f, ok := files[filePath]; ok {
+		return f, nil
+	}
+	content, err := s.workspaceReader.ReadFile(ctx, filePath, types.ReadOptions{EndLine: maxReadLine})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := s.fileDefinitionParser.Parse(ctx, &types.SourceFile{
+		Path:    filePath,
+		Content: content,
+	}, definition.ParseOptions{IncludeContent: true})
+	if err != nil {
+		return nil, err
+	}
+	f := &parsedFile{lines: strings.Split(string(content), "\n")}
+	for _, d := range parsed.Definitions {
+		if len(d.Range) == 4 {
+			f.definitions = append(f.definitions, d)
+		}
+	}
+	files[filePath] = f
+	return f, nil
+}
+
+// innermostDefinition 包含 [startLine, endLine] 的最小定义（行号从0开始）
+func innermostDefinition(definitions []*types.Definition, startLine, endLine int32) *types.Definition {
+	var res *types.Definition
+	for _, d := range definitions {
+		if d.Range[0] > startLine || d.Range[2] < endLine {
+			continue
+		}
+		if res == nil || d.Range[2]-d.Range[0] < res.Range[2]-res.Range[0] {
+			res = d
+		}
+	}
+	return res
+}
+
+// windowSnippet 截取 [startLine, endLine] 行（从1开始）
+func windowSnippet(kind, filePath string, lines []string, startLine, endLine int, score float64) *dto.ContextSnippet {
+	startLine = max(startLine, 1)
+	endLine = min(endLine, len(lines))
+	if endLine < startLine {
+		endLine = startLine
+	}
+	var content string
+	if startLine <= len(lines) {
+		content = strings.Join(lines[startLine-1:endLine], "\n")
+	}
+	return &dto.ContextSnippet{
+		Kind:      kind,
+		FilePath:  filePath,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Content:   content,
+		Score:     score,
+	}
+}
+
+// rankContextSnippets 按得分降序排序，所在定义始终排在首位
+func rankContextSnippets(snippets []*dto.ContextSnippet) []*dto.ContextSnippet {
+	sort.SliceStable(snippets, func(i, j int) bool {
+		if (snippets[i].Kind == ContextKindEnclosing) != (snippets[j].Kind == ContextKindEnclosing) {
+			return snippets[i].Kind == ContextKindEnclosing
+		}
+		return snippets[i].Score > snippets[j].Score
+	})
+	return snippets
+}
+
+// dedupContextSnippets 按排序顺序去除重叠片段：被包含的直接丢弃，部分重叠的裁掉重叠行
+func dedupContextSnippets(snippets []*dto.ContextSnippet) []*dto.ContextSnippet {
+	accepted := make([]*dto.ContextSnippet, 0, len(snippets))
+	for _, c := range snippets {
+		keep := true
+		if c.Kind != ContextKindOutline {
+			for _, a := range accepted {
+				if a.Kind == ContextKindOutline || a.FilePath != c.FilePath {
+					continue
+				}
+				if c.EndLine < a.StartLine || c.StartLine > a.EndLine {
+					continue
+				}
+				if c.StartLine >= a.StartLine && c.EndLine <= a.EndLine {
+					keep = false
+					break
+				}
+				if c.StartLine < a.StartLine && c.EndLine > a.EndLine {
+					// 两端都有剩余，无法保留为连续片段
+					keep = false
+					break
+				}
+				if c.StartLine < a.StartLine {
+					trimContextSnippet(c, c.StartLine, a.StartLine-1)
+				} else {
+					trimContextSnippet(c, a.EndLine+1, c.EndLine)
+				}
+			}
+		}
+		if keep {
+			accepted = append(accepted, c)
+		}
+	}
+	return accepted
+}
+
+// trimContextSnippet 将片段裁剪到 [startLine, endLine] 行
+func trimContextSnippet(s *dto.ContextSnippet, startLine, endLine int) {
+	lines := strings.Split(s.Content, "\n")
+	from := min(max(startLine-s.StartLine, 0), len(lines))
+	to := min(max(endLine-s.StartLine+1, from), len(lines))
+	s.Content = strings.Join(lines[from:to], "\n")
+	s.StartLine, s.EndLine = startLine, endLine
+	s.Truncated = true
+}
+
+// packContextSnippets 按顺序贪心装入预算，放不下的片段在剩余预算足够时截断尾部
+func packContextSnippets(snippets []*dto.ContextSnippet, budget int, estimator TokenEstimator) *dto.ContextData {
+	data := &dto.ContextData{TokenBudget: budget, List: make([]*dto.ContextSnippet, 0, len(snippets))}
+	for _, s := range snippets {
+		s.Tokens = estimator.EstimateTokens(s.Content)
+		remain := budget - data.UsedTokens
+		if s.Tokens > remain {
+			if s.Kind != ContextKindEnclosing && remain < contextMinTruncateTokens {
+				data.Dropped++
+				continue
+			}
+			if !truncateContextSnippet(s, remain, estimator) {
+				data.Dropped++
+				continue
+			}
+		}
+		data.UsedTokens += s.Tokens
+		data.List = append(data.List, s)
+	}
+	return data
+}
+
+// truncateContextSnippet 保留不超过 limit 个 token 的开头若干行
+func truncateContextSnippet(s *dto.ContextSnippet, limit int, estimator TokenEstimator) bool {
+	lines := strings.Split(s.Content, "\n")
+	tokens, n := 0, 0
+	for ; n < len(lines); n++ {
+		t := estimator.EstimateTokens(lines[n] + "\n")
+		if tokens+t > limit {
+			break
+		}
+		tokens += t
+	}
+	if n == 0 {
+		return false
+	}
+	s.Content = strings.Join(lines[:n], "\n")
+	s.Tokens = estimator.EstimateTokens(s.Content)
+	if s.Kind != ContextKindOutline {
+		s.EndLine = s.StartLine + n - 1
+	}
+	s.Truncated = true
+	return true
+}
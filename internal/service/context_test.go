@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/internal/dto"
+)
+
+func TestDefaultTokenEstimator(t *testing.T) {
+	assert.Equal(t, 0, DefaultTokenEstimator.EstimateTokens(""))
+	assert.Equal(t, 3, DefaultTokenEstimator.EstimateTokens("func main"))
+	assert.Equal(t, 2, DefaultTokenEstimator.EstimateTokens("索引"))
+}
+
+func TestRankAndDedupContextSnippets(t *testing.T) {
+	snippets := []*dto.ContextSnippet{
+		{Kind: ContextKindCaller, FilePath: "/a.go", StartLine: 1, EndLine: 4, Content: "l1\nl2\nl3\nl4", Score: 0.6},
+		{Kind: ContextKindDefinition, FilePath: "/a.go", StartLine: 12, EndLine: 14, Content: "l12\nl13\nl14", Score: 0.8},
+		{Kind: ContextKindEnclosing, FilePath: "/a.go", StartLine: 3, EndLine: 10, Content: "enclosing", Score: 0.1},
+		{Kind: ContextKindDefinition, FilePath: "/a.go", StartLine: 5, EndLine: 6, Content: "l5\nl6", Score: 0.9},
+		{Kind: ContextKindOutline, FilePath: "/a.go", StartLine: 1, EndLine: 20, Content: "1: package a", Score: 0.4},
+	}
+	res := dedupContextSnippets(rankContextSnippets(snippets))
+
+	assert.Len(t, res, 4)
+	assert.Equal(t, ContextKindEnclosing, res[0].Kind)
+	assert.Equal(t, 12, res[1].StartLine)
+	// 部分重叠的调用方被裁剪为不重叠的部分
+	assert.Equal(t, ContextKindCaller, res[2].Kind)
+	assert.Equal(t, 1, res[2].StartLine)
+	assert.Equal(t, 2, res[2].EndLine)
+	assert.Equal(t, "l1\nl2", res[2].Content)
+	assert.True(t, res[2].Truncated)
+	assert.Equal(t, ContextKindOutline, res[3].Kind)
+}
+
+func TestPackContextSnippets(t *testing.T) {
+	estimator := TokenEstimatorFunc(func(text string) int {
+		return len(strings.Fields(text))
+	})
+	snippets := []*dto.ContextSnippet{
+		{Kind: ContextKindEnclosing, FilePath: "/a.go", StartLine: 1, EndLine: 3, Content: "a a\nb b\nc c"},
+		{Kind: ContextKindDefinition, FilePath: "/b.go", StartLine: 1, EndLine: 1, Content: "d d d d d"},
+		{Kind: ContextKindDefinition, FilePath: "/c.go", StartLine: 1, EndLine: 1, Content: "e"},
+	}
+	data := packContextSnippets(snippets, 5, estimator)
+
+	assert.Equal(t, 5, data.TokenBudget)
+	assert.Equal(t, 5, data.UsedTokens)
+	assert.Equal(t, 1, data.Dropped)
+	assert.Len(t, data.List, 2)
+	// 所在定义超出预算时截断尾部
+	assert.True(t, data.List[0].Truncated)
+	assert.Equal(t, 2, data.List[0].EndLine)
+	assert.Equal(t, "/c.go", data.List[1].FilePath)
+}
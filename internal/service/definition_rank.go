@@ -0,0 +1,232 @@
+package service
+
+import (
+	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 各信号的置信度权重，累加后截断到 [0, 1]
+const (
+	confidenceNameMatch      = 0.1
+	confidenceSameFile       = 0.5
+	confidenceSamePackage    = 0.3
+	confidenceImportedSymbol = 0.45
+	confidenceImportedPath   = 0.3
+	confidenceOwnerMatch     = 0.15
+	confidenceArityMatch     = 0.15
+	confidenceArityMismatch  = -0.1
+	confidenceLanguageMatch  = 0.05
+	confidenceNotSource      = -0.2
+)
+
+const (
+	reasonDefinitionInRange = "definition in range"
+	reasonSameFile          = "same file"
+	reasonSamePackage       = "same package"
+	reasonImportedSymbol    = "imported symbol"
+	reasonImportedPath      = "imported package"
+	reasonOwnerMatch        = "owner match"
+	reasonArityMatch        = "parameter count match"
+	reasonArityMismatch     = "parameter count mismatch"
+	reasonLanguageMatch     = "language match"
+	reasonGeneratedFile     = "generated file"
+	reasonVendoredFile      = "vendored file"
+	reasonNameOnly          = "name match only"
+)
+
+// definitionSource 查找定义的调用或引用，用于给候选定义打分
+type definitionSource struct {
+	Owner    string   // 调用/引用的限定对象，如接收者、包名、类名
+	ArgCount int      // 调用的实参个数，引用为 -1
+	ArgTypes []string // 调用的实参类型，无法推断的为空
+}
+
+// definitionRankContext 查询所在文件的信息
+type definitionRankContext struct {
+	FilePath string
+	Language lang.Language
+	Imports  []*codegraphpb.Import
+}
+
+// sourceFromElement 从索引中的调用/引用元素提取打分信息，table 为其所在文件的索引，用于推断实参类型
+func sourceFromElement(table *codegraphpb.FileElementTable, e *codegraphpb.Element) *definitionSource {
+	source := &definitionSource{ArgCount: -1}
+	source.Owner, _ = proto.GetOwnerFromExtraData(e.ExtraData)
+	if e.ElementType == codegraphpb.ElementType_CALL {
+		params, _ := proto.GetParametersFromExtraData(e.ExtraData)
+		source.ArgCount = len(params)
+		source.ArgTypes = inferArgumentTypes(params, enclosingDeclaredTypes(table, e))
+	}
+	return source
+}
+
+// sourceFromResolved 从代码片段解析出的调用/引用提取打分信息
+func sourceFromResolved(e resolver.Element) *definitionSource {
+	switch v := e.(type) {
+	case *resolver.Call:
+		args := make([]resolver.Parameter, 0, len(v.Parameters))
+		for _, p := range v.Parameters {
+			args = append(args, *p)
+		}
+		return &definitionSource{Owner: v.Owner, ArgCount: len(v.Parameters), ArgTypes: inferArgumentTypes(args, nil)}
+	case *resolver.Reference:
+		return &definitionSource{Owner: v.Owner, ArgCount: -1}
+	}
+	return &definitionSource{ArgCount: -1}
+}
+
+// scoreDefinition 按导入匹配、同文件/同包、所属类型、参数个数与类型及语言计算候选定义的置信度，element 为定义在其文件索引中的元素，可能为空
+func scoreDefinition(rc *definitionRankContext, source *definitionSource, def *types.Definition,
+	element *codegraphpb.Element) (float64, string) {
+	score := confidenceNameMatch
+	var reasons []string
+
+	switch {
+	case def.Path == rc.FilePath:
+		score += confidenceSameFile
+		reasons = append(reasons, reasonSameFile)
+	case utils.IsSameParentDir(def.Path, rc.FilePath):
+		score += confidenceSamePackage
+		reasons = append(reasons, reasonSamePackage)
+	default:
+		imported := types.EmptyString
+		for _, imp := range rc.Imports {
+			if !analyzer.IsImportPathInFilePath(imp, def.Path) {
+				continue
+			}
+			if imp.Name == def.Name || imp.Alias == def.Name {
+				imported = reasonImportedSymbol
+				break
+			}
+			imported = reasonImportedPath
+		}
+		if imported == reasonImportedSymbol {
+			score += confidenceImportedSymbol
+			reasons = append(reasons, imported)
+		} else if imported == reasonImportedPath {
+			score += confidenceImportedPath
+			reasons = append(reasons, imported)
+		}
+	}
+
+	if source != nil && source.Owner != types.EmptyString && ownerMatches(source.Owner, def, element) {
+		score += confidenceOwnerMatch
+		reasons = append(reasons, reasonOwnerMatch)
+	}
+
+	if source != nil && source.ArgCount >= 0 && element != nil &&
+		(element.ElementType == codegraphpb.ElementType_FUNCTION || element.ElementType == codegraphpb.ElementType_METHOD) {
+		params, err := proto.GetParametersFromExtraData(element.ExtraData)
+		if err == nil {
+			if arityMatches(source.ArgCount, params) {
+				score += confidenceArityMatch
+				reasons = append(reasons, reasonArityMatch)
+				if declared, err := declaredParameters(element); err == nil {
+					if ok, exact := matchOverload(source.ArgCount, source.ArgTypes, declared); !ok {
+						score += confidenceTypeMismatch
+						reasons = append(reasons, reasonTypeMismatch)
+					} else if exact > 0 {
+						score += confidenceTypeMatch
+						reasons = append(reasons, reasonTypeMatch)
+					}
+				}
+			} else {
+				score += confidenceArityMismatch
+				reasons = append(reasons, reasonArityMismatch)
+			}
+		}
+	}
+
+	if language, err := lang.InferLanguage(def.Path); err == nil && language == rc.Language {
+		score += confidenceLanguageMatch
+		if len(reasons) == 0 {
+			reasons = append(reasons, reasonNameOnly)
+		}
+		reasons = append(reasons, reasonLanguageMatch)
+	} else if len(reasons) == 0 {
+		reasons = append(reasons, reasonNameOnly)
+	}
+
+	// 生成、第三方的代码降权，优先手写的定义
+	switch def.Origin {
+	case types.FileOriginGenerated, types.FileOriginMinified:
+		score += confidenceNotSource
+		reasons = append(reasons, reasonGeneratedFile)
+	case types.FileOriginVendored:
+		score += confidenceNotSource
+		reasons = append(reasons, reasonVendoredFile)
+	}
+
+	score = math.Max(0, math.Min(1, score))
+	return math.Round(score*100) / 100, strings.Join(reasons, ", ")
+}
+
+// ownerMatches 限定对象与定义所属类型一致，或与定义所在包（目录）名一致
+func ownerMatches(owner string, def *types.Definition, element *codegraphpb.Element) bool {
+	qualifier := owner
+	if idx := strings.LastIndexAny(qualifier, ".:"); idx >= 0 {
+		qualifier = qualifier[idx+1:]
+	}
+	qualifier = strings.TrimPrefix(qualifier, types.Star)
+	if qualifier == types.EmptyString {
+		return false
+	}
+	defOwner := def.Container
+	if element != nil {
+		if o, err := proto.GetOwnerFromExtraData(element.ExtraData); err == nil && o != types.EmptyString {
+			defOwner = o
+		}
+	}
+	if defOwner != types.EmptyString && strings.EqualFold(strings.TrimPrefix(defOwner, types.Star), qualifier) {
+		return true
+	}
+	return strings.EqualFold(filepath.Base(filepath.Dir(def.Path)), qualifier)
+}
+
+// arityMatches 实参个数与形参个数一致，可变参数（Go ...T、Python *args）时实参不少于固定参数个数即可
+func arityMatches(argCount int, params []resolver.Parameter) bool {
+	if len(params) > 0 {
+		last := params[len(params)-1]
+		variadic := strings.HasPrefix(last.Name, types.Star) || strings.HasPrefix(last.Name, "...")
+		for _, t := range last.Type {
+			if strings.HasPrefix(t, "...") {
+				variadic = true
+			}
+		}
+		if variadic {
+			return argCount >= len(params)-1
+		}
+	}
+	return argCount == len(params)
+}
+
+// rankDefinitions 为候选定义打分并选择重载，过滤低于 minConfidence 的候选，按置信度降序稳定排序
+func rankDefinitions(rc *definitionRankContext, defs []*types.Definition, sources map[*types.Definition]*definitionSource,
+	elements map[*types.Definition]*codegraphpb.Element, minConfidence float64) []*types.Definition {
+	for _, d := range defs {
+		if d.Reason == types.EmptyString {
+			d.Confidence, d.Reason = scoreDefinition(rc, sources[d], d, elements[d])
+		}
+	}
+	defs = resolveOverloads(defs, sources, elements)
+	res := make([]*types.Definition, 0, len(defs))
+	for _, d := range defs {
+		if d.Confidence < minConfidence {
+			continue
+		}
+		res = append(res, d)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Confidence > res[j].Confidence
+	})
+	return res
+}
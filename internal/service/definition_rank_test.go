@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+)
+
+func methodElement(t *testing.T, owner string, params []resolver.Parameter) *codegraphpb.Element {
+	extra := map[string][]byte{}
+	if owner != "" {
+		b, err := json.Marshal(owner)
+		assert.NoError(t, err)
+		extra["owner"] = b
+	}
+	if len(params) > 0 {
+		b, err := json.Marshal(params)
+		assert.NoError(t, err)
+		extra["parameters"] = b
+	}
+	return &codegraphpb.Element{ElementType: codegraphpb.ElementType_METHOD, ExtraData: extra}
+}
+
+func TestScoreDefinition(t *testing.T) {
+	rc := &definitionRankContext{
+		FilePath: "/repo/app/main.go",
+		Language: lang.Go,
+		Imports:  []*codegraphpb.Import{{Name: "store", Source: "example.com/repo/pkg/store"}},
+	}
+	source := &definitionSource{Owner: "store", ArgCount: 2}
+	params := []resolver.Parameter{{Name: "ctx"}, {Name: "key"}}
+
+	imported := &types.Definition{Name: "Get", Path: "/repo/pkg/store/store.go"}
+	score, reason := scoreDefinition(rc, source, imported, methodElement(t, "", params))
+	assert.InDelta(t, 0.1+0.3+0.15+0.15+0.05, score, 0.001)
+	assert.Equal(t, "imported package, owner match, parameter count match, language match", reason)
+
+	other := &types.Definition{Name: "Get", Path: "/repo/pkg/cache/cache.go"}
+	otherScore, reason := scoreDefinition(rc, source, other, methodElement(t, "Cache", []resolver.Parameter{{Name: "key"}}))
+	assert.Less(t, otherScore, score)
+	assert.Equal(t, "parameter count mismatch, language match", reason)
+
+	nameOnly, reason := scoreDefinition(rc, nil, &types.Definition{Name: "Get", Path: "/repo/web/get.ts"}, nil)
+	assert.InDelta(t, 0.1, nameOnly, 0.001)
+	assert.Equal(t, reasonNameOnly, reason)
+
+	sameFile, reason := scoreDefinition(rc, nil, &types.Definition{Name: "run", Path: rc.FilePath}, nil)
+	assert.Greater(t, sameFile, score-0.2)
+	assert.Contains(t, reason, reasonSameFile)
+
+	generated := &types.Definition{Name: "Get", Path: "/repo/pkg/store/store.pb.go", Origin: types.FileOriginGenerated}
+	generatedScore, reason := scoreDefinition(rc, source, generated, methodElement(t, "", params))
+	assert.InDelta(t, score+confidenceNotSource, generatedScore, 0.001)
+	assert.Contains(t, reason, reasonGeneratedFile)
+}
+
+func TestArityMatches(t *testing.T) {
+	assert.True(t, arityMatches(0, nil))
+	assert.False(t, arityMatches(1, nil))
+	variadic := []resolver.Parameter{{Name: "format", Type: []string{"string"}}, {Name: "args", Type: []string{"...any"}}}
+	assert.True(t, arityMatches(1, variadic))
+	assert.True(t, arityMatches(4, variadic))
+	assert.False(t, arityMatches(0, variadic))
+	assert.True(t, arityMatches(3, []resolver.Parameter{{Name: "self"}, {Name: "*args"}}))
+}
+
+func TestRankDefinitions(t *testing.T) {
+	rc := &definitionRankContext{FilePath: "/repo/a/main.go", Language: lang.Go}
+	inRange := &types.Definition{Name: "x", Path: rc.FilePath, Confidence: 1, Reason: reasonDefinitionInRange}
+	far := &types.Definition{Name: "y", Path: "/repo/b/y.go"}
+	near := &types.Definition{Name: "y", Path: "/repo/a/y.go"}
+
+	ranked := rankDefinitions(rc, []*types.Definition{far, near, inRange}, nil, nil, 0)
+	assert.Equal(t, []*types.Definition{inRange, near, far}, ranked)
+	assert.Equal(t, reasonDefinitionInRange, inRange.Reason)
+
+	ranked = rankDefinitions(rc, []*types.Definition{far, near, inRange}, nil, nil, 0.3)
+	assert.Equal(t, []*types.Definition{inRange, near}, ranked)
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	eventCoalesceWindow  = time.Second      // 工作区最近的文件事件在此时间内时，等待后续事件一起处理
+	eventCoalesceMaxWait = 10 * time.Second // 持续产生事件时，最早的事件最长等待时间
+)
+
+// coalesceReady 工作区的文件事件是否可以处理：已静默 eventCoalesceWindow，或最早的事件已等待 eventCoalesceMaxWait
+func coalesceReady(events []*model.Event, now time.Time) bool {
+	var first, last time.Time
+	for _, e := range events {
+		t := eventTime(e)
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return now.Sub(last) >= eventCoalesceWindow || now.Sub(first) >= eventCoalesceMaxWait
+}
+
+// readyFileEvents 按工作区分组文件事件，只保留可以处理的工作区，仍在持续变化的工作区等待下一轮
+func readyFileEvents(events []*model.Event, now time.Time) map[string][]*model.Event {
+	grouped := make(map[string][]*model.Event)
+	for _, event := range events {
+		grouped[event.WorkspacePath] = append(grouped[event.WorkspacePath], event)
+	}
+	for workspacePath, group := range grouped {
+		if !coalesceReady(group, now) {
+			delete(grouped, workspacePath)
+		}
+	}
+	return grouped
+}
+
+// fileChange 同一文件的事件链合并后的变更，重命名后跟随新路径
+type fileChange struct {
+	origin   string // 事件链开始时的路径，索引所在的位置
+	path     string // 事件链结束时的路径
+	created  bool   // 事件链以新增开始，之前没有索引
+	deleted  bool
+	modified bool
+	events   []*model.Event
+}
+
+// renamed 需要将索引从原路径移到新路径
+func (fc *fileChange) renamed() bool {
+	return !fc.created && !fc.deleted && fc.origin != fc.path
+}
+
+// removed 需要删除原路径的索引，新增后又删除的文件没有索引
+func (fc *fileChange) removed() bool {
+	return fc.deleted && !fc.created
+}
+
+// indexed 需要索引新路径
+func (fc *fileChange) indexed() bool {
+	return fc.modified && !fc.deleted
+}
+
+// coalesceFileEvents 按事件先后合并同一文件的事件链：
+// 新增→修改为新增，新增→…→删除不处理，删除→新增为修改，a→b→c 的重命名为 a→c，重命名覆盖的文件按删除处理
+func coalesceFileEvents(events []*model.Event) []*fileChange {
+	var changes []*fileChange
+	current := make(map[string]*fileChange)
+	get := func(path string, created bool) *fileChange {
+		if fc, ok := current[path]; ok {
+			return fc
+		}
+		fc := &fileChange{origin: path, path: path, created: created}
+		current[path] = fc
+		changes = append(changes, fc)
+		return fc
+	}
+	for _, e := range events {
+		var fc *fileChange
+		switch e.EventType {
+		case model.EventTypeAddFile, model.EventTypeModifyFile:
+			fc = get(e.SourceFilePath, e.EventType == model.EventTypeAddFile)
+			fc.deleted, fc.modified = false, true
+		case model.EventTypeDeleteFile:
+			fc = get(e.SourceFilePath, false)
+			fc.deleted, fc.modified = true, false
+		case model.EventTypeRenameFile:
+			fc = get(e.SourceFilePath, false)
+			delete(current, e.SourceFilePath)
+			if overwritten, ok := current[e.TargetFilePath]; ok {
+				overwritten.deleted, overwritten.modified = true, false
+			}
+			fc.path = e.TargetFilePath
+			current[e.TargetFilePath] = fc
+		default:
+			continue
+		}
+		fc.events = append(fc.events, e)
+	}
+	return changes
+}
+
+// splitCoalesceSegments 按事件先后分段，段内合并后先删除、再重命名、最后索引，结果与逐个处理一致。
+// 目录与其下文件的事件、重命名后又使用原路径的事件会改变先后依赖，分到下一段
+func splitCoalesceSegments(events []*model.Event) [][]*model.Event {
+	sorted := make([]*model.Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+
+	var segments [][]*model.Event
+	var segment []*model.Event
+	paths := make(map[string]bool)
+	renamedFrom := make(map[string]bool)
+	conflicts := func(path string) bool {
+		if renamedFrom[path] {
+			return true
+		}
+		for p := range paths {
+			if utils.IsSubdir(p, path) || utils.IsSubdir(path, p) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, e := range sorted {
+		eventPaths := []string{e.SourceFilePath}
+		if e.EventType == model.EventTypeRenameFile {
+			eventPaths = append(eventPaths, e.TargetFilePath)
+		}
+		for _, p := range eventPaths {
+			if len(segment) > 0 && conflicts(p) {
+				segments = append(segments, segment)
+				segment = nil
+				paths = make(map[string]bool)
+				renamedFrom = make(map[string]bool)
+				break
+			}
+		}
+		segment = append(segment, e)
+		for _, p := range eventPaths {
+			paths[p] = true
+		}
+		if e.EventType == model.EventTypeRenameFile {
+			renamedFrom[e.SourceFilePath] = true
+		}
+	}
+	if len(segment) > 0 {
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// groupChangesByProject 按路径所属的项目分组，保持项目顺序，不属于任何项目的变更单独一组
+func groupChangesByProject(projects []*workspace.Project, changes []*fileChange,
+	pathOf func(*fileChange) string) [][]*fileChange {
+	groups := make([][]*fileChange, len(projects)+1)
+	for _, fc := range changes {
+		n := len(projects)
+		for idx, p := range projects {
+			if path := pathOf(fc); path == p.Path || utils.IsSubdir(p.Path, path) {
+				n = idx
+				break
+			}
+		}
+		groups[n] = append(groups[n], fc)
+	}
+	var result [][]*fileChange
+	for _, g := range groups {
+		if len(g) > 0 {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// processFileEvents 合并工作区的文件事件，每个项目批量删除、索引一次，按变更结果更新其中每个事件的状态
+func (c *CodegraphProcessor) processFileEvents(ctx context.Context, workspacePath string, events []*model.Event) error {
+	segments := splitCoalesceSegments(events)
+	for _, segment := range segments {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		changes := coalesceFileEvents(segment)
+		c.logger.Info("codegraph coalesced %d file events into %d changes: %s", len(segment), len(changes), workspacePath)
+		c.applyFileChanges(ctx, workspacePath, changes)
+	}
+	return nil
+}
+
+// applyFileChanges 执行合并后的变更，失败的变更对应的事件标记为失败
+func (c *CodegraphProcessor) applyFileChanges(ctx context.Context, workspacePath string, changes []*fileChange) {
+	results := make(map[*fileChange]error, len(changes))
+	fail := func(group []*fileChange, err error) {
+		if err == nil {
+			return
+		}
+		for _, fc := range group {
+			results[fc] = errors.Join(results[fc], err)
+		}
+	}
+	projects := c.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
+
+	var removes, renames, indexes []*fileChange
+	for _, fc := range changes {
+		if fc.removed() {
+			removes = append(removes, fc)
+		}
+		if fc.renamed() {
+			renames = append(renames, fc)
+		}
+		if !fc.indexed() {
+			continue
+		}
+		fileInfo, err := c.workspaceReader.Stat(fc.path)
+		if errors.Is(err, workspace.ErrPathNotExists) {
+			c.logger.Error("codegraph failed to index file %s, not exists.", fc.path)
+			fail([]*fileChange{fc}, err)
+			continue
+		}
+		if err == nil && fileInfo.IsDir {
+			c.logger.Error("codegraph file %s is dir, not process.", fc.path)
+			continue
+		}
+		indexes = append(indexes, fc)
+	}
+
+	for _, group := range groupChangesByProject(projects, removes, changeOrigin) {
+		fail(group, c.indexer.RemoveIndexes(ctx, workspacePath, changePaths(group, changeOrigin)))
+	}
+	for _, fc := range renames {
+		fail([]*fileChange{fc}, c.indexer.RenameIndexes(ctx, workspacePath, fc.origin, fc.path))
+	}
+	for _, group := range groupChangesByProject(projects, indexes, changePath) {
+		fail(group, c.indexer.IndexFiles(ctx, workspacePath, changePaths(group, changePath)))
+	}
+
+	for _, fc := range changes {
+		for _, event := range fc.events {
+			if err := c.updateEventStatusFinally(event, results[fc]); err != nil {
+				c.logger.Error("failed to process %s event for codegraph: %v", event.EventType, err)
+			}
+		}
+	}
+}
+
+func changeOrigin(fc *fileChange) string { return fc.origin }
+
+func changePath(fc *fileChange) string { return fc.path }
+
+func changePaths(changes []*fileChange, pathOf func(*fileChange) string) []string {
+	paths := make([]string, 0, len(changes))
+	for _, fc := range changes {
+		paths = append(paths, pathOf(fc))
+	}
+	return paths
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/test/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCoalesceReady(t *testing.T) {
+	now := time.Now()
+	events := []*model.Event{{CreatedAt: now.Add(-5 * time.Second)}, {CreatedAt: now.Add(-100 * time.Millisecond)}}
+	assert.False(t, coalesceReady(events, now))
+	assert.True(t, coalesceReady(events, now.Add(eventCoalesceWindow)))
+	// 持续变化时不超过最长等待时间
+	events[0].CreatedAt = now.Add(-eventCoalesceMaxWait)
+	assert.True(t, coalesceReady(events, now))
+}
+
+func TestReadyFileEvents(t *testing.T) {
+	now := time.Now()
+	quiet := &model.Event{ID: 1, WorkspacePath: "/a", CreatedAt: now.Add(-5 * time.Second)}
+	busy := []*model.Event{
+		{ID: 2, WorkspacePath: "/b", CreatedAt: now.Add(-5 * time.Second)},
+		{ID: 3, WorkspacePath: "/b", CreatedAt: now.Add(-100 * time.Millisecond)},
+	}
+	// 仍在合并等待中的工作区不返回
+	assert.Equal(t, map[string][]*model.Event{"/a": {quiet}}, readyFileEvents(append([]*model.Event{quiet}, busy...), now))
+	assert.Empty(t, readyFileEvents(busy, now))
+}
+
+func TestGroupChangesByProject(t *testing.T) {
+	projects := []*workspace.Project{{Path: "/ws/app"}, {Path: "/ws/app2"}}
+	inApp := &fileChange{path: "/ws/app/main.go"}
+	inApp2 := &fileChange{path: "/ws/app2/main.go"}
+	root := &fileChange{path: "/ws/app2"}
+	outside := &fileChange{path: "/ws/README.md"}
+	// 路径前缀相同的项目不会混淆
+	assert.Equal(t, [][]*fileChange{{inApp}, {inApp2, root}, {outside}},
+		groupChangesByProject(projects, []*fileChange{inApp2, inApp, root, outside}, changePath))
+}
+
+func TestCoalesceFileEvents(t *testing.T) {
+	type change struct {
+		origin, path              string
+		removed, renamed, indexed bool
+		events                    []int64
+	}
+	tests := []struct {
+		name   string
+		events []*model.Event
+		want   []change
+	}{
+		{
+			name: "新增、修改合并为一次索引",
+			events: []*model.Event{
+				{ID: 1, EventType: model.EventTypeAddFile, SourceFilePath: "/w/a.go"},
+				{ID: 2, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/a.go"},
+				{ID: 3, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/a.go"},
+			},
+			want: []change{{origin: "/w/a.go", path: "/w/a.go", indexed: true, events: []int64{1, 2, 3}}},
+		},
+		{
+			name: "新增后删除不处理",
+			events: []*model.Event{
+				{ID: 1, EventType: model.EventTypeAddFile, SourceFilePath: "/w/a.go"},
+				{ID: 2, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/a.go"},
+				{ID: 3, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/a.go"},
+			},
+			want: []change{{origin: "/w/a.go", path: "/w/a.go", events: []int64{1, 2, 3}}},
+		},
+		{
+			name: "修改后删除、删除后新增",
+			events: []*model.Event{
+				{ID: 1, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/a.go"},
+				{ID: 2, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/a.go"},
+				{ID: 3, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/b.go"},
+				{ID: 4, EventType: model.EventTypeAddFile, SourceFilePath: "/w/b.go"},
+			},
+			want: []change{
+				{origin: "/w/a.go", path: "/w/a.go", removed: true, events: []int64{1, 2}},
+				{origin: "/w/b.go", path: "/w/b.go", indexed: true, events: []int64{3, 4}},
+			},
+		},
+		{
+			name: "重命名链",
+			events: []*model.Event{
+				{ID: 1, EventType: model.EventTypeRenameFile, SourceFilePath: "/w/a.go", TargetFilePath: "/w/b.go"},
+				{ID: 2, EventType: model.EventTypeRenameFile, SourceFilePath: "/w/b.go", TargetFilePath: "/w/c.go"},
+				{ID: 3, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/c.go"},
+				{ID: 4, EventType: model.EventTypeAddFile, SourceFilePath: "/w/d.go"},
+				{ID: 5, EventType: model.EventTypeRenameFile, SourceFilePath: "/w/d.go", TargetFilePath: "/w/e.go"},
+			},
+			want: []change{
+				{origin: "/w/a.go", path: "/w/c.go", renamed: true, indexed: true, events: []int64{1, 2, 3}},
+				// 新增的文件没有索引，只索引新路径
+				{origin: "/w/d.go", path: "/w/e.go", indexed: true, events: []int64{4, 5}},
+			},
+		},
+		{
+			name: "重命名覆盖已有文件",
+			events: []*model.Event{
+				{ID: 1, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/b.go"},
+				{ID: 2, EventType: model.EventTypeRenameFile, SourceFilePath: "/w/a.go", TargetFilePath: "/w/b.go"},
+			},
+			want: []change{
+				{origin: "/w/b.go", path: "/w/b.go", removed: true, events: []int64{1}},
+				{origin: "/w/a.go", path: "/w/b.go", renamed: true, events: []int64{2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []change
+			for _, fc := range coalesceFileEvents(tt.events) {
+				c := change{origin: fc.origin, path: fc.path, removed: fc.removed(), renamed: fc.renamed(), indexed: fc.indexed()}
+				for _, e := range fc.events {
+					c.events = append(c.events, e.ID)
+				}
+				got = append(got, c)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitCoalesceSegments(t *testing.T) {
+	events := []*model.Event{
+		{ID: 2, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/b.go"},
+		{ID: 1, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/a.go"},
+		{ID: 3, EventType: model.EventTypeRenameFile, SourceFilePath: "/w/a.go", TargetFilePath: "/w/c.go"},
+		// 重命名后原路径又有新文件
+		{ID: 4, EventType: model.EventTypeAddFile, SourceFilePath: "/w/a.go"},
+		{ID: 5, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/pkg/d.go"},
+		// 目录与其下文件的事件
+		{ID: 6, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/pkg"},
+	}
+	var got [][]int64
+	for _, segment := range splitCoalesceSegments(events) {
+		var ids []int64
+		for _, e := range segment {
+			ids = append(ids, e.ID)
+		}
+		got = append(got, ids)
+	}
+	assert.Equal(t, [][]int64{{1, 2, 3}, {4, 5}, {6}}, got)
+}
+
+func TestCodegraphProcessor_processFileEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	mockWorkspaceReader := mocks.NewMockWorkspaceReader(ctrl)
+	mockIndexer := mocks.NewMockIndexer(ctrl)
+	mockEventRepo := mocks.NewMockEventRepository(ctrl)
+
+	processor := &CodegraphProcessor{
+		logger:          mockLogger,
+		workspaceReader: mockWorkspaceReader,
+		indexer:         mockIndexer,
+		eventRepo:       mockEventRepo,
+	}
+	events := []*model.Event{
+		{ID: 1, EventType: model.EventTypeAddFile, SourceFilePath: "/w/p1/a.go"},
+		{ID: 2, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/p1/a.go"},
+		{ID: 3, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/p1/b.go"},
+		{ID: 4, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/p2/c.go"},
+		{ID: 5, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/p1/d.go"},
+		{ID: 6, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/p2/e.go"},
+		{ID: 7, EventType: model.EventTypeAddFile, SourceFilePath: "/w/p2/f.go"},
+		{ID: 8, EventType: model.EventTypeDeleteFile, SourceFilePath: "/w/p2/f.go"},
+		{ID: 9, EventType: model.EventTypeModifyFile, SourceFilePath: "/w/p1/gone.go"},
+	}
+	mockWorkspaceReader.EXPECT().FindProjects(gomock.Any(), "/w", false, gomock.Any()).Return([]*workspace.Project{
+		{Path: "/w/p1", Uuid: "p1"}, {Path: "/w/p2", Uuid: "p2"},
+	})
+	mockWorkspaceReader.EXPECT().Stat("/w/p1/gone.go").Return(nil, workspace.ErrPathNotExists)
+	mockWorkspaceReader.EXPECT().Stat(gomock.Any()).Return(&types.FileInfo{}, nil).Times(3)
+	gomock.InOrder(
+		mockIndexer.EXPECT().RemoveIndexes(gomock.Any(), "/w", []string{"/w/p1/d.go"}).Return(nil),
+		mockIndexer.EXPECT().RemoveIndexes(gomock.Any(), "/w", []string{"/w/p2/e.go"}).Return(nil),
+		mockIndexer.EXPECT().IndexFiles(gomock.Any(), "/w", []string{"/w/p1/a.go", "/w/p1/b.go"}).Return(nil),
+		mockIndexer.EXPECT().IndexFiles(gomock.Any(), "/w", []string{"/w/p2/c.go"}).Return(errors.New("save failed")),
+	)
+	statuses := make(map[int64]int)
+	mockEventRepo.EXPECT().UpdateEvent(gomock.Any()).DoAndReturn(func(event *model.Event) error {
+		statuses[event.ID] = event.CodegraphStatus
+		return nil
+	}).Times(len(events))
+
+	assert.NoError(t, processor.processFileEvents(context.Background(), "/w", events))
+	assert.Equal(t, map[int64]int{
+		1: model.CodegraphStatusSuccess, 2: model.CodegraphStatusSuccess, 3: model.CodegraphStatusSuccess,
+		4: model.CodegraphStatusFailed, 5: model.CodegraphStatusSuccess, 6: model.CodegraphStatusSuccess,
+		7: model.CodegraphStatusSuccess, 8: model.CodegraphStatusSuccess, 9: model.CodegraphStatusFailed,
+	}, statuses)
+}
@@ -15,6 +15,7 @@ import (
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/internal/utils"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
 )
 
@@ -46,6 +47,9 @@ type ExtensionService interface {
 
 	// GetIndexStatus 获取索引状态
 	GetIndexStatus(ctx context.Context, workspacePath string) (*dto.IndexStatusResponse, error)
+
+	// UpdateWatchConfig 更新工作区文件监听配置
+	UpdateWatchConfig(ctx context.Context, workspacePath string, watch *config.WatchConfig) error
 }
 
 // CheckIgnoreResult 检查结果
@@ -434,6 +438,21 @@ func (s *extensionService) SwitchIndex(ctx context.Context, workspacePath, switc
 	return nil
 }
 
+// UpdateWatchConfig 保存工作区文件监听配置，文件监听任务下次同步时生效
+func (s *extensionService) UpdateWatchConfig(ctx context.Context, workspacePath string, watch *config.WatchConfig) error {
+	codebaseConfig, err := s.storage.GetCodebaseConfig(utils.GenerateCodebaseID(workspacePath))
+	if err != nil {
+		return fmt.Errorf("failed to get codebase config: %w", err)
+	}
+	codebaseConfig.Watch = watch
+	if err = s.storage.SaveCodebaseConfig(codebaseConfig); err != nil {
+		return fmt.Errorf("failed to save codebase config: %w", err)
+	}
+	s.logger.Info("watch config for workspace %s set to enabled=%v, debounce=%d ms",
+		workspacePath, watch.Enabled, watch.DebounceMs)
+	return nil
+}
+
 // deleteNonProcessingEvents 删除非进行中状态事件
 func (s *extensionService) deleteNonProcessingEvents(workspacePath string) error {
 	// 定义非进行中状态
@@ -1076,6 +1095,11 @@ func (s *extensionService) GetIndexStatus(ctx context.Context, workspacePath str
 		data.Embedding = s.calculateEmbeddingStatus(workspace)
 		data.Codegraph = s.calculateCodegraphStatus(workspace)
 	}
+	// 文件数超过上限时未索引的文件，便于用户了解符号缺失的原因
+	data.Embedding.TotalDropped, data.Embedding.DroppedFiles = s.fileScanner.DroppedFiles(workspacePath)
+	data.Codegraph.TotalDropped, data.Codegraph.DroppedFiles = s.codebaseService.GetDroppedFiles(workspacePath)
+	data.Governor = s.codebaseService.GetGovernorState()
+	data.Config = loadConfigStatus(workspacePath)
 
 	// 构建响应
 	response := &dto.IndexStatusResponse{
@@ -1084,7 +1108,8 @@ func (s *extensionService) GetIndexStatus(ctx context.Context, workspacePath str
 		Data:    data,
 	}
 
-	s.logger.Info("successfully retrieved index status for workspace: %s, data: %v", workspacePath, data)
+	// 状态订阅按固定间隔轮询，不在 Info 级别逐次记录
+	s.logger.Debug("successfully retrieved index status for workspace: %s, data: %v", workspacePath, data)
 	return response, nil
 }
 
@@ -1241,3 +1266,16 @@ func (s *extensionService) calculateCodegraphStatus(workspace *model.Workspace)
 
 	return status
 }
+
+// loadConfigStatus 工作区配置文件的校验结果，没有配置文件时返回 nil
+func loadConfigStatus(workspacePath string) *dto.ConfigStatus {
+	wsConfig, errs := workspace.LoadConfig(workspacePath)
+	if wsConfig == nil && len(errs) == 0 {
+		return nil
+	}
+	status := &dto.ConfigStatus{Path: filepath.Join(workspacePath, workspace.ConfigFileName)}
+	for _, err := range errs {
+		status.Errors = append(status.Errors, err.Error())
+	}
+	return status
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// droppedReport 超出文件数上限未索引的文件及保留的文件
+type droppedReport struct {
+	total int
+	files []string        // 按优先级排序，最多 utils.MaxReportedDroppedFiles 个
+	kept  map[string]bool // 保留索引的文件
+}
+
+// droppedFiles 各项目最近一次索引收集文件时的选择结果，按项目路径记录，查询时沿用而不重新选择
+type droppedFiles struct {
+	mu       sync.RWMutex
+	projects map[string]droppedReport
+}
+
+// set 记录项目的选择结果，dropped 为按优先级排序的丢弃文件，为空时清除
+func (d *droppedFiles) set(projectPath string, kept map[string]bool, dropped []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(dropped) == 0 {
+		delete(d.projects, projectPath)
+		return
+	}
+	if d.projects == nil {
+		d.projects = make(map[string]droppedReport)
+	}
+	files := dropped
+	if len(files) > utils.MaxReportedDroppedFiles {
+		files = files[:utils.MaxReportedDroppedFiles]
+	}
+	d.projects[projectPath] = droppedReport{total: len(dropped), files: files, kept: kept}
+}
+
+// kept 项目最近一次索引时保留的文件，没有超过上限或未索引过时返回 false
+func (d *droppedFiles) kept(projectPath string) (map[string]bool, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	report, ok := d.projects[projectPath]
+	return report.kept, ok
+}
+
+// get 工作区下各项目丢弃的文件数及文件
+func (d *droppedFiles) get(workspacePath string) (int, []string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var total int
+	var files []string
+	for projectPath, report := range d.projects {
+		if projectPath != workspacePath && !strings.HasPrefix(projectPath, workspacePath+string(filepath.Separator)) {
+			continue
+		}
+		total += report.total
+		for _, f := range report.files {
+			if len(files) < utils.MaxReportedDroppedFiles {
+				files = append(files, f)
+			}
+		}
+	}
+	return total, files
+}
+
+// GetDroppedFiles 获取工作区超出文件数上限未索引的文件数及文件
+func (i *indexer) GetDroppedFiles(workspacePath string) (int, []string) {
+	return i.dropped.get(workspacePath)
+}
+
+// maxFileCandidates 超过文件数上限时参与选择的候选文件数上限，避免超大仓库遍历过久
+func maxFileCandidates(maxFiles int) int {
+	return maxFiles * utils.FileCandidateFactor
+}
+
+// selectFiles 索引时文件数超过上限，按源码优先、最近修改、被导入次数、路径深度选择要索引的文件，记录选择结果
+func (i *indexer) selectFiles(ctx context.Context, projectUuid string, projectPath string,
+	files map[string]int64, maxFiles int) map[string]int64 {
+	if len(files) <= maxFiles {
+		i.dropped.set(projectPath, nil, nil)
+		return files
+	}
+	selected, dropped := rankFiles(projectPath, files, maxFiles, i.inboundImportCounts(ctx, projectUuid))
+	kept := make(map[string]bool, len(selected))
+	for path := range selected {
+		kept[path] = true
+	}
+	i.dropped.set(projectPath, kept, dropped)
+	i.logger.Warn("project %s has %d files, exceeds max files %d, dropped %d files", projectPath,
+		len(files), maxFiles, len(dropped))
+	return selected
+}
+
+// keptFiles 查询时文件数超过上限，沿用最近一次索引的选择结果，未索引过时按不含被导入次数的优先级选择，不记录结果
+func (i *indexer) keptFiles(projectPath string, files map[string]int64, maxFiles int) map[string]int64 {
+	if len(files) <= maxFiles {
+		return files
+	}
+	kept, ok := i.dropped.kept(projectPath)
+	if !ok {
+		selected, _ := rankFiles(projectPath, files, maxFiles, nil)
+		return selected
+	}
+	selected := make(map[string]int64, len(kept))
+	for path, modTime := range files {
+		if kept[path] {
+			selected[path] = modTime
+		}
+	}
+	return selected
+}
+
+// rankFiles 按优先级选择 maxFiles 个文件，测试目录等按相对于项目的路径判断，返回保留的文件及按优先级排序的丢弃文件
+func rankFiles(projectPath string, files map[string]int64, maxFiles int, inbound map[string]int) (map[string]int64, []string) {
+	candidates := make([]*utils.FileCandidate, 0, len(files))
+	absPaths := make(map[*utils.FileCandidate]string, len(files))
+	for path, modTime := range files {
+		relPath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			relPath = path
+		}
+		c := &utils.FileCandidate{
+			Path:           relPath,
+			ModTime:        modTime,
+			InboundImports: inboundImports(inbound, path),
+		}
+		candidates = append(candidates, c)
+		absPaths[c] = path
+	}
+	kept, dropped := utils.SelectFiles(candidates, maxFiles, time.Now())
+	selected := make(map[string]int64, len(kept))
+	for _, c := range kept {
+		selected[absPaths[c]] = c.ModTime
+	}
+	droppedPaths := make([]string, 0, len(dropped))
+	for _, c := range dropped {
+		droppedPaths = append(droppedPaths, absPaths[c])
+	}
+	return selected, droppedPaths
+}
+
+// inboundImportCounts 根据已有索引中的导入估算被导入的次数，按导入来源的末段（包名、模块名、类名）计数，
+// 每个文件对同一名称只计一次。首次索引时没有导入信息，为空
+func (i *indexer) inboundImportCounts(ctx context.Context, projectUuid string) map[string]int {
+	counts := make(map[string]int)
+	iter := i.storage.Iter(ctx, projectUuid)
+	defer func(iter store.Iterator) {
+		if err := iter.Close(); err != nil {
+			i.logger.Error("project %s iter close err: %v", projectUuid, err)
+		}
+	}(iter)
+	for iter.Next() {
+		if !store.IsElementPathKey(iter.Key()) {
+			continue
+		}
+		var elementTable codegraphpb.FileElementTable
+		if err := store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, imp := range elementTable.Imports {
+			for _, name := range []string{importName(imp.Source), importName(imp.Name)} {
+				if name != types.EmptyString && !seen[name] {
+					seen[name] = true
+					counts[name]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// importName 导入来源的末段：github.com/a/b 为 b，./utils.js、utils.js 为 utils，java.util.List 为 List
+func importName(source string) string {
+	source = strings.Trim(source, "\"'<> ")
+	if source == types.EmptyString || source == "*" {
+		return types.EmptyString
+	}
+	if _, err := lang.InferLanguage(source); err == nil || strings.ContainsAny(source, "/\\") {
+		base := filepath.Base(filepath.ToSlash(source))
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	segs := strings.Split(source, ".")
+	return segs[len(segs)-1]
+}
+
+// inboundImports 文件的被导入次数，按文件名（不含扩展名）及所在目录名匹配
+func inboundImports(counts map[string]int, path string) int {
+	if len(counts) == 0 {
+		return 0
+	}
+	base := filepath.Base(path)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	n := counts[stem]
+	if dir := filepath.Base(filepath.Dir(path)); dir != stem {
+		n += counts[dir]
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"github.com/stretchr/testify/assert": "assert",
+		"\"./utils.js\"":                     "utils",
+		"modules.js":                         "modules",
+		"java.util.List":                     "List",
+		"app.models":                         "models",
+		"<vector>":                           "vector",
+		"*":                                  "",
+	}
+	for source, want := range tests {
+		assert.Equal(t, want, importName(source), source)
+	}
+	counts := map[string]int{"assert": 3, "user": 2}
+	assert.Equal(t, 2, inboundImports(counts, "/repo/src/user.ts"))
+	assert.Equal(t, 3, inboundImports(counts, "/repo/assert/equal.go"))
+	assert.Equal(t, 0, inboundImports(nil, "/repo/src/user.ts"))
+}
+
+func TestDroppedFiles(t *testing.T) {
+	var d droppedFiles
+	d.set("/ws/p1", map[string]bool{"/ws/p1/a.go": true}, []string{"/ws/p1/a_test.go", "/ws/p1/b_test.go"})
+	d.set("/ws/p2", nil, []string{"/ws/p2/c_test.go"})
+	d.set("/other", nil, []string{"/other/d.go"})
+
+	total, files := d.get("/ws/p1")
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []string{"/ws/p1/a_test.go", "/ws/p1/b_test.go"}, files)
+	total, files = d.get("/ws")
+	assert.Equal(t, 3, total)
+	assert.Len(t, files, 3)
+
+	kept, ok := d.kept("/ws/p1")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]bool{"/ws/p1/a.go": true}, kept)
+
+	// 文件数不再超过上限时清除
+	d.set("/ws/p1", nil, nil)
+	total, _ = d.get("/ws")
+	assert.Equal(t, 1, total)
+	_, ok = d.kept("/ws/p1")
+	assert.False(t, ok)
+}
+
+func TestRankFiles(t *testing.T) {
+	// 项目位于名为 test 的目录下时，按相对路径判断测试文件
+	files := map[string]int64{
+		"/home/test/repo/src/main.go":      1,
+		"/home/test/repo/src/main_test.go": 1,
+		"/home/test/repo/tests/e2e.go":     1,
+	}
+	selected, dropped := rankFiles("/home/test/repo", files, 1, nil)
+	assert.Equal(t, map[string]int64{"/home/test/repo/src/main.go": 1}, selected)
+	assert.ElementsMatch(t, []string{"/home/test/repo/src/main_test.go", "/home/test/repo/tests/e2e.go"}, dropped)
+
+	i := &indexer{}
+	// 查询时沿用索引的选择结果
+	i.dropped.set("/home/test/repo", map[string]bool{"/home/test/repo/tests/e2e.go": true},
+		[]string{"/home/test/repo/src/main.go", "/home/test/repo/src/main_test.go"})
+	assert.Equal(t, map[string]int64{"/home/test/repo/tests/e2e.go": 1}, i.keptFiles("/home/test/repo", files, 1))
+	assert.Equal(t, files, i.keptFiles("/home/test/repo", files, 3))
+}
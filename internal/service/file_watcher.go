@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	gitignore "github.com/sabhiram/go-gitignore"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/logger"
+)
+
+const (
+	defaultWatchDebounce = 500 * time.Millisecond
+	// 文件持续变化时，最长等待此时长后生成事件
+	maxWatchDelayFactor  = 10
+	watchEventTimeFormat = "2006-01-02 15:04:05"
+)
+
+// ignoreFileNames 忽略规则文件，工作区根目录及子目录中的都生效
+var ignoreFileNames = []string{".gitignore", ".coignore"}
+
+// FileWatchService 工作区文件监听服务，终端编辑、git pull、代码生成等 IDE 之外的变更也能及时生成事件
+type FileWatchService interface {
+	// Sync 为开启监听的活跃工作区启动监听，停止其余工作区的监听
+	Sync(ctx context.Context, workspaces []*model.Workspace)
+	// Stop 停止所有监听
+	Stop()
+}
+
+type fileWatchService struct {
+	mu          sync.Mutex
+	watchers    map[string]*workspaceWatcher
+	extension   ExtensionService
+	scanService FileScanService
+	fileScanner repository.ScannerInterface
+	storage     repository.StorageInterface
+	eventRepo   repository.EventRepository
+	logger      logger.Logger
+}
+
+// NewFileWatchService 创建文件监听服务，监听到的变更与扩展发布的事件走同样的处理
+func NewFileWatchService(
+	extension ExtensionService,
+	scanService FileScanService,
+	fileScanner repository.ScannerInterface,
+	storage repository.StorageInterface,
+	eventRepo repository.EventRepository,
+	logger logger.Logger,
+) FileWatchService {
+	return &fileWatchService{
+		watchers:    make(map[string]*workspaceWatcher),
+		extension:   extension,
+		scanService: scanService,
+		fileScanner: fileScanner,
+		storage:     storage,
+		eventRepo:   eventRepo,
+		logger:      logger,
+	}
+}
+
+// watchConfig 工作区的监听配置，未配置时不监听
+func (s *fileWatchService) watchConfig(workspacePath string) config.WatchConfig {
+	var watch config.WatchConfig
+	if codebaseConfig, err := s.storage.GetCodebaseConfig(utils.GenerateCodebaseID(workspacePath)); err == nil &&
+		codebaseConfig.Watch != nil {
+		watch = *codebaseConfig.Watch
+	}
+	if watch.DebounceMs <= 0 {
+		watch.DebounceMs = int(defaultWatchDebounce.Milliseconds())
+	}
+	return watch
+}
+
+func (s *fileWatchService) Sync(ctx context.Context, workspaces []*model.Workspace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	desired := make(map[string]config.WatchConfig, len(workspaces))
+	for _, w := range workspaces {
+		if watch := s.watchConfig(w.WorkspacePath); watch.Enabled {
+			desired[w.WorkspacePath] = watch
+		}
+	}
+
+	for path, w := range s.watchers {
+		if watch, ok := desired[path]; ok && watch == w.config {
+			continue
+		}
+		s.logger.Info("stop watching workspace %s", path)
+		w.stop()
+		delete(s.watchers, path)
+	}
+
+	for path, watch := range desired {
+		if _, ok := s.watchers[path]; ok {
+			continue
+		}
+		w, err := s.newWorkspaceWatcher(path, watch)
+		if err != nil {
+			s.logger.Error("failed to watch workspace %s: %v", path, err)
+			continue
+		}
+		s.logger.Info("start watching workspace %s, debounce %d ms", path, watch.DebounceMs)
+		s.watchers[path] = w
+		var runCtx context.Context
+		runCtx, w.cancel = context.WithCancel(ctx)
+		go w.run(runCtx)
+	}
+}
+
+func (s *fileWatchService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path, w := range s.watchers {
+		w.stop()
+		delete(s.watchers, path)
+	}
+}
+
+// publish 生成与扩展发布相同的事件，忽略规则、路径转换及去重由 PublishEvents 处理
+func (s *fileWatchService) publish(ctx context.Context, workspacePath string, events []dto.WorkspaceEvent) {
+	if len(events) == 0 {
+		return
+	}
+	count, err := s.extension.PublishEvents(ctx, workspacePath, "", events)
+	if err != nil {
+		s.logger.Error("failed to publish watched events for workspace %s: %v", workspacePath, err)
+		return
+	}
+	s.logger.Debug("published %d/%d watched events for workspace %s", count, len(events), workspacePath)
+}
+
+// fullScan 事件队列溢出时丢失了变更，扫描整个工作区：语义索引按哈希树比对，代码图按文件时间戳增量构建
+func (s *fileWatchService) fullScan(workspacePath string) {
+	s.logger.Warn("watcher events overflow in workspace %s, fall back to full scan", workspacePath)
+	if _, err := s.scanService.DetectFileChanges(workspacePath); err != nil {
+		s.logger.Error("failed to scan workspace %s: %v", workspacePath, err)
+	}
+	event := &model.Event{
+		WorkspacePath:   workspacePath,
+		EventType:       model.EventTypeOpenWorkspace,
+		EmbeddingStatus: model.EmbeddingStatusSuccess,
+		CodegraphStatus: model.CodegraphStatusInit,
+	}
+	if err := s.eventRepo.CreateEvent(event); err != nil {
+		s.logger.Error("failed to create open_workspace event for workspace %s: %v", workspacePath, err)
+	}
+}
+
+// workspaceWatcher 单个工作区的监听，递归监听未被忽略的目录
+type workspaceWatcher struct {
+	service       *fileWatchService
+	workspacePath string
+	config        config.WatchConfig
+	watcher       *fsnotify.Watcher
+	ignore        *gitignore.GitIgnore            // 根目录的忽略规则及默认规则
+	nested        map[string]*gitignore.GitIgnore // 子目录 -> 该目录下忽略规则文件的规则，相对于该目录匹配
+	dirs          map[string]bool                 // 监听中的目录
+	files         map[string]bool                 // 监听目录下未被忽略的文件，目录删除或移走时据此生成文件的删除事件
+	// pending 等待生成事件的路径，值表示首个变更是否为创建
+	pending map[string]bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func (s *fileWatchService) newWorkspaceWatcher(workspacePath string, watch config.WatchConfig) (*workspaceWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &workspaceWatcher{
+		service:       s,
+		workspacePath: workspacePath,
+		config:        watch,
+		watcher:       watcher,
+		ignore:        s.fileScanner.LoadIgnoreRules(workspacePath),
+		nested:        make(map[string]*gitignore.GitIgnore),
+		dirs:          make(map[string]bool),
+		files:         make(map[string]bool),
+		pending:       make(map[string]bool),
+		done:          make(chan struct{}),
+	}
+	if err = w.addDir(workspacePath, false); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// ignored 路径是否被默认规则、根目录及上级子目录中的 .gitignore/.coignore 忽略
+func (w *workspaceWatcher) ignored(path string, isDir bool) bool {
+	if matchRelPath(w.ignore, w.workspacePath, path, isDir) {
+		return true
+	}
+	for dir, rules := range w.nested {
+		if matchRelPath(rules, dir, path, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRelPath 以 path 相对于 base 的路径匹配规则，path 不在 base 下时不匹配
+func matchRelPath(rules *gitignore.GitIgnore, base string, path string, isDir bool) bool {
+	relPath, err := filepath.Rel(base, path)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	if isDir {
+		relPath += "/"
+	}
+	return rules.MatchesPath(relPath)
+}
+
+// loadNestedIgnore 加载子目录中的忽略规则文件，没有规则时移除该目录的规则
+func (w *workspaceWatcher) loadNestedIgnore(dir string) {
+	var lines []string
+	for _, name := range ignoreFileNames {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		lines = append(lines, strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")...)
+	}
+	if len(lines) == 0 {
+		delete(w.nested, dir)
+		return
+	}
+	w.nested[dir] = gitignore.CompileIgnoreLines(lines...)
+}
+
+// addDir 递归监听目录。新建的目录中的文件可能在监听之前已经创建，作为创建事件处理
+func (w *workspaceWatcher) addDir(root string, created bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if !w.ignored(path, false) {
+				w.files[path] = true
+				if created {
+					w.markPending(path, true)
+				}
+			}
+			return nil
+		}
+		if path != root && w.ignored(path, true) {
+			return filepath.SkipDir
+		}
+		// 先加载目录自身的忽略规则，再遍历其中的文件
+		if path != w.workspacePath {
+			w.loadNestedIgnore(path)
+		}
+		if err = w.watcher.Add(path); err != nil {
+			// 超出系统监听数限制时，该目录的变更由定时扫描发现
+			w.service.logger.Warn("failed to watch dir %s: %v", path, err)
+		}
+		w.dirs[path] = true
+		return nil
+	})
+}
+
+func (w *workspaceWatcher) markPending(path string, created bool) {
+	if _, ok := w.pending[path]; !ok {
+		w.pending[path] = created
+	}
+}
+
+func (w *workspaceWatcher) run(ctx context.Context) {
+	defer close(w.done)
+	defer w.watcher.Close()
+
+	debounce := time.Duration(w.config.DebounceMs) * time.Millisecond
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	var firstPending time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(ev)
+			if len(w.pending) == 0 {
+				continue
+			}
+			if firstPending.IsZero() {
+				firstPending = time.Now()
+			}
+			// 静默 debounce 后生成事件，持续变化时最长等待 maxWatchDelayFactor 倍
+			if time.Since(firstPending) < debounce*maxWatchDelayFactor {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				w.pending = make(map[string]bool)
+				firstPending = time.Time{}
+				w.service.fullScan(w.workspacePath)
+				continue
+			}
+			w.service.logger.Error("watch workspace %s err: %v", w.workspacePath, err)
+		case <-timer.C:
+			w.service.publish(ctx, w.workspacePath, w.flush())
+			firstPending = time.Time{}
+		}
+	}
+}
+
+// handle 记录变更的路径，新建的目录递归监听，根目录或子目录中的忽略规则文件变化时重新加载规则
+func (w *workspaceWatcher) handle(ev fsnotify.Event) {
+	if ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) {
+		return
+	}
+	if slices.Contains(ignoreFileNames, filepath.Base(ev.Name)) {
+		if dir := filepath.Dir(ev.Name); dir == w.workspacePath {
+			w.ignore = w.service.fileScanner.LoadIgnoreRules(w.workspacePath)
+		} else {
+			w.loadNestedIgnore(dir)
+		}
+	}
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			if !w.ignored(ev.Name, true) {
+				if err = w.addDir(ev.Name, true); err != nil {
+					w.service.logger.Warn("failed to watch dir %s: %v", ev.Name, err)
+				}
+			}
+			return
+		}
+	}
+	if w.ignored(ev.Name, w.dirs[ev.Name]) {
+		return
+	}
+	w.markPending(ev.Name, ev.Has(fsnotify.Create))
+}
+
+// flush 按文件当前状态生成事件：存在时为新增或修改，不存在时为删除，创建后又删除的文件不生成事件。
+// 重命名表现为旧路径删除、新路径新增，删除或移走的目录展开为其下文件的删除
+func (w *workspaceWatcher) flush() []dto.WorkspaceEvent {
+	paths := make([]string, 0, len(w.pending))
+	for path := range w.pending {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	deleted := make(map[string]bool)
+	var changed []dto.WorkspaceEvent
+	for _, path := range paths {
+		created := w.pending[path]
+		var eventType string
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			continue
+		case err == nil && created:
+			eventType = model.EventTypeAddFile
+		case err == nil:
+			eventType = model.EventTypeModifyFile
+		case w.dirs[path]:
+			for _, f := range w.removeDir(path) {
+				deleted[f] = true
+			}
+			continue
+		case created:
+			delete(w.files, path)
+			continue
+		default:
+			deleted[path] = true
+			continue
+		}
+		w.files[path] = true
+		changed = append(changed, dto.WorkspaceEvent{EventType: eventType, SourcePath: path})
+	}
+	w.pending = make(map[string]bool)
+
+	for path := range deleted {
+		delete(w.files, path)
+		changed = append(changed, dto.WorkspaceEvent{EventType: model.EventTypeDeleteFile, SourcePath: path})
+	}
+	sort.SliceStable(changed, func(a, b int) bool { return changed[a].SourcePath < changed[b].SourcePath })
+	now := time.Now().Format(watchEventTimeFormat)
+	for idx := range changed {
+		changed[idx].EventTime = now
+	}
+	return changed
+}
+
+// removeDir 停止监听已删除或移走的目录及其子目录，返回其下已知的文件
+func (w *workspaceWatcher) removeDir(dir string) []string {
+	prefix := dir + string(filepath.Separator)
+	for d := range w.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			// 目录已不存在时监听已被系统移除，移走的目录仍需显式移除
+			_ = w.watcher.Remove(d)
+			delete(w.dirs, d)
+			delete(w.nested, d)
+		}
+	}
+	var files []string
+	for f := range w.files {
+		if strings.HasPrefix(f, prefix) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (w *workspaceWatcher) stop() {
+	if w.cancel != nil {
+		w.cancel()
+		<-w.done
+		return
+	}
+	w.watcher.Close()
+}
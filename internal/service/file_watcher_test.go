@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/test/mocks"
+)
+
+type recordExtensionService struct {
+	ExtensionService
+	events chan dto.WorkspaceEvent
+}
+
+func (s *recordExtensionService) PublishEvents(ctx context.Context, workspacePath, clientID string,
+	events []dto.WorkspaceEvent) (int, error) {
+	for _, e := range events {
+		s.events <- e
+	}
+	return len(events), nil
+}
+
+func TestWorkspaceWatcher(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("ignored/\n"), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "ignored"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0644))
+
+	mockLogger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		mockLogger.On(level, mock.Anything, mock.Anything).Return()
+	}
+	extension := &recordExtensionService{events: make(chan dto.WorkspaceEvent, 100)}
+	s := &fileWatchService{
+		watchers:    make(map[string]*workspaceWatcher),
+		extension:   extension,
+		fileScanner: repository.NewFileScanner(mockLogger),
+		logger:      mockLogger,
+	}
+	w, err := s.newWorkspaceWatcher(dir, config.WatchConfig{Enabled: true, DebounceMs: 50})
+	assert.NoError(t, err)
+	var ctx context.Context
+	ctx, w.cancel = context.WithCancel(context.Background())
+	go w.run(ctx)
+	defer w.stop()
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc A() {}\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c.go"), []byte("package a\n"), 0644))
+	// 创建后又删除的文件不生成事件
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tmp.go"), []byte("package a\n"), 0644))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "tmp.go")))
+	// 新建目录中的文件
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "d.go"), []byte("package sub\n"), 0644))
+	// 忽略的目录
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ignored", "x.go"), []byte("package x\n"), 0644))
+
+	want := map[string]string{
+		filepath.Join(dir, "a.go"):        "modify_file",
+		filepath.Join(dir, "c.go"):        "add_file",
+		filepath.Join(dir, "sub", "d.go"): "add_file",
+	}
+	got := make(map[string]string)
+	timeout := time.After(5 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case e := <-extension.events:
+			got[e.SourcePath] = e.EventType
+		case <-timeout:
+			t.Fatalf("timeout waiting for events, got %v", got)
+		}
+	}
+	// 没有多余的事件
+	select {
+	case e := <-extension.events:
+		got[e.SourcePath] = e.EventType
+	case <-time.After(300 * time.Millisecond):
+	}
+	assert.Equal(t, want, got)
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "c.go")))
+	select {
+	case e := <-extension.events:
+		assert.Equal(t, dto.WorkspaceEvent{EventType: "delete_file", EventTime: e.EventTime,
+			SourcePath: filepath.Join(dir, "c.go")}, e)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for delete event")
+	}
+}
+
+func TestWorkspaceWatcher_NestedIgnoreAndDirRename(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg", "inner"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", ".gitignore"), []byte("gen/\n"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg", "gen"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("package pkg\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "inner", "b.go"), []byte("package inner\n"), 0644))
+
+	mockLogger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		mockLogger.On(level, mock.Anything, mock.Anything).Return()
+	}
+	extension := &recordExtensionService{events: make(chan dto.WorkspaceEvent, 100)}
+	s := &fileWatchService{
+		watchers:    make(map[string]*workspaceWatcher),
+		extension:   extension,
+		fileScanner: repository.NewFileScanner(mockLogger),
+		logger:      mockLogger,
+	}
+	w, err := s.newWorkspaceWatcher(dir, config.WatchConfig{Enabled: true, DebounceMs: 50})
+	assert.NoError(t, err)
+	var ctx context.Context
+	ctx, w.cancel = context.WithCancel(context.Background())
+	go w.run(ctx)
+	defer w.stop()
+
+	collect := func(n int) map[string]string {
+		got := make(map[string]string)
+		timeout := time.After(5 * time.Second)
+		for len(got) < n {
+			select {
+			case e := <-extension.events:
+				got[e.SourcePath] = e.EventType
+			case <-timeout:
+				t.Fatalf("timeout waiting for events, got %v", got)
+			}
+		}
+		select {
+		case e := <-extension.events:
+			got[e.SourcePath] = e.EventType
+		case <-time.After(300 * time.Millisecond):
+		}
+		return got
+	}
+
+	// 子目录中的 .gitignore 生效，修改后重新加载
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "gen", "x.go"), []byte("package gen\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", ".gitignore"), []byte("gen/\n*.tmp.go\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "c.tmp.go"), []byte("package pkg\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("package pkg\n\nfunc A() {}\n"), 0644))
+	assert.Equal(t, map[string]string{
+		filepath.Join(dir, "pkg", "a.go"): "modify_file",
+	}, collect(1))
+
+	// 目录重命名展开为其下文件的删除及新增
+	assert.NoError(t, os.Rename(filepath.Join(dir, "pkg", "inner"), filepath.Join(dir, "pkg", "moved")))
+	assert.Equal(t, map[string]string{
+		filepath.Join(dir, "pkg", "inner", "b.go"): "delete_file",
+		filepath.Join(dir, "pkg", "moved", "b.go"): "add_file",
+	}, collect(2))
+}
+
+func TestFileWatchService_WatchConfig(t *testing.T) {
+	storage := &mocks.MockStorageManager{}
+	storage.On("GetCodebaseConfig", mock.Anything).Return(&config.CodebaseConfig{}, nil).Once()
+	storage.On("GetCodebaseConfig", mock.Anything).Return(&config.CodebaseConfig{
+		Watch: &config.WatchConfig{Enabled: true}}, nil).Once()
+	s := &fileWatchService{storage: storage}
+
+	// 未配置时不监听
+	assert.Equal(t, config.WatchConfig{DebounceMs: 500}, s.watchConfig("/ws"))
+	assert.Equal(t, config.WatchConfig{Enabled: true, DebounceMs: 500}, s.watchConfig("/ws"))
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGovernorMaxLoadPerCPU  = 0.75
+	defaultGovernorMaxRSSMB       = 2048
+	defaultGovernorMinAvailableMB = 1024
+	defaultGovernorPauseInterval  = 2 * time.Second
+)
+
+const (
+	governorReasonUnavailable = "system stats unavailable"
+	governorReasonRSS         = "process rss over limit"
+	governorReasonMemory      = "available memory below limit"
+	governorReasonLoad        = "system load over limit"
+	governorReasonIdle        = "system idle"
+	governorReasonSteady      = "steady"
+)
+
+// GovernorConfig 后台索引资源调节器配置，并发、内存均为硬上限
+type GovernorConfig struct {
+	MaxConcurrency int           // 并发批次数上限
+	MaxLoadPerCPU  float64       // 1 分钟平均负载与 CPU 核数之比的上限，超过时降低并发，低于一半时提高并发
+	MaxRSSMB       int           // 进程常驻内存上限，超过时降为单并发并暂停
+	MinAvailableMB int           // 系统可用内存下限，低于时降为单并发并暂停
+	PauseInterval  time.Duration // 压力下批次之间的暂停时长
+}
+
+// initGovernorConfig 从环境变量读取调节器配置，未设置或无效时使用默认值，并发上限不低于 MaxConcurrency
+func initGovernorConfig(config *GovernorConfig, baseline int) {
+	if val, ok := lookupEnvInt("GOVERNOR_MAX_CONCURRENCY"); ok {
+		config.MaxConcurrency = val
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = runtime.NumCPU() / 2
+	}
+	if config.MaxConcurrency < baseline {
+		config.MaxConcurrency = baseline
+	}
+	if envVal, ok := os.LookupEnv("GOVERNOR_MAX_LOAD_PER_CPU"); ok {
+		if val, err := strconv.ParseFloat(envVal, 64); err == nil && val > 0 {
+			config.MaxLoadPerCPU = val
+		}
+	}
+	if config.MaxLoadPerCPU <= 0 {
+		config.MaxLoadPerCPU = defaultGovernorMaxLoadPerCPU
+	}
+	if val, ok := lookupEnvInt("GOVERNOR_MAX_RSS_MB"); ok {
+		config.MaxRSSMB = val
+	}
+	if config.MaxRSSMB <= 0 {
+		config.MaxRSSMB = defaultGovernorMaxRSSMB
+	}
+	if val, ok := lookupEnvInt("GOVERNOR_MIN_AVAILABLE_MB"); ok {
+		config.MinAvailableMB = val
+	}
+	if config.MinAvailableMB <= 0 {
+		config.MinAvailableMB = defaultGovernorMinAvailableMB
+	}
+	if val, ok := lookupEnvInt("GOVERNOR_PAUSE_MS"); ok {
+		config.PauseInterval = time.Duration(val) * time.Millisecond
+	}
+	if config.PauseInterval <= 0 {
+		config.PauseInterval = defaultGovernorPauseInterval
+	}
+}
+
+// lookupEnvInt 读取正整数环境变量
+func lookupEnvInt(key string) (int, bool) {
+	envVal, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, false
+	}
+	val, err := strconv.Atoi(envVal)
+	if err != nil || val <= 0 {
+		return 0, false
+	}
+	return val, true
+}
+
+// systemStats 系统负载及内存
+type systemStats struct {
+	Load1       float64
+	CPUs        int
+	RSSMB       int
+	AvailableMB int
+}
+
+// readSystemStats 从 /proc 读取 1 分钟平均负载、进程常驻内存及系统可用内存，仅支持 Linux
+func readSystemStats() (*systemStats, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("system stats not supported on %s", runtime.GOOS)
+	}
+	loadavg, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(loadavg))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid /proc/loadavg: %q", loadavg)
+	}
+	load1, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid /proc/loadavg: %w", err)
+	}
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return nil, err
+	}
+	rssKB, err := procValueKB(status, "VmRSS")
+	if err != nil {
+		return nil, err
+	}
+	meminfo, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	availableKB, err := procValueKB(meminfo, "MemAvailable")
+	if err != nil {
+		return nil, err
+	}
+	return &systemStats{
+		Load1:       load1,
+		CPUs:        runtime.NumCPU(),
+		RSSMB:       int(rssKB / 1024),
+		AvailableMB: int(availableKB / 1024),
+	}, nil
+}
+
+// procValueKB 读取 /proc 文件中 "Key:   123 kB" 格式的值
+func procValueKB(content []byte, key string) (int64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || name != key {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			break
+		}
+		return strconv.ParseInt(fields[0], 10, 64)
+	}
+	return 0, fmt.Errorf("%s not found", key)
+}
+
+// governor 后台索引资源调节器：每个批次开始前采样系统负载及内存，空闲时逐步提高并发，负载高时降低并发，
+// 内存紧张时降为单并发，压力下批次之间暂停。所有项目的索引任务共用一个调节器，并发上限对全部任务生效
+type governor struct {
+	mu       sync.Mutex
+	config   GovernorConfig
+	baseline int
+	state    types.GovernorState
+	sample   func() (*systemStats, error)
+	limiter  *batchLimiter // 所有索引任务共用的批次信号量
+}
+
+func newGovernor(config GovernorConfig, baseline int) *governor {
+	return &governor{
+		config:   config,
+		baseline: baseline,
+		state:    types.GovernorState{Concurrency: baseline, MaxConcurrency: config.MaxConcurrency},
+		sample:   readSystemStats,
+		limiter:  newBatchLimiter(),
+	}
+}
+
+// adjust 采样并调整并发，返回当前并发批次数及批次之间的暂停时长，暂停仅用于两个批次之间，首个批次不暂停
+func (g *governor) adjust() (int, time.Duration) {
+	stats, err := g.sample()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	state := &g.state
+	state.UpdatedAt = time.Now().Unix()
+	state.Paused, state.PauseMs = false, 0
+	if err != nil {
+		// 无法采样时保持配置的并发
+		state.Concurrency, state.Reason = g.baseline, governorReasonUnavailable
+		return state.Concurrency, 0
+	}
+	state.Load1, state.CPUs, state.RSSMB, state.AvailableMB = stats.Load1, stats.CPUs, stats.RSSMB, stats.AvailableMB
+
+	var pause time.Duration
+	loadPerCPU := stats.Load1 / float64(max(stats.CPUs, 1))
+	switch {
+	case stats.RSSMB > g.config.MaxRSSMB:
+		state.Concurrency, state.Reason, pause = 1, governorReasonRSS, g.config.PauseInterval
+	case stats.AvailableMB < g.config.MinAvailableMB:
+		state.Concurrency, state.Reason, pause = 1, governorReasonMemory, g.config.PauseInterval
+	case loadPerCPU > g.config.MaxLoadPerCPU:
+		state.Concurrency, state.Reason, pause = max(state.Concurrency/2, 1), governorReasonLoad, g.config.PauseInterval
+	case loadPerCPU < g.config.MaxLoadPerCPU/2:
+		state.Concurrency, state.Reason = min(state.Concurrency+1, g.config.MaxConcurrency), governorReasonIdle
+	default:
+		state.Reason = governorReasonSteady
+	}
+	state.Paused, state.PauseMs = pause > 0, pause.Milliseconds()
+	return state.Concurrency, pause
+}
+
+// acquire 等待所有索引任务正在执行的批次数小于并发上限，ctx 结束时放弃等待
+func (g *governor) acquire(ctx context.Context, concurrency int) error {
+	return g.limiter.acquire(ctx, concurrency)
+}
+
+// release 批次执行结束
+func (g *governor) release() {
+	g.limiter.release()
+}
+
+// State 当前状态的副本
+func (g *governor) State() *types.GovernorState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	state := g.state
+	return &state
+}
+
+// batchLimiter 并发批次数可以动态调整的信号量
+type batchLimiter struct {
+	mu      sync.Mutex
+	running int
+	wake    chan struct{} // 批次结束时关闭，唤醒等待者
+}
+
+func newBatchLimiter() *batchLimiter {
+	return &batchLimiter{wake: make(chan struct{})}
+}
+
+// acquire 等待正在执行的批次数小于 limit，ctx 结束时返回 ctx.Err()
+func (l *batchLimiter) acquire(ctx context.Context, limit int) error {
+	for {
+		l.mu.Lock()
+		if l.running < max(limit, 1) {
+			l.running++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+func (l *batchLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// sleepContext 暂停 d，context 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// GetGovernorState 获取后台索引资源调节器的状态
+func (i *indexer) GetGovernorState() *types.GovernorState {
+	return i.governor.State()
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernor_Adjust(t *testing.T) {
+	config := GovernorConfig{
+		MaxConcurrency: 4,
+		MaxLoadPerCPU:  1,
+		MaxRSSMB:       1024,
+		MinAvailableMB: 512,
+		PauseInterval:  time.Second,
+	}
+	g := newGovernor(config, 2)
+	stats := &systemStats{Load1: 0.5, CPUs: 4, RSSMB: 100, AvailableMB: 4096}
+	var sampleErr error
+	g.sample = func() (*systemStats, error) {
+		return stats, sampleErr
+	}
+
+	// 空闲时逐步提高并发，不超过上限
+	for _, want := range []int{3, 4, 4} {
+		concurrency, pause := g.adjust()
+		assert.Equal(t, want, concurrency)
+		assert.Zero(t, pause)
+	}
+	assert.Equal(t, governorReasonIdle, g.State().Reason)
+
+	// 负载适中时保持
+	stats.Load1 = 3
+	concurrency, pause := g.adjust()
+	assert.Equal(t, 4, concurrency)
+	assert.Zero(t, pause)
+	assert.Equal(t, governorReasonSteady, g.State().Reason)
+
+	// 负载过高时减半并暂停
+	stats.Load1 = 8
+	concurrency, pause = g.adjust()
+	assert.Equal(t, 2, concurrency)
+	assert.Equal(t, time.Second, pause)
+	state := g.State()
+	assert.True(t, state.Paused)
+	assert.Equal(t, int64(1000), state.PauseMs)
+	assert.Equal(t, governorReasonLoad, state.Reason)
+
+	// 内存紧张时降为单并发
+	stats.Load1, stats.AvailableMB = 0.5, 256
+	concurrency, pause = g.adjust()
+	assert.Equal(t, 1, concurrency)
+	assert.Equal(t, time.Second, pause)
+	assert.Equal(t, governorReasonMemory, g.State().Reason)
+
+	stats.AvailableMB, stats.RSSMB = 4096, 2048
+	concurrency, _ = g.adjust()
+	assert.Equal(t, 1, concurrency)
+	assert.Equal(t, governorReasonRSS, g.State().Reason)
+
+	// 无法采样时恢复配置的并发
+	sampleErr = errors.New("unavailable")
+	concurrency, pause = g.adjust()
+	assert.Equal(t, 2, concurrency)
+	assert.Zero(t, pause)
+	assert.False(t, g.State().Paused)
+	assert.Equal(t, governorReasonUnavailable, g.State().Reason)
+}
+
+func TestProcValueKB(t *testing.T) {
+	content := []byte("MemTotal:       16303412 kB\nMemFree:         1234567 kB\nMemAvailable:    8151706 kB\n")
+	val, err := procValueKB(content, "MemAvailable")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8151706), val)
+	_, err = procValueKB(content, "VmRSS")
+	assert.Error(t, err)
+}
+
+func TestBatchLimiter(t *testing.T) {
+	limiter := newBatchLimiter()
+	var running, peak int32
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		assert.NoError(t, limiter.acquire(context.Background(), 3))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer limiter.release()
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+func TestBatchLimiter_Cancel(t *testing.T) {
+	limiter := newBatchLimiter()
+	assert.NoError(t, limiter.acquire(context.Background(), 1))
+	// 已满时取消的任务不再等待其他批次释放
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.acquire(ctx, 1), context.DeadlineExceeded)
+	limiter.release()
+	assert.NoError(t, limiter.acquire(context.Background(), 1))
+}
+
+func TestGovernor_SharedLimit(t *testing.T) {
+	g := newGovernor(GovernorConfig{MaxConcurrency: 2}, 2)
+	var running, peak int32
+	var tasks sync.WaitGroup
+	// 两个索引任务共用调节器，并发批次数合计不超过上限
+	for task := 0; task < 2; task++ {
+		tasks.Add(1)
+		go func() {
+			defer tasks.Done()
+			var wg sync.WaitGroup
+			for n := 0; n < 10; n++ {
+				assert.NoError(t, g.acquire(context.Background(), 2))
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer g.release()
+					cur := atomic.AddInt32(&running, 1)
+					for {
+						old := atomic.LoadInt32(&peak)
+						if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					atomic.AddInt32(&running, -1)
+				}()
+			}
+			wg.Wait()
+		}()
+	}
+	tasks.Wait()
+	assert.LessOrEqual(t, peak, int32(2))
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"container/heap"
+	"errors"
+	"sort"
+	"time"
+)
+
+// 索引队列优先级，数值越小越先处理
+const (
+	priorityOpenFile            = iota // 编辑器中打开的文件
+	priorityRecentEdit                 // 最近修改的文件
+	priorityActiveWorkspaceFile        // 活跃工作区的文件事件
+	priorityFile                       // 其他工作区的文件事件
+	priorityActiveWorkspace            // 活跃工作区的打开、重建
+	priorityWorkspace                  // 其他工作区的打开、重建（冷构建）
+)
+
+const (
+	recentEditWindow        = 10 * time.Minute // 在此时间内修改的文件视为最近编辑
+	queueFetchLimit         = 1000             // 每轮从事件表加载的文件事件数，同一工作区的合并后批量处理
+	queueWorkspaceLimit     = 10               // 每轮从事件表加载的工作区事件数
+	queueYieldCheckInterval = 2 * time.Second  // 工作区构建期间检查交互式事件的间隔
+)
+
+// queueMinBuildSlice 工作区构建每次至少运行的时长，之后有文件事件时才让出
+var queueMinBuildSlice = 30 * time.Second
+
+var (
+	fileEventTypes = []string{model.EventTypeAddFile, model.EventTypeModifyFile,
+		model.EventTypeDeleteFile, model.EventTypeRenameFile}
+	workspaceEventTypes = []string{model.EventTypeRebuildWorkspace, model.EventTypeOpenWorkspace}
+)
+
+// errIndexYielded 工作区构建让出给交互式编辑，事件保持构建中状态，之后从已索引的位置继续
+var errIndexYielded = errors.New("workspace index yielded to interactive events")
+
+// queueHints 计算优先级的依据
+type queueHints struct {
+	openFiles       map[string]time.Time // 编辑器中打开的文档及最近更新时间
+	activeWorkspace string               // 最近有交互的工作区
+	now             time.Time
+}
+
+// newQueueHints 以最近更新的打开文档所在的工作区为活跃工作区，没有打开文档时取最近修改的文件事件所在的工作区
+func newQueueHints(openFiles map[string]time.Time, workspacePaths []string, events []*model.Event) *queueHints {
+	hints := &queueHints{openFiles: openFiles, now: time.Now()}
+	var latest time.Time
+	for path, updatedAt := range openFiles {
+		if !updatedAt.After(latest) {
+			continue
+		}
+		for _, w := range workspacePaths {
+			if utils.IsSubdir(w, path) {
+				hints.activeWorkspace, latest = w, updatedAt
+				break
+			}
+		}
+	}
+	if hints.activeWorkspace != "" {
+		return hints
+	}
+	for _, e := range events {
+		if isFileEvent(e) && eventTime(e).After(latest) {
+			hints.activeWorkspace, latest = e.WorkspacePath, eventTime(e)
+		}
+	}
+	return hints
+}
+
+func isFileEvent(event *model.Event) bool {
+	for _, t := range fileEventTypes {
+		if event.EventType == t {
+			return true
+		}
+	}
+	return false
+}
+
+func eventTime(event *model.Event) time.Time {
+	if event.UpdatedAt.After(event.CreatedAt) {
+		return event.UpdatedAt
+	}
+	return event.CreatedAt
+}
+
+// priority 事件的优先级
+func (h *queueHints) priority(event *model.Event) int {
+	active := event.WorkspacePath == h.activeWorkspace
+	if !isFileEvent(event) {
+		if active {
+			return priorityActiveWorkspace
+		}
+		return priorityWorkspace
+	}
+	for _, path := range []string{event.SourceFilePath, event.TargetFilePath} {
+		if _, ok := h.openFiles[path]; ok && path != "" {
+			return priorityOpenFile
+		}
+	}
+	if h.now.Sub(eventTime(event)) <= recentEditWindow {
+		return priorityRecentEdit
+	}
+	if active {
+		return priorityActiveWorkspaceFile
+	}
+	return priorityFile
+}
+
+type queueItem struct {
+	event    *model.Event
+	priority int
+}
+
+// indexQueue 跨工作区的索引优先队列，事件按优先级处理，同优先级按事件 ID 先后处理。
+// 事件本身持久化在事件表中，每轮处理前从事件表重新加载
+type indexQueue []*queueItem
+
+// newIndexQueue 计算事件优先级并建堆。同一文件的多个事件取其中最高的优先级，保证按事件先后处理
+func newIndexQueue(events []*model.Event, hints *queueHints) *indexQueue {
+	filePriority := make(map[string]int)
+	priorities := make([]int, len(events))
+	for n, e := range events {
+		priorities[n] = hints.priority(e)
+		if !isFileEvent(e) {
+			continue
+		}
+		if p, ok := filePriority[e.SourceFilePath]; !ok || priorities[n] < p {
+			filePriority[e.SourceFilePath] = priorities[n]
+		}
+	}
+	q := make(indexQueue, 0, len(events))
+	for n, e := range events {
+		if isFileEvent(e) {
+			priorities[n] = filePriority[e.SourceFilePath]
+		}
+		q = append(q, &queueItem{event: e, priority: priorities[n]})
+	}
+	heap.Init(&q)
+	return &q
+}
+
+func (q indexQueue) Len() int { return len(q) }
+
+func (q indexQueue) Less(a, b int) bool {
+	if q[a].priority != q[b].priority {
+		return q[a].priority < q[b].priority
+	}
+	return q[a].event.ID < q[b].event.ID
+}
+
+func (q indexQueue) Swap(a, b int) { q[a], q[b] = q[b], q[a] }
+
+func (q *indexQueue) Push(x interface{}) { *q = append(*q, x.(*queueItem)) }
+
+func (q *indexQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// pop 取出优先级最高的事件
+func (q *indexQueue) pop() *queueItem {
+	return heap.Pop(q).(*queueItem)
+}
+
+// prioritizeFiles 工作区内文件的索引顺序：打开的文件，最近修改的文件（新的在前），其余文件
+func (i *indexer) prioritizeFiles(projectUuid string, files []*types.FileWithModTimestamp) {
+	open := i.buffers.tables(projectUuid)
+	recent := time.Now().Add(-recentEditWindow).Unix()
+	rank := func(f *types.FileWithModTimestamp) int {
+		if _, ok := open[f.Path]; ok {
+			return 0
+		}
+		if f.ModTime >= recent {
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(files, func(a, b int) bool {
+		ra, rb := rank(files[a]), rank(files[b])
+		if ra != rb {
+			return ra < rb
+		}
+		if ra == 1 && files[a].ModTime != files[b].ModTime {
+			return files[a].ModTime > files[b].ModTime
+		}
+		return files[a].Path < files[b].Path
+	})
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/types"
+)
+
+func TestIndexQueue(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	events := []*model.Event{
+		{ID: 1, WorkspacePath: "/b", EventType: model.EventTypeRebuildWorkspace, CreatedAt: old},
+		{ID: 2, WorkspacePath: "/a", EventType: model.EventTypeOpenWorkspace, CreatedAt: old},
+		{ID: 3, WorkspacePath: "/b", EventType: model.EventTypeAddFile, SourceFilePath: "/b/x.go", CreatedAt: old},
+		{ID: 4, WorkspacePath: "/a", EventType: model.EventTypeModifyFile, SourceFilePath: "/a/y.go", CreatedAt: old},
+		{ID: 5, WorkspacePath: "/b", EventType: model.EventTypeModifyFile, SourceFilePath: "/b/z.go", CreatedAt: time.Now()},
+		{ID: 6, WorkspacePath: "/a", EventType: model.EventTypeModifyFile, SourceFilePath: "/a/open.go", CreatedAt: old},
+		// 同一文件的事件按先后处理
+		{ID: 7, WorkspacePath: "/a", EventType: model.EventTypeDeleteFile, SourceFilePath: "/a/y.go", CreatedAt: time.Now().Add(-time.Minute)},
+	}
+	openFiles := map[string]time.Time{"/a/open.go": time.Now()}
+	hints := newQueueHints(openFiles, []string{"/a", "/b"}, events)
+	assert.Equal(t, "/a", hints.activeWorkspace)
+
+	queue := newIndexQueue(events, hints)
+	var order []int64
+	for queue.Len() > 0 {
+		order = append(order, queue.pop().event.ID)
+	}
+	assert.Equal(t, []int64{6, 4, 5, 7, 3, 2, 1}, order)
+
+	// 没有打开的文档时，最近修改的文件事件所在的工作区为活跃工作区
+	hints = newQueueHints(nil, []string{"/a", "/b"}, events)
+	assert.Equal(t, "/b", hints.activeWorkspace)
+}
+
+func TestIndexer_prioritizeFiles(t *testing.T) {
+	i := &indexer{buffers: newBufferOverlay()}
+	i.buffers.put(&overlayBuffer{ProjectUuid: "p", Path: "/p/open.go", Version: 1})
+	now := time.Now().Unix()
+	files := []*types.FileWithModTimestamp{
+		{Path: "/p/b.go", ModTime: now - 86400},
+		{Path: "/p/recent.go", ModTime: now - 60},
+		{Path: "/p/a.go", ModTime: now - 86400},
+		{Path: "/p/open.go", ModTime: now - 86400},
+		{Path: "/p/latest.go", ModTime: now},
+	}
+	i.prioritizeFiles("p", files)
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"/p/open.go", "/p/latest.go", "/p/recent.go", "/p/a.go", "/p/b.go"}, paths)
+}
@@ -1,17 +1,22 @@
 package service
 
 import (
+	"bytes"
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/pkg/codegraph/analyzer"
 	"codebase-indexer/pkg/codegraph/cache"
+	"codebase-indexer/pkg/codegraph/chunker"
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/parser"
 	"codebase-indexer/pkg/codegraph/proto"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/resolver"
 	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/structural"
+	"codebase-indexer/pkg/codegraph/textindex"
 	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/vector"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
 	"context"
@@ -19,12 +24,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
 )
 
 const maxQueryLineLimit = 200
+const maxDocFillDefinitions = 100
 
 type IndexerConfig struct {
 	MaxConcurrency int
@@ -33,6 +45,7 @@ type IndexerConfig struct {
 	MaxProjects    int
 	VisitPattern   *types.VisitPattern
 	CacheCapacity  int
+	Governor       GovernorConfig // 后台索引资源调节器，MaxConcurrency 为其起始并发
 }
 
 // Indexer 定义代码索引器的接口，便于mock测试
@@ -55,14 +68,54 @@ type Indexer interface {
 	// QueryReferences 查询引用
 	QueryReferences(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error)
 
+	// WalkReferences 逐个回调引用，用于分页及流式输出
+	WalkReferences(ctx context.Context, opts *types.QueryReferenceOptions, visit types.ReferenceVisitor) error
+
 	// QueryDefinitions 查询定义
 	QueryDefinitions(ctx context.Context, options *types.QueryDefinitionOptions) ([]*types.Definition, error)
 
+	// QueryText 全文检索，按 BM25 排序
+	QueryText(ctx context.Context, options *types.QueryTextOptions) ([]*textindex.Hit, error)
+
+	// QuerySemantic 本地向量检索，可与符号名匹配结果融合
+	QuerySemantic(ctx context.Context, options *types.QuerySemanticOptions) ([]*vector.Hit, error)
+
+	// QueryHover 查找光标处的标识符及其定义
+	QueryHover(ctx context.Context, options *types.QueryHoverOptions) (*types.SymbolHover, error)
+
+	// QueryImportedFiles 查找文件的导入解析到的项目内文件，按匹配的导入数降序，不含文件本身
+	QueryImportedFiles(ctx context.Context, workspacePath string, filePath string) ([]string, error)
+
+	// QueryRename 计算重命名的工作区编辑及冲突
+	QueryRename(ctx context.Context, opts *types.QueryRenameOptions) (*types.RenameResult, error)
+
+	// QueryStructural 在工作区内执行 tree-sitter 结构化查询，逐个回调捕获
+	QueryStructural(ctx context.Context, options *types.QueryStructuralOptions,
+		emit func(*structural.Capture) error) (*types.StructuralSearchStats, error)
+
 	// GetSummary 获取代码图摘要信息
 	GetSummary(ctx context.Context, workspacePath string) (*types.CodeGraphSummary, error)
 
+	// UpdateBuffer 解析编辑器中未保存文档的内容，作为覆盖层优先于索引参与查询，返回覆盖层中的版本号
+	UpdateBuffer(ctx context.Context, workspacePath string, filePath string, version int64, content []byte) (int64, error)
+
+	// CloseBuffer 关闭文档时丢弃其覆盖层
+	CloseBuffer(ctx context.Context, workspacePath string, filePath string) error
+
+	// GetBuffer 获取未保存文档的内容，不存在时返回 false
+	GetBuffer(ctx context.Context, workspacePath string, filePath string) ([]byte, bool)
+
 	// IndexIter 获取索引迭代器
 	IndexIter(ctx context.Context, projectUuid string) store.Iterator
+
+	// GetGovernorState 获取后台索引资源调节器的状态
+	GetGovernorState() *types.GovernorState
+
+	// GetOpenBuffers 获取编辑器中打开的文档路径及最近更新时间
+	GetOpenBuffers() map[string]time.Time
+
+	// GetDroppedFiles 获取工作区超出文件数上限未索引的文件数及文件（按优先级排序，有数量上限）
+	GetDroppedFiles(workspacePath string) (int, []string)
 }
 
 // indexer 代码索引器
@@ -72,7 +125,13 @@ type indexer struct {
 	analyzer            *analyzer.DependencyAnalyzer // 跨文件依赖分析
 	workspaceReader     workspace.WorkspaceReader    // 进行工作区的文件读取、项目识别、项目列表维护
 	storage             store.GraphStorage           // 存储
+	textIndex           *textindex.Index             // 全文索引，可为空
+	vectorStore         *vector.Store                // 本地向量索引，可为空
+	chunker             *chunker.Chunker
 	workspaceRepository repository.WorkspaceRepository
+	buffers             *bufferOverlay // 编辑器中未保存文档的解析结果
+	governor            *governor      // 后台索引资源调节器
+	dropped             droppedFiles   // 超出文件数上限未索引的文件
 	config              *IndexerConfig
 	logger              logger.Logger
 }
@@ -86,6 +145,8 @@ type BatchProcessParams struct {
 	BatchSize   int
 	TotalFiles  int
 	Project     *workspace.Project
+	Incremental bool        // 以缓存的语法树增量解析，用于频繁修改的文件
+	SaveMu      *sync.Mutex // 批次并发时串行保存，符号表的读取、追加、写入不能并发
 }
 
 // BatchProcessResult 批处理结果
@@ -105,6 +166,7 @@ type BatchProcessingParams struct {
 	WorkspacePath        string
 	Concurrency          int
 	BatchSize            int
+	Incremental          bool // 以缓存的语法树增量解析，用于频繁修改的文件
 }
 
 // BatchProcessingResult 批处理阶段结果
@@ -153,6 +215,8 @@ func NewCodeIndexer(
 	analyzer *analyzer.DependencyAnalyzer,
 	workspaceReader workspace.WorkspaceReader,
 	storage store.GraphStorage,
+	textIndex *textindex.Index,
+	vectorStore *vector.Store,
 	workspaceRepository repository.WorkspaceRepository,
 	config IndexerConfig,
 	logger logger.Logger,
@@ -164,7 +228,12 @@ func NewCodeIndexer(
 		analyzer:            analyzer,
 		workspaceReader:     workspaceReader,
 		storage:             storage,
+		textIndex:           textIndex,
+		vectorStore:         vectorStore,
+		chunker:             chunker.NewChunker(chunker.Options{}),
 		workspaceRepository: workspaceRepository,
+		buffers:             newBufferOverlay(),
+		governor:            newGovernor(config.Governor, config.MaxConcurrency),
 		config:              &config,
 		logger:              logger,
 	}
@@ -219,6 +288,8 @@ func initConfig(config *IndexerConfig) {
 	if config.CacheCapacity <= 0 {
 		config.CacheCapacity = defaultCacheCapacity
 	}
+
+	initGovernorConfig(&config.Governor, config.MaxConcurrency)
 }
 
 // IndexWorkspace 索引整个工作区
@@ -244,6 +315,10 @@ func (i *indexer) IndexWorkspace(ctx context.Context, workspacePath string) (*ty
 
 	// 循环项目，逐个处理
 	for _, project := range projects {
+		// 取消或让出时停止，已索引的文件下次按时间戳过滤
+		if err := ctx.Err(); err != nil {
+			return taskMetrics, err
+		}
 		projectTaskMetrics, err := i.indexProject(ctx, workspacePath, project)
 		if err != nil {
 			i.logger.Error("index project %s err: %v",
@@ -256,6 +331,9 @@ func (i *indexer) IndexWorkspace(ctx context.Context, workspacePath string) (*ty
 		taskMetrics.TotalFailedFiles += projectTaskMetrics.TotalFailedFiles
 		taskMetrics.FailedFilePaths = append(taskMetrics.FailedFilePaths, projectTaskMetrics.FailedFilePaths...)
 	}
+	if err := ctx.Err(); err != nil {
+		return taskMetrics, err
+	}
 
 	i.logger.Info("workspace %s index end. cost %d ms, indexed %d projects, visited %d files, "+
 		"parsed %d files successfully, failed %d files", workspacePath,
@@ -284,7 +362,7 @@ func (i *indexer) indexProject(ctx context.Context, workspacePath string, projec
 	databasePreviousFileNum := workspaceModel.CodegraphFileNum
 
 	// 收集要处理的源码文件
-	sourceFileTimestamps, err := i.collectFiles(ctx, workspacePath, project.Path)
+	sourceFileTimestamps, err := i.collectFiles(ctx, workspacePath, project)
 	if err != nil {
 		return &types.IndexTaskMetrics{TotalFiles: 0}, []error{fmt.Errorf("collect project files err:%v", err)}
 	}
@@ -301,6 +379,8 @@ func (i *indexer) indexProject(ctx context.Context, workspacePath string, projec
 	sourceFileTimestamps = nil
 
 	filteredCnt := totalFilesCnt - len(needIndexFiles)
+	// 打开的文件及最近修改的文件优先索引
+	i.prioritizeFiles(projectUuid, needIndexFiles)
 
 	i.logger.Info("workspace %s filter files by timestamp cost %d ms, total %d files, remaining %d files, filtered %d files.", workspacePath,
 		time.Since(filterStart).Milliseconds(), totalFilesCnt, len(needIndexFiles), filteredCnt)
@@ -417,6 +497,16 @@ func (i *indexer) RemoveIndexes(ctx context.Context, workspacePath string, fileP
 		if err != nil {
 			errs = append(errs, err)
 		}
+		if i.textIndex != nil {
+			if err = i.textIndex.RemoveFiles(ctx, projectUuid, files); err != nil {
+				errs = append(errs, fmt.Errorf("remove text index failed: %w", err))
+			}
+		}
+		if i.vectorStore != nil {
+			if err = i.vectorStore.RemoveFiles(ctx, projectUuid, files); err != nil {
+				errs = append(errs, fmt.Errorf("remove vector index failed: %w", err))
+			}
+		}
 		totalRemoved += removed
 		i.logger.Info("remove project %s files index end, cost %d ms, removed %d index.", projectUuid,
 			time.Since(pStart).Milliseconds(), removed)
@@ -678,6 +768,7 @@ func (i *indexer) IndexFiles(ctx context.Context, workspacePath string, filePath
 				PreviousFileNum:      workspaceModel.FileNum,
 				Concurrency:          i.config.MaxConcurrency,
 				BatchSize:            i.config.MaxBatchSize,
+				Incremental:          true,
 			}
 
 			batchResult, err := i.indexFilesInBatches(ctx, batchParams)
@@ -876,6 +967,23 @@ func (i *indexer) checkElementTables(elementTables []*parser.FileElementTable) {
 
 // QueryReferences 实现查询接口
 func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error) {
+	var definitions []*types.RelationNode
+	err := i.WalkReferences(ctx, opts, func(definition *types.RelationNode, reference *types.RelationNode) error {
+		if reference == nil {
+			definitions = append(definitions, definition)
+			return nil
+		}
+		definition.Children = append(definition.Children, reference)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// WalkReferences 查找范围内或指定名称的定义，遍历索引逐个回调其引用，不在内存中汇总结果
+func (i *indexer) WalkReferences(ctx context.Context, opts *types.QueryReferenceOptions, visit types.ReferenceVisitor) error {
 
 	startTime := time.Now()
 	filePath := opts.FilePath
@@ -892,23 +1000,19 @@ func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenc
 		opts.EndLine = opts.StartLine + maxQueryLineLimit
 	}
 
-	project, err := i.workspaceReader.GetProjectByFilePath(ctx, opts.Workspace, filePath, true)
+	project, err := i.getProject(ctx, opts.Workspace, filePath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	projectUuid := project.Uuid
 
 	language, err := lang.InferLanguage(filePath)
 	if err != nil {
-		return nil, lang.ErrUnSupportedLanguage
+		return lang.ErrUnSupportedLanguage
 	}
 
-	exists, err := i.storage.ProjectIndexExists(projectUuid)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check workspace %s index, err:%v", opts.Workspace, err)
-	}
-	if !exists {
-		return nil, fmt.Errorf("workspace %s index not exists", opts.Workspace)
+	if err = i.checkProjectIndexExists(ctx, opts.Workspace, projectUuid); err != nil {
+		return err
 	}
 
 	defer func() {
@@ -916,16 +1020,12 @@ func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenc
 	}()
 
 	// 1. 获取文件元素表
-	var fileElementTable codegraphpb.FileElementTable
-	fileTableBytes, err := i.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath})
+	fileElementTable, err := i.loadFileElementTable(ctx, projectUuid, language, filePath)
 	if errors.Is(err, store.ErrKeyNotFound) {
-		return nil, fmt.Errorf("index not found for file %s", filePath)
+		return fmt.Errorf("index not found for file %s", filePath)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file %s index, err: %v", filePath, err)
-	}
-	if err = store.UnmarshalValue(fileTableBytes, &fileElementTable); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal file %s index value, err: %v", filePath, err)
+		return fmt.Errorf("failed to get file %s index, err: %v", filePath, err)
 	}
 
 	var definitions []*types.RelationNode
@@ -933,10 +1033,10 @@ func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenc
 
 	// Find root symbols based on query options
 	if opts.SymbolName != types.EmptyString {
-		foundSymbols = i.querySymbolsByName(&fileElementTable, opts)
+		foundSymbols = i.querySymbolsByName(fileElementTable, opts)
 		i.logger.Debug("Found %d symbols by name and line", len(foundSymbols))
 	} else {
-		foundSymbols = i.querySymbolsByLines(ctx, &fileElementTable, opts)
+		foundSymbols = i.querySymbolsByLines(ctx, fileElementTable, opts)
 		i.logger.Debug("Found %d symbols by position", len(foundSymbols))
 	}
 
@@ -944,11 +1044,11 @@ func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenc
 	if len(foundSymbols) == 0 {
 		i.logger.Debug("symbol not found: name %s line %d:%d in document %s", opts.SymbolName,
 			opts.StartLine, opts.EndLine, opts.FilePath)
-		return definitions, nil
+		return nil
 	}
 
-	// root
-	definitionNames := make(map[string]*types.RelationNode, len(foundSymbols))
+	// root，同名定义（重载）按调用的实参个数及类型区分
+	definitionNames := make(map[string][]*rootDefinition, len(foundSymbols))
 	// 找定义节点，以定义节点为根节点进行深度遍历
 	for _, s := range foundSymbols {
 		// 定义作为根节点
@@ -970,24 +1070,28 @@ func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenc
 			Children:   make([]*types.RelationNode, 0),
 		}
 		definitions = append(definitions, def)
-		// TODO 未处理同名定义问题，存在覆盖情况
-		definitionNames[s.Name] = def
+		root := &rootDefinition{node: def}
+		if s.ElementType == codegraphpb.ElementType_METHOD || s.ElementType == codegraphpb.ElementType_FUNCTION {
+			if params, err := declaredParameters(s); err == nil {
+				root.params = params
+				if root.params == nil {
+					root.params = []declaredParameter{}
+				}
+			}
+		}
+		definitionNames[s.Name] = append(definitionNames[s.Name], root)
 	}
 	if len(definitions) == 0 {
-		return definitions, nil
+		return nil
 	}
-	iter := i.storage.Iter(ctx, projectUuid)
-	defer iter.Close()
-	for iter.Next() {
-		key := iter.Key()
-		if store.IsSymbolNameKey(key) {
-			continue
-		}
-		var elementTable codegraphpb.FileElementTable
-		if err = store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
-			i.logger.Error("failed to unmarshal file %s element_table value, err: %v", filePath, err)
-			continue
+	for _, def := range definitions {
+		if err = visit(def, nil); err != nil {
+			return err
 		}
+	}
+	// 未保存的文档以覆盖层中的解析结果代替索引
+	buffers := i.buffers.tables(projectUuid)
+	walkTable := func(elementTable *codegraphpb.FileElementTable) error {
 		// TODO 根据import 过滤
 
 		for _, element := range elementTable.Elements {
@@ -999,18 +1103,96 @@ func (i *indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenc
 				continue
 			}
 			// 引用
-			if v, ok := definitionNames[element.Name]; ok {
-				v.Children = append(v.Children, &types.RelationNode{
+			roots, ok := definitionNames[element.Name]
+			if !ok {
+				continue
+			}
+			matched, ambiguous := matchRootDefinitions(elementTable, element, roots)
+			for _, v := range matched {
+				if err := visit(v.node, &types.RelationNode{
 					FilePath:   elementTable.Path,
 					SymbolName: element.Name,
 					Position:   types.ToPosition(element.Range),
 					NodeType:   string(proto.ElementTypeFromProto(element.ElementType)),
-				})
+					Ambiguous:  ambiguous,
+				}); err != nil {
+					return err
+				}
 			}
 		}
+		return nil
+	}
+	iter := i.storage.Iter(ctx, projectUuid)
+	defer iter.Close()
+	for iter.Next() {
+		if err = utils.CheckContextCanceled(ctx); err != nil {
+			return err
+		}
+		key := iter.Key()
+		if store.IsSymbolNameKey(key) {
+			continue
+		}
+		var elementTable codegraphpb.FileElementTable
+		if err = store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
+			i.logger.Error("failed to unmarshal file %s element_table value, err: %v", filePath, err)
+			continue
+		}
+		table := &elementTable
+		if buffer, ok := buffers[elementTable.Path]; ok {
+			table = buffer
+			delete(buffers, elementTable.Path)
+		}
+		if err = walkTable(table); err != nil {
+			return err
+		}
+	}
+	// 尚未索引的未保存文档
+	for _, table := range buffers {
+		if err = walkTable(table); err != nil {
+			return err
+		}
 	}
 
-	return definitions, nil
+	return nil
+}
+
+// rootDefinition 引用树的根定义，params 为函数/方法的形参，类、接口或未知时为 nil
+type rootDefinition struct {
+	node   *types.RelationNode
+	params []declaredParameter
+}
+
+// matchRootDefinitions 调用所对应的根定义：只有一个同名定义或不是调用时全部返回；存在重载时按实参个数及类型选择，
+// 多个重载同样匹配时返回全部匹配的重载并标记为歧义，没有重载匹配时返回全部同名定义
+func matchRootDefinitions(table *codegraphpb.FileElementTable, element *codegraphpb.Element,
+	roots []*rootDefinition) ([]*rootDefinition, bool) {
+	if len(roots) < 2 || element.ElementType != codegraphpb.ElementType_CALL {
+		return roots, false
+	}
+	args, err := proto.GetParametersFromExtraData(element.ExtraData)
+	if err != nil {
+		return roots, false
+	}
+	signatures := make([][]declaredParameter, len(roots))
+	for idx, r := range roots {
+		signatures[idx] = r.params
+	}
+	best := selectOverloads(len(args), inferArgumentTypes(args, enclosingDeclaredTypes(table, element)), signatures)
+	if len(best) == 0 {
+		return roots, false
+	}
+	selected := make(map[int]struct{}, len(best))
+	for _, idx := range best {
+		selected[idx] = struct{}{}
+	}
+	matched := make([]*rootDefinition, 0, len(roots))
+	for idx, r := range roots {
+		// 类、接口（如与构造函数同名的类）不参与重载选择
+		if _, ok := selected[idx]; ok || r.params == nil {
+			matched = append(matched, r)
+		}
+	}
+	return matched, len(best) > 1
 }
 
 // querySymbolsByLines 按位置查询 occurrence
@@ -1144,7 +1326,7 @@ func (i *indexer) querySymbolsByName(doc *codegraphpb.FileElementTable, opts *ty
 
 func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefinitionOptions) ([]*types.Definition, error) {
 	filePath := options.FilePath
-	project, err := i.workspaceReader.GetProjectByFilePath(ctx, options.Workspace, filePath, true)
+	project, err := i.getProject(ctx, options.Workspace, filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -1155,12 +1337,8 @@ func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefi
 		return nil, lang.ErrUnSupportedLanguage
 	}
 
-	exists, err := i.storage.ProjectIndexExists(projectUuid)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check workspace %s index, err:%v", options.Workspace, err)
-	}
-	if !exists {
-		return nil, fmt.Errorf("workspace %s index not exists", options.Workspace)
+	if err = i.checkProjectIndexExists(ctx, options.Workspace, projectUuid); err != nil {
+		return nil, err
 	}
 
 	startTime := time.Now()
@@ -1177,6 +1355,10 @@ func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefi
 	snippet := options.CodeSnippet
 
 	var currentImports []*codegraphpb.Import
+	var currentTable *codegraphpb.FileElementTable
+	// 候选定义对应的调用/引用，用于打分
+	sources := make(map[*types.Definition]*definitionSource)
+	snippetSources := make(map[string]*definitionSource)
 	// 根据代码片段中的标识符名模糊搜索
 	if len(snippet) > 0 {
 		// 调用tree_sitter 解析，获取所有的标识符及位置
@@ -1196,6 +1378,11 @@ func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefi
 				dependencyNames = append(dependencyNames, c.Name)
 			} else if r, ok := e.(*resolver.Reference); ok {
 				dependencyNames = append(dependencyNames, r.Name)
+			} else {
+				continue
+			}
+			if _, ok := snippetSources[e.GetName()]; !ok {
+				snippetSources[e.GetName()] = sourceFromResolved(e)
 			}
 		}
 		if len(dependencyNames) == 0 {
@@ -1228,32 +1415,31 @@ func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefi
 				if d == nil {
 					continue
 				}
-				res = append(res, &types.Definition{
+				def := &types.Definition{
 					Name:  name,
 					Type:  string(proto.ElementTypeFromProto(d.ElementType)),
 					Path:  d.Path,
 					Range: d.Range,
-				})
+				}
+				sources[def] = snippetSources[name]
+				res = append(res, def)
 			}
 
 		}
 
 	} else {
 		// 1. 获取文档
-		var fileTable codegraphpb.FileElementTable
-		fileTableBytes, err := i.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath})
+		fileTable, err := i.loadFileElementTable(ctx, projectUuid, language, filePath)
 		if errors.Is(err, store.ErrKeyNotFound) {
 			return nil, fmt.Errorf("index not found for file %s", filePath)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to get file %s index, err: %v", filePath, err)
 		}
-		if err = store.UnmarshalValue(fileTableBytes, &fileTable); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal file %s index value, err: %v", filePath, err)
-		}
 
-		foundSymbols = i.findSymbolInDocByLineRange(ctx, &fileTable, queryStartLine, queryEndLine)
+		foundSymbols = i.findSymbolInDocByLineRange(ctx, fileTable, queryStartLine, queryEndLine)
 		currentImports = fileTable.Imports
+		currentTable = fileTable
 	}
 
 	// 去重, 如果range 出现过，则剔除
@@ -1267,32 +1453,23 @@ func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefi
 				continue
 			}
 			res = append(res, &types.Definition{
-				Path:  filePath,
-				Name:  s.Name,
-				Range: s.Range,
-				Type:  string(proto.ElementTypeFromProto(s.ElementType)),
+				Path:       filePath,
+				Name:       s.Name,
+				Range:      s.Range,
+				Type:       string(proto.ElementTypeFromProto(s.ElementType)),
+				Confidence: 1,
+				Reason:     reasonDefinitionInRange,
 			})
 			continue
 		} else { // 引用
 			// 加载定义
+			var occurrences []*codegraphpb.Occurrence
 			bytes, err := i.storage.Get(ctx, projectUuid, store.SymbolNameKey{Name: s.GetName(),
 				Language: language})
 			if err == nil {
 				var exist codegraphpb.SymbolOccurrence
 				if err = store.UnmarshalValue(bytes, &exist); err == nil {
-					filtered := i.analyzer.FilterByImports(filePath, currentImports, exist.Occurrences)
-					if len(filtered) == 0 {
-						// 防止全部过滤掉
-						filtered = exist.Occurrences
-					}
-					for _, o := range filtered {
-						res = append(res, &types.Definition{
-							Path:  o.Path,
-							Name:  s.Name,
-							Range: o.Range,
-							Type:  string(proto.ToDefinitionElementType(proto.ElementTypeFromProto(s.ElementType))),
-						})
-					}
+					occurrences = exist.Occurrences
 				} else {
 					i.logger.Debug("unmarshal symbol occurrence err:%v", err)
 				}
@@ -1300,10 +1477,533 @@ func (i *indexer) QueryDefinitions(ctx context.Context, options *types.QueryDefi
 			} else if !errors.Is(err, store.ErrKeyNotFound) {
 				i.logger.Debug("get symbol occurrence err:%v", err)
 			}
+			// 未保存文档中的定义位置以覆盖层为准
+			occurrences = i.buffers.definitionOccurrences(projectUuid, s.GetName(), occurrences)
+			if len(occurrences) == 0 {
+				continue
+			}
+			filtered := i.analyzer.FilterByImports(filePath, currentImports, occurrences)
+			if len(filtered) == 0 {
+				// 防止全部过滤掉，无法区分的候选由置信度排序
+				filtered = occurrences
+			}
+			source := sourceFromElement(currentTable, s)
+			for _, o := range filtered {
+				def := &types.Definition{
+					Path:  o.Path,
+					Name:  s.Name,
+					Range: o.Range,
+					Type:  string(proto.ToDefinitionElementType(proto.ElementTypeFromProto(s.ElementType))),
+				}
+				sources[def] = source
+				res = append(res, def)
+			}
 		}
 	}
 
-	return res, nil
+	elements := i.fillDefinitionDetails(ctx, projectUuid, res)
+	return rankDefinitions(&definitionRankContext{
+		FilePath: filePath,
+		Language: language,
+		Imports:  currentImports,
+	}, res, sources, elements, options.MinConfidence), nil
+}
+
+// fillDefinitionDetails 从定义所在文件的索引中读取注释、装饰器、签名及所属容器，按名称和起始行匹配元素，返回匹配到的元素
+func (i *indexer) fillDefinitionDetails(ctx context.Context, projectUuid string, defs []*types.Definition) map[*types.Definition]*codegraphpb.Element {
+	tables := make(map[string]*codegraphpb.FileElementTable)
+	elements := make(map[*types.Definition]*codegraphpb.Element)
+	for idx, d := range defs {
+		if idx >= maxDocFillDefinitions {
+			break
+		}
+		if len(d.Range) == 0 {
+			continue
+		}
+		table, ok := tables[d.Path]
+		if !ok {
+			var err error
+			if table, err = i.getFileElementTableByPath(ctx, projectUuid, d.Path); err != nil {
+				i.logger.Debug("fill definition details get file %s element table err:%v", d.Path, err)
+				table = nil
+			}
+			tables[d.Path] = table
+		}
+		if table == nil {
+			continue
+		}
+		for _, e := range table.Elements {
+			if e.Name != d.Name || len(e.Range) == 0 || e.Range[0] != d.Range[0] {
+				continue
+			}
+			if doc, err := proto.GetDocCommentFromExtraData(e.ExtraData); err == nil && doc != nil {
+				d.Doc, d.Decorators, d.Signature = doc.Doc, doc.Decorators, doc.Signature
+			}
+			d.Container = findContainer(table, e)
+			d.Origin = proto.FileOriginFromProto(table.Origin)
+			elements[d] = e
+			break
+		}
+	}
+	return elements
+}
+
+// findContainer 所属容器：优先取解析时记录的 owner，其次取范围内最小的类或接口
+func findContainer(table *codegraphpb.FileElementTable, element *codegraphpb.Element) string {
+	if owner, err := proto.GetOwnerFromExtraData(element.ExtraData); err == nil && owner != types.EmptyString {
+		return owner
+	}
+	var container *codegraphpb.Element
+	for _, e := range table.Elements {
+		if e == element || e.Name == element.Name || len(e.Range) < 4 || len(element.Range) < 4 {
+			continue
+		}
+		if e.ElementType != codegraphpb.ElementType_CLASS && e.ElementType != codegraphpb.ElementType_INTERFACE {
+			continue
+		}
+		if !rangeContains(e.Range, element.Range) {
+			continue
+		}
+		if container == nil || rangeContains(container.Range, e.Range) {
+			container = e
+		}
+	}
+	if container != nil {
+		return container.Name
+	}
+	return types.EmptyString
+}
+
+// rangeContains outer 是否包含 inner，range 为 startLine, startColumn, endLine, endColumn
+func rangeContains(outer, inner []int32) bool {
+	if outer[0] > inner[0] || (outer[0] == inner[0] && outer[1] > inner[1]) {
+		return false
+	}
+	return outer[2] > inner[2] || (outer[2] == inner[2] && outer[3] >= inner[3])
+}
+
+// QueryHover 查找光标处的标识符，按 QueryDefinitions 的方式解析其定义，光标位于定义本身时优先返回该定义
+func (i *indexer) QueryHover(ctx context.Context, options *types.QueryHoverOptions) (*types.SymbolHover, error) {
+	if options.Line <= 0 || options.Column <= 0 {
+		return nil, fmt.Errorf("invalid position %d:%d", options.Line, options.Column)
+	}
+	project, err := i.getProject(ctx, options.Workspace, options.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := i.readFile(ctx, project.Uuid, options.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	name, nameRange, err := identifierAt(options.FilePath, content, uint(options.Line-1), uint(options.Column-1))
+	if err != nil {
+		return nil, err
+	}
+
+	defs, err := i.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+		Workspace: options.Workspace,
+		FilePath:  options.FilePath,
+		StartLine: options.Line,
+		EndLine:   options.Line,
+	})
+	if err != nil {
+		return nil, err
+	}
+	hover := &types.SymbolHover{Name: name, Range: nameRange}
+	for _, d := range defs {
+		if d.Name != name {
+			continue
+		}
+		// 光标就在定义上
+		if d.Path == options.FilePath && len(d.Range) >= 4 && rangeContains(d.Range, nameRange) {
+			hover.Definitions = append([]*types.Definition{d}, hover.Definitions...)
+			continue
+		}
+		hover.Definitions = append(hover.Definitions, d)
+	}
+	return hover, nil
+}
+
+// parseTrees 使用文件对应语言的 tree-sitter 语法解析内容，单文件组件的每个 <script> 块按其 lang 分别解析，
+// 块外的内容为空白，节点位置即为在原文件中的位置。调用方负责关闭
+func parseTrees(path string, content []byte) ([]*sitter.Tree, error) {
+	sources, err := lang.ScriptSources(path, content)
+	if err != nil {
+		return nil, err
+	}
+	sitterParser := sitter.NewParser()
+	defer sitterParser.Close()
+	trees := make([]*sitter.Tree, 0, len(sources))
+	for _, source := range sources {
+		if err = sitterParser.SetLanguage(source.Parser.SitterLanguage()); err != nil {
+			closeTrees(trees)
+			return nil, err
+		}
+		tree := sitterParser.Parse(source.Content, nil)
+		if tree == nil {
+			closeTrees(trees)
+			return nil, fmt.Errorf("failed to parse file %s", path)
+		}
+		trees = append(trees, tree)
+	}
+	return trees, nil
+}
+
+func closeTrees(trees []*sitter.Tree) {
+	for _, tree := range trees {
+		tree.Close()
+	}
+}
+
+// identifierAt 查找位置处的标识符节点，column 按字符计；光标位于标识符末尾时取前一个字符
+func identifierAt(path string, content []byte, row, column uint) (string, []int32, error) {
+	trees, err := parseTrees(path, content)
+	if err != nil {
+		return types.EmptyString, nil, err
+	}
+	defer closeTrees(trees)
+
+	for _, col := range []uint{column, column - 1} {
+		if col > column {
+			break // column 为 0
+		}
+		point := sitter.Point{Row: row, Column: byteColumn(content, row, col)}
+		for _, tree := range trees {
+			node := tree.RootNode().NamedDescendantForPointRange(point, point)
+			if node != nil && isIdentifierKind(node.Kind()) {
+				return node.Utf8Text(content), nodeRange(node), nil
+			}
+		}
+	}
+	return types.EmptyString, nil, fmt.Errorf("no identifier found at %d:%d", row+1, column+1)
+}
+
+// byteColumn 将行内的字符列转换为 tree-sitter 使用的字节列，超出行尾时取行尾
+func byteColumn(content []byte, row, column uint) uint {
+	start := 0
+	for ; row > 0; row-- {
+		idx := bytes.IndexByte(content[start:], '\n')
+		if idx < 0 {
+			return column
+		}
+		start += idx + 1
+	}
+	line := content[start:]
+	if idx := bytes.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
+	}
+	offset := 0
+	for ; column > 0 && offset < len(line); column-- {
+		_, size := utf8.DecodeRune(line[offset:])
+		offset += size
+	}
+	return uint(offset)
+}
+
+func nodeRange(node *sitter.Node) []int32 {
+	start, end := node.StartPosition(), node.EndPosition()
+	return []int32{int32(start.Row), int32(start.Column), int32(end.Row), int32(end.Column)}
+}
+
+func isIdentifierKind(kind string) bool {
+	return strings.HasSuffix(kind, "identifier") || kind == "name" || kind == "constant"
+}
+
+var identifierPattern = regexp.MustCompile(`^[\p{L}_$][\p{L}\p{N}_$]*$`)
+
+// renameSite 需要重命名的元素，Range 为元素范围，标识符位置在文件解析后确定
+type renameSite struct {
+	Range      []int32
+	Definition bool
+}
+
+// QueryImportedFiles 遍历项目中同语言文件的索引，按 import 路径匹配文件路径，匹配的导入数越多排序越靠前
+func (i *indexer) QueryImportedFiles(ctx context.Context, workspacePath string, filePath string) ([]string, error) {
+	project, err := i.getProject(ctx, workspacePath, filePath)
+	if err != nil {
+		return nil, err
+	}
+	language, err := lang.InferLanguage(filePath)
+	if err != nil {
+		return nil, err
+	}
+	table, err := i.loadFileElementTable(ctx, project.Uuid, language, filePath)
+	if err != nil {
+		return nil, err
+	}
+	// IsImportPathInFilePath 对空的 Name 或 Source 会匹配任意路径，只保留非空字段
+	imports := make([]*codegraphpb.Import, 0, len(table.Imports))
+	for _, imp := range table.Imports {
+		name, source := imp.Name, imp.Source
+		if name == types.EmptyString {
+			name = source
+		}
+		if source == types.EmptyString {
+			source = name
+		}
+		if name != types.EmptyString {
+			imports = append(imports, &codegraphpb.Import{Name: name, Source: source})
+		}
+	}
+	if len(imports) == 0 {
+		return nil, nil
+	}
+
+	iter := i.storage.Iter(ctx, project.Uuid)
+	if iter == nil {
+		return nil, fmt.Errorf("failed to iterate project %s index", project.Uuid)
+	}
+	defer iter.Close()
+	counts := make(map[string]int)
+	var paths []string
+	for iter.Next() {
+		if !store.IsElementPathKey(iter.Key()) {
+			continue
+		}
+		key, err := store.ToElementPathKey(iter.Key())
+		if err != nil || key.Path == filePath || key.Language != language {
+			continue
+		}
+		for _, imp := range imports {
+			if analyzer.IsImportPathInFilePath(imp, key.Path) {
+				counts[key.Path]++
+			}
+		}
+		if counts[key.Path] > 0 {
+			paths = append(paths, key.Path)
+		}
+	}
+	sort.SliceStable(paths, func(a, b int) bool {
+		return counts[paths[a]] > counts[paths[b]]
+	})
+	return paths, nil
+}
+
+// QueryRename 计算重命名的工作区编辑：按 QueryHover 解析光标处符号的定义，遍历项目索引，
+// 在经 import 过滤后能看到该定义的文件中收集解析到该定义的同名引用，无法确定的引用不重命名而是作为冲突报告，
+// 并报告重名、遮蔽等冲突
+func (i *indexer) QueryRename(ctx context.Context, opts *types.QueryRenameOptions) (*types.RenameResult, error) {
+	if !identifierPattern.MatchString(opts.NewName) {
+		return nil, fmt.Errorf("invalid new name %q", opts.NewName)
+	}
+	hover, err := i.QueryHover(ctx, &types.QueryHoverOptions{
+		Workspace: opts.Workspace,
+		FilePath:  opts.FilePath,
+		Line:      opts.Line,
+		Column:    opts.Column,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hover.Name == opts.NewName {
+		return nil, fmt.Errorf("new name is the same as %s", hover.Name)
+	}
+	if len(hover.Definitions) == 0 {
+		return nil, fmt.Errorf("definition of %s not found", hover.Name)
+	}
+	project, err := i.getProject(ctx, opts.Workspace, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	startTime := time.Now()
+	defer func() {
+		i.logger.Info("query rename %s cost %d ms", hover.Name, time.Since(startTime).Milliseconds())
+	}()
+
+	target := hover.Definitions[0]
+	result := &types.RenameResult{
+		Name:       hover.Name,
+		NewName:    opts.NewName,
+		Definition: target,
+		Edits:      make(map[string][]*types.TextEdit),
+	}
+	for _, d := range hover.Definitions[1:] {
+		result.Conflicts = append(result.Conflicts, &types.RenameConflict{
+			Kind:    types.RenameConflictAmbiguous,
+			Path:    d.Path,
+			Name:    d.Name,
+			Range:   d.Range,
+			Message: fmt.Sprintf("%s also resolves to this definition, which is not renamed", hover.Name),
+		})
+	}
+
+	// 定义处及光标处的标识符总是需要重命名
+	sites := make(map[string][]*renameSite)
+	sites[target.Path] = append(sites[target.Path], &renameSite{Range: target.Range, Definition: true})
+	sites[opts.FilePath] = append(sites[opts.FilePath], &renameSite{Range: hover.Range})
+	shadowed := make(map[string][]*types.RenameConflict)
+	defOccurrence := []*codegraphpb.Occurrence{{Path: target.Path, Range: target.Range}}
+	resolver := i.newRenameResolver(ctx, project.Uuid, target)
+
+	iter := i.storage.Iter(ctx, project.Uuid)
+	if iter == nil {
+		return nil, fmt.Errorf("failed to iterate project %s index", project.Uuid)
+	}
+	defer iter.Close()
+	for iter.Next() {
+		if !store.IsElementPathKey(iter.Key()) {
+			continue
+		}
+		var table codegraphpb.FileElementTable
+		if err = store.UnmarshalValue(iter.Value(), &table); err != nil {
+			i.logger.Debug("query rename unmarshal %s err:%v", iter.Key(), err)
+			continue
+		}
+		// 只处理经 import 过滤后能看到该定义的文件
+		if len(i.analyzer.FilterByImports(table.Path, table.Imports, defOccurrence)) == 0 {
+			continue
+		}
+		for _, e := range table.Elements {
+			if !isValidRange(e.Range) {
+				continue
+			}
+			if e.Name == opts.NewName {
+				if e.IsDefinition && utils.IsSameParentDir(table.Path, target.Path) {
+					result.Conflicts = append(result.Conflicts, &types.RenameConflict{
+						Kind:    types.RenameConflictExists,
+						Path:    table.Path,
+						Name:    e.Name,
+						Range:   e.Range,
+						Message: fmt.Sprintf("%s is already defined in the same package", e.Name),
+					})
+				} else {
+					shadowed[table.Path] = append(shadowed[table.Path], &types.RenameConflict{
+						Kind:    types.RenameConflictShadowing,
+						Path:    table.Path,
+						Name:    e.Name,
+						Range:   e.Range,
+						Message: fmt.Sprintf("%s is already used in this file", e.Name),
+					})
+				}
+				continue
+			}
+			if e.Name != hover.Name || e.IsDefinition ||
+				(e.ElementType != codegraphpb.ElementType_REFERENCE && e.ElementType != codegraphpb.ElementType_CALL) {
+				continue
+			}
+			switch resolver.resolve(&table, e) {
+			case renameResolvedTarget:
+				sites[table.Path] = append(sites[table.Path], &renameSite{Range: e.Range})
+			case renameResolvedAmbiguous:
+				result.Conflicts = append(result.Conflicts, &types.RenameConflict{
+					Kind:    types.RenameConflictUnresolved,
+					Path:    table.Path,
+					Name:    e.Name,
+					Range:   e.Range,
+					Message: fmt.Sprintf("cannot tell whether %s refers to the renamed definition, not renamed", e.Name),
+				})
+			}
+		}
+		for _, imp := range table.Imports {
+			// 按名称导入该定义（Java、TS 等），导入语句中的名称同样需要重命名
+			if imp.Name == hover.Name && isValidRange(imp.Range) && analyzer.IsImportPathInFilePath(imp, target.Path) {
+				sites[table.Path] = append(sites[table.Path], &renameSite{Range: imp.Range})
+			}
+			if imp.Alias == opts.NewName || imp.Name == opts.NewName {
+				shadowed[table.Path] = append(shadowed[table.Path], &types.RenameConflict{
+					Kind:    types.RenameConflictShadowing,
+					Path:    table.Path,
+					Name:    opts.NewName,
+					Range:   imp.Range,
+					Message: fmt.Sprintf("%s is already imported in this file", opts.NewName),
+				})
+			}
+		}
+	}
+
+	for path, fileSites := range sites {
+		edits, err := i.renameEdits(ctx, project.Uuid, path, hover.Name, opts.NewName, fileSites)
+		if err != nil {
+			i.logger.Debug("query rename file %s err:%v", path, err)
+			continue
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		result.Edits[path] = edits
+		// 只有存在编辑的文件才会发生遮蔽
+		result.Conflicts = append(result.Conflicts, shadowed[path]...)
+	}
+	return result, nil
+}
+
+// renameEdits 解析文件，在每个元素范围内定位名为 name 的标识符：定义优先取作为 name 字段的标识符，
+// 引用取范围内第一个尚未使用的标识符
+func (i *indexer) renameEdits(ctx context.Context, projectUuid, path, name, newName string,
+	sites []*renameSite) ([]*types.TextEdit, error) {
+	content, err := i.readFile(ctx, projectUuid, path)
+	if err != nil {
+		return nil, err
+	}
+	trees, err := parseTrees(path, content)
+	if err != nil {
+		return nil, err
+	}
+	defer closeTrees(trees)
+
+	var identifiers []*sitter.Node
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if isIdentifierKind(node.Kind()) && node.Utf8Text(content) == name {
+			identifiers = append(identifiers, node)
+			return
+		}
+		for j := uint(0); j < node.NamedChildCount(); j++ {
+			if child := node.NamedChild(j); child != nil {
+				walk(child)
+			}
+		}
+	}
+	for _, tree := range trees {
+		walk(tree.RootNode())
+	}
+
+	sort.SliceStable(sites, func(a, b int) bool {
+		if sites[a].Definition != sites[b].Definition {
+			return sites[a].Definition
+		}
+		return comparePosition(sites[a].Range, sites[b].Range) < 0
+	})
+	used := make(map[uint]bool)
+	var edits []*types.TextEdit
+	for _, site := range sites {
+		var found *sitter.Node
+		for _, node := range identifiers {
+			r := nodeRange(node)
+			if used[node.StartByte()] || !rangeContains(site.Range, r) {
+				continue
+			}
+			if found == nil {
+				found = node
+			}
+			if !site.Definition {
+				break
+			}
+			if parent := node.Parent(); parent != nil {
+				if nameNode := parent.ChildByFieldName("name"); nameNode != nil && nameNode.StartByte() == node.StartByte() {
+					found = node
+					break
+				}
+			}
+		}
+		if found == nil {
+			continue
+		}
+		used[found.StartByte()] = true
+		edits = append(edits, &types.TextEdit{Range: nodeRange(found), NewText: newName})
+	}
+	sort.Slice(edits, func(a, b int) bool {
+		return comparePosition(edits[a].Range, edits[b].Range) < 0
+	})
+	return edits, nil
+}
+
+// comparePosition 按起始行列比较
+func comparePosition(a, b []int32) int {
+	if a[0] != b[0] {
+		return int(a[0] - b[0])
+	}
+	return int(a[1] - b[1])
 }
 
 func (i *indexer) searchSymbolNames(ctx context.Context, projectUuid string, language lang.Language, names []string, imports []*codegraphpb.Import) (
@@ -1446,6 +2146,12 @@ func (i *indexer) RemoveAllIndexes(ctx context.Context, workspacePath string) er
 	var errs []error
 	for _, p := range projects {
 		errs = append(errs, i.storage.DeleteAll(ctx, p.Uuid))
+		if i.textIndex != nil {
+			errs = append(errs, i.textIndex.DeleteAll(ctx, p.Uuid))
+		}
+		if i.vectorStore != nil {
+			errs = append(errs, i.vectorStore.DeleteAll(ctx, p.Uuid))
+		}
 	}
 	// 将数据库数据置为0
 	if err := i.workspaceRepository.UpdateCodegraphInfo(workspacePath, 0, time.Now().Unix()); err != nil {
@@ -1481,6 +2187,16 @@ func (i *indexer) RenameIndexes(ctx context.Context, workspacePath string, sourc
 	}
 
 	sourceProjectUuid, targetProjectUuid := sourceProject.Uuid, targetProject.Uuid
+	if i.textIndex != nil {
+		if err := i.textIndex.RenameFiles(ctx, sourceProjectUuid, targetProjectUuid, sourceFilePath, targetFilePath); err != nil {
+			i.logger.Error("rename text index %s to %s err:%v", sourceFilePath, targetFilePath, err)
+		}
+	}
+	if i.vectorStore != nil {
+		if err := i.vectorStore.RenameFiles(ctx, sourceProjectUuid, targetProjectUuid, sourceFilePath, targetFilePath); err != nil {
+			i.logger.Error("rename vector index %s to %s err:%v", sourceFilePath, targetFilePath, err)
+		}
+	}
 	// 可能是文件，也可能是目录
 	sourceTables, err := i.searchFileElementTablesByPath(ctx, sourceProjectUuid, []string{sourceFilePath})
 	if err != nil {
@@ -1578,19 +2294,315 @@ func (i *indexer) RenameIndexes(ctx context.Context, workspacePath string, sourc
 	return nil
 }
 
-// getFileElementTableByPath 通过路径获取FileElementTable
-func (i *indexer) getFileElementTableByPath(ctx context.Context, projectUuid string, filePath string) (*codegraphpb.FileElementTable, error) {
-	language, err := lang.InferLanguage(filePath)
+// QueryText 在工作区所有项目的全文索引中检索，合并后按得分排序
+func (i *indexer) QueryText(ctx context.Context, options *types.QueryTextOptions) ([]*textindex.Hit, error) {
+	if i.textIndex == nil {
+		return nil, fmt.Errorf("text index is not enabled")
+	}
+	projects := i.workspaceReader.FindProjects(ctx, options.Workspace, false, workspace.DefaultVisitPattern)
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project found in workspace %s", options.Workspace)
+	}
+	startTime := time.Now()
+	defer func() {
+		i.logger.Info("query text cost %d ms", time.Since(startTime).Milliseconds())
+	}()
+
+	searchOpts := textindex.SearchOptions{
+		Limit:  options.Limit,
+		Filter: textindex.PathFilter(options.Workspace, options.IncludePaths, options.ExcludePaths),
+	}
+	var res []*textindex.Hit
+	for _, p := range projects {
+		hits, err := i.textIndex.Search(ctx, p.Uuid, options.Query, searchOpts)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, hits...)
+	}
+	sort.SliceStable(res, func(a, b int) bool {
+		return res[a].Score > res[b].Score
+	})
+	if options.Limit > 0 && len(res) > options.Limit {
+		res = res[:options.Limit]
+	}
+	return res, nil
+}
+
+// QuerySemantic 在工作区所有项目的向量索引中检索。开启 Hybrid 时，
+// 与符号名匹配到的定义所在分块按倒数排名融合
+func (i *indexer) QuerySemantic(ctx context.Context, options *types.QuerySemanticOptions) ([]*vector.Hit, error) {
+	if i.vectorStore == nil {
+		return nil, fmt.Errorf("local embedding provider is not configured")
+	}
+	projects := i.workspaceReader.FindProjects(ctx, options.Workspace, false, workspace.DefaultVisitPattern)
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project found in workspace %s", options.Workspace)
+	}
+	startTime := time.Now()
+	defer func() {
+		i.logger.Info("query semantic cost %d ms", time.Since(startTime).Milliseconds())
+	}()
+
+	filter := textindex.PathFilter(options.Workspace, options.IncludePaths, options.ExcludePaths)
+	var res []*vector.Hit
+	for _, p := range projects {
+		hits, err := i.vectorStore.Search(ctx, p.Uuid, options.Query, vector.SearchOptions{
+			Limit:  options.Limit,
+			Filter: filter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if options.Hybrid {
+			symbolHits := i.searchSymbolChunks(ctx, p.Uuid, options.Query, options.Limit, filter)
+			hits = vector.FuseRRF(vector.DefaultRRFConstant, hits, symbolHits)
+		}
+		res = append(res, hits...)
+	}
+	sort.SliceStable(res, func(a, b int) bool {
+		return res[a].Score > res[b].Score
+	})
+	if options.Limit > 0 && len(res) > options.Limit {
+		res = res[:options.Limit]
+	}
+	return res, nil
+}
+
+// searchSymbolChunks 按查询词项匹配符号名，返回匹配定义所在的分块，按匹配度排序
+func (i *indexer) searchSymbolChunks(ctx context.Context, projectUuid string, query string, limit int,
+	filter func(path string) bool) []*vector.Hit {
+	queryTerms := make(map[string]struct{})
+	for _, t := range textindex.QueryTerms(query) {
+		queryTerms[t] = struct{}{}
+	}
+	type symbolMatch struct {
+		occurrence *codegraphpb.SymbolOccurrence
+		score      float64
+	}
+	var matches []*symbolMatch
+	iter := i.storage.Iter(ctx, projectUuid)
+	if iter == nil {
+		return nil
+	}
+	for iter.Next() {
+		if !store.IsSymbolNameKey(iter.Key()) {
+			continue
+		}
+		key, err := store.ToSymbolNameKey(iter.Key())
+		if err != nil {
+			continue
+		}
+		score := symbolNameScore(key.Name, queryTerms)
+		if score == 0 {
+			continue
+		}
+		occurrence := new(codegraphpb.SymbolOccurrence)
+		if err = store.UnmarshalValue(iter.Value(), occurrence); err != nil {
+			continue
+		}
+		matches = append(matches, &symbolMatch{occurrence: occurrence, score: score})
+	}
+	if err := iter.Close(); err != nil {
+		i.logger.Debug("close iter for project %s err:%v", projectUuid, err)
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+		return len(matches[a].occurrence.Name) < len(matches[b].occurrence.Name)
+	})
+
+	var hits []*vector.Hit
+	seen := make(map[string]struct{})
+	for _, m := range matches {
+		for _, o := range m.occurrence.Occurrences {
+			if (filter != nil && !filter(o.Path)) || len(o.Range) == 0 {
+				continue
+			}
+			chunks, err := i.vectorStore.ChunksByPath(ctx, projectUuid, o.Path)
+			if err != nil {
+				continue
+			}
+			for _, c := range chunks {
+				key := symbolMapKey(c.FilePath, c.Range)
+				if _, ok := seen[key]; ok || o.Range[0] < c.Range[0] || o.Range[0] > c.Range[2] {
+					continue
+				}
+				seen[key] = struct{}{}
+				hits = append(hits, &vector.Hit{Chunk: c, Score: m.score})
+				break
+			}
+		}
+		if limit > 0 && len(hits) >= limit {
+			break
+		}
+	}
+	return hits
+}
+
+// symbolNameScore 符号名与查询词项的匹配度：整词命中为 1，否则为命中子词的占比
+func symbolNameScore(name string, queryTerms map[string]struct{}) float64 {
+	terms := textindex.SplitTerms(name)
+	if len(terms) == 0 {
+		return 0
+	}
+	if _, ok := queryTerms[terms[0].Text]; ok && len(terms[0].Text) == len(name) {
+		return 1
+	}
+	parts, matched := 0, 0
+	for _, t := range terms {
+		if t.End-t.Start == len(name) {
+			continue
+		}
+		parts++
+		if _, ok := queryTerms[t.Text]; ok {
+			matched++
+		}
+	}
+	if parts == 0 {
+		return 0
+	}
+	return float64(matched) / float64(parts)
+}
+
+// QueryStructural 在工作区内指定语言的文件上执行 tree-sitter 查询，按 MaxConcurrency 并发解析。
+// 同一文件的捕获连续、串行地回调 emit，达到 Limit 或 emit 返回错误时终止
+func (i *indexer) QueryStructural(ctx context.Context, options *types.QueryStructuralOptions,
+	emit func(*structural.Capture) error) (*types.StructuralSearchStats, error) {
+	language, err := lang.ToLanguage(options.Language)
 	if err != nil {
 		return nil, err
 	}
-	bytes, err := i.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath})
+	query, err := structural.Compile(language, options.Query)
 	if err != nil {
 		return nil, err
 	}
-	var referTable codegraphpb.FileElementTable
-	err = store.UnmarshalValue(bytes, &referTable)
-	return &referTable, err
+	defer query.Close()
+
+	projects := i.workspaceReader.FindProjects(ctx, options.Workspace, false, workspace.DefaultVisitPattern)
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project found in workspace %s", options.Workspace)
+	}
+	startTime := time.Now()
+	stats := &types.StructuralSearchStats{}
+	defer func() {
+		i.logger.Info("query structural %s cost %d ms, files %d, matched %d, captures %d",
+			language, time.Since(startTime).Milliseconds(), stats.Files, stats.Matched, stats.Captures)
+	}()
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		emitErr error
+	)
+	files := make(chan string, i.config.MaxConcurrency)
+	for w := 0; w < i.config.MaxConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range files {
+				if searchCtx.Err() != nil {
+					continue
+				}
+				content, err := i.workspaceReader.ReadFile(searchCtx, path, types.ReadOptions{})
+				if err != nil {
+					i.logger.Debug("query structural read file %s err:%v", path, err)
+					continue
+				}
+				var captures []*structural.Capture
+				if err = query.Match(searchCtx, path, content, func(c *structural.Capture) error {
+					captures = append(captures, c)
+					return nil
+				}); err != nil {
+					i.logger.Debug("query structural match file %s err:%v", path, err)
+					continue
+				}
+
+				mu.Lock()
+				stats.Files++
+				if len(captures) > 0 {
+					stats.Matched++
+				}
+				for _, c := range captures {
+					if emitErr != nil || stats.Truncated {
+						break
+					}
+					if options.Limit > 0 && stats.Captures >= options.Limit {
+						stats.Truncated = true
+						cancel()
+						break
+					}
+					if err = emit(c); err != nil {
+						emitErr = err
+						cancel()
+						break
+					}
+					stats.Captures++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	filter := textindex.PathFilter(options.Workspace, options.IncludePaths, options.ExcludePaths)
+	var walkErr error
+walk:
+	for _, p := range projects {
+		collected, err := i.collectQueryFiles(searchCtx, options.Workspace, p)
+		if err != nil {
+			walkErr = err
+			break
+		}
+		paths := make([]string, 0, len(collected))
+		for path := range collected {
+			if filter != nil && !filter(path) {
+				continue
+			}
+			// 按语法筛选文件，如 tsx 与 typescript 的查询分别只匹配各自的文件；
+			// 单文件组件的 <script> 块可能使用其中任一语法，匹配时按块的 lang 筛选
+			if lang.IsSFCFile(path) {
+				if !lang.IsSFCScriptLanguage(language) {
+					continue
+				}
+			} else if langParser, err := lang.GetSitterParserByFilePath(path); err != nil || langParser.Language != language {
+				continue
+			}
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			select {
+			case files <- path:
+			case <-searchCtx.Done():
+				break walk
+			}
+		}
+	}
+	close(files)
+	wg.Wait()
+
+	if emitErr != nil {
+		return stats, emitErr
+	}
+	if err = ctx.Err(); err != nil {
+		return stats, err
+	}
+	if walkErr != nil && !stats.Truncated {
+		return stats, walkErr
+	}
+	return stats, nil
+}
+
+// getFileElementTableByPath 通过路径获取FileElementTable
+func (i *indexer) getFileElementTableByPath(ctx context.Context, projectUuid string, filePath string) (*codegraphpb.FileElementTable, error) {
+	language, err := lang.InferLanguage(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return i.loadFileElementTable(ctx, projectUuid, language, filePath)
 }
 
 // getFileElementTableByPath 通过路径获取FileElementTable
@@ -1607,7 +2619,8 @@ func (i *indexer) getSymbolOccurrenceByName(ctx context.Context, projectUuid str
 }
 
 // parseFilesOptimized 优化版本的文件解析函数，减少内存分配
-func (i *indexer) parseFiles(ctx context.Context, files []*types.FileWithModTimestamp) ([]*parser.FileElementTable, *types.IndexTaskMetrics, error) {
+func (i *indexer) parseFiles(ctx context.Context, projectUuid string, files []*types.FileWithModTimestamp,
+	incremental bool) ([]*parser.FileElementTable, *types.IndexTaskMetrics, error) {
 	totalFiles := len(files)
 
 	// 优化：预分配切片容量，减少动态扩容
@@ -1618,6 +2631,9 @@ func (i *indexer) parseFiles(ctx context.Context, files []*types.FileWithModTime
 	}
 
 	var errs []error
+	var textDocs []*textindex.Document
+	var chunks []*chunker.Chunk
+	var chunkedPaths []string
 
 	for _, f := range files {
 		language, err := lang.InferLanguage(f.Path)
@@ -1633,13 +2649,25 @@ func (i *indexer) parseFiles(ctx context.Context, files []*types.FileWithModTime
 			i.logger.Debug("read file %s err:%v", f, err)
 			continue
 		}
+		// 压缩、打包后的文件不索引
+		origin := parser.ClassifyFile(f.Path, content)
+		if origin.Excluded() {
+			i.logger.Debug("skip %s file %s", origin, f.Path)
+			continue
+		}
 		// 创建源文件对象并解析
 		sourceFile := &types.SourceFile{
-			Path:    f.Path,
-			Content: content,
+			Path:         f.Path,
+			Content:      content,
+			CollectTerms: i.textIndex != nil,
 		}
 
-		fileElementTable, err := i.parser.Parse(ctx, sourceFile)
+		var fileElementTable *parser.FileElementTable
+		if incremental {
+			fileElementTable, err = i.parser.ParseIncremental(ctx, sourceFile)
+		} else {
+			fileElementTable, err = i.parser.Parse(ctx, sourceFile)
+		}
 		if err != nil {
 			projectTaskMetrics.TotalFailedFiles++
 			projectTaskMetrics.FailedFilePaths = append(projectTaskMetrics.FailedFilePaths, f.Path)
@@ -1650,7 +2678,36 @@ func (i *indexer) parseFiles(ctx context.Context, files []*types.FileWithModTime
 			continue
 		}
 		fileElementTable.Timestamp = f.ModTime
+		fileElementTable.ApplyOrigin(origin)
 		fileElementTables = append(fileElementTables, fileElementTable)
+		// 编辑器保存后磁盘内容与未保存文档一致，丢弃覆盖层
+		i.buffers.removeSaved(projectUuid, f.Path, content)
+		if i.textIndex != nil {
+			textDocs = append(textDocs, &textindex.Document{Path: f.Path, Content: content,
+				Terms: fileElementTable.Terms, Length: fileElementTable.TermCount})
+			fileElementTable.Terms = nil
+		}
+		if i.vectorStore != nil {
+			fileChunks, err := i.chunker.Chunk(ctx, sourceFile)
+			if err != nil {
+				i.logger.Debug("chunk file %s err:%v", f.Path, err)
+			}
+			chunks = append(chunks, fileChunks...)
+			chunkedPaths = append(chunkedPaths, f.Path)
+		}
+	}
+
+	// 全文索引，失败不影响代码图索引
+	if len(textDocs) > 0 {
+		if err := i.textIndex.IndexFiles(ctx, projectUuid, textDocs); err != nil {
+			i.logger.Error("index text of %d files err:%v", len(textDocs), err)
+		}
+	}
+	// 向量化在后台队列中进行，不阻塞索引；没有分块的文件同样清除旧向量
+	if len(chunkedPaths) > 0 {
+		if err := i.vectorStore.Enqueue(ctx, projectUuid, chunkedPaths, chunks); err != nil {
+			i.logger.Error("enqueue vectors of %d files err:%v", len(chunkedPaths), err)
+		}
 	}
 
 	return fileElementTables, projectTaskMetrics, errors.Join(errs...)
@@ -1678,7 +2735,7 @@ func (i *indexer) processBatch(ctx context.Context, batchId int, params *BatchPr
 		batchId, params.BatchStart, params.BatchEnd, params.TotalFiles, params.BatchSize)
 
 	// 解析文件
-	elementTables, metrics, err := i.parseFiles(ctx, params.SourceFiles)
+	elementTables, metrics, err := i.parseFiles(ctx, params.ProjectUuid, params.SourceFiles, params.Incremental)
 	if err != nil {
 		return nil, fmt.Errorf("parse files failed: %w", err)
 	}
@@ -1690,6 +2747,10 @@ func (i *indexer) processBatch(ctx context.Context, batchId int, params *BatchPr
 		params.BatchStart, params.BatchEnd, params.TotalFiles, time.Since(batchStartTime).Milliseconds())
 
 	// 项目符号表存储
+	if params.SaveMu != nil {
+		params.SaveMu.Lock()
+		defer params.SaveMu.Unlock()
+	}
 	symbolStart := time.Now()
 
 	symbolMetrics, err := i.analyzer.SaveSymbolOccurrences(ctx, params.ProjectUuid, params.TotalFiles, elementTables, symbolCache)
@@ -1727,9 +2788,32 @@ func (i *indexer) processBatch(ctx context.Context, batchId int, params *BatchPr
 	return metrics, nil
 }
 
-// collectFiles 收集文件用于index
-func (i *indexer) collectFiles(ctx context.Context, workspacePath string, projectPath string) (map[string]int64, error) {
+// collectFiles 收集文件用于index，超过文件数上限时按优先级选择并记录选择结果
+func (i *indexer) collectFiles(ctx context.Context, workspacePath string, project *workspace.Project) (map[string]int64, error) {
 	startTime := time.Now()
+	filePathModTimestamps, maxFiles, err := i.walkFiles(ctx, workspacePath, project)
+	if err != nil {
+		return nil, err
+	}
+	filePathModTimestamps = i.selectFiles(ctx, project.Uuid, project.Path, filePathModTimestamps, maxFiles)
+
+	i.logger.Info("collect project source files finish. cost %d ms, found %d source files to index, max files limit %d",
+		time.Since(startTime).Milliseconds(), len(filePathModTimestamps), maxFiles)
+
+	return filePathModTimestamps, nil
+}
+
+// collectQueryFiles 收集查询的文件，超过文件数上限时沿用索引时的选择结果，不重新选择
+func (i *indexer) collectQueryFiles(ctx context.Context, workspacePath string, project *workspace.Project) (map[string]int64, error) {
+	files, maxFiles, err := i.walkFiles(ctx, workspacePath, project)
+	if err != nil {
+		return nil, err
+	}
+	return i.keptFiles(project.Path, files, maxFiles), nil
+}
+
+// walkFiles 遍历项目中未被忽略的文件，超过文件数上限后继续收集候选文件，返回文件及文件数上限
+func (i *indexer) walkFiles(ctx context.Context, workspacePath string, project *workspace.Project) (map[string]int64, int, error) {
 	filePathModTimestamps := make(map[string]int64, 100)
 	ignoreConfig := i.ignoreScanner.LoadIgnoreConfig(workspacePath)
 	if ignoreConfig == nil {
@@ -1744,6 +2828,12 @@ func (i *indexer) collectFiles(ctx context.Context, workspacePath string, projec
 		visitPattern.SkipFunc = func(fileInfo *types.FileInfo) (bool, error) {
 			return i.ignoreScanner.CheckIgnoreFile(ignoreConfig, workspacePath, fileInfo)
 		}
+		if includesUnder := ignoreConfig.IncludesUnder; includesUnder != nil {
+			visitPattern.KeepDirFunc = func(dirPath string) bool {
+				relPath, err := filepath.Rel(workspacePath, dirPath)
+				return err == nil && includesUnder(relPath)
+			}
+		}
 		maxFiles = ignoreConfig.MaxFileCount
 	}
 
@@ -1751,13 +2841,19 @@ func (i *indexer) collectFiles(ctx context.Context, workspacePath string, projec
 	if i.config.MaxFiles > 0 {
 		maxFiles = i.config.MaxFiles
 	}
+	// 工作区配置文件优先于环境变量
+	if wsConfig, _ := workspace.LoadConfig(workspacePath); wsConfig != nil && wsConfig.Limits.MaxFiles > 0 {
+		maxFiles = wsConfig.Limits.MaxFiles
+	}
 
-	err := i.workspaceReader.WalkFile(ctx, projectPath, func(walkCtx *types.WalkContext) error {
+	// 超过上限后继续收集候选文件，不按遍历顺序截断
+	maxCandidates := maxFileCandidates(maxFiles)
+	err := i.workspaceReader.WalkFile(ctx, project.Path, func(walkCtx *types.WalkContext) error {
 		if walkCtx.Info.IsDir {
 			return nil
 		}
-		if len(filePathModTimestamps) >= maxFiles {
-			i.logger.Info("collect source files max files %d reached, return.", maxFiles)
+		if len(filePathModTimestamps) >= maxCandidates {
+			i.logger.Info("collect source files max candidates %d reached, return.", maxCandidates)
 			return filepath.SkipAll
 		}
 		filePathModTimestamps[walkCtx.Path] = walkCtx.Info.ModTime.Unix()
@@ -1765,20 +2861,25 @@ func (i *indexer) collectFiles(ctx context.Context, workspacePath string, projec
 	}, types.WalkOptions{IgnoreError: true, VisitPattern: visitPattern})
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-
-	i.logger.Info("collect project source files finish. cost %d ms, found %d source files to index, max files limit %d",
-		time.Since(startTime).Milliseconds(), len(filePathModTimestamps), maxFiles)
-
-	return filePathModTimestamps, nil
+	return filePathModTimestamps, maxFiles, nil
 }
 
-// indexFilesInBatches 批量处理文件
+// indexFilesInBatches 批量处理文件，并发批次数及批次之间的暂停由资源调节器根据系统负载和内存决定，
+// 并发上限由所有项目的索引任务共享，工作区配置文件可进一步限制并发批次数及指定批次大小
 func (i *indexer) indexFilesInBatches(ctx context.Context, params *BatchProcessingParams) (*BatchProcessingResult, error) {
+	var maxConcurrency int
+	batchSize := params.BatchSize
+	if wsConfig, _ := workspace.LoadConfig(params.WorkspacePath); wsConfig != nil {
+		maxConcurrency = wsConfig.Limits.MaxConcurrency
+		if wsConfig.Limits.BatchSize > 0 {
+			batchSize = wsConfig.Limits.BatchSize
+		}
+	}
 
-	i.logger.Info("%s, concurrency: %d, batch_size: %d cache_capacity: %d",
-		params.Project.Path, i.config.MaxConcurrency, i.config.MaxBatchSize, i.config.CacheCapacity)
+	i.logger.Info("%s, concurrency: %d, max_concurrency: %d, batch_size: %d cache_capacity: %d",
+		params.Project.Path, params.Concurrency, i.config.Governor.MaxConcurrency, batchSize, i.config.CacheCapacity)
 
 	startTime := time.Now()
 	totalNeedIndexFiles := len(params.NeedIndexSourceFiles)
@@ -1794,11 +2895,48 @@ func (i *indexer) indexFilesInBatches(ctx context.Context, params *BatchProcessi
 	symbolCache := cache.NewLRUCache[*codegraphpb.SymbolOccurrence](1000, i.config.CacheCapacity)
 	defer symbolCache.Purge()
 
+	var (
+		mu     sync.Mutex // 保护进度及指标
+		saveMu sync.Mutex
+		wg     sync.WaitGroup
+	)
 	var processedFilesCnt int
 	var batchId int
 	// 处理批次
 	for m := 0; m < totalNeedIndexFiles; {
-		batch := utils.Min(totalNeedIndexFiles-m, params.BatchSize)
+		// 取消或让出时不再提交新批次
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+			break
+		}
+		concurrency, pause := i.governor.adjust()
+		if maxConcurrency > 0 {
+			concurrency = min(concurrency, maxConcurrency)
+		}
+		// 只在批次之间暂停，首个批次立即开始
+		if batchId == 0 {
+			pause = 0
+		}
+		if pause > 0 {
+			i.logger.Info("%s governor pause %d ms before batch-%d, concurrency %d, reason: %s",
+				params.ProjectUuid, pause.Milliseconds(), batchId+1, concurrency, i.governor.State().Reason)
+		}
+		if err := sleepContext(ctx, pause); err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+			break
+		}
+		if err := i.governor.acquire(ctx, concurrency); err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+			break
+		}
+
+		batch := utils.Min(totalNeedIndexFiles-m, batchSize)
 		batchStart, batchEnd := m, m+batch
 		sourceFilesBatch := params.NeedIndexSourceFiles[batchStart:batchEnd]
 		batchId++
@@ -1811,15 +2949,28 @@ func (i *indexer) indexFilesInBatches(ctx context.Context, params *BatchProcessi
 			BatchSize:   batch,
 			TotalFiles:  totalNeedIndexFiles,
 			Project:     params.Project,
+			Incremental: params.Incremental,
+			SaveMu:      &saveMu,
 		}
 
 		// 提交任务
-		err := func(ctx context.Context, taskID int) error {
+		wg.Add(1)
+		go func(ctx context.Context, taskID int) {
+			defer wg.Done()
+			defer i.governor.release()
 			batchStartTime := time.Now()
 			metrics, err := i.processBatch(ctx, taskID, batchParams, symbolCache)
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
 				i.logger.Debug("batch-%d process batch err:%v", taskID, err)
-				return fmt.Errorf("process batch err:%w", err)
+				errs = append(errs, fmt.Errorf("process batch err:%w", err))
+				// 整个批次失败，计入失败文件
+				projectMetrics.TotalFailedFiles += len(batchParams.SourceFiles)
+				for _, f := range batchParams.SourceFiles {
+					projectMetrics.FailedFilePaths = append(projectMetrics.FailedFilePaths, f.Path)
+				}
+				return
 			}
 
 			processedFilesCnt += metrics.TotalFiles - metrics.TotalFailedFiles
@@ -1837,20 +2988,18 @@ func (i *indexer) indexFilesInBatches(ctx context.Context, params *BatchProcessi
 				PreviousNum:   params.PreviousFileNum,
 				WorkspacePath: params.WorkspacePath,
 			}); err != nil {
-				return fmt.Errorf("update progress failed: %w", err)
+				i.logger.Debug("%s update progress failed: %v", params.ProjectUuid, err)
+				return
 			}
 
 			i.logger.Info("update batch-%d workspace %s successful, file num %d/%d, cache size %d, cost %d ms, batch %d cost %d ms",
 				taskID, params.WorkspacePath, processedFilesCnt+params.PreviousFileNum,
 				totalNeedIndexFiles, symbolCache.Len(), time.Since(batchUpdateStart).Milliseconds(), batch, time.Since(batchStartTime).Milliseconds())
-			return nil
 		}(ctx, batchId)
-		if err != nil {
-			i.logger.Debug("%s submit task err:%v", params.ProjectUuid, err)
-		}
 
 		m += batch
 	}
+	wg.Wait()
 
 	// 最终更新进度
 	// 最终更新
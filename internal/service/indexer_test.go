@@ -1,12 +1,14 @@
 package service
 
 import (
+	"bytes"
 	"codebase-indexer/internal/config"
 	"codebase-indexer/internal/database"
 	"codebase-indexer/internal/model"
 	"codebase-indexer/internal/repository"
 	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
 	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/structural"
 	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/logger"
@@ -16,6 +18,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -84,15 +87,18 @@ func setupTestEnvironment(t *testing.T) *testEnvironment {
 	// repository
 	// Initialize database manager
 	dbConfig := config.DefaultDatabaseConfig()
+	// 数据库放在临时目录，不写入源码目录下的 .costrict
+	dbConfig.DataDir = t.TempDir()
 	dbManager := database.NewSQLiteManager(dbConfig, newLogger)
 	err = dbManager.Initialize()
 	if err != nil {
 		panic(err)
 	}
+	t.Cleanup(func() { _ = dbManager.Close() })
 	// Initialize repositories
 	workspaceRepo := repository.NewWorkspaceRepository(dbManager, newLogger)
 
-	return &testEnvironment{
+	env := &testEnvironment{
 		ctx:                ctx,
 		cancel:             cancel,
 		storageDir:         storageDir,
@@ -105,6 +111,9 @@ func setupTestEnvironment(t *testing.T) *testEnvironment {
 		workspaceDir:       workspaceDir,
 		scanner:            repository.NewFileScanner(newLogger),
 	}
+	// 临时数据库中没有工作区记录，索引进度需要写入工作区
+	assert.NoError(t, initWorkspaceModel(env))
+	return env
 }
 
 // teardownTestEnvironment 清理测试环境，关闭连接和删除临时文件
@@ -128,6 +137,8 @@ func createTestIndexer(env *testEnvironment, visitPattern *types.VisitPattern) I
 		env.dependencyAnalyzer,
 		env.workspaceReader,
 		env.storage,
+		nil,
+		nil,
 		env.repository,
 		IndexerConfig{VisitPattern: visitPattern},
 		env.logger,
@@ -683,3 +694,349 @@ func TestInitConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestSymbolNameScore(t *testing.T) {
+	queryTerms := map[string]struct{}{"load": {}, "config": {}, "main": {}}
+	assert.Equal(t, 1.0, symbolNameScore("main", queryTerms))
+	assert.Equal(t, 1.0, symbolNameScore("LoadConfig", queryTerms))
+	assert.Equal(t, 0.5, symbolNameScore("SaveConfig", queryTerms))
+	assert.Equal(t, 0.0, symbolNameScore("StartServer", queryTerms))
+}
+
+func TestIndexer_QueryStructural(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+
+	var captures []*structural.Capture
+	stats, err := codeIndexer.QueryStructural(env.ctx, &types.QueryStructuralOptions{
+		Workspace:    env.workspaceDir,
+		Language:     string(lang.Go),
+		Query:        `(function_declaration name: (identifier) @name (#eq? @name "Compile"))`,
+		IncludePaths: []string{"pkg/codegraph/structural"},
+	}, func(c *structural.Capture) error {
+		captures = append(captures, c)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, captures, 1)
+	assert.Equal(t, "name", captures[0].Name)
+	assert.Equal(t, "Compile", captures[0].Text)
+	assert.Equal(t, filepath.Join(env.workspaceDir, "pkg/codegraph/structural/query.go"), captures[0].FilePath)
+	assert.Equal(t, 1, stats.Matched)
+
+	// 达到 Limit 后提前结束
+	stats, err = codeIndexer.QueryStructural(env.ctx, &types.QueryStructuralOptions{
+		Workspace:    env.workspaceDir,
+		Language:     string(lang.Go),
+		Query:        `(function_declaration name: (identifier) @name)`,
+		Limit:        2,
+		IncludePaths: []string{"pkg/codegraph/structural"},
+	}, func(c *structural.Capture) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Captures)
+	assert.True(t, stats.Truncated)
+
+	_, err = codeIndexer.QueryStructural(env.ctx, &types.QueryStructuralOptions{
+		Workspace: env.workspaceDir,
+		Language:  string(lang.Go),
+		Query:     `(function_declaration`,
+	}, func(c *structural.Capture) error { return nil })
+	var queryErr *structural.QueryError
+	assert.ErrorAs(t, err, &queryErr)
+}
+
+func TestIndexer_QueryHover(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+	_, err := codeIndexer.IndexWorkspace(env.ctx, env.workspaceDir)
+	assert.NoError(t, err)
+
+	filePath := filepath.Join(env.workspaceDir, "internal/service/indexer.go")
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	lines := strings.Split(string(content), "\n")
+	position := func(substr, symbol string) (int, int) {
+		for idx, line := range lines {
+			if strings.Contains(line, substr) {
+				return idx + 1, strings.Index(line, symbol) + 1
+			}
+		}
+		t.Fatalf("%s not found", substr)
+		return 0, 0
+	}
+
+	// 调用处：解析到同文件的函数定义
+	line, column := position("if !rangeContains(e.Range, element.Range)", "rangeContains")
+	hover, err := codeIndexer.QueryHover(env.ctx, &types.QueryHoverOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: line, Column: column + 3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "rangeContains", hover.Name)
+	if assert.NotEmpty(t, hover.Definitions) {
+		def := hover.Definitions[0]
+		assert.Equal(t, filePath, def.Path)
+		assert.Equal(t, "func rangeContains(outer, inner []int32) bool", def.Signature)
+		assert.Contains(t, def.Doc, "rangeContains outer 是否包含 inner")
+	}
+
+	// 定义处：方法的容器为接收者类型
+	line, column = position("func (i *indexer) QueryHover(", "QueryHover")
+	hover, err = codeIndexer.QueryHover(env.ctx, &types.QueryHoverOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: line, Column: column,
+	})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, hover.Definitions) {
+		assert.Equal(t, int32(line-1), hover.Definitions[0].Range[0])
+		assert.Equal(t, "indexer", hover.Definitions[0].Container)
+	}
+
+	_, err = codeIndexer.QueryHover(env.ctx, &types.QueryHoverOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: 1, Column: 1,
+	})
+	assert.Error(t, err)
+}
+
+func TestIdentifierAt(t *testing.T) {
+	content := []byte("package main\n\nfunc main() {\n\tmsg := \"你好\"; fmt.Println(msg)\n}\n")
+	// 列按字符计，"你好" 占 6 个字节
+	name, nameRange, err := identifierAt("main.go", content, 3, 18)
+	assert.NoError(t, err)
+	assert.Equal(t, "Println", name)
+	// 返回的范围为字节列
+	assert.Equal(t, []int32{3, 22, 3, 29}, nameRange)
+
+	// 光标位于标识符末尾
+	name, _, err = identifierAt("main.go", content, 3, 29)
+	assert.NoError(t, err)
+	assert.Equal(t, "msg", name)
+
+	// 单文件组件按 <script lang="ts"> 块解析
+	vue := []byte("<template>\n  <div>{{ total }}</div>\n</template>\n<script lang=\"ts\">\nconst total: number = count()\n</script>\n")
+	name, nameRange, err = identifierAt("Total.vue", vue, 4, 23)
+	assert.NoError(t, err)
+	assert.Equal(t, "count", name)
+	assert.Equal(t, []int32{4, 22, 4, 27}, nameRange)
+}
+
+func TestIndexer_QueryImportedFiles(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+	_, err := codeIndexer.IndexWorkspace(env.ctx, env.workspaceDir)
+	assert.NoError(t, err)
+
+	filePath := filepath.Join(env.workspaceDir, "internal/service/indexer.go")
+	files, err := codeIndexer.QueryImportedFiles(env.ctx, env.workspaceDir, filePath)
+	assert.NoError(t, err)
+	assert.NotContains(t, files, filePath)
+	// 导入的 analyzer 包中的文件
+	assert.Contains(t, files, filepath.Join(env.workspaceDir, "pkg/codegraph/analyzer/dependency.go"))
+}
+
+func TestIndexer_QueryRename(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+	_, err := codeIndexer.IndexWorkspace(env.ctx, env.workspaceDir)
+	assert.NoError(t, err)
+
+	filePath := filepath.Join(env.workspaceDir, "internal/service/indexer.go")
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	lines := strings.Split(string(content), "\n")
+	defLine := 0
+	for idx, line := range lines {
+		if strings.HasPrefix(line, "func rangeContains(") {
+			defLine = idx + 1
+			break
+		}
+	}
+	assert.NotZero(t, defLine)
+
+	res, err := codeIndexer.QueryRename(env.ctx, &types.QueryRenameOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: defLine, Column: 6, NewName: "containsRange",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "rangeContains", res.Name)
+	edits := res.Edits[filePath]
+	assert.Len(t, edits, strings.Count(string(content), "rangeContains("))
+	for _, e := range edits {
+		line := lines[e.Range[0]]
+		assert.Equal(t, "rangeContains", line[e.Range[1]:e.Range[3]])
+		assert.Equal(t, "containsRange", e.NewText)
+	}
+	var ranges [][]int32
+	for _, e := range edits {
+		ranges = append(ranges, e.Range)
+	}
+	assert.Contains(t, ranges, []int32{int32(defLine - 1), 5, int32(defLine - 1), 18})
+
+	// 新名称与同包内已有定义重名
+	res, err = codeIndexer.QueryRename(env.ctx, &types.QueryRenameOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: defLine, Column: 6, NewName: "isValidRange",
+	})
+	assert.NoError(t, err)
+	var kinds []string
+	for _, c := range res.Conflicts {
+		kinds = append(kinds, c.Kind)
+	}
+	assert.Contains(t, kinds, types.RenameConflictExists)
+
+	_, err = codeIndexer.QueryRename(env.ctx, &types.QueryRenameOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: defLine, Column: 6, NewName: "1invalid",
+	})
+	assert.Error(t, err)
+}
+
+func TestIndexer_QuerySession(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+	_, err := codeIndexer.IndexWorkspace(env.ctx, env.workspaceDir)
+	assert.NoError(t, err)
+
+	filePath := filepath.Join(env.workspaceDir, "internal/service/indexer.go")
+	opts := &types.QueryDefinitionOptions{Workspace: env.workspaceDir, FilePath: filePath, StartLine: 1, EndLine: 300}
+	expected, err := codeIndexer.QueryDefinitions(env.ctx, opts)
+	assert.NoError(t, err)
+
+	ctx := withQuerySession(env.ctx)
+	impl := codeIndexer.(*indexer)
+	project, err := impl.getProject(ctx, env.workspaceDir, filePath)
+	assert.NoError(t, err)
+	table, err := impl.loadFileElementTable(ctx, project.Uuid, lang.Go, filePath)
+	assert.NoError(t, err)
+	cached, err := impl.loadFileElementTable(ctx, project.Uuid, lang.Go, filePath)
+	assert.NoError(t, err)
+	assert.Same(t, table, cached)
+
+	// 会话内并发查询结果与独立查询一致
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defs, err := codeIndexer.QueryDefinitions(ctx, opts)
+			assert.NoError(t, err)
+			assert.Len(t, defs, len(expected))
+		}()
+	}
+	wg.Wait()
+
+	_, err = impl.getProject(ctx, env.workspaceDir, "/not/in/workspace.go")
+	assert.Error(t, err)
+}
+
+func TestIndexer_UpdateBuffer(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+	_, err := codeIndexer.IndexWorkspace(env.ctx, env.workspaceDir)
+	assert.NoError(t, err)
+
+	filePath := filepath.Join(env.workspaceDir, "internal/service/pagination.go")
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	// 未保存的内容在文件开头新增定义及调用
+	dirty := bytes.Replace(content, []byte("package service\n"),
+		[]byte("package service\n\nfunc unsavedHelper() {}\n\nfunc unsavedCaller() { unsavedHelper() }\n"), 1)
+	version, err := codeIndexer.UpdateBuffer(env.ctx, env.workspaceDir, filePath, 2, dirty)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	// 过期的版本被忽略
+	version, err = codeIndexer.UpdateBuffer(env.ctx, env.workspaceDir, filePath, 1, content)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	buffer, ok := codeIndexer.GetBuffer(env.ctx, env.workspaceDir, filePath)
+	assert.True(t, ok)
+	assert.Equal(t, dirty, buffer)
+
+	opts := &types.QueryDefinitionOptions{Workspace: env.workspaceDir, FilePath: filePath, StartLine: 5, EndLine: 5}
+	defs, err := codeIndexer.QueryDefinitions(env.ctx, opts)
+	assert.NoError(t, err)
+	var names []string
+	for _, d := range defs {
+		names = append(names, d.Name)
+	}
+	assert.Contains(t, names, "unsavedHelper")
+
+	nodes, err := codeIndexer.QueryReferences(env.ctx, &types.QueryReferenceOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, SymbolName: "unsavedHelper",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, nodes, 1) {
+		assert.Len(t, nodes[0].Children, 1)
+	}
+
+	// 悬停及重命名基于未保存的内容定位标识符
+	hover, err := codeIndexer.QueryHover(env.ctx, &types.QueryHoverOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: 5, Column: 24,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "unsavedHelper", hover.Name)
+	res, err := codeIndexer.QueryRename(env.ctx, &types.QueryRenameOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, Line: 5, Column: 24, NewName: "savedHelper",
+	})
+	assert.NoError(t, err)
+	dirtyLines := strings.Split(string(dirty), "\n")
+	if assert.Len(t, res.Edits[filePath], 2) {
+		for _, e := range res.Edits[filePath] {
+			assert.Equal(t, "unsavedHelper", dirtyLines[e.Range[0]][e.Range[1]:e.Range[3]])
+		}
+	}
+
+	// 关闭文档后以索引为准
+	assert.NoError(t, codeIndexer.CloseBuffer(env.ctx, env.workspaceDir, filePath))
+	_, ok = codeIndexer.GetBuffer(env.ctx, env.workspaceDir, filePath)
+	assert.False(t, ok)
+	nodes, err = codeIndexer.QueryReferences(env.ctx, &types.QueryReferenceOptions{
+		Workspace: env.workspaceDir, FilePath: filePath, SymbolName: "unsavedHelper",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, nodes)
+}
+
+func TestIndexer_WalkReferences(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer teardownTestEnvironment(t, env, nil)
+	codeIndexer := createTestIndexer(env, testVisitPattern)
+	_, err := codeIndexer.IndexWorkspace(env.ctx, env.workspaceDir)
+	assert.NoError(t, err)
+
+	opts := &types.QueryReferenceOptions{
+		Workspace:  env.workspaceDir,
+		FilePath:   filepath.Join(env.workspaceDir, "internal/service/indexer.go"),
+		SymbolName: "isValidRange",
+	}
+	nodes, err := codeIndexer.QueryReferences(env.ctx, opts)
+	assert.NoError(t, err)
+	if !assert.NotEmpty(t, nodes) {
+		return
+	}
+	total := 0
+	for _, n := range nodes {
+		total += len(n.Children)
+	}
+	assert.Greater(t, total, 1)
+
+	// 回调顺序：先根定义，再引用；引用不会追加到根定义的 Children
+	var definitions, references int
+	err = codeIndexer.WalkReferences(env.ctx, opts, func(definition *types.RelationNode, reference *types.RelationNode) error {
+		if reference == nil {
+			assert.Zero(t, references)
+			definitions++
+			return nil
+		}
+		assert.Empty(t, definition.Children)
+		references++
+		if references == 1 {
+			return errStopWalk
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStopWalk)
+	assert.Equal(t, len(nodes), definitions)
+	assert.Equal(t, 1, references)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 覆盖层最多保存的未保存文档数，超过时丢弃最久未更新的文档
+const maxOverlayBuffers = 200
+
+// bufferOverlay 编辑器中未保存文档的解析结果，按项目及文件路径保存，查询时优先于磁盘上的索引。
+// 保存的 FileElementTable 只读，调用方不能修改
+type bufferOverlay struct {
+	mu      sync.RWMutex
+	buffers map[string]*overlayBuffer
+}
+
+// overlayBuffer 未保存文档的内容及解析结果，Version 为编辑器的文档版本号
+type overlayBuffer struct {
+	ProjectUuid string
+	Path        string
+	Version     int64
+	Content     []byte
+	Hash        [sha256.Size]byte
+	Table       *codegraphpb.FileElementTable
+	UpdatedAt   time.Time
+}
+
+func newBufferOverlay() *bufferOverlay {
+	return &bufferOverlay{buffers: make(map[string]*overlayBuffer)}
+}
+
+func overlayKey(projectUuid, path string) string {
+	return projectUuid + "\x00" + path
+}
+
+// put 保存文档，版本号不大于已有版本时忽略（编辑器的请求可能乱序到达），返回覆盖层中的版本号
+func (o *bufferOverlay) put(buffer *overlayBuffer) int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key := overlayKey(buffer.ProjectUuid, buffer.Path)
+	if exist, ok := o.buffers[key]; ok && exist.Version >= buffer.Version {
+		return exist.Version
+	}
+	buffer.Hash = sha256.Sum256(buffer.Content)
+	buffer.UpdatedAt = time.Now()
+	o.buffers[key] = buffer
+	if len(o.buffers) > maxOverlayBuffers {
+		var oldest string
+		for k, b := range o.buffers {
+			if oldest == "" || b.UpdatedAt.Before(o.buffers[oldest].UpdatedAt) {
+				oldest = k
+			}
+		}
+		delete(o.buffers, oldest)
+	}
+	return buffer.Version
+}
+
+// get 获取文档，不存在时返回 nil
+func (o *bufferOverlay) get(projectUuid, path string) *overlayBuffer {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.buffers[overlayKey(projectUuid, path)]
+}
+
+// remove 丢弃文档（关闭文档时）
+func (o *bufferOverlay) remove(projectUuid, path string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key := overlayKey(projectUuid, path)
+	_, ok := o.buffers[key]
+	delete(o.buffers, key)
+	return ok
+}
+
+// removeSaved 文档已保存到磁盘时丢弃：磁盘内容与文档内容一致，之后以磁盘上的索引为准
+func (o *bufferOverlay) removeSaved(projectUuid, path string, content []byte) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key := overlayKey(projectUuid, path)
+	exist, ok := o.buffers[key]
+	if !ok || exist.Hash != sha256.Sum256(content) {
+		return false
+	}
+	delete(o.buffers, key)
+	return true
+}
+
+// tables 项目内所有未保存文档的解析结果，key 为文件路径
+func (o *bufferOverlay) tables(projectUuid string) map[string]*codegraphpb.FileElementTable {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	var res map[string]*codegraphpb.FileElementTable
+	for _, b := range o.buffers {
+		if b.ProjectUuid != projectUuid {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]*codegraphpb.FileElementTable)
+		}
+		res[b.Path] = b.Table
+	}
+	return res
+}
+
+// updatedAt 所有未保存文档的路径及最近更新时间
+func (o *bufferOverlay) updatedAt() map[string]time.Time {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	res := make(map[string]time.Time, len(o.buffers))
+	for _, b := range o.buffers {
+		res[b.Path] = b.UpdatedAt
+	}
+	return res
+}
+
+// definitionOccurrences 以未保存文档中的定义替换符号索引中位于这些文档的定义位置，并补充文档中新增的定义
+func (o *bufferOverlay) definitionOccurrences(projectUuid string, name string,
+	occurrences []*codegraphpb.Occurrence) []*codegraphpb.Occurrence {
+	tables := o.tables(projectUuid)
+	if len(tables) == 0 {
+		return occurrences
+	}
+	res := make([]*codegraphpb.Occurrence, 0, len(occurrences))
+	for _, oc := range occurrences {
+		if _, ok := tables[oc.Path]; !ok {
+			res = append(res, oc)
+		}
+	}
+	paths := make([]string, 0, len(tables))
+	for path := range tables {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, e := range tables[path].Elements {
+			if !e.IsDefinition || e.Name != name || e.ElementType == codegraphpb.ElementType_IMPORT ||
+				e.ElementType == codegraphpb.ElementType_PACKAGE {
+				continue
+			}
+			res = append(res, &codegraphpb.Occurrence{
+				Path:        path,
+				Range:       e.Range,
+				ElementType: e.ElementType,
+			})
+		}
+	}
+	return res
+}
+
+// UpdateBuffer 解析编辑器中未保存文档的内容，作为覆盖层优先于索引参与查询，返回覆盖层中的版本号
+func (i *indexer) UpdateBuffer(ctx context.Context, workspacePath string, filePath string, version int64,
+	content []byte) (int64, error) {
+	project, err := i.getProject(ctx, workspacePath, filePath)
+	if err != nil {
+		return 0, err
+	}
+	if exist := i.buffers.get(project.Uuid, filePath); exist != nil && exist.Version >= version {
+		return exist.Version, nil
+	}
+	// 文档频繁修改，基于缓存的语法树增量解析
+	table, err := i.parser.ParseIncremental(ctx, &types.SourceFile{Path: filePath, Content: content})
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse file %s: %w", filePath, err)
+	}
+	table.ApplyOrigin(parser.ClassifyFile(filePath, content))
+	tables := []*parser.FileElementTable{table}
+	if err = i.preprocessImports(ctx, tables, project); err != nil {
+		i.logger.Debug("preprocess buffer %s imports err:%v", filePath, err)
+	}
+	return i.buffers.put(&overlayBuffer{
+		ProjectUuid: project.Uuid,
+		Path:        filePath,
+		Version:     version,
+		Content:     content,
+		Table:       proto.FileElementTablesToProto(tables)[0],
+	}), nil
+}
+
+// CloseBuffer 关闭文档时丢弃其覆盖层
+func (i *indexer) CloseBuffer(ctx context.Context, workspacePath string, filePath string) error {
+	project, err := i.getProject(ctx, workspacePath, filePath)
+	if err != nil {
+		return err
+	}
+	i.buffers.remove(project.Uuid, filePath)
+	return nil
+}
+
+// GetBuffer 获取未保存文档的内容，不存在时返回 false
+func (i *indexer) GetBuffer(ctx context.Context, workspacePath string, filePath string) ([]byte, bool) {
+	project, err := i.getProject(ctx, workspacePath, filePath)
+	if err != nil {
+		return nil, false
+	}
+	if buffer := i.buffers.get(project.Uuid, filePath); buffer != nil {
+		return buffer.Content, true
+	}
+	return nil, false
+}
+
+// readFile 读取文件内容，编辑器中有未保存的文档时以文档内容为准，与查询使用的覆盖层一致
+func (i *indexer) readFile(ctx context.Context, projectUuid string, filePath string) ([]byte, error) {
+	if buffer := i.buffers.get(projectUuid, filePath); buffer != nil {
+		return buffer.Content, nil
+	}
+	return i.workspaceReader.ReadFile(ctx, filePath, types.ReadOptions{})
+}
+
+// GetOpenBuffers 编辑器中打开的文档路径及最近更新时间，用于索引队列的优先级
+func (i *indexer) GetOpenBuffers() map[string]time.Time {
+	return i.buffers.updatedAt()
+}
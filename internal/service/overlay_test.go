@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+)
+
+func TestBufferOverlay(t *testing.T) {
+	overlay := newBufferOverlay()
+	table := &codegraphpb.FileElementTable{Path: "/repo/a.go", Elements: []*codegraphpb.Element{
+		{Name: "Run", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{4, 0, 6, 1}},
+		{Name: "Run", ElementType: codegraphpb.ElementType_CALL, Range: []int32{9, 1, 9, 6}},
+	}}
+	assert.Equal(t, int64(3), overlay.put(&overlayBuffer{ProjectUuid: "p", Path: "/repo/a.go", Version: 3,
+		Content: []byte("v3"), Table: table}))
+	// 版本号不大于已有版本的内容被忽略
+	assert.Equal(t, int64(3), overlay.put(&overlayBuffer{ProjectUuid: "p", Path: "/repo/a.go", Version: 2,
+		Content: []byte("v2")}))
+	assert.Equal(t, []byte("v3"), overlay.get("p", "/repo/a.go").Content)
+	assert.Nil(t, overlay.get("other", "/repo/a.go"))
+
+	// 覆盖层中的定义代替索引中同一文件的定义
+	occurrences := overlay.definitionOccurrences("p", "Run", []*codegraphpb.Occurrence{
+		{Path: "/repo/a.go", Range: []int32{1, 0, 3, 1}},
+		{Path: "/repo/b.go", Range: []int32{2, 0, 2, 10}},
+	})
+	assert.Equal(t, []*codegraphpb.Occurrence{
+		{Path: "/repo/b.go", Range: []int32{2, 0, 2, 10}},
+		{Path: "/repo/a.go", Range: []int32{4, 0, 6, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+	}, occurrences)
+
+	// 磁盘内容与文档一致时才丢弃
+	assert.False(t, overlay.removeSaved("p", "/repo/a.go", []byte("v2")))
+	assert.True(t, overlay.removeSaved("p", "/repo/a.go", []byte("v3")))
+	assert.Nil(t, overlay.get("p", "/repo/a.go"))
+	assert.False(t, overlay.remove("p", "/repo/a.go"))
+}
@@ -0,0 +1,482 @@
+package service
+
+import (
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 内置类型归一后的类别，不同语言的同类基础类型视为同一类型
+const (
+	typeString  = "string"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+	typeChar    = "char"
+	typeNull    = "null"
+)
+
+const reasonTypeMatch = "parameter type match"
+const reasonTypeMismatch = "parameter type mismatch"
+const reasonAmbiguousOverload = "ambiguous overload"
+
+const (
+	confidenceTypeMatch    = 0.1
+	confidenceTypeMismatch = -0.15
+)
+
+var builtinTypes = map[string]string{
+	"String": typeString, "string": typeString, "str": typeString, "wstring": typeString, "CharSequence": typeString,
+	"char": typeChar, "Character": typeChar, "wchar_t": typeChar,
+	"boolean": typeBoolean, "Boolean": typeBoolean, "bool": typeBoolean,
+	"int": typeNumber, "Integer": typeNumber, "long": typeNumber, "Long": typeNumber, "short": typeNumber,
+	"Short": typeNumber, "byte": typeNumber, "Byte": typeNumber, "float": typeNumber, "Float": typeNumber,
+	"double": typeNumber, "Double": typeNumber, "number": typeNumber, "Number": typeNumber, "bigint": typeNumber,
+	"unsigned": typeNumber, "signed": typeNumber, "size_t": typeNumber, "int8_t": typeNumber, "int16_t": typeNumber,
+	"int32_t": typeNumber, "int64_t": typeNumber, "uint8_t": typeNumber, "uint16_t": typeNumber,
+	"uint32_t": typeNumber, "uint64_t": typeNumber,
+}
+
+// 形参声明中不属于类型的修饰符
+var parameterModifiers = map[string]struct{}{
+	"final": {}, "const": {}, "volatile": {}, "readonly": {}, "public": {}, "private": {}, "protected": {},
+	"override": {}, "struct": {}, "class": {}, "enum": {}, "unsigned": {}, "signed": {}, "long": {}, "short": {},
+}
+
+var (
+	numberLiteralPattern  = regexp.MustCompile(`^[+-]?(0[xXbBoO][0-9a-fA-F_]+|[0-9][0-9_']*(\.[0-9_]*)?([eE][+-]?[0-9]+)?|\.[0-9]+([eE][+-]?[0-9]+)?)[lLfFdDuUnN]*$`)
+	newExpressionPattern  = regexp.MustCompile(`^new\s+([\p{L}_$][\p{L}\p{N}_$.:]*)`)
+	castExpressionPattern = regexp.MustCompile(`^\(\s*([\p{L}_$][\p{L}\p{N}_$.:<>\s]*?)\s*\)\s*[\p{L}\p{N}_$("'!~]`)
+)
+
+// declaredParameter 定义的形参，Type 为归一化后的类型，未知时为空
+type declaredParameter struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+// normalizeType 去掉修饰符、泛型参数、数组/指针/引用标记及限定前缀，内置类型归一为类别
+func normalizeType(t string) string {
+	t = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), types.Colon))
+	// 函数类型、对象字面量类型及联合类型不参与比较
+	if t == types.EmptyString || t == types.PrimitiveType || strings.HasPrefix(t, "(") || strings.HasPrefix(t, "{") ||
+		strings.Contains(t, "=>") || strings.Contains(t, "|") {
+		return types.EmptyString
+	}
+	fields := strings.Fields(t)
+	var kept []string
+	for _, f := range fields {
+		if _, ok := parameterModifiers[f]; ok {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if len(kept) == 0 {
+		// unsigned、long 等单独作为类型
+		return typeNumber
+	}
+	t = strings.Join(kept, types.EmptyString)
+	pointer := strings.ContainsAny(t, "*")
+	array := strings.Contains(t, "[]")
+	if idx := strings.IndexAny(t, "<([*&"); idx > 0 {
+		t = t[:idx]
+	}
+	t = strings.TrimSuffix(strings.TrimPrefix(t, "..."), "...")
+	if idx := strings.LastIndexAny(t, ".:"); idx >= 0 {
+		t = t[idx+1:]
+	}
+	if t == "char" && pointer {
+		return typeString
+	}
+	if category, ok := builtinTypes[t]; ok {
+		if array {
+			return category + "[]"
+		}
+		return category
+	}
+	if array {
+		return t + "[]"
+	}
+	return t
+}
+
+// isBuiltinType 是否为内置类型类别
+func isBuiltinType(t string) bool {
+	switch strings.TrimSuffix(t, "[]") {
+	case typeString, typeNumber, typeBoolean, typeChar:
+		return true
+	}
+	return false
+}
+
+// inferArgumentType 由实参文本推断类型：字面量、new 表达式、强制转换，标识符取所在函数形参的声明类型，无法推断时为空
+func inferArgumentType(arg string, declared map[string]string) string {
+	arg = strings.TrimSpace(arg)
+	if arg == types.EmptyString {
+		return types.EmptyString
+	}
+	switch arg {
+	case "true", "false", "True", "False":
+		return typeBoolean
+	case "null", "nullptr", "NULL", "None", "undefined", "nil":
+		return typeNull
+	}
+	switch arg[0] {
+	case '"', '`':
+		return typeString
+	case '\'':
+		// Java/C/C++ 的字符字面量，其余语言为字符串
+		if unquoted := strings.Trim(arg, "'"); len([]rune(unquoted)) == 1 || (strings.HasPrefix(unquoted, "\\") && len(unquoted) <= 6) {
+			return typeChar
+		}
+		return typeString
+	}
+	if numberLiteralPattern.MatchString(arg) {
+		return typeNumber
+	}
+	if m := newExpressionPattern.FindStringSubmatch(arg); m != nil {
+		return normalizeType(m[1])
+	}
+	if m := castExpressionPattern.FindStringSubmatch(arg); m != nil {
+		// (x) + y 等带括号的表达式不是强制转换，只接受内置类型或首字母大写的类型名
+		if t := normalizeType(m[1]); isBuiltinType(t) || t != types.EmptyString && unicode.IsUpper([]rune(t)[0]) {
+			return t
+		}
+	}
+	if identifierPattern.MatchString(arg) {
+		return declared[arg]
+	}
+	return types.EmptyString
+}
+
+// inferArgumentTypes 推断调用每个实参的类型
+func inferArgumentTypes(args []resolver.Parameter, declared map[string]string) []string {
+	res := make([]string, len(args))
+	for idx, a := range args {
+		res[idx] = inferArgumentType(a.Name, declared)
+	}
+	return res
+}
+
+// declaredParameters 定义的形参。索引记录的类型不含基础类型（仅记为 primitive_type），
+// 因此优先从签名文本解析类型，个数与索引记录的形参不一致时退回索引记录的类型
+func declaredParameters(element *codegraphpb.Element) ([]declaredParameter, error) {
+	params, err := proto.GetParametersFromExtraData(element.ExtraData)
+	if err != nil {
+		return nil, err
+	}
+	var fromSignature []declaredParameter
+	if doc, err := proto.GetDocCommentFromExtraData(element.ExtraData); err == nil && doc != nil {
+		fromSignature = parseSignatureParameters(doc.Signature, element.Name)
+	}
+	if len(fromSignature) == len(params) && len(params) > 0 {
+		return fromSignature, nil
+	}
+	res := make([]declaredParameter, len(params))
+	for idx, p := range params {
+		res[idx] = declaredParameter{
+			Name:     strings.TrimLeft(p.Name, "*.&"),
+			Variadic: strings.HasPrefix(p.Name, types.Star) || strings.HasPrefix(p.Name, "..."),
+		}
+		for _, t := range p.Type {
+			if strings.HasPrefix(t, "...") {
+				res[idx].Variadic = true
+			}
+			if res[idx].Type == types.EmptyString {
+				res[idx].Type = normalizeType(t)
+			}
+		}
+	}
+	return res, nil
+}
+
+// parseSignatureParameters 从签名文本解析形参列表，支持 `类型 名称`（Java、C/C++、C#）及 `名称: 类型`（TypeScript、Python 等）两种写法
+func parseSignatureParameters(signature string, name string) []declaredParameter {
+	start := strings.Index(signature, name+"(")
+	if start < 0 {
+		start = strings.Index(signature, name+"<")
+	}
+	if start < 0 {
+		start = 0
+	}
+	open := strings.IndexByte(signature[start:], '(')
+	if open < 0 {
+		return nil
+	}
+	open += start
+	var parts []string
+	depth := 0
+	last := open + 1
+	for idx := open; idx < len(signature); idx++ {
+		switch signature[idx] {
+		case '(', '<', '[', '{':
+			depth++
+		case ')', '>', ']', '}':
+			if isArrow(signature, idx) {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				if part := strings.TrimSpace(signature[last:idx]); part != types.EmptyString {
+					parts = append(parts, part)
+				}
+				return parseParameterParts(parts)
+			}
+		case ',':
+			if depth == 1 {
+				parts = append(parts, strings.TrimSpace(signature[last:idx]))
+				last = idx + 1
+			}
+		}
+	}
+	return nil
+}
+
+func parseParameterParts(parts []string) []declaredParameter {
+	res := make([]declaredParameter, 0, len(parts))
+	for _, part := range parts {
+		if idx := topLevelIndex(part, '='); idx > 0 {
+			part = strings.TrimSpace(part[:idx])
+		}
+		var fields []string
+		for _, f := range strings.Fields(part) {
+			if !strings.HasPrefix(f, "@") {
+				fields = append(fields, f)
+			}
+		}
+		part = strings.Join(fields, " ")
+		if part == types.EmptyString || part == "void" || part == "self" || part == "cls" {
+			continue
+		}
+		p := declaredParameter{Variadic: strings.Contains(part, "...") || strings.HasPrefix(part, types.Star)}
+		if idx := topLevelIndex(part, ':'); idx > 0 && !strings.Contains(part[:idx], "::") {
+			p.Name = strings.TrimSpace(part[:idx])
+			p.Type = normalizeType(part[idx+1:])
+		} else if idx := strings.LastIndexAny(part, " *&."); idx > 0 && idx < len(part)-1 {
+			p.Name = part[idx+1:]
+			p.Type = normalizeType(part[:idx+1])
+		} else {
+			p.Type = normalizeType(part)
+		}
+		p.Name = strings.TrimRight(strings.TrimLeft(p.Name, "*.&"), "?")
+		if fields := strings.Fields(p.Name); len(fields) > 0 {
+			p.Name = fields[len(fields)-1]
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// topLevelIndex 不在括号、泛型参数内的字符位置
+func topLevelIndex(s string, c byte) int {
+	depth := 0
+	for idx := 0; idx < len(s); idx++ {
+		switch s[idx] {
+		case '(', '<', '[', '{':
+			depth++
+		case ')', '>', ']', '}':
+			if !isArrow(s, idx) {
+				depth--
+			}
+		case c:
+			if depth == 0 && !(c == ':' && isScopeOperator(s, idx)) {
+				return idx
+			}
+		}
+	}
+	return -1
+}
+
+// isArrow s[idx] 为 => 或 -> 中的 >，不是括号
+func isArrow(s string, idx int) bool {
+	return s[idx] == '>' && idx > 0 && (s[idx-1] == '=' || s[idx-1] == '-')
+}
+
+// isScopeOperator s[idx] 为 :: 中的 :
+func isScopeOperator(s string, idx int) bool {
+	return (idx+1 < len(s) && s[idx+1] == ':') || (idx > 0 && s[idx-1] == ':')
+}
+
+// enclosingDeclaredTypes 调用所在函数/方法的形参名到类型的映射，用于推断以形参作为实参时的类型
+func enclosingDeclaredTypes(table *codegraphpb.FileElementTable, call *codegraphpb.Element) map[string]string {
+	if table == nil || len(call.Range) < 4 {
+		return nil
+	}
+	var enclosing *codegraphpb.Element
+	for _, e := range table.Elements {
+		if !e.IsDefinition || len(e.Range) < 4 ||
+			(e.ElementType != codegraphpb.ElementType_FUNCTION && e.ElementType != codegraphpb.ElementType_METHOD) {
+			continue
+		}
+		if !rangeContains(e.Range, call.Range) {
+			continue
+		}
+		if enclosing == nil || rangeContains(enclosing.Range, e.Range) {
+			enclosing = e
+		}
+	}
+	if enclosing == nil {
+		return nil
+	}
+	params, err := declaredParameters(enclosing)
+	if err != nil {
+		return nil
+	}
+	declared := make(map[string]string, len(params))
+	for _, p := range params {
+		if p.Name != types.EmptyString && p.Type != types.EmptyString && !p.Variadic {
+			declared[p.Name] = p.Type
+		}
+	}
+	return declared
+}
+
+// typeCompatible 实参能否传给形参，exact 表示类型一致。任一类型未知、实参为 null、
+// 形参为自定义类型（可能是父类、接口或泛型参数）时视为兼容
+func typeCompatible(arg, param string) (compatible bool, exact bool) {
+	if arg == types.EmptyString || param == types.EmptyString {
+		return true, false
+	}
+	if arg == param {
+		return true, true
+	}
+	if arg == typeNull || !isBuiltinType(param) {
+		return true, false
+	}
+	// 字符可隐式转换为整数
+	return arg == typeChar && param == typeNumber, false
+}
+
+// matchOverload 调用与候选定义是否匹配：实参个数一致（考虑可变参数）且已知类型均兼容，exact 为类型一致的实参个数
+func matchOverload(argCount int, argTypes []string, params []declaredParameter) (bool, int) {
+	if !arityMatchesDeclared(argCount, params) {
+		return false, 0
+	}
+	exact := 0
+	for idx, at := range argTypes {
+		if idx >= len(params) || params[idx].Variadic {
+			break
+		}
+		compatible, same := typeCompatible(at, params[idx].Type)
+		if !compatible {
+			return false, 0
+		}
+		if same {
+			exact++
+		}
+	}
+	return true, exact
+}
+
+func arityMatchesDeclared(argCount int, params []declaredParameter) bool {
+	if len(params) > 0 && params[len(params)-1].Variadic {
+		return argCount >= len(params)-1
+	}
+	return argCount == len(params)
+}
+
+// selectOverloads 从同名候选中选出与调用最匹配的重载下标：先按个数及类型兼容过滤，再取类型一致个数最多者。
+// signatures 中为 nil 的候选签名未知，不参与选择；没有候选匹配时返回 nil
+func selectOverloads(argCount int, argTypes []string, signatures [][]declaredParameter) []int {
+	var best []int
+	bestExact := -1
+	for idx, params := range signatures {
+		if params == nil {
+			continue
+		}
+		ok, exact := matchOverload(argCount, argTypes, params)
+		if !ok {
+			continue
+		}
+		if exact > bestExact {
+			best, bestExact = []int{idx}, exact
+		} else if exact == bestExact {
+			best = append(best, idx)
+		}
+	}
+	return best
+}
+
+// overloadKey 同一调用的候选中互为重载的定义：名称相同且属于同一类型，无所属类型时位于同一文件
+func overloadKey(source *definitionSource, def *types.Definition) overloadGroupKey {
+	key := overloadGroupKey{source: source, name: def.Name, container: def.Container}
+	if def.Container == types.EmptyString {
+		key.path = def.Path
+	}
+	return key
+}
+
+type overloadGroupKey struct {
+	source    *definitionSource
+	name      string
+	container string
+	path      string
+}
+
+// resolveOverloads 对同一调用的重载候选按实参个数及类型选择：剔除不匹配的重载，多个重载同样匹配时标记为歧义。
+// 没有任何重载匹配时保留全部候选，由置信度排序
+func resolveOverloads(defs []*types.Definition, sources map[*types.Definition]*definitionSource,
+	elements map[*types.Definition]*codegraphpb.Element) []*types.Definition {
+	groups := make(map[overloadGroupKey][]*types.Definition)
+	signatures := make(map[*types.Definition][]declaredParameter)
+	for _, d := range defs {
+		source, element := sources[d], elements[d]
+		if source == nil || source.ArgCount < 0 || element == nil ||
+			(element.ElementType != codegraphpb.ElementType_FUNCTION && element.ElementType != codegraphpb.ElementType_METHOD) {
+			continue
+		}
+		params, err := declaredParameters(element)
+		if err != nil {
+			continue
+		}
+		if params == nil {
+			params = []declaredParameter{}
+		}
+		signatures[d] = params
+		key := overloadKey(source, d)
+		groups[key] = append(groups[key], d)
+	}
+
+	dropped := make(map[*types.Definition]struct{})
+	for key, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		candidates := make([][]declaredParameter, len(group))
+		for idx, d := range group {
+			candidates[idx] = signatures[d]
+		}
+		best := selectOverloads(key.source.ArgCount, key.source.ArgTypes, candidates)
+		if len(best) == 0 {
+			continue
+		}
+		selected := make(map[int]struct{}, len(best))
+		for _, idx := range best {
+			selected[idx] = struct{}{}
+		}
+		for idx, d := range group {
+			if _, ok := selected[idx]; !ok {
+				dropped[d] = struct{}{}
+				continue
+			}
+			if len(best) > 1 {
+				d.Ambiguous = true
+				d.Reason = strings.Join([]string{d.Reason, reasonAmbiguousOverload}, ", ")
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return defs
+	}
+	res := make([]*types.Definition, 0, len(defs)-len(dropped))
+	for _, d := range defs {
+		if _, ok := dropped[d]; !ok {
+			res = append(res, d)
+		}
+	}
+	return res
+}
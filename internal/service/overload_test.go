@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+)
+
+func overloadElement(t *testing.T, name string, signature string, params []resolver.Parameter) *codegraphpb.Element {
+	e := methodElement(t, "", params)
+	e.Name = name
+	b, err := json.Marshal(signature)
+	assert.NoError(t, err)
+	e.ExtraData["signature"] = b
+	return e
+}
+
+func TestParseSignatureParameters(t *testing.T) {
+	params := parseSignatureParameters("public <T> void add(final int count, @NonNull Map<String, T> values, String... rest)", "add")
+	assert.Equal(t, []declaredParameter{
+		{Name: "count", Type: typeNumber},
+		{Name: "values", Type: "Map"},
+		{Name: "rest", Type: typeString, Variadic: true},
+	}, params)
+
+	params = parseSignatureParameters("void print(const std::string& text, char *buf, unsigned long n = 0)", "print")
+	assert.Equal(t, []declaredParameter{
+		{Name: "text", Type: typeString},
+		{Name: "buf", Type: typeString},
+		{Name: "n", Type: typeNumber},
+	}, params)
+
+	params = parseSignatureParameters("format(value?: number, cb: (err: Error) => void): string", "format")
+	assert.Equal(t, []declaredParameter{
+		{Name: "value", Type: typeNumber},
+		{Name: "cb", Type: types.EmptyString},
+	}, params)
+
+	assert.Empty(t, parseSignatureParameters("void run()", "run"))
+}
+
+func TestInferArgumentType(t *testing.T) {
+	declared := map[string]string{"name": typeString}
+	cases := map[string]string{
+		`"hello"`:        typeString,
+		"'a'":            typeChar,
+		`'\n'`:           typeChar,
+		"'hello'":        typeString,
+		"42":             typeNumber,
+		"3.14f":          typeNumber,
+		"0x1F":           typeNumber,
+		"true":           typeBoolean,
+		"null":           typeNull,
+		"new User(id)":   "User",
+		"(Integer) obj":  typeNumber,
+		"(a) + b":        types.EmptyString,
+		"name":           typeString,
+		"other":          types.EmptyString,
+		"user.getName()": types.EmptyString,
+	}
+	for arg, want := range cases {
+		assert.Equal(t, want, inferArgumentType(arg, declared), arg)
+	}
+}
+
+func TestSelectOverloads(t *testing.T) {
+	byInt := []declaredParameter{{Name: "x", Type: typeNumber}}
+	byString := []declaredParameter{{Name: "x", Type: typeString}}
+	byUser := []declaredParameter{{Name: "x", Type: "User"}}
+	two := []declaredParameter{{Name: "x", Type: typeNumber}, {Name: "y", Type: typeNumber}}
+	signatures := [][]declaredParameter{byInt, byString, byUser, two, nil}
+
+	assert.Equal(t, []int{0}, selectOverloads(1, []string{typeNumber}, signatures))
+	assert.Equal(t, []int{1}, selectOverloads(1, []string{typeString}, signatures))
+	// 自定义类型的形参可能是父类或接口，类型未知时所有个数匹配的重载都兼容
+	assert.Equal(t, []int{2}, selectOverloads(1, []string{"Admin"}, signatures))
+	assert.Equal(t, []int{0, 1, 2}, selectOverloads(1, []string{types.EmptyString}, signatures))
+	assert.Equal(t, []int{0, 1, 2}, selectOverloads(1, []string{typeNull}, signatures))
+	assert.Equal(t, []int{3}, selectOverloads(2, []string{typeChar, typeNumber}, signatures))
+	assert.Nil(t, selectOverloads(3, nil, signatures))
+}
+
+func TestResolveOverloads(t *testing.T) {
+	byInt := &types.Definition{Name: "add", Path: "/repo/Calc.java", Container: "Calc"}
+	byString := &types.Definition{Name: "add", Path: "/repo/Calc.java", Container: "Calc"}
+	byLong := &types.Definition{Name: "add", Path: "/repo/Calc.java", Container: "Calc"}
+	other := &types.Definition{Name: "add", Path: "/repo/Other.java", Container: "Other"}
+	elements := map[*types.Definition]*codegraphpb.Element{
+		byInt:    overloadElement(t, "add", "public int add(int a)", []resolver.Parameter{{Name: "a"}}),
+		byString: overloadElement(t, "add", "public String add(String a)", []resolver.Parameter{{Name: "a", Type: []string{"String"}}}),
+		byLong:   overloadElement(t, "add", "public long add(long a)", []resolver.Parameter{{Name: "a"}}),
+		other:    overloadElement(t, "add", "public void add(String a)", []resolver.Parameter{{Name: "a", Type: []string{"String"}}}),
+	}
+	defs := []*types.Definition{byInt, byString, byLong, other}
+
+	literal := &definitionSource{ArgCount: 1, ArgTypes: []string{typeString}}
+	sources := map[*types.Definition]*definitionSource{byInt: literal, byString: literal, byLong: literal, other: literal}
+	assert.Equal(t, []*types.Definition{byString, other}, resolveOverloads(defs, sources, elements))
+	assert.False(t, byString.Ambiguous)
+
+	number := &definitionSource{ArgCount: 1, ArgTypes: []string{typeNumber}}
+	sources = map[*types.Definition]*definitionSource{byInt: number, byString: number, byLong: number, other: number}
+	assert.Equal(t, []*types.Definition{byInt, byLong, other}, resolveOverloads(defs, sources, elements))
+	assert.True(t, byInt.Ambiguous)
+	assert.True(t, byLong.Ambiguous)
+	assert.Contains(t, byInt.Reason, reasonAmbiguousOverload)
+	assert.False(t, other.Ambiguous)
+
+	// 没有任何重载匹配时保留全部候选
+	byInt.Ambiguous, byLong.Ambiguous = false, false
+	none := &definitionSource{ArgCount: 3}
+	sources = map[*types.Definition]*definitionSource{byInt: none, byString: none, byLong: none, other: none}
+	assert.Equal(t, defs, resolveOverloads(defs, sources, elements))
+}
+
+func TestMatchRootDefinitions(t *testing.T) {
+	class := &rootDefinition{node: &types.RelationNode{SymbolName: "User"}}
+	byName := &rootDefinition{node: &types.RelationNode{SymbolName: "User"}, params: []declaredParameter{{Name: "name", Type: typeString}}}
+	byId := &rootDefinition{node: &types.RelationNode{SymbolName: "User"}, params: []declaredParameter{{Name: "id", Type: typeNumber}}}
+	roots := []*rootDefinition{class, byName, byId}
+
+	args, err := json.Marshal([]resolver.Parameter{{Name: "42"}})
+	assert.NoError(t, err)
+	call := &codegraphpb.Element{Name: "User", ElementType: codegraphpb.ElementType_CALL,
+		Range: []int32{5, 4, 5, 16}, ExtraData: map[string][]byte{"parameters": args}}
+	matched, ambiguous := matchRootDefinitions(&codegraphpb.FileElementTable{}, call, roots)
+	assert.Equal(t, []*rootDefinition{class, byId}, matched)
+	assert.False(t, ambiguous)
+
+	args, err = json.Marshal([]resolver.Parameter{{Name: "value"}})
+	assert.NoError(t, err)
+	call.ExtraData["parameters"] = args
+	matched, ambiguous = matchRootDefinitions(&codegraphpb.FileElementTable{}, call, roots)
+	assert.Equal(t, roots, matched)
+	assert.True(t, ambiguous)
+
+	// 实参为所在方法的形参时取其声明类型
+	enclosing := overloadElement(t, "create", "public User create(String value)", []resolver.Parameter{{Name: "value"}})
+	enclosing.IsDefinition = true
+	enclosing.Range = []int32{4, 2, 6, 3}
+	table := &codegraphpb.FileElementTable{Elements: []*codegraphpb.Element{enclosing, call}}
+	matched, ambiguous = matchRootDefinitions(table, call, roots)
+	assert.Equal(t, []*rootDefinition{class, byName}, matched)
+	assert.False(t, ambiguous)
+
+	reference := &codegraphpb.Element{Name: "User", ElementType: codegraphpb.ElementType_REFERENCE}
+	matched, ambiguous = matchRootDefinitions(&codegraphpb.FileElementTable{}, reference, roots)
+	assert.Equal(t, roots, matched)
+	assert.False(t, ambiguous)
+}
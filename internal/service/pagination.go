@@ -0,0 +1,66 @@
+package service
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/types"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const defaultPageLimit = 100
+const maxPageLimit = 1000
+
+const cursorPrefix = "offset:"
+
+// errStopWalk 回调中提前结束遍历，不作为错误返回
+var errStopWalk = errors.New("stop walk")
+
+// pagination 游标分页参数，cursor 为上一页返回的 nextCursor
+type pagination struct {
+	Offset int
+	Limit  int
+}
+
+// parsePagination 解析分页参数，limit 与 cursor 均未指定时返回 nil，表示不分页
+func parsePagination(limit int, cursor string) (*pagination, error) {
+	if limit <= 0 && cursor == types.EmptyString {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	p := &pagination{Limit: limit}
+	if cursor == types.EmptyString {
+		return p, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return nil, errs.NewParamError("invalid cursor %s", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+	if err != nil || offset < 0 {
+		return nil, errs.NewParamError("invalid cursor %s", cursor)
+	}
+	p.Offset = offset
+	return p, nil
+}
+
+// nextCursor 下一页的游标，total 为本次已知的结果总数，没有更多结果时返回空
+func (p *pagination) nextCursor(total int) string {
+	next := p.Offset + p.Limit
+	if next >= total {
+		return types.EmptyString
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(next)))
+}
+
+// window 当前页在长度为 total 的结果中的范围
+func (p *pagination) window(total int) (int, int) {
+	start := min(p.Offset, total)
+	return start, min(start+p.Limit, total)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/types"
+)
+
+func TestParsePagination(t *testing.T) {
+	page, err := parsePagination(0, "")
+	assert.NoError(t, err)
+	assert.Nil(t, page)
+
+	page, err = parsePagination(5000, "")
+	assert.NoError(t, err)
+	assert.Equal(t, maxPageLimit, page.Limit)
+
+	page, err = parsePagination(10, "")
+	assert.NoError(t, err)
+	cursor := page.nextCursor(25)
+	assert.NotEmpty(t, cursor)
+
+	next, err := parsePagination(0, cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, next.Offset)
+	assert.Equal(t, defaultPageLimit, next.Limit)
+
+	page = &pagination{Offset: 20, Limit: 10}
+	assert.Empty(t, page.nextCursor(25))
+	start, end := page.window(25)
+	assert.Equal(t, 20, start)
+	assert.Equal(t, 25, end)
+
+	_, err = parsePagination(10, "not-a-cursor")
+	assert.Error(t, err)
+}
+
+func TestPageTree(t *testing.T) {
+	file := func(name string) *types.TreeNode {
+		return &types.TreeNode{FileInfo: types.FileInfo{Name: name}}
+	}
+	// 先序：a, a/1, a/2, b, b/3
+	tree := []*types.TreeNode{
+		{FileInfo: types.FileInfo{Name: "a", IsDir: true}, Children: []*types.TreeNode{file("1"), file("2")}},
+		{FileInfo: types.FileInfo{Name: "b", IsDir: true}, Children: []*types.TreeNode{file("3")}},
+	}
+	assert.Equal(t, 5, countTreeNodes(tree))
+
+	index := 0
+	paged := pageTree(tree, &pagination{Offset: 2, Limit: 2}, &index)
+	// a/2 所在目录 a 保留，b 在当前页内
+	if assert.Len(t, paged, 2) {
+		assert.Equal(t, "a", paged[0].Name)
+		assert.Len(t, paged[0].Children, 1)
+		assert.Equal(t, "2", paged[0].Children[0].Name)
+		assert.Equal(t, "b", paged[1].Name)
+		assert.Empty(t, paged[1].Children)
+	}
+	// 原树不受影响
+	assert.Len(t, tree[0].Children, 2)
+}
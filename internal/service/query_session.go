@@ -0,0 +1,133 @@
+package service
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"fmt"
+	"sync"
+)
+
+type querySessionKey struct{}
+
+// querySession 单次请求内多个查询共享的项目解析结果、索引存在性及解码后的 FileElementTable，
+// 通过 context 传递，未携带时每次查询独立读取。缓存的 FileElementTable 只读，调用方不能修改
+type querySession struct {
+	mu       sync.Mutex
+	projects map[string]*sessionEntry[[]*workspace.Project]
+	exists   map[string]*sessionEntry[bool]
+	tables   map[string]*sessionEntry[*codegraphpb.FileElementTable]
+}
+
+// sessionEntry 同一个 key 只加载一次，并发请求等待首次加载的结果
+type sessionEntry[T any] struct {
+	once  sync.Once
+	value T
+	err   error
+}
+
+func newQuerySession() *querySession {
+	return &querySession{
+		projects: make(map[string]*sessionEntry[[]*workspace.Project]),
+		exists:   make(map[string]*sessionEntry[bool]),
+		tables:   make(map[string]*sessionEntry[*codegraphpb.FileElementTable]),
+	}
+}
+
+// withQuerySession 返回携带查询会话的 context
+func withQuerySession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, querySessionKey{}, newQuerySession())
+}
+
+func querySessionFromContext(ctx context.Context) *querySession {
+	s, _ := ctx.Value(querySessionKey{}).(*querySession)
+	return s
+}
+
+func loadEntry[T any](s *querySession, entries map[string]*sessionEntry[T], key string, load func() (T, error)) (T, error) {
+	s.mu.Lock()
+	entry, ok := entries[key]
+	if !ok {
+		entry = &sessionEntry[T]{}
+		entries[key] = entry
+	}
+	s.mu.Unlock()
+	entry.once.Do(func() {
+		entry.value, entry.err = load()
+	})
+	return entry.value, entry.err
+}
+
+// getProject 查找文件所属项目，会话内同一工作区只查找一次项目
+func (i *indexer) getProject(ctx context.Context, workspacePath string, filePath string) (*workspace.Project, error) {
+	s := querySessionFromContext(ctx)
+	if s == nil {
+		return i.workspaceReader.GetProjectByFilePath(ctx, workspacePath, filePath, true)
+	}
+	if exists, err := i.workspaceReader.Exists(ctx, filePath); err == nil && !exists {
+		return nil, workspace.ErrPathNotExists
+	}
+	if !utils.IsSubdir(workspacePath, filePath) {
+		return nil, fmt.Errorf("file %s is not in workspace %s", filePath, workspacePath)
+	}
+	projects, _ := loadEntry(s, s.projects, workspacePath, func() ([]*workspace.Project, error) {
+		return i.workspaceReader.FindProjects(ctx, workspacePath, true, workspace.DefaultVisitPattern), nil
+	})
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("found no projects in workspace %s", workspacePath)
+	}
+	for _, p := range projects {
+		if utils.IsSubdir(p.Path, filePath) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to find project which file %s belongs to in workspace %s", filePath, workspacePath)
+}
+
+// checkProjectIndexExists 检查项目索引是否存在
+func (i *indexer) checkProjectIndexExists(ctx context.Context, workspacePath string, projectUuid string) error {
+	load := func() (bool, error) {
+		return i.storage.ProjectIndexExists(projectUuid)
+	}
+	var exists bool
+	var err error
+	if s := querySessionFromContext(ctx); s != nil {
+		exists, err = loadEntry(s, s.exists, projectUuid, load)
+	} else {
+		exists, err = load()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check workspace %s index, err:%v", workspacePath, err)
+	}
+	if !exists {
+		return fmt.Errorf("workspace %s index not exists", workspacePath)
+	}
+	return nil
+}
+
+// loadFileElementTable 读取并解码文件的 FileElementTable，会话内同一文件只解码一次；未保存的文档取覆盖层中的解析结果
+func (i *indexer) loadFileElementTable(ctx context.Context, projectUuid string, language lang.Language,
+	filePath string) (*codegraphpb.FileElementTable, error) {
+	// 未保存的文档优先
+	if buffer := i.buffers.get(projectUuid, filePath); buffer != nil {
+		return buffer.Table, nil
+	}
+	load := func() (*codegraphpb.FileElementTable, error) {
+		bytes, err := i.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath})
+		if err != nil {
+			return nil, err
+		}
+		var table codegraphpb.FileElementTable
+		if err = store.UnmarshalValue(bytes, &table); err != nil {
+			return nil, err
+		}
+		return &table, nil
+	}
+	if s := querySessionFromContext(ctx); s != nil {
+		return loadEntry(s, s.tables, projectUuid+"\x00"+filePath, load)
+	}
+	return load()
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"errors"
+	"strings"
+)
+
+// renameResolution 同名调用/引用的解析结果
+type renameResolution int
+
+const (
+	renameResolvedOther     renameResolution = iota // 指向其他同名定义
+	renameResolvedTarget                            // 指向被重命名的定义
+	renameResolvedAmbiguous                         // 无法确定指向哪个同名定义
+)
+
+// ownerMatch 调用的 owner 与候选定义所属容器的匹配情况
+type ownerMatch int
+
+const (
+	ownerMismatch ownerMatch = iota
+	ownerMatched
+	ownerUnknown
+)
+
+// renameCandidate 与被重命名符号同名的定义
+type renameCandidate struct {
+	occurrence *codegraphpb.Occurrence
+	container  string
+	params     []declaredParameter // 函数/方法的形参，类、接口或未知时为 nil
+	target     bool
+}
+
+// renameResolver 将与被重命名符号同名的调用/引用解析到定义：先按 import 过滤可见的同名定义，
+// 再按 owner 与定义所属容器匹配，仍有多个时按 matchRootDefinitions 的方式以实参选择重载
+type renameResolver struct {
+	analyzer   *analyzer.DependencyAnalyzer
+	candidates []*renameCandidate
+}
+
+// newRenameResolver 收集项目中与 target 同名的定义及其所属容器、形参
+func (i *indexer) newRenameResolver(ctx context.Context, projectUuid string, target *types.Definition) *renameResolver {
+	r := &renameResolver{analyzer: i.analyzer}
+	var occurrences []*codegraphpb.Occurrence
+	if language, err := lang.InferLanguage(target.Path); err == nil {
+		bytes, err := i.storage.Get(ctx, projectUuid, store.SymbolNameKey{Name: target.Name, Language: language})
+		if err == nil {
+			var exist codegraphpb.SymbolOccurrence
+			if err = store.UnmarshalValue(bytes, &exist); err == nil {
+				occurrences = exist.Occurrences
+			}
+		} else if !errors.Is(err, store.ErrKeyNotFound) {
+			i.logger.Debug("query rename get symbol %s err:%v", target.Name, err)
+		}
+	}
+	occurrences = i.buffers.definitionOccurrences(projectUuid, target.Name, occurrences)
+
+	hasTarget := false
+	for _, o := range occurrences {
+		if o.Path == target.Path && isValidRange(o.Range) && o.Range[0] == target.Range[0] {
+			hasTarget = true
+		}
+	}
+	if !hasTarget {
+		occurrences = append(occurrences, &codegraphpb.Occurrence{Path: target.Path, Range: target.Range})
+	}
+
+	tables := make(map[string]*codegraphpb.FileElementTable)
+	for _, o := range occurrences {
+		if !isValidRange(o.Range) {
+			continue
+		}
+		c := &renameCandidate{
+			occurrence: o,
+			target:     o.Path == target.Path && o.Range[0] == target.Range[0],
+		}
+		table, ok := tables[o.Path]
+		if !ok {
+			var err error
+			if table, err = i.getFileElementTableByPath(ctx, projectUuid, o.Path); err != nil {
+				table = nil
+			}
+			tables[o.Path] = table
+		}
+		found := false
+		if table != nil {
+			for _, e := range table.Elements {
+				if !e.IsDefinition || e.Name != target.Name || !isValidRange(e.Range) || e.Range[0] != o.Range[0] {
+					continue
+				}
+				found = true
+				c.container = findContainer(table, e)
+				if e.ElementType == codegraphpb.ElementType_METHOD || e.ElementType == codegraphpb.ElementType_FUNCTION {
+					if params, err := declaredParameters(e); err == nil {
+						c.params = params
+						if c.params == nil {
+							c.params = []declaredParameter{}
+						}
+					}
+				}
+				break
+			}
+		}
+		// 文件索引中已不存在的定义是过期的符号记录
+		if !found && !c.target {
+			continue
+		}
+		r.candidates = append(r.candidates, c)
+	}
+	return r
+}
+
+// resolve 解析文件中的调用/引用指向的同名定义
+func (r *renameResolver) resolve(table *codegraphpb.FileElementTable, element *codegraphpb.Element) renameResolution {
+	occurrences := make([]*codegraphpb.Occurrence, 0, len(r.candidates))
+	for _, c := range r.candidates {
+		occurrences = append(occurrences, c.occurrence)
+	}
+	visible := make(map[*codegraphpb.Occurrence]bool)
+	for _, o := range r.analyzer.FilterByImports(table.Path, table.Imports, occurrences) {
+		visible[o] = true
+	}
+
+	owner, _ := proto.GetOwnerFromExtraData(element.ExtraData)
+	containers := make(map[string]bool, len(r.candidates))
+	for _, c := range r.candidates {
+		if c.container != types.EmptyString {
+			containers[c.container] = true
+		}
+	}
+	var matched, unknown []*renameCandidate
+	var target *renameCandidate
+	for _, c := range r.candidates {
+		if !visible[c.occurrence] {
+			continue
+		}
+		switch matchOwner(table, element, owner, containers, c) {
+		case ownerMatched:
+			matched = append(matched, c)
+		case ownerUnknown:
+			unknown = append(unknown, c)
+		default:
+			continue
+		}
+		if c.target {
+			target = c
+		}
+	}
+	if target == nil {
+		return renameResolvedOther
+	}
+	if len(matched)+len(unknown) == 1 {
+		return renameResolvedTarget
+	}
+	if len(unknown) > 0 {
+		return renameResolvedAmbiguous
+	}
+
+	// 多个同名定义的 owner 均匹配，按实参选择重载
+	roots := make([]*rootDefinition, len(matched))
+	for idx, c := range matched {
+		roots[idx] = &rootDefinition{params: c.params}
+	}
+	selected, ambiguous := matchRootDefinitions(table, element, roots)
+	if ambiguous || len(selected) == len(roots) {
+		return renameResolvedAmbiguous
+	}
+	for idx, root := range roots {
+		for _, s := range selected {
+			if s == root && matched[idx].target {
+				return renameResolvedTarget
+			}
+		}
+	}
+	return renameResolvedOther
+}
+
+// matchOwner 调用/引用的 owner 能否指向候选定义：无 owner 或 this/self 时要求候选为函数或与引用位于同一容器；
+// owner 为容器名（静态调用）或导入的包时匹配；owner 为已知类型的形参时按类型匹配；
+// owner 为其他同名定义的容器名时不匹配，其余无法确定
+func matchOwner(table *codegraphpb.FileElementTable, element *codegraphpb.Element, owner string,
+	containers map[string]bool, c *renameCandidate) ownerMatch {
+	enclosing := func() string {
+		var container *codegraphpb.Element
+		for _, e := range table.Elements {
+			if !e.IsDefinition || !isValidRange(e.Range) || !isValidRange(element.Range) {
+				continue
+			}
+			if e.ElementType != codegraphpb.ElementType_CLASS && e.ElementType != codegraphpb.ElementType_INTERFACE {
+				continue
+			}
+			if rangeContains(e.Range, element.Range) && (container == nil || rangeContains(container.Range, e.Range)) {
+				container = e
+			}
+		}
+		if container == nil {
+			return types.EmptyString
+		}
+		return container.Name
+	}
+	switch owner {
+	case types.EmptyString:
+		if c.container == types.EmptyString || c.container == enclosing() {
+			return ownerMatched
+		}
+		return ownerMismatch
+	case "this", "self", "cls":
+		if c.container == enclosing() {
+			return ownerMatched
+		}
+		return ownerMismatch
+	case c.container:
+		return ownerMatched
+	}
+	for _, imp := range table.Imports {
+		if imp.Alias == owner || lastSegment(imp.Name) == owner || lastSegment(imp.Source) == owner {
+			if c.container == types.EmptyString && analyzer.IsImportPathInFilePath(imp, c.occurrence.Path) {
+				return ownerMatched
+			}
+			return ownerMismatch
+		}
+	}
+	if declared, ok := enclosingDeclaredTypes(table, element)[owner]; ok {
+		if t := normalizeType(declared); t != types.EmptyString {
+			if t == normalizeType(c.container) {
+				return ownerMatched
+			}
+			return ownerMismatch
+		}
+	}
+	if containers[owner] {
+		return ownerMismatch
+	}
+	return ownerUnknown
+}
+
+// lastSegment 按 . / 分隔的最后一段
+func lastSegment(s string) string {
+	if idx := strings.LastIndexAny(s, "./"); idx >= 0 {
+		return s[idx+1:]
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+)
+
+func TestRenameResolver_Resolve(t *testing.T) {
+	inA := &renameCandidate{occurrence: &codegraphpb.Occurrence{Path: "/repo/src/A.java", Range: []int32{3, 2, 5, 3}},
+		container: "A", params: []declaredParameter{}, target: true}
+	inB := &renameCandidate{occurrence: &codegraphpb.Occurrence{Path: "/repo/src/B.java", Range: []int32{3, 2, 5, 3}},
+		container: "B", params: []declaredParameter{}}
+	r := &renameResolver{analyzer: &analyzer.DependencyAnalyzer{}, candidates: []*renameCandidate{inA, inB}}
+
+	class := &codegraphpb.Element{Name: "A", ElementType: codegraphpb.ElementType_CLASS, IsDefinition: true,
+		Range: []int32{0, 0, 20, 1}}
+	run := overloadElement(t, "run", "public void run(B b)", []resolver.Parameter{{Name: "b"}})
+	run.IsDefinition = true
+	run.Range = []int32{8, 2, 12, 3}
+	table := &codegraphpb.FileElementTable{Path: "/repo/src/A.java", Elements: []*codegraphpb.Element{class, run}}
+
+	call := func(owner string) *codegraphpb.Element {
+		e := &codegraphpb.Element{Name: "get", ElementType: codegraphpb.ElementType_CALL,
+			Range: []int32{10, 4, 10, 12}, ExtraData: map[string][]byte{}}
+		if owner != "" {
+			b, err := json.Marshal(owner)
+			assert.NoError(t, err)
+			e.ExtraData["owner"] = b
+		}
+		return e
+	}
+
+	// this 指向所在类
+	assert.Equal(t, renameResolvedTarget, r.resolve(table, call("this")))
+	// 无 owner 时按所在类匹配
+	assert.Equal(t, renameResolvedTarget, r.resolve(table, call("")))
+	// owner 为声明类型为 B 的形参
+	assert.Equal(t, renameResolvedOther, r.resolve(table, call("b")))
+	// owner 为静态调用的类名
+	assert.Equal(t, renameResolvedOther, r.resolve(table, call("B")))
+	// owner 类型未知，无法确定
+	assert.Equal(t, renameResolvedAmbiguous, r.resolve(table, call("unknown")))
+}
@@ -18,6 +18,9 @@ import (
 	"codebase-indexer/internal/dto"
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/codegraph/chunker"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/logger"
 )
 
@@ -33,6 +36,7 @@ type Scheduler struct {
 	httpSync        repository.SyncInterface
 	fileScanner     repository.ScannerInterface
 	storage         repository.StorageInterface
+	chunker         *chunker.Chunker
 	schedulerConfig *SchedulerConfig
 	logger          logger.Logger
 	mutex           sync.Mutex
@@ -49,6 +53,7 @@ func NewScheduler(httpSync repository.SyncInterface, fileScanner repository.Scan
 		httpSync:        httpSync,
 		fileScanner:     fileScanner,
 		storage:         storageManager,
+		chunker:         chunker.NewChunker(chunker.Options{}),
 		schedulerConfig: defaultSchedulerConfig(),
 		restartCh:       make(chan struct{}),
 		updateCh:        make(chan struct{}),
@@ -374,6 +379,25 @@ type SyncMetadata struct {
 	CodebasePath string             `json:"codebasePath"`
 	FileList     []utils.FileStatus `json:"fileList"`
 	Timestamp    int64              `json:"timestamp"`
+	Chunks       []*chunker.Chunk   `json:"chunks,omitempty"`
+}
+
+// chunkFile 按语法结构对新增、修改的文件分块，不支持的语言不分块，由服务端按整文件处理
+func (s *Scheduler) chunkFile(codebasePath string, fileStatus *utils.FileStatus) []*chunker.Chunk {
+	if _, err := lang.InferLanguage(fileStatus.Path); err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(codebasePath, fileStatus.Path))
+	if err != nil {
+		s.logger.Warn("failed to read file for chunking: %s, error: %v", fileStatus.Path, err)
+		return nil
+	}
+	chunks, err := s.chunker.Chunk(context.Background(), &types.SourceFile{Path: fileStatus.Path, Content: content})
+	if err != nil {
+		s.logger.Debug("failed to chunk file: %s, error: %v", fileStatus.Path, err)
+		return nil
+	}
+	return chunks
 }
 
 // CreateChangesZip Create zip file containing file changes and metadata
@@ -423,7 +447,9 @@ func (s *Scheduler) CreateChangesZip(config *config.CodebaseConfig, changes []*u
 			if err := utils.AddFileToZip(zipWriter, change.Path, config.CodebasePath); err != nil {
 				// Continue trying to add other files but log error
 				s.logger.Warn("failed to add file to zip: %s, error: %v", change.Path, err)
+				continue
 			}
+			metadata.Chunks = append(metadata.Chunks, s.chunkFile(config.CodebasePath, change)...)
 		}
 	}
 
@@ -587,6 +613,8 @@ func (s *Scheduler) CreateSingleFileZip(config *config.CodebaseConfig, fileStatu
 		if err := utils.AddFileToZip(zipWriter, fileStatus.Path, config.CodebasePath); err != nil {
 			// Continue trying to add other files but log error
 			s.logger.Warn("failed to add file to zip: %s, error: %v", fileStatus.Path, err)
+		} else {
+			metadata.Chunks = s.chunkFile(config.CodebasePath, fileStatus)
 		}
 	}
 
@@ -663,7 +691,9 @@ func (s *Scheduler) CreateFilesZip(config *config.CodebaseConfig, fileStatus []*
 			if err := utils.AddFileToZip(zipWriter, f.Path, config.CodebasePath); err != nil {
 				// Continue trying to add other files but log error
 				s.logger.Warn("failed to add file to zip: %s, error: %v", f.Path, err)
+				continue
 			}
+			metadata.Chunks = append(metadata.Chunks, s.chunkFile(config.CodebasePath, f)...)
 		}
 	}
 
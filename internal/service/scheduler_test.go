@@ -1,15 +1,19 @@
 package service
 
 import (
+	"archive/zip"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"codebase-indexer/internal/config"
 	"codebase-indexer/internal/dto"
 	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/codegraph/chunker"
 	"codebase-indexer/test/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -218,6 +222,7 @@ func TestCreateChangesZip(t *testing.T) {
 		httpSync:        mockHttpSync,
 		fileScanner:     mockFileScanner,
 		storage:         mockStorage,
+		chunker:         chunker.NewChunker(chunker.Options{}),
 		schedulerConfig: schedulerConfig,
 		logger:          mockLogger,
 	}
@@ -243,6 +248,43 @@ func TestCreateChangesZip(t *testing.T) {
 		assert.NotEmpty(t, path)
 		mockLogger.AssertCalled(t, "Warn", "failed to add file to zip: %s, error: %v", mock.Anything, mock.Anything)
 	})
+
+	t.Run("ChunkManifest", func(t *testing.T) {
+		tmp := t.TempDir()
+		utils.UploadTmpDir = tmp
+		codebasePath := filepath.Join(tmp, "chunks")
+		assert.NoError(t, os.MkdirAll(codebasePath, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(codebasePath, "main.go"),
+			[]byte("package main\n\nfunc main() {\n}\n"), 0644))
+		config := &config.CodebaseConfig{
+			CodebaseId:   "test-id",
+			CodebasePath: codebasePath,
+		}
+		changes := []*utils.FileStatus{
+			{Path: "main.go", Status: utils.FILE_STATUS_ADDED},
+		}
+
+		path, err := s.CreateChangesZip(config, changes)
+		assert.NoError(t, err)
+
+		reader, err := zip.OpenReader(path)
+		assert.NoError(t, err)
+		defer reader.Close()
+		var metadata SyncMetadata
+		for _, f := range reader.File {
+			if !strings.HasPrefix(f.Name, ".shenma_sync/") {
+				continue
+			}
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			assert.NoError(t, json.NewDecoder(rc).Decode(&metadata))
+			_ = rc.Close()
+		}
+		assert.Len(t, metadata.Chunks, 1)
+		assert.Equal(t, "main.go", metadata.Chunks[0].FilePath)
+		assert.Equal(t, "go", metadata.Chunks[0].Language)
+		assert.Equal(t, []string{"main"}, metadata.Chunks[0].Symbols)
+	})
 }
 
 func TestUploadChangesZip(t *testing.T) {
@@ -254,6 +296,7 @@ func TestUploadChangesZip(t *testing.T) {
 	)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
 
 	s := &Scheduler{
 		httpSync:        mockHttpSync,
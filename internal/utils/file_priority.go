@@ -0,0 +1,104 @@
+// utils/file_priority.go - File selection when a project exceeds the file count limit
+package utils
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FileCandidateFactor bounds how many files are walked as candidates, relative to the limit
+	FileCandidateFactor = 10
+	// RecentModifyWindow files modified within this window are preferred
+	RecentModifyWindow = 30 * 24 * time.Hour
+	// MaxReportedDroppedFiles caps the dropped file list kept for status reporting
+	MaxReportedDroppedFiles = 200
+)
+
+// testDirNames directories holding tests, fixtures and examples rather than project source
+var testDirNames = map[string]bool{
+	"test": true, "tests": true, "__tests__": true, "__mocks__": true, "spec": true, "specs": true,
+	"testdata": true, "fixtures": true, "fixture": true, "mocks": true, "mock": true,
+	"examples": true, "example": true, "e2e": true, "benchmark": true, "benchmarks": true,
+}
+
+// FileCandidate a file competing for a slot under the file count limit
+type FileCandidate struct {
+	Path           string
+	ModTime        int64 // unix seconds
+	InboundImports int   // estimated number of files importing this file
+}
+
+// IsTestFile reports whether the path looks like a test, fixture or example file
+func IsTestFile(filePath string) bool {
+	slashed := filepath.ToSlash(filePath)
+	dir, base := path.Split(slashed)
+	for _, seg := range strings.Split(dir, "/") {
+		if testDirNames[strings.ToLower(seg)] {
+			return true
+		}
+	}
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	lower := strings.ToLower(stem)
+	return strings.HasSuffix(lower, "_test") || strings.HasSuffix(lower, ".test") ||
+		strings.HasSuffix(lower, ".spec") || strings.HasSuffix(lower, "_spec") ||
+		strings.HasPrefix(lower, "test_") || strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests")
+}
+
+// SelectFiles keeps at most limit files when there are more candidates. Source files are kept before
+// tests and fixtures, then recently modified files, files with more inbound imports, and shallower paths.
+// Both results are sorted by priority; dropped is empty when nothing exceeds the limit.
+func SelectFiles(candidates []*FileCandidate, limit int, now time.Time) (kept, dropped []*FileCandidate) {
+	if limit <= 0 || len(candidates) <= limit {
+		return candidates, nil
+	}
+	recent := now.Add(-RecentModifyWindow).Unix()
+	type rank struct {
+		test    bool
+		recent  bool
+		inbound int
+		depth   int
+	}
+	ranks := make(map[*FileCandidate]rank, len(candidates))
+	for _, c := range candidates {
+		ranks[c] = rank{
+			test:    IsTestFile(c.Path),
+			recent:  c.ModTime >= recent,
+			inbound: c.InboundImports,
+			depth:   strings.Count(filepath.ToSlash(c.Path), "/"),
+		}
+	}
+	sorted := make([]*FileCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		ra, rb := ranks[sorted[a]], ranks[sorted[b]]
+		switch {
+		case ra.test != rb.test:
+			return !ra.test
+		case ra.recent != rb.recent:
+			return ra.recent
+		case ra.inbound != rb.inbound:
+			return ra.inbound > rb.inbound
+		case ra.depth != rb.depth:
+			return ra.depth < rb.depth
+		}
+		return sorted[a].Path < sorted[b].Path
+	})
+	return sorted[:limit], sorted[limit:]
+}
+
+// DroppedPaths paths of the dropped files, capped at MaxReportedDroppedFiles
+func DroppedPaths(dropped []*FileCandidate) []string {
+	n := len(dropped)
+	if n > MaxReportedDroppedFiles {
+		n = MaxReportedDroppedFiles
+	}
+	paths := make([]string, 0, n)
+	for _, c := range dropped[:n] {
+		paths = append(paths, c.Path)
+	}
+	return paths
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := map[string]bool{
+		"/repo/internal/service/indexer.go":       false,
+		"/repo/internal/service/indexer_test.go":  true,
+		"/repo/src/app.spec.ts":                   true,
+		"/repo/src/__tests__/app.ts":              true,
+		"/repo/pkg/parser/testdata/sample.go":     true,
+		"/repo/src/main/java/com/x/UserTest.java": true,
+		"/repo/tests/test_user.py":                true,
+		"/repo/src/latest.go":                     false,
+		"/repo/src/contest.py":                    false,
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, IsTestFile(path), path)
+	}
+}
+
+func TestSelectFiles(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * RecentModifyWindow).Unix()
+	candidates := []*FileCandidate{
+		{Path: "/repo/a/b/c/deep.go", ModTime: old},
+		{Path: "/repo/fixtures/data.go", ModTime: now.Unix()},
+		{Path: "/repo/shallow.go", ModTime: old},
+		{Path: "/repo/a/b/imported.go", ModTime: old, InboundImports: 5},
+		{Path: "/repo/a/b/c/recent.go", ModTime: now.Unix()},
+	}
+
+	kept, dropped := SelectFiles(candidates, 10, now)
+	assert.Len(t, kept, 5)
+	assert.Empty(t, dropped)
+
+	kept, dropped = SelectFiles(candidates, 3, now)
+	var keptPaths, droppedPaths []string
+	for _, c := range kept {
+		keptPaths = append(keptPaths, c.Path)
+	}
+	for _, c := range dropped {
+		droppedPaths = append(droppedPaths, c.Path)
+	}
+	assert.Equal(t, []string{"/repo/a/b/c/recent.go", "/repo/a/b/imported.go", "/repo/shallow.go"}, keptPaths)
+	// tests and fixtures go last
+	assert.Equal(t, []string{"/repo/a/b/c/deep.go", "/repo/fixtures/data.go"}, droppedPaths)
+	assert.Equal(t, droppedPaths, DroppedPaths(dropped))
+}
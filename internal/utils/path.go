@@ -19,6 +19,7 @@ var (
 	WorkspaceDir = "./.costrict/cache/codebase-indexer/workspace"
 	EmbeddingDir = "./.costrict/cache/codebase-indexer/embedding"
 	IndexDir     = "./.costrict/cache/codebase-indexer/index"
+	TextIndexDir = "./.costrict/cache/codebase-indexer/textindex"
 	AuthJsonFile = "./.costrict/share/auth.json"
 )
 
@@ -211,6 +212,24 @@ func GetCacheIndexDir(cachePath string) (string, error) {
 	return indexPath, nil
 }
 
+// GetCacheTextIndexDir gets full-text index directory
+func GetCacheTextIndexDir(cachePath string) (string, error) {
+	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
+		return "", fmt.Errorf("cache path %s does not exist", cachePath)
+	}
+
+	textIndexPath := filepath.Join(cachePath, "textindex")
+
+	// Ensure config directory exists
+	if err := os.MkdirAll(textIndexPath, 0755); err != nil {
+		return "", err
+	}
+
+	TextIndexDir = textIndexPath
+
+	return textIndexPath, nil
+}
+
 func GetAuthJsonFile(rootPath string) (string, error) {
 	if _, err := os.Stat(rootPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("root path %s does not exist", rootPath)
@@ -205,7 +205,7 @@ func (da *DependencyAnalyzer) FilterByImports(filePath string, imports []*codegr
 
 		// 3、根据import，当前def的路径包含imp的路径
 		for _, imp := range imports {
-			if IsImportPathInFilePath(imp, filePath) {
+			if IsImportPathInFilePath(imp, def.Path) {
 				found = append(found, def)
 				break
 			}
@@ -36,6 +36,12 @@ func (da *DependencyAnalyzer) PreprocessImports(ctx context.Context,
 // processImportByLanguage 根据语言类型统一处理导入
 func (da *DependencyAnalyzer) processImportByLanguage(imp *resolver.Import, language lang.Language,
 	project *workspace.Project) *resolver.Import {
+	// ts 路径别名，转为项目内的绝对路径
+	if (language == lang.TypeScript || language == lang.JavaScript) && project != nil && len(project.JsPathAliases) > 0 {
+		if resolved, ok := resolvePathAlias(imp.Source, project.JsPathAliases); ok {
+			imp.Source = resolved
+		}
+	}
 	// go项目，只处理 module前缀的，过滤非module前缀的
 	packageType, err := da.PackageClassifier.ClassifyPackage(language, imp.Name, project)
 	if err != nil {
@@ -69,6 +75,31 @@ func (da *DependencyAnalyzer) processImportByLanguage(imp *resolver.Import, lang
 	return imp
 }
 
+// resolvePathAlias 按路径别名解析导入，同 tsconfig paths：精确匹配优先，其次最长的别名前缀，使用第一个目标
+func resolvePathAlias(source string, aliases map[string][]string) (string, bool) {
+	best := -1
+	var resolved string
+	for alias, targets := range aliases {
+		if len(targets) == 0 {
+			continue
+		}
+		prefix, suffix, wildcard := strings.Cut(alias, types.Star)
+		if !wildcard {
+			if source == alias && len(alias)+1 > best {
+				best, resolved = len(alias)+1, targets[0]
+			}
+			continue
+		}
+		if len(source) < len(prefix)+len(suffix) || !strings.HasPrefix(source, prefix) ||
+			!strings.HasSuffix(source, suffix) || len(prefix) <= best {
+			continue
+		}
+		best = len(prefix)
+		resolved = strings.Replace(targets[0], types.Star, source[len(prefix):len(source)-len(suffix)], 1)
+	}
+	return resolved, best >= 0
+}
+
 // resolveRelativePath 统一解析相对路径
 func (da *DependencyAnalyzer) resolveRelativePath(importPath, currentFilePath string) string {
 	currentDir := filepath.Dir(currentFilePath)
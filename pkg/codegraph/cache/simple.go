@@ -14,12 +14,13 @@ type node[T any] struct {
 
 // LRUCache 带并发锁的LRU缓存（仅限制最大容量，依赖map自动扩容）
 type LRUCache[T any] struct {
-	cache       map[string]*node[T] // 快速查找映射，依赖Go自动扩容
-	head        *node[T]            // 头节点（最近使用）
-	tail        *node[T]            // 尾节点（最少使用）
-	maxCapacity int                 // 最大元素数量（超过则淘汰）
-	size        int                 // 当前元素数量
-	mu          sync.Mutex          // 互斥锁，保证并发安全
+	cache       map[string]*node[T]       // 快速查找映射，依赖Go自动扩容
+	head        *node[T]                  // 头节点（最近使用）
+	tail        *node[T]                  // 尾节点（最少使用）
+	maxCapacity int                       // 最大元素数量（超过则淘汰）
+	size        int                       // 当前元素数量
+	mu          sync.Mutex                // 互斥锁，保证并发安全
+	onEvict     func(key string, value T) // 元素被淘汰、替换或清理时回调，可为空
 }
 
 // NewLRUCache 创建新的LRU缓存
@@ -88,6 +89,9 @@ func (c *LRUCache[T]) Put(key string, value T) {
 
 	// 若已存在，更新值并移到头部
 	if node, ok := c.cache[key]; ok {
+		if c.onEvict != nil {
+			c.onEvict(key, node.value)
+		}
 		node.value = value
 		c.moveToHead(node)
 		return
@@ -107,14 +111,45 @@ func (c *LRUCache[T]) Put(key string, value T) {
 		removedNode := c.removeTail()
 		delete(c.cache, removedNode.key)
 		c.size--
+		if c.onEvict != nil {
+			c.onEvict(removedNode.key, removedNode.value)
+		}
 	}
 }
 
+// Remove 移除并返回元素，不触发淘汰回调，元素由调用方接管
+func (c *LRUCache[T]) Remove(key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node, ok := c.cache[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	c.removeNode(node)
+	delete(c.cache, key)
+	c.size--
+	return node.value, true
+}
+
+// SetEvictCallback 设置元素被淘汰、替换或清理时的回调，用于释放元素持有的资源
+func (c *LRUCache[T]) SetEvictCallback(fn func(key string, value T)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvict = fn
+}
+
 // Purge 清理所有缓存
 func (c *LRUCache[T]) Purge() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.onEvict != nil {
+		for n := c.head.next; n != c.tail; n = n.next {
+			c.onEvict(n.key, n.value)
+		}
+	}
 	// 用初始容量重新创建map（或保留原容量，根据需求选择）
 	c.cache = make(map[string]*node[T], len(c.cache))
 	c.head.next = c.tail
@@ -237,3 +237,36 @@ func TestNewLRUCacheLenAndMaxCapacity(t *testing.T) {
 		t.Errorf("MaxCapacity() = %d, 期望 %d", got, maxCapacity)
 	}
 }
+
+// TestLRUCacheRemoveAndEvictCallback 验证 Remove 不触发回调，淘汰、替换及清理时触发回调
+func TestLRUCacheRemoveAndEvictCallback(t *testing.T) {
+	cache := NewLRUCache[int](0, 2)
+	evicted := map[string]int{}
+	cache.SetEvictCallback(func(key string, value int) {
+		evicted[key] = value
+	})
+
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	if v, ok := cache.Remove("a"); !ok || v != 1 {
+		t.Errorf("Remove(a) = %d, %v, 期望 1, true", v, ok)
+	}
+	if _, ok := cache.Remove("a"); ok {
+		t.Errorf("Remove(a) 重复移除应返回 false")
+	}
+	if len(evicted) != 0 || cache.Len() != 1 {
+		t.Errorf("Remove 不应触发回调, evicted=%v len=%d", evicted, cache.Len())
+	}
+
+	cache.Put("b", 3)
+	cache.Put("c", 4)
+	cache.Put("d", 5)
+	if evicted["b"] != 3 || len(evicted) != 1 {
+		t.Errorf("替换及淘汰回调不正确: %v", evicted)
+	}
+
+	cache.Purge()
+	if evicted["c"] != 4 || evicted["d"] != 5 || cache.Len() != 0 {
+		t.Errorf("Purge 回调不正确: %v", evicted)
+	}
+}
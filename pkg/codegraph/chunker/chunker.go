@@ -0,0 +1,291 @@
+package chunker
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+const (
+	// DefaultMaxChunkSize 单个分块的最大字节数，超过时按语句拆分
+	DefaultMaxChunkSize = 2000
+	blockKind           = "block"
+	symbolSeparator     = "."
+)
+
+// 作为分块边界的定义类型
+var unitTypes = map[string]struct{}{
+	string(types.ElementTypeFunction):  {},
+	string(types.ElementTypeMethod):    {},
+	string(types.ElementTypeClass):     {},
+	string(types.ElementTypeStruct):    {},
+	string(types.ElementTypeInterface): {},
+	string(types.ElementTypeEnum):      {},
+	string(types.ElementTypeTrait):     {},
+	string(types.ElementTypeUnion):     {},
+}
+
+// Options 分块参数
+type Options struct {
+	MaxChunkSize int
+}
+
+// Chunk 与函数、类、方法边界对齐的代码块
+type Chunk struct {
+	FilePath string `json:"filePath"`
+	Language string `json:"language"`
+	// SymbolPath 分块所属的符号，如 UserService.GetUser，文件级代码为空
+	SymbolPath string `json:"symbolPath"`
+	// Symbols 分块内完整包含的符号
+	Symbols []string `json:"symbols,omitempty"`
+	// Kind 所属符号的类型，如 definition.method，不属于任何符号时为 block
+	Kind string `json:"kind"`
+	// Range startLine, startColumn, endLine, endColumn，从 0 开始
+	Range     []int32 `json:"range"`
+	StartByte uint    `json:"startByte"`
+	EndByte   uint    `json:"endByte"`
+	Content   string  `json:"-"`
+}
+
+// unit 作为分块边界的定义节点
+type unit struct {
+	kind       string
+	path       string
+	start, end uint
+	parent     *unit
+}
+
+// segment 展开后的最小片段，owner 为被拆分的外层定义，只有 owner 相同的片段才能合并
+type segment struct {
+	start, end           uint
+	startPoint, endPoint sitter.Point
+	owner                *unit
+}
+
+// Chunker 基于 tree-sitter 语法树的分块器，复用 SourceFileParser 的 BaseQueries 识别定义
+type Chunker struct {
+	options Options
+}
+
+// NewChunker 创建分块器
+func NewChunker(options Options) *Chunker {
+	if options.MaxChunkSize <= 0 {
+		options.MaxChunkSize = DefaultMaxChunkSize
+	}
+	return &Chunker{options: options}
+}
+
+// Chunk 对源文件分块。定义不超过上限时整体作为一块，相邻的小定义合并；
+// 超过上限的定义按子节点（语句）递归拆分
+func (c *Chunker) Chunk(ctx context.Context, sourceFile *types.SourceFile) ([]*Chunk, error) {
+	// 单文件组件（.vue）的每个 <script> 块按其 lang 分块，其余文件为整个文件
+	sources, err := lang.ScriptSources(sourceFile.Path, sourceFile.Content)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]*Chunk, 0)
+	for _, source := range sources {
+		sourceChunks, err := c.chunkSource(ctx, sourceFile.Path, source)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, sourceChunks...)
+	}
+	return chunks, nil
+}
+
+// chunkSource 按 source 的语法解析并分块
+func (c *Chunker) chunkSource(ctx context.Context, path string, source *lang.ScriptSource) ([]*Chunk, error) {
+	langParser := source.Parser
+	baseQuery, ok := parser.BaseQueries[langParser.Language]
+	if !ok {
+		return nil, lang.ErrQueryNotFound
+	}
+
+	sitterParser := sitter.NewParser()
+	defer sitterParser.Close()
+	if err := sitterParser.SetLanguage(langParser.SitterLanguage()); err != nil {
+		return nil, err
+	}
+	content := source.Content
+	tree := sitterParser.Parse(content, nil)
+	if tree == nil {
+		return nil, fmt.Errorf("failed to parse file: %s", path)
+	}
+	defer tree.Close()
+
+	units, err := c.collectUnits(ctx, baseQuery, tree.RootNode(), content)
+	if err != nil {
+		return nil, err
+	}
+
+	// 根节点总是展开，避免分块包含文件首尾的空白
+	root := tree.RootNode()
+	var segments []*segment
+	for i := uint(0); i < root.ChildCount(); i++ {
+		segments = append(segments, c.expand(root.Child(i), nil, units)...)
+	}
+	chunks := make([]*Chunk, 0)
+	for _, group := range c.merge(segments) {
+		first, last := group[0], group[len(group)-1]
+		chunk := &Chunk{
+			FilePath: path,
+			Language: string(langParser.Language),
+			Range: []int32{int32(first.startPoint.Row), int32(first.startPoint.Column),
+				int32(last.endPoint.Row), int32(last.endPoint.Column)},
+			StartByte: first.start,
+			EndByte:   last.end,
+			Content:   string(content[first.start:last.end]),
+		}
+		annotate(chunk, first.owner, units)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// collectUnits 通过 BaseQueries 收集定义节点，并按包含关系生成符号路径
+func (c *Chunker) collectUnits(ctx context.Context, query *sitter.Query, root *sitter.Node,
+	content []byte) (map[[2]uint]*unit, error) {
+	captureNames := query.CaptureNames()
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	matches := qc.Matches(query, root, content)
+
+	var list []*unit
+	seen := make(map[[2]uint]*unit)
+	for {
+		if err := utils.CheckContextCanceled(ctx); err != nil {
+			return nil, err
+		}
+		match := matches.Next()
+		if match == nil {
+			break
+		}
+		var u *unit
+		var name string
+		for _, capture := range match.Captures {
+			captureName := captureNames[capture.Index]
+			if _, ok := unitTypes[captureName]; ok {
+				u = &unit{kind: captureName, start: capture.Node.StartByte(), end: capture.Node.EndByte()}
+			}
+		}
+		if u == nil {
+			continue
+		}
+		for _, capture := range match.Captures {
+			if captureNames[capture.Index] == u.kind+".name" {
+				name = capture.Node.Utf8Text(content)
+				break
+			}
+		}
+		key := [2]uint{u.start, u.end}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		u.path = name
+		seen[key] = u
+		list = append(list, u)
+	}
+
+	// 外层在前，通过栈确定父子关系
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].start != list[j].start {
+			return list[i].start < list[j].start
+		}
+		return list[i].end > list[j].end
+	})
+	var stack []*unit
+	for _, u := range list {
+		for len(stack) > 0 && stack[len(stack)-1].end < u.end {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			u.parent = stack[len(stack)-1]
+			if u.parent.path != "" && u.path != "" {
+				u.path = u.parent.path + symbolSeparator + u.path
+			}
+		}
+		stack = append(stack, u)
+	}
+	return seen, nil
+}
+
+// expand 将超过上限的节点展开为子节点，直到每个片段都不超过上限或无法再拆分
+func (c *Chunker) expand(node *sitter.Node, owner *unit, units map[[2]uint]*unit) []*segment {
+	start, end := node.StartByte(), node.EndByte()
+	if end-start <= uint(c.options.MaxChunkSize) || node.ChildCount() == 0 {
+		if start == end {
+			return nil
+		}
+		return []*segment{{start: start, end: end, startPoint: node.StartPosition(),
+			endPoint: node.EndPosition(), owner: owner}}
+	}
+	if u, ok := units[[2]uint{start, end}]; ok {
+		owner = u
+	}
+	var segments []*segment
+	for i := uint(0); i < node.ChildCount(); i++ {
+		segments = append(segments, c.expand(node.Child(i), owner, units)...)
+	}
+	return segments
+}
+
+// merge 贪心合并相邻且属于同一外层定义的片段，合并后不超过上限
+func (c *Chunker) merge(segments []*segment) [][]*segment {
+	var groups [][]*segment
+	var current []*segment
+	for _, s := range segments {
+		if len(current) > 0 && (current[0].owner != s.owner ||
+			s.end-current[0].start > uint(c.options.MaxChunkSize)) {
+			groups = append(groups, current)
+			current = nil
+		}
+		current = append(current, s)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// annotate 填充分块的符号信息。只包含一个完整定义时归属于该定义，否则归属于被拆分的外层定义
+func annotate(chunk *Chunk, owner *unit, units map[[2]uint]*unit) {
+	var contained []*unit
+	for _, u := range units {
+		if u.parent == owner && u.start >= chunk.StartByte && u.end <= chunk.EndByte {
+			contained = append(contained, u)
+		}
+	}
+	sort.Slice(contained, func(i, j int) bool {
+		return contained[i].start < contained[j].start
+	})
+	for _, u := range contained {
+		if u.path != "" {
+			chunk.Symbols = append(chunk.Symbols, u.path)
+		}
+	}
+
+	primary := owner
+	if len(contained) == 1 && isMostly(chunk, contained[0]) {
+		primary = contained[0]
+	}
+	chunk.Kind = blockKind
+	if primary != nil {
+		chunk.SymbolPath = primary.path
+		chunk.Kind = primary.kind
+	}
+}
+
+// isMostly 定义是否占据分块的主体（仅附带注释、空白等少量内容）
+func isMostly(chunk *Chunk, u *unit) bool {
+	rest := strings.TrimSpace(chunk.Content[:u.start-chunk.StartByte]) +
+		strings.TrimSpace(chunk.Content[u.end-chunk.StartByte:])
+	return len(rest) <= int(u.end-u.start)
+}
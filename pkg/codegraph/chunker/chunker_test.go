@@ -0,0 +1,133 @@
+package chunker
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/types"
+)
+
+const goSource = `package main
+
+import "fmt"
+
+func small() int {
+	return 1
+}
+
+func tiny() int {
+	return 2
+}
+
+func large(n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		total += i
+	}
+	if total > 100 {
+		fmt.Println("large total", total)
+	}
+	for j := 0; j < n; j++ {
+		total -= j
+	}
+	fmt.Println("done", total)
+	return total
+}
+`
+
+func TestChunker_MergeSmallSiblings(t *testing.T) {
+	chunks, err := NewChunker(Options{}).Chunk(context.Background(),
+		&types.SourceFile{Path: "/repo/main.go", Content: []byte(goSource)})
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, "go", chunks[0].Language)
+	assert.Equal(t, blockKind, chunks[0].Kind)
+	assert.Equal(t, []string{"small", "tiny", "large"}, chunks[0].Symbols)
+	assert.Equal(t, []int32{0, 0, 25, 1}, chunks[0].Range)
+}
+
+func TestChunker_SplitLargeFunction(t *testing.T) {
+	chunks, err := NewChunker(Options{MaxChunkSize: 120}).Chunk(context.Background(),
+		&types.SourceFile{Path: "/repo/main.go", Content: []byte(goSource)})
+	assert.NoError(t, err)
+
+	var largeChunks []*Chunk
+	for _, c := range chunks {
+		assert.Equal(t, goSource[c.StartByte:c.EndByte], c.Content)
+		if c.SymbolPath == "large" {
+			largeChunks = append(largeChunks, c)
+			assert.Equal(t, "definition.function", c.Kind)
+		}
+	}
+	// 超过上限的函数按语句拆分
+	assert.Greater(t, len(largeChunks), 1)
+	assert.True(t, strings.HasPrefix(largeChunks[0].Content, "func large(n int) int {"))
+	assert.True(t, strings.HasSuffix(largeChunks[len(largeChunks)-1].Content, "}"))
+	// 函数边界对齐：没有分块同时包含 tiny 和 large 的内容
+	for _, c := range chunks {
+		assert.False(t, strings.Contains(c.Content, "tiny") && strings.Contains(c.Content, "total"))
+	}
+}
+
+func TestChunker_SymbolPath(t *testing.T) {
+	source := `public class UserService {
+    public String getName(int id) {
+        String name = "user-" + id;
+        return name.trim();
+    }
+
+    public int getAge(int id) {
+        int age = id * 2;
+        return age + 1;
+    }
+}
+`
+	chunks, err := NewChunker(Options{MaxChunkSize: 100}).Chunk(context.Background(),
+		&types.SourceFile{Path: "/repo/UserService.java", Content: []byte(source)})
+	assert.NoError(t, err)
+
+	paths := make(map[string]string)
+	for _, c := range chunks {
+		paths[c.SymbolPath] = c.Kind
+	}
+	assert.Equal(t, "definition.method", paths["UserService.getName"])
+	assert.Equal(t, "definition.method", paths["UserService.getAge"])
+}
+
+func TestChunker_UnsupportedFile(t *testing.T) {
+	_, err := NewChunker(Options{}).Chunk(context.Background(),
+		&types.SourceFile{Path: "/repo/README.md", Content: []byte("# readme")})
+	assert.Error(t, err)
+}
+
+func TestChunker_VueScriptBlocks(t *testing.T) {
+	source := `<template>
+  <div class="card">{{ user.name }}</div>
+</template>
+
+<script setup lang="ts">
+interface User {
+  name: string
+}
+
+function formatUser(user: User): string {
+  return user.name
+}
+</script>
+`
+	chunks, err := NewChunker(Options{}).Chunk(context.Background(),
+		&types.SourceFile{Path: "/repo/UserCard.vue", Content: []byte(source)})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, chunks) {
+		for _, c := range chunks {
+			// 只对 <script> 块分块，按 lang 使用 TypeScript 语法
+			assert.Equal(t, "typescript", c.Language)
+			assert.Equal(t, source[c.StartByte:c.EndByte], c.Content)
+			assert.NotContains(t, c.Content, "<template>")
+		}
+		assert.Equal(t, []string{"User", "formatUser"}, chunks[0].Symbols)
+	}
+}
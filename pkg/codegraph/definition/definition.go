@@ -3,6 +3,7 @@ package definition
 import (
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/resolver"
 	"codebase-indexer/pkg/codegraph/types"
 	"context"
 	"fmt"
@@ -32,18 +33,41 @@ func (s *DefParser) Parse(ctx context.Context, codeFile *types.SourceFile, opts
 	if err != nil {
 		return nil, err
 	}
-	query, ok := parser.DefinitionQueries[langConf.Language]
+	// 单文件组件（.vue）的每个 <script> 块按其 lang 解析，其余文件为整个文件
+	sources, err := lang.ScriptSources(codeFile.Path, codeFile.Content)
+	if err != nil {
+		return nil, err
+	}
+	definitions := make([]*types.Definition, 0)
+	for _, source := range sources {
+		defs, err := s.parseSource(source, opts)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, defs...)
+	}
+
+	// 返回结构信息，包含处理后的定义
+	return &types.CodeDefinition{
+		Definitions: definitions,
+		Path:        codeFile.Path,
+		Language:    string(langConf.IndexLanguage()),
+	}, nil
+}
+
+// parseSource 按 source 的语法解析并执行定义查询
+func (s *DefParser) parseSource(source *lang.ScriptSource, opts ParseOptions) ([]*types.Definition, error) {
+	query, ok := parser.DefinitionQueries[source.Parser.Language]
 	if !ok {
 		return nil, lang.ErrQueryNotFound
 	}
 
 	sitterParser := sitter.NewParser()
 	defer sitterParser.Close()
-	sitterLanguage := langConf.SitterLanguage()
-	if err := sitterParser.SetLanguage(sitterLanguage); err != nil {
+	if err := sitterParser.SetLanguage(source.Parser.SitterLanguage()); err != nil {
 		return nil, err
 	}
-	code := codeFile.Content
+	code := source.Content
 	tree := sitterParser.Parse(code, nil)
 	if tree == nil {
 		return nil, fmt.Errorf("failed to parse file")
@@ -68,13 +92,7 @@ func (s *DefParser) Parse(ctx context.Context, codeFile *types.SourceFile, opts
 		}
 		definitions = append(definitions, def)
 	}
-
-	// 返回结构信息，包含处理后的定义
-	return &types.CodeDefinition{
-		Definitions: definitions,
-		Path:        codeFile.Path,
-		Language:    string(langConf.Language),
-	}, nil
+	return definitions, nil
 }
 
 // ProcessDefinitionNode provides shared functionality for processing structure matches
@@ -122,10 +140,16 @@ func (p *DefParser) ProcessDefinitionNode(match *sitter.QueryMatch, query *sitte
 		content = source[defNode.StartByte():defNode.EndByte()]
 	}
 
-	return &types.Definition{
+	def := &types.Definition{
 		Type:    defType,
 		Name:    nodeName,
 		Range:   []int32{int32(startLine), int32(startColumn), int32(endLine), int32(endColumn)},
 		Content: content,
-	}, nil
+	}
+	if doc := resolver.ExtractDocComment(nameNode, source); doc != nil {
+		def.Doc = doc.Doc
+		def.Decorators = doc.Decorators
+		def.Signature = doc.Signature
+	}
+	return def, nil
 }
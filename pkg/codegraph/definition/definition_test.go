@@ -114,3 +114,109 @@ var TestVar = "test"
 		assert.True(t, foundDefs[name], "definition %s was not found", name)
 	}
 }
+
+func TestParseDocComment(t *testing.T) {
+	code := []byte(`/**
+ * 计算总价
+ * @param items 商品列表
+ */
+export function total(items: Item[]): number {
+  return 0;
+}
+
+// Item 商品
+interface Item {
+  price: number;
+}
+`)
+	structure, err := NewDefinitionParser().Parse(context.Background(), &types.SourceFile{
+		Content: code,
+		Path:    "total.ts",
+	}, ParseOptions{})
+	assert.NoError(t, err)
+
+	defs := make(map[string]*types.Definition)
+	for _, def := range structure.Definitions {
+		defs[def.Name] = def
+	}
+	if assert.Contains(t, defs, "total") {
+		assert.Equal(t, "计算总价\n@param items 商品列表", defs["total"].Doc)
+		assert.Equal(t, "export function total(items: Item[]): number", defs["total"].Signature)
+	}
+	if assert.Contains(t, defs, "Item") {
+		assert.Equal(t, "Item 商品", defs["Item"].Doc)
+		assert.Equal(t, "interface Item", defs["Item"].Signature)
+	}
+}
+
+func TestParseTSXFileStructure(t *testing.T) {
+	code := []byte(`import React from "react";
+
+export interface ButtonProps {
+  label: string;
+}
+
+export function Button({ label }: ButtonProps) {
+  return <button>{label}</button>;
+}
+
+const App = () => <Button label="ok" />;
+`)
+	structure, err := NewDefinitionParser().Parse(context.Background(), &types.SourceFile{
+		Content: code,
+		Path:    "App.tsx",
+	}, ParseOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "typescript", structure.Language)
+
+	defs := make(map[string][]string)
+	for _, def := range structure.Definitions {
+		defs[def.Name] = append(defs[def.Name], def.Type)
+	}
+	assert.Equal(t, []string{"declaration.interface"}, defs["ButtonProps"])
+	assert.Equal(t, []string{"declaration.function"}, defs["Button"])
+	assert.Equal(t, []string{"declaration.let"}, defs["App"])
+	for name := range defs {
+		assert.NotContains(t, name, "import", "statement text should not be used as a name")
+	}
+}
+
+func TestParseVueFileStructure(t *testing.T) {
+	code := []byte(`<template>
+  <div class="card">{{ formatUser(user) }}</div>
+</template>
+
+<script setup lang="ts">
+interface User {
+  name: string
+}
+
+function formatUser(user: User): string {
+  return user.name
+}
+</script>
+
+<style scoped>
+.card { color: red; }
+</style>
+`)
+	structure, err := NewDefinitionParser().Parse(context.Background(), &types.SourceFile{
+		Content: code,
+		Path:    "UserCard.vue",
+	}, ParseOptions{})
+	assert.NoError(t, err)
+
+	// <script lang="ts"> 按 TypeScript 语法解析，模板及样式不参与解析
+	defs := make(map[string]*types.Definition)
+	for _, def := range structure.Definitions {
+		defs[def.Name] = def
+	}
+	if assert.Contains(t, defs, "User") {
+		assert.Equal(t, "declaration.interface", defs["User"].Type)
+		assert.Equal(t, []int32{5, 0, 7, 1}, defs["User"].Range)
+	}
+	if assert.Contains(t, defs, "formatUser") {
+		assert.Equal(t, "declaration.function", defs["formatUser"].Type)
+	}
+	assert.Len(t, defs, 2)
+}
@@ -32,6 +32,7 @@ const (
 	Go         Language = "go"
 	JavaScript Language = "javascript"
 	TypeScript Language = "typescript"
+	TSX        Language = "tsx"
 	Rust       Language = "rust"
 	C          Language = "c"
 	CPP        Language = "cpp"
@@ -47,6 +48,8 @@ type TreeSitterParser struct {
 	Language       Language
 	SitterLanguage func() *sitter.Language
 	SupportedExts  []string
+	// DialectOf 方言所属的语言，如 TSX 属于 TypeScript。方言使用自己的语法及查询解析，符号、索引按所属语言归类
+	DialectOf Language
 }
 
 // treeSitterParsers 定义了所有支持的语言配置
@@ -84,7 +87,15 @@ var treeSitterParsers = []*TreeSitterParser{
 		SitterLanguage: func() *sitter.Language {
 			return sitter.NewLanguage(sittertypescript.LanguageTypescript())
 		},
-		SupportedExts: []string{".ts", ".tsx"},
+		SupportedExts: []string{".ts"},
+	},
+	{
+		Language: TSX,
+		SitterLanguage: func() *sitter.Language {
+			return sitter.NewLanguage(sittertypescript.LanguageTSX())
+		},
+		SupportedExts: []string{".tsx"},
+		DialectOf:     TypeScript,
 	},
 	//{
 	//	Language: Rust,
@@ -163,7 +174,25 @@ func InferLanguage(path string) (Language, error) {
 	if langConf == nil {
 		return types.EmptyString, ErrLanguageParserNotFound
 	}
-	return langConf.Language, nil
+	return langConf.IndexLanguage(), nil
+}
+
+// IndexLanguage 符号、索引所属的语言，方言为其所属的语言
+func (p *TreeSitterParser) IndexLanguage() Language {
+	if p.DialectOf != types.EmptyString {
+		return p.DialectOf
+	}
+	return p.Language
+}
+
+// IndexLanguage 语法对应的索引语言，如 TSX 按 TypeScript 索引
+func IndexLanguage(language Language) Language {
+	for _, p := range treeSitterParsers {
+		if p.Language == language {
+			return p.IndexLanguage()
+		}
+	}
+	return language
 }
 
 func GetSitterParserByFilePath(path string) (*TreeSitterParser, error) {
@@ -0,0 +1,216 @@
+package lang
+
+import (
+	"bytes"
+	"codebase-indexer/pkg/codegraph/types"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const vueExt = ".vue"
+
+// SFCBlock 单文件组件的顶层块，Start、End 为块内容（不含标签）在文件中的字节偏移
+type SFCBlock struct {
+	Tag   string
+	Attrs string
+	Start int
+	End   int
+}
+
+// ScriptSource 文件中按同一语法解析的内容，Content 与原文件等长，解析结果的位置即为在原文件中的位置
+type ScriptSource struct {
+	Parser  *TreeSitterParser
+	Content []byte
+}
+
+var sfcLangAttrPattern = regexp.MustCompile(`(?i)\blang\s*=\s*["']?([\w-]+)`)
+
+// IsSFCFile 是否为单文件组件（.vue）
+func IsSFCFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), vueExt)
+}
+
+// IsSFCScriptLanguage 单文件组件的 <script> 块可能使用的语法
+func IsSFCScriptLanguage(language Language) bool {
+	return language == JavaScript || language == TypeScript || language == TSX
+}
+
+// ScriptSources 文件中需要解析的内容：普通文件为整个文件；单文件组件的每个 <script> 块按其 lang
+// 选择语法，块外的内容替换为空白，<template>、<style> 等块不解析
+func ScriptSources(path string, content []byte) ([]*ScriptSource, error) {
+	if !IsSFCFile(path) {
+		langParser, err := GetSitterParserByFilePath(path)
+		if err != nil {
+			return nil, err
+		}
+		return []*ScriptSource{{Parser: langParser, Content: content}}, nil
+	}
+	var sources []*ScriptSource
+	for _, block := range SplitSFCBlocks(content) {
+		if block.Tag != "script" {
+			continue
+		}
+		langParser, err := GetSitterParserByLanguage(block.ScriptLanguage())
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, &ScriptSource{
+			Parser:  langParser,
+			Content: MaskOutside(content, block.Start, block.End),
+		})
+	}
+	return sources, nil
+}
+
+// ScriptLanguage <script lang="ts"> 为 TypeScript，lang="tsx" 为 TSX，其余为 JavaScript
+func (b SFCBlock) ScriptLanguage() Language {
+	if m := sfcLangAttrPattern.FindStringSubmatch(b.Attrs); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "ts", "typescript":
+			return TypeScript
+		case "tsx":
+			return TSX
+		}
+	}
+	return JavaScript
+}
+
+// MaskOutside 将 [start, end) 之外的内容替换为空格，保留换行，使行列位置不变
+func MaskOutside(content []byte, start, end int) []byte {
+	masked := make([]byte, len(content))
+	for i, b := range content {
+		if (i < start || i >= end) && b != '\n' {
+			b = ' '
+		}
+		masked[i] = b
+	}
+	return masked
+}
+
+// SplitSFCBlocks 按顶层标签切分单文件组件，<template> 可以嵌套，<script>、<style> 及自定义块的内容为原始文本
+func SplitSFCBlocks(content []byte) []SFCBlock {
+	var blocks []SFCBlock
+	lower := asciiLower(content)
+	pos := 0
+	for pos < len(content) {
+		lt := bytes.IndexByte(content[pos:], '<')
+		if lt < 0 {
+			break
+		}
+		pos += lt
+		if bytes.HasPrefix(content[pos:], []byte("<!--")) {
+			end := bytes.Index(content[pos:], []byte("-->"))
+			if end < 0 {
+				break
+			}
+			pos += end + len("-->")
+			continue
+		}
+		tag := tagName(lower, pos+1)
+		if tag == types.EmptyString {
+			pos++
+			continue
+		}
+		openEnd := tagEnd(content, pos)
+		if openEnd < 0 {
+			break
+		}
+		block := SFCBlock{Tag: tag, Attrs: string(content[pos+1+len(tag) : openEnd]), Start: openEnd + 1}
+		if bytes.HasSuffix(bytes.TrimSpace(content[pos:openEnd]), []byte("/")) {
+			// 自闭合的块没有内容
+			pos = openEnd + 1
+			continue
+		}
+		if tag == "template" {
+			block.End = closingTemplate(lower, block.Start)
+		} else {
+			block.End = closingTag(lower, block.Start, tag)
+		}
+		blocks = append(blocks, block)
+		closeEnd := bytes.IndexByte(content[block.End:], '>')
+		if closeEnd < 0 {
+			break
+		}
+		pos = block.End + closeEnd + 1
+	}
+	return blocks
+}
+
+// asciiLower 只转换 ASCII 字母，保持字节偏移不变
+func asciiLower(content []byte) []byte {
+	lower := make([]byte, len(content))
+	for i, b := range content {
+		if b >= 'A' && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		lower[i] = b
+	}
+	return lower
+}
+
+// tagName 读取 pos 处的标签名，不是开始标签时返回空
+func tagName(lower []byte, pos int) string {
+	end := pos
+	for end < len(lower) && isTagNameByte(lower[end]) {
+		end++
+	}
+	if end == pos || lower[pos] < 'a' || lower[pos] > 'z' {
+		return types.EmptyString
+	}
+	return string(lower[pos:end])
+}
+
+func isTagNameByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '-' || b == '_' || b == '.'
+}
+
+// tagEnd 开始标签结束的 '>' 的位置，跳过引号中的内容
+func tagEnd(content []byte, pos int) int {
+	var quote byte
+	for i := pos; i < len(content); i++ {
+		switch b := content[i]; {
+		case quote != 0:
+			if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case b == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// closingTag 原始文本块的结束标签位置，没有时为文件末尾
+func closingTag(lower []byte, start int, tag string) int {
+	if end := bytes.Index(lower[start:], []byte("</"+tag)); end >= 0 {
+		return start + end
+	}
+	return len(lower)
+}
+
+// closingTemplate 与顶层 <template> 匹配的结束标签位置，内部可以嵌套 <template>
+func closingTemplate(lower []byte, start int) int {
+	open, closing := []byte("<template"), []byte("</template")
+	depth := 1
+	for pos := start; pos < len(lower); {
+		nextOpen := bytes.Index(lower[pos:], open)
+		nextClose := bytes.Index(lower[pos:], closing)
+		if nextClose < 0 {
+			break
+		}
+		if nextOpen >= 0 && nextOpen < nextClose {
+			depth++
+			pos += nextOpen + len(open)
+			continue
+		}
+		depth--
+		if depth == 0 {
+			return pos + nextClose
+		}
+		pos += nextClose + len(closing)
+	}
+	return len(lower)
+}
@@ -0,0 +1,67 @@
+package lang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSFC = `<template>
+  <div>
+    <template v-if="ready"><user-card /></template>
+    <Form.Item label="名称" />
+  </div>
+</template>
+
+<script lang="ts">
+export default { name: 'UserList' }
+</script>
+
+<script setup lang="ts">
+interface User {
+  name: string
+}
+</script>
+
+<style scoped>
+.card > .title { color: red; }
+</style>
+`
+
+func TestSplitSFCBlocks(t *testing.T) {
+	content := []byte(testSFC)
+	blocks := SplitSFCBlocks(content)
+	var tags []string
+	for _, b := range blocks {
+		tags = append(tags, b.Tag)
+	}
+	assert.Equal(t, []string{"template", "script", "script", "style"}, tags)
+	// 嵌套的 <template> 不结束顶层块
+	assert.Contains(t, string(content[blocks[0].Start:blocks[0].End]), "<Form.Item")
+	assert.Equal(t, "\nexport default { name: 'UserList' }\n", string(content[blocks[1].Start:blocks[1].End]))
+	assert.Equal(t, TypeScript, blocks[2].ScriptLanguage())
+	assert.Equal(t, JavaScript, SFCBlock{}.ScriptLanguage())
+}
+
+func TestScriptSources(t *testing.T) {
+	content := []byte(testSFC)
+	sources, err := ScriptSources("UserList.vue", content)
+	assert.NoError(t, err)
+	if assert.Len(t, sources, 2) {
+		for _, s := range sources {
+			assert.Equal(t, TypeScript, s.Parser.Language)
+			// 块外的内容替换为空白，位置与原文件一致
+			assert.Len(t, s.Content, len(content))
+			assert.NotContains(t, string(s.Content), "<template>")
+			assert.Equal(t, strings.Count(testSFC, "\n"), strings.Count(string(s.Content), "\n"))
+		}
+		assert.Contains(t, string(sources[1].Content), "interface User")
+	}
+
+	sources, err = ScriptSources("main.go", []byte("package main\n"))
+	assert.NoError(t, err)
+	if assert.Len(t, sources, 1) {
+		assert.Equal(t, Go, sources[0].Parser.Language)
+	}
+}
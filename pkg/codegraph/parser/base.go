@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"codebase-indexer/pkg/codegraph/cache"
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/textindex"
 	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/logger"
@@ -16,13 +18,19 @@ import (
 type SourceFileParser struct {
 	logger          logger.Logger
 	resolverManager *resolver.ResolverManager
+	trees           *cache.LRUCache[*cachedTree] // 最近解析文件的语法树，用于增量解析
 }
 
 func NewSourceFileParser(logger logger.Logger) *SourceFileParser {
 	resolveManager := resolver.NewResolverManager()
+	trees := cache.NewLRUCache[*cachedTree](treeCacheSize, treeCacheSize)
+	trees.SetEvictCallback(func(_ string, t *cachedTree) {
+		t.tree.Close()
+	})
 	return &SourceFileParser{
 		logger:          logger,
 		resolverManager: resolveManager,
+		trees:           trees,
 	}
 }
 
@@ -39,6 +47,11 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 		}
 	}()
 
+	// 单文件组件按 <script> 块分别解析
+	if lang.IsSFCFile(sourceFile.Path) {
+		return p.parseVue(ctx, sourceFile)
+	}
+
 	// Extract file extension
 	langParser, err := lang.GetSitterParserByFilePath(sourceFile.Path)
 	if err != nil {
@@ -60,7 +73,26 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 
 	defer tree.Close()
 
-	baseQuery, ok := BaseQueries[langParser.Language]
+	table, err := p.resolveTree(ctx, langParser.Language, tree, sourceFile, nil)
+	if err != nil {
+		return nil, err
+	}
+	collectTerms(table, tree, sourceFile)
+	return table, nil
+}
+
+// collectTerms 按需从语法树提取全文索引词项，避免全文索引再次解析文件
+func collectTerms(table *FileElementTable, tree *sitter.Tree, sourceFile *types.SourceFile) {
+	if sourceFile.CollectTerms {
+		table.Terms, table.TermCount = textindex.TokenizeTree(tree.RootNode(), sourceFile.Content)
+	}
+}
+
+// resolveTree 对语法树执行 base query 并解析匹配结果，span 不为空时只处理与其相交的匹配
+func (p *SourceFileParser) resolveTree(ctx context.Context, language lang.Language, tree *sitter.Tree,
+	sourceFile *types.SourceFile, span *byteSpan) (*FileElementTable, error) {
+	content := sourceFile.Content
+	baseQuery, ok := BaseQueries[language]
 	if !ok {
 		return nil, lang.ErrQueryNotFound
 	}
@@ -73,6 +105,9 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 
 	qc := sitter.NewQueryCursor()
 	defer qc.Close()
+	if span != nil {
+		qc.SetByteRange(span.startByte, span.endByte)
+	}
 	matches := qc.Matches(baseQuery, tree.RootNode(), content)
 
 	// 消费 matches，并调用 ProcessStructureMatch 处理匹配结果
@@ -83,7 +118,7 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 	elements := make([]resolver.Element, 0)
 	for {
 		// 统一的上下文取消检测函数
-		if err = utils.CheckContextCanceled(ctx); err != nil {
+		if err := utils.CheckContextCanceled(ctx); err != nil {
 			return nil, fmt.Errorf("tree_sitter base processor context canceled: %v", err)
 		}
 
@@ -92,7 +127,7 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 			break
 		}
 		// TODO Parent 、Children 关系处理。比如变量定义在函数中，函数定义在类中。
-		elems, err := p.processNode(ctx, langParser.Language, match, captureNames, sourceFile)
+		elems, err := p.processNode(ctx, language, match, captureNames, sourceFile)
 		// match.Remove()
 		if err != nil {
 			p.logger.Debug("tree_sitter base processor processNode error: %v", err)
@@ -128,7 +163,7 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 		Path:     sourceFile.Path,
 		Package:  sourcePackage,
 		Imports:  imports,
-		Language: langParser.Language,
+		Language: lang.IndexLanguage(language),
 		Elements: elements,
 	}, nil
 }
@@ -163,10 +198,60 @@ func (p *SourceFileParser) processNode(
 		p.logger.Debug("parse match err: %v", err)
 	}
 	resolvedElements = append(resolvedElements, elements...)
+	attachDocComment(match, captureNames, rootCaptureName, resolvedElements, sourceFile.Content)
 
 	return resolvedElements, nil
 }
 
+// 需要记录注释及签名的定义类型
+var docElementTypes = map[types.ElementType]struct{}{
+	types.ElementTypeFunction:  {},
+	types.ElementTypeMethod:    {},
+	types.ElementTypeClass:     {},
+	types.ElementTypeInterface: {},
+	types.ElementTypeStruct:    {},
+	types.ElementTypeEnum:      {},
+	types.ElementTypeTrait:     {},
+	types.ElementTypeUnion:     {},
+	types.ElementTypeTypedef:   {},
+	types.ElementTypeTypeAlias: {},
+}
+
+func needDocComment(element resolver.Element) bool {
+	if _, ok := docElementTypes[element.GetType()]; ok {
+		return true
+	}
+	// 局部变量不记录
+	return element.GetType() == types.ElementTypeVariable &&
+		element.GetScope() != types.ScopeFunction && element.GetScope() != types.ScopeBlock
+}
+
+// attachDocComment 为定义元素附加前导注释、装饰器及签名，名称节点取 <root>.name 捕获
+func attachDocComment(match *sitter.QueryMatch, captureNames []string, rootCaptureName string,
+	elements []resolver.Element, content []byte) {
+	var nameNode *sitter.Node
+	for _, capture := range match.Captures {
+		if captureNames[capture.Index] == rootCaptureName+".name" {
+			nameNode = &capture.Node
+			break
+		}
+	}
+	if nameNode == nil {
+		return
+	}
+	name := nameNode.Utf8Text(content)
+	var doc *resolver.DocComment
+	for _, e := range elements {
+		if e.GetName() != name || !needDocComment(e) {
+			continue
+		}
+		if doc == nil {
+			doc = resolver.ExtractDocComment(nameNode, content)
+		}
+		e.SetDocComment(doc)
+	}
+}
+
 func isSamePosition(source []int32, target []int32) bool {
 	if len(source) != len(target) {
 		return false
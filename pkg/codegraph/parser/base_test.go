@@ -266,3 +266,91 @@ func readFile(path string) []byte {
 // 		}
 // 	}
 // }
+
+func TestParseDocComment(t *testing.T) {
+	parser := NewSourceFileParser(initLogger())
+	testCases := []struct {
+		name       string
+		path       string
+		content    string
+		element    string
+		doc        string
+		decorators []string
+		signature  string
+	}{
+		{
+			name: "Go",
+			path: "doc.go",
+			content: `package doc
+
+var x = 1 // 行尾注释
+
+// Add 求和
+// 返回 a+b
+func Add(a int,
+	b int) int {
+	return a + b
+}
+`,
+			element:   "Add",
+			doc:       "Add 求和\n返回 a+b",
+			signature: "func Add(a int, b int) int",
+		},
+		{
+			name: "Java",
+			path: "Doc.java",
+			content: `public class Doc {
+    /**
+     * 获取名称
+     * @param id 编号
+     */
+    @Override
+    @Deprecated(since = "1")
+    public String getName(int id) {
+        return "";
+    }
+}
+`,
+			element:    "getName",
+			doc:        "获取名称\n@param id 编号",
+			decorators: []string{"@Override", `@Deprecated(since = "1")`},
+			signature:  `@Override @Deprecated(since = "1") public String getName(int id)`,
+		},
+		{
+			name: "Python",
+			path: "doc.py",
+			content: `class Service:
+    # 不属于方法的注释
+
+    @staticmethod
+    def load(path: str) -> dict:
+        """加载配置
+
+        path: 文件路径
+        """
+        return {}
+`,
+			element:    "load",
+			doc:        "加载配置\n\npath: 文件路径",
+			decorators: []string{"@staticmethod"},
+			signature:  "@staticmethod def load(path: str) -> dict",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parser.Parse(context.Background(), &types.SourceFile{Path: tt.path, Content: []byte(tt.content)})
+			assert.NoError(t, err)
+			var doc *resolver.DocComment
+			for _, e := range res.Elements {
+				if e.GetName() == tt.element && e.GetDocComment() != nil {
+					doc = e.GetDocComment()
+				}
+			}
+			if assert.NotNil(t, doc) {
+				assert.Equal(t, tt.doc, doc.Doc)
+				assert.Equal(t, tt.decorators, doc.Decorators)
+				assert.Equal(t, tt.signature, doc.Signature)
+			}
+		})
+	}
+}
@@ -13,6 +13,10 @@ type FileElementTable struct {
 	Imports   []*resolver.Import
 	Language  lang.Language
 	Elements  []resolver.Element
+	Origin    types.FileOrigin
+	// Terms 全文索引词项及词频，TermCount 为词项总数，仅 SourceFile.CollectTerms 时填充
+	Terms     map[string]int
+	TermCount int
 }
 
 func newRootElement(elementTypeValue string, rootIndex uint32) resolver.Element {
@@ -109,6 +113,9 @@ func newRootElement(elementTypeValue string, rootIndex uint32) resolver.Element
 	case types.ElementTypeEnumConstant:
 		base.Type = types.ElementTypeVariable
 		return &resolver.Variable{BaseElement: base}
+	case types.ElementTypeReference:
+		base.Type = types.ElementTypeReference
+		return &resolver.Reference{BaseElement: base}
 	default:
 		// base.Type = types.ElementTypeUndefined
 		base.Type = types.ElementType(elementTypeValue)
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"bytes"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"math"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	originSampleSize     = 64 * 1024 // 内容启发式只检查文件开头的部分
+	originHeaderSize     = 2 * 1024  // 生成标记所在的文件头
+	minifiedMinSize      = 1024      // 过小的文件不按内容判断
+	minifiedLineLength   = 500       // 平均行长超过该值，不是手写的代码
+	denseLineLength      = 110       // 平均行长超过该值且几乎没有空白，为压缩的代码
+	denseWhitespaceRatio = 0.08
+	dataLineLength       = 1000 // 超长行的熵接近 base64 时，为嵌入的编码数据
+	dataEntropy          = 5.5  // 比特/字节，源码通常在 4.5~5.2
+)
+
+// generatedHeaderPattern 生成工具写入的文件头注释，标记须位于注释行开头，如 Go 的 Code generated ... DO NOT EDIT.、
+// protoc 的 Generated by the protocol buffer compiler. DO NOT EDIT!、@generated、C# 的 <auto-generated>
+var generatedHeaderPattern = regexp.MustCompile(`(?im)^\s*(?://+|#+|/\*+|\*+|--)?\s*` +
+	`(?:code generated\b.*\bdo not edit|generated by\b.*\bdo not edit|@generated\b|<auto-generated|auto-?generated file)`)
+
+// generatedFileSuffixes 生成代码的文件名后缀，参考 linguist 的 generated.rb
+var generatedFileSuffixes = []string{
+	".pb.go", ".pb.gw.go", "_grpc.pb.go", ".pb.validate.go", "_generated.go",
+	".pb.h", ".pb.cc", ".pb.c", "_pb2.py", "_pb2_grpc.py", "_pb2.pyi",
+	"_pb.js", "_pb.d.ts", "_grpc_pb.js", "_grpc_pb.d.ts", ".generated.ts", ".generated.js",
+	".designer.cs", ".g.cs", ".g.i.cs",
+}
+
+// generatedFilePrefixes 生成代码的文件名前缀
+var generatedFilePrefixes = []string{"zz_generated."}
+
+// minifiedFileSuffixes 压缩、打包后的文件名后缀
+var minifiedFileSuffixes = []string{".min.js", "-min.js", ".min.mjs", ".min.cjs", ".bundle.js", ".min.css"}
+
+// vendoredDirs 第三方代码所在的目录，参考 linguist 的 vendor.yml
+var vendoredDirs = map[string]bool{
+	"vendor": true, "vendors": true, "third_party": true, "third-party": true, "thirdparty": true,
+	"node_modules": true, "bower_components": true, "jspm_packages": true, "Godeps": true,
+	"site-packages": true, "dist-packages": true, "Pods": true, "Carthage": true,
+}
+
+// ClassifyFile 按路径规则、生成标记、行长及熵判断文件来源
+func ClassifyFile(filePath string, content []byte) types.FileOrigin {
+	slashed := filepath.ToSlash(filePath)
+	dir, base := path.Split(slashed)
+	lowerBase := strings.ToLower(base)
+	for _, s := range minifiedFileSuffixes {
+		if strings.HasSuffix(lowerBase, s) {
+			return types.FileOriginMinified
+		}
+	}
+	for _, s := range generatedFileSuffixes {
+		if strings.HasSuffix(lowerBase, s) {
+			return types.FileOriginGenerated
+		}
+	}
+	for _, p := range generatedFilePrefixes {
+		if strings.HasPrefix(lowerBase, p) {
+			return types.FileOriginGenerated
+		}
+	}
+	header := content
+	if len(header) > originHeaderSize {
+		header = header[:originHeaderSize]
+	}
+	if generatedHeaderPattern.Match(header) {
+		return types.FileOriginGenerated
+	}
+	for _, seg := range strings.Split(dir, types.Slash) {
+		if vendoredDirs[seg] {
+			return types.FileOriginVendored
+		}
+	}
+	return classifyContent(content)
+}
+
+// classifyContent 按行长、空白比例及熵判断压缩的代码和嵌入的编码数据
+func classifyContent(content []byte) types.FileOrigin {
+	if len(content) < minifiedMinSize {
+		return types.FileOriginSource
+	}
+	sample := content
+	if len(sample) > originSampleSize {
+		sample = sample[:originSampleSize]
+	}
+	lines := bytes.Count(sample, []byte(types.LF)) + 1
+	avgLineLength := len(sample) / lines
+	if avgLineLength >= minifiedLineLength {
+		return types.FileOriginMinified
+	}
+	if avgLineLength >= denseLineLength && whitespaceRatio(sample) < denseWhitespaceRatio {
+		return types.FileOriginMinified
+	}
+	var longest []byte
+	for _, line := range bytes.Split(sample, []byte(types.LF)) {
+		if len(line) > len(longest) {
+			longest = line
+		}
+	}
+	if len(longest) >= dataLineLength && entropy(longest) >= dataEntropy {
+		return types.FileOriginGenerated
+	}
+	return types.FileOriginSource
+}
+
+func whitespaceRatio(content []byte) float64 {
+	var n int
+	for _, b := range content {
+		if b == ' ' || b == '\t' || b == '\n' || b == '\r' {
+			n++
+		}
+	}
+	return float64(n) / float64(len(content))
+}
+
+// entropy 字节的香农熵，比特/字节
+func entropy(content []byte) float64 {
+	var counts [256]int
+	for _, b := range content {
+		counts[b]++
+	}
+	var e float64
+	total := float64(len(content))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / total
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// ApplyOrigin 记录文件来源，生成、第三方的文件只保留定义，去掉调用与引用
+func (t *FileElementTable) ApplyOrigin(origin types.FileOrigin) {
+	t.Origin = origin
+	if !origin.DefinitionOnly() {
+		return
+	}
+	elements := t.Elements[:0]
+	for _, e := range t.Elements {
+		if isDefinitionElement(e) {
+			elements = append(elements, e)
+		}
+	}
+	t.Elements = elements
+}
+
+func isDefinitionElement(e resolver.Element) bool {
+	switch e.GetType() {
+	case types.ElementTypeClass, types.ElementTypeInterface, types.ElementTypeMethod,
+		types.ElementTypeFunction, types.ElementTypeVariable:
+		return true
+	}
+	return false
+}
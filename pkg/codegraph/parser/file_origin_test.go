@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"encoding/base64"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFile(t *testing.T) {
+	source := strings.Repeat("func add(a, b int) int {\n\treturn a + b\n}\n\n", 50)
+	random := make([]byte, 3000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    types.FileOrigin
+	}{
+		{name: "源码", path: "/repo/pkg/math/add.go", content: source, want: types.FileOriginSource},
+		{name: "protobuf 生成", path: "/repo/pkg/proto/file.pb.go", content: source, want: types.FileOriginGenerated},
+		{name: "python protobuf 生成", path: "/repo/api/service_pb2.py", want: types.FileOriginGenerated},
+		{name: "k8s 生成", path: "/repo/api/zz_generated.deepcopy.go", want: types.FileOriginGenerated},
+		{
+			name:    "Go 生成标记",
+			path:    "/repo/mocks/mock_store.go",
+			content: "// Code generated by MockGen. DO NOT EDIT.\n// Source: store.go\n\npackage mocks\n",
+			want:    types.FileOriginGenerated,
+		},
+		{
+			name:    "protoc 生成标记",
+			path:    "/repo/gen/message.h",
+			content: "// Generated by the protocol buffer compiler.  DO NOT EDIT!\n// source: message.proto\n",
+			want:    types.FileOriginGenerated,
+		},
+		{name: "@generated 标记", path: "/repo/web/schema.ts", content: "/**\n * @generated\n */\nexport type A = {}\n", want: types.FileOriginGenerated},
+		{
+			name:    "注释中提到生成标记",
+			path:    "/repo/tools/lint.go",
+			content: "package tools\n\n// isGenerated 检查 \"Code generated ... DO NOT EDIT.\" 标记\nfunc isGenerated() {}\n",
+			want:    types.FileOriginSource,
+		},
+		{name: "第三方目录", path: "/repo/third_party/zlib/zlib.c", content: source, want: types.FileOriginVendored},
+		{name: "压缩文件名", path: "/repo/static/jquery.min.js", content: source, want: types.FileOriginMinified},
+		{
+			name:    "压缩内容",
+			path:    "/repo/static/app.js",
+			content: strings.Repeat("var a=function(b,c){return b+c};a(1,2);", 100),
+			want:    types.FileOriginMinified,
+		},
+		{
+			name:    "嵌入编码数据",
+			path:    "/repo/assets/bindata.go",
+			content: source + "var data = \"" + base64.StdEncoding.EncodeToString(random) + "\"\n" + source,
+			want:    types.FileOriginGenerated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyFile(tt.path, []byte(tt.content)))
+		})
+	}
+}
+
+func TestFileElementTable_ApplyOrigin(t *testing.T) {
+	newTable := func() *FileElementTable {
+		return &FileElementTable{Elements: []resolver.Element{
+			&resolver.Function{BaseElement: &resolver.BaseElement{Name: "Get", Type: types.ElementTypeFunction}},
+			&resolver.Call{BaseElement: &resolver.BaseElement{Name: "Marshal", Type: types.ElementTypeFunctionCall}},
+			&resolver.Reference{BaseElement: &resolver.BaseElement{Name: "Message", Type: types.ElementTypeReference}},
+			&resolver.Class{BaseElement: &resolver.BaseElement{Name: "Message", Type: types.ElementTypeClass}},
+		}}
+	}
+
+	table := newTable()
+	table.ApplyOrigin(types.FileOriginSource)
+	assert.Len(t, table.Elements, 4)
+
+	table = newTable()
+	table.ApplyOrigin(types.FileOriginGenerated)
+	assert.Equal(t, types.FileOriginGenerated, table.Origin)
+	var names []string
+	for _, e := range table.Elements {
+		names = append(names, e.GetName())
+	}
+	assert.Equal(t, []string{"Get", "Message"}, names)
+}
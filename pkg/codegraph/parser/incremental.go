@@ -0,0 +1,599 @@
+package parser
+
+import (
+	"bytes"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// 缓存语法树的文件数
+const treeCacheSize = 64
+
+// 需要重新解析的范围超过文件内容的该比例时直接全量解析
+const maxIncrementalSpanRatio = 0.5
+
+// cachedTree 文件最近一次解析的内容、语法树及解析结果，缓存期间语法树归缓存所有
+type cachedTree struct {
+	hash     [sha256.Size]byte
+	language lang.Language
+	content  []byte
+	tree     *sitter.Tree
+	table    *FileElementTable
+}
+
+// byteSpan 需要重新解析元素的范围
+type byteSpan struct {
+	startByte  uint
+	endByte    uint
+	startPoint sitter.Point
+	endPoint   sitter.Point
+}
+
+func (s *byteSpan) widen(startByte, endByte uint, startPoint, endPoint sitter.Point) {
+	if startByte < s.startByte {
+		s.startByte, s.startPoint = startByte, startPoint
+	}
+	if endByte > s.endByte {
+		s.endByte, s.endPoint = endByte, endPoint
+	}
+}
+
+func (s *byteSpan) widenNode(n *sitter.Node) {
+	s.widen(n.StartByte(), n.EndByte(), n.StartPosition(), n.EndPosition())
+}
+
+// ParseIncremental 解析频繁修改的文件：内容未变化时复用上次的结果；内容变化时以上次的语法树为基础增量解析，
+// 只重新解析变更范围内的元素，范围外的元素平移位置后复用。首次解析的文件按 Parse 全量解析并缓存语法树
+func (p *SourceFileParser) ParseIncremental(ctx context.Context,
+	sourceFile *types.SourceFile) (result *FileElementTable, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			err = fmt.Errorf("panic during parsing file %s: %v\nStack trace:\n%s",
+				sourceFile.Path, r, string(stack))
+			p.logger.Error("Parse panic recovered: %v", err)
+			result = nil
+		}
+	}()
+
+	// 单文件组件由多个块组成，不缓存语法树，每次全量解析
+	if lang.IsSFCFile(sourceFile.Path) {
+		return p.parseVue(ctx, sourceFile)
+	}
+
+	langParser, err := lang.GetSitterParserByFilePath(sourceFile.Path)
+	if err != nil {
+		return nil, err
+	}
+	content := sourceFile.Content
+	hash := sha256.Sum256(content)
+
+	// 取出缓存的语法树，解析期间由当前调用独占
+	prev, cached := p.trees.Remove(sourceFile.Path)
+	if cached && prev.language != langParser.Language {
+		prev.tree.Close()
+		cached = false
+	}
+	if cached && prev.hash == hash {
+		// 放回缓存后语法树可能被淘汰关闭，先提取词项
+		res := prev.table.clone()
+		collectTerms(res, prev.tree, sourceFile)
+		p.trees.Put(sourceFile.Path, prev)
+		return res, nil
+	}
+
+	sitterParser := sitter.NewParser()
+	defer sitterParser.Close()
+	if err := sitterParser.SetLanguage(langParser.SitterLanguage()); err != nil {
+		return nil, err
+	}
+
+	var oldTree *sitter.Tree
+	var edit *sitter.InputEdit
+	if cached {
+		defer prev.tree.Close()
+		edit = diffEdit(prev.content, content)
+		prev.tree.Edit(edit)
+		oldTree = prev.tree
+	}
+	tree := sitterParser.Parse(content, oldTree)
+	if tree != nil && cached && tree.RootNode().HasError() {
+		// 错误恢复的结果与是否复用旧语法树有关，含语法错误时重新全量解析，与 Parse 的结果保持一致
+		tree.Close()
+		tree, cached = sitterParser.Parse(content, nil), false
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("failed to parse file: %s", sourceFile.Path)
+	}
+
+	var table *FileElementTable
+	if cached {
+		table, err = p.reparse(ctx, langParser.Language, prev, tree, edit, sourceFile)
+	} else {
+		table, err = p.resolveTree(ctx, langParser.Language, tree, sourceFile, nil)
+	}
+	if err != nil {
+		tree.Close()
+		return nil, err
+	}
+	res := table.clone()
+	collectTerms(res, tree, sourceFile)
+	p.trees.Put(sourceFile.Path, &cachedTree{
+		hash:     hash,
+		language: langParser.Language,
+		content:  content,
+		tree:     tree,
+		table:    table,
+	})
+	return res, nil
+}
+
+// reparse 重新解析变更范围内的元素，与编辑前范围外的元素合并
+func (p *SourceFileParser) reparse(ctx context.Context, language lang.Language, prev *cachedTree, tree *sitter.Tree,
+	edit *sitter.InputEdit, sourceFile *types.SourceFile) (*FileElementTable, error) {
+	content := sourceFile.Content
+	span := changedSpan(prev.tree, tree, edit)
+	for span != nil && float64(span.endByte-span.startByte) <= maxIncrementalSpanRatio*float64(len(content)) {
+		fresh, err := p.resolveTree(ctx, language, tree, sourceFile, span)
+		if err != nil {
+			return nil, err
+		}
+		// 元素的匹配可能依赖范围外的节点（如所属类的名称），范围需要完整包含与之相交的元素
+		if coverElements(span, content, prev.table, fresh, edit) {
+			continue
+		}
+		if merged, ok := mergeTables(prev.table, fresh, edit, span); ok {
+			return merged, nil
+		}
+		break
+	}
+	return p.resolveTree(ctx, language, tree, sourceFile, nil)
+}
+
+// coverElements 扩大范围使其完整包含与之相交的元素，编辑前的元素按编辑平移位置，范围扩大时返回 true
+func coverElements(span *byteSpan, content []byte, prev, fresh *FileElementTable, edit *sitter.InputEdit) bool {
+	var ranges [][]int32
+	for _, imp := range prev.Imports {
+		ranges = append(ranges, clampRange(imp.GetRange(), edit))
+	}
+	for _, e := range prev.Elements {
+		ranges = append(ranges, clampRange(e.GetRange(), edit))
+	}
+	for _, imp := range fresh.Imports {
+		ranges = append(ranges, imp.GetRange())
+	}
+	for _, e := range fresh.Elements {
+		ranges = append(ranges, e.GetRange())
+	}
+	grown := false
+	for _, rng := range ranges {
+		if len(rng) < 4 || !overlapsSpan(rng, span) {
+			continue
+		}
+		start := sitter.Point{Row: uint(rng[0]), Column: uint(rng[1])}
+		end := sitter.Point{Row: uint(rng[2]), Column: uint(rng[3])}
+		if pointLE(span.startPoint, start) && pointLE(end, span.endPoint) {
+			continue
+		}
+		startByte, ok := byteAt(content, start)
+		if !ok {
+			continue
+		}
+		endByte, ok := byteAt(content, end)
+		if !ok {
+			continue
+		}
+		span.widen(startByte, endByte, start, end)
+		grown = true
+	}
+	return grown
+}
+
+// clampRange 按编辑平移范围，位于编辑内的端点移到编辑的新终点
+func clampRange(rng []int32, edit *sitter.InputEdit) []int32 {
+	if len(rng) < 4 {
+		return rng
+	}
+	res := make([]int32, 0, 4)
+	for _, p := range []sitter.Point{{Row: uint(rng[0]), Column: uint(rng[1])}, {Row: uint(rng[2]), Column: uint(rng[3])}} {
+		switch {
+		case pointLE(edit.OldEndPosition, p):
+			p = shiftPoint(p, edit)
+		case pointLE(edit.StartPosition, p):
+			p = edit.NewEndPosition
+		}
+		res = append(res, int32(p.Row), int32(p.Column))
+	}
+	return res
+}
+
+// byteAt 行列对应的字节偏移，列为行内字节偏移
+func byteAt(content []byte, p sitter.Point) (uint, bool) {
+	var row uint
+	offset := 0
+	for row < p.Row {
+		idx := bytes.IndexByte(content[offset:], '\n')
+		if idx < 0 {
+			return 0, false
+		}
+		offset += idx + 1
+		row++
+	}
+	if offset+int(p.Column) > len(content) {
+		return 0, false
+	}
+	return uint(offset) + p.Column, true
+}
+
+// diffEdit 比较新旧内容的公共前缀和后缀，得到一次编辑
+func diffEdit(oldContent, newContent []byte) *sitter.InputEdit {
+	prefix := 0
+	for prefix < len(oldContent) && prefix < len(newContent) && oldContent[prefix] == newContent[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldContent)-prefix && suffix < len(newContent)-prefix &&
+		oldContent[len(oldContent)-1-suffix] == newContent[len(newContent)-1-suffix] {
+		suffix++
+	}
+	oldEnd, newEnd := len(oldContent)-suffix, len(newContent)-suffix
+	return &sitter.InputEdit{
+		StartByte:      uint(prefix),
+		OldEndByte:     uint(oldEnd),
+		NewEndByte:     uint(newEnd),
+		StartPosition:  pointAt(newContent, prefix),
+		OldEndPosition: pointAt(oldContent, oldEnd),
+		NewEndPosition: pointAt(newContent, newEnd),
+	}
+}
+
+// pointAt 字节偏移对应的行列，列为行内字节偏移
+func pointAt(content []byte, offset int) sitter.Point {
+	var row, lineStart int
+	for i := 0; i < offset; i++ {
+		if content[i] == '\n' {
+			row++
+			lineStart = i + 1
+		}
+	}
+	return sitter.Point{Row: uint(row), Column: uint(offset - lineStart)}
+}
+
+// changedSpan 计算需要重新解析的范围：编辑及语法树变化的范围，扩大到包含它的语句或成员定义；
+// 定义头部（名称、参数、继承等）变化时扩大到整个定义，因为其成员的所属类型等信息依赖头部；
+// 并包含紧随其后的注释、装饰器及定义，因为该定义的前导注释、装饰器可能变化。需要全量解析时返回 nil
+func changedSpan(oldTree, newTree *sitter.Tree, edit *sitter.InputEdit) *byteSpan {
+	changed := &byteSpan{
+		startByte: edit.StartByte, endByte: edit.NewEndByte,
+		startPoint: edit.StartPosition, endPoint: edit.NewEndPosition,
+	}
+	for _, r := range oldTree.ChangedRanges(newTree) {
+		changed.widen(r.StartByte, r.EndByte, r.StartPoint, r.EndPoint)
+	}
+	root := newTree.RootNode()
+	if oldTree.RootNode().HasError() {
+		// 编辑前含语法错误时，修复错误可能改变编辑范围外的结构
+		return nil
+	}
+	node := root.NamedDescendantForByteRange(changed.startByte, changed.endByte)
+	if node == nil {
+		return nil
+	}
+	target := node
+	for n := node; n != nil; n = n.Parent() {
+		body := n.ChildByFieldName("body")
+		if body != nil && (changed.startByte < body.StartByte() || changed.endByte > body.EndByte()) {
+			target = n
+		}
+	}
+
+	span := *changed
+	// C++ 成员的可见性取决于其前最近的访问说明符，访问说明符变化时重新解析整个类体
+	if fields := fieldList(node); fields != nil {
+		oldFields := fieldList(oldTree.RootNode().NamedDescendantForByteRange(edit.StartByte, edit.NewEndByte))
+		if hasAccessSpecifier(fields) || (oldFields != nil && hasAccessSpecifier(oldFields)) {
+			span.widenNode(fields)
+		}
+	}
+	if target.Id() == node.Id() && (node.Id() == root.Id() || resolver.IsContainerKind(node.Kind())) {
+		// 变更位于容器的子节点之间（如空行、注释），取相交及前后相邻的子节点
+		var prev, last *sitter.Node
+		for i := uint(0); i < node.NamedChildCount(); i++ {
+			child := node.NamedChild(i)
+			if child == nil {
+				continue
+			}
+			if child.EndByte() <= changed.startByte {
+				prev = child
+			}
+			if child.EndByte() > changed.startByte && child.StartByte() < changed.endByte {
+				span.widenNode(child)
+				last = child
+			}
+		}
+		if prev != nil {
+			span.widenNode(prev)
+			if last == nil {
+				last = prev
+			}
+		}
+		if last != nil {
+			if next := nextDefinition(last); next != nil {
+				span.widenNode(next)
+			}
+		} else if node.NamedChildCount() > 0 {
+			span.widenNode(node.NamedChild(0))
+		}
+		return &span
+	}
+	if target.Id() == root.Id() {
+		return nil
+	}
+	stmt := statementNode(target)
+	span.widenNode(stmt)
+	if next := nextDefinition(stmt); next != nil {
+		span.widenNode(next)
+	}
+	return &span
+}
+
+// statementNode 节点所在的语句或成员定义，即其父节点为容器或根节点的祖先
+func statementNode(n *sitter.Node) *sitter.Node {
+	for {
+		parent := n.Parent()
+		if parent == nil || parent.Parent() == nil || resolver.IsContainerKind(parent.Kind()) {
+			return n
+		}
+		n = parent
+	}
+}
+
+// fieldList 节点所在的 C++ 类体
+func fieldList(n *sitter.Node) *sitter.Node {
+	for ; n != nil; n = n.Parent() {
+		if types.ToNodeKind(n.Kind()) == types.NodeKindFieldList {
+			return n
+		}
+	}
+	return nil
+}
+
+func hasAccessSpecifier(fields *sitter.Node) bool {
+	for i := uint(0); i < fields.NamedChildCount(); i++ {
+		if child := fields.NamedChild(i); child != nil && types.ToNodeKind(child.Kind()) == types.NodeKindAccessSpecifier {
+			return true
+		}
+	}
+	return false
+}
+
+// nextDefinition 节点之后跳过注释、装饰器的第一个兄弟节点，其前导注释、装饰器依赖节点之后的内容
+func nextDefinition(n *sitter.Node) *sitter.Node {
+	next := n.NextNamedSibling()
+	for next != nil && (resolver.IsCommentKind(next.Kind()) || resolver.IsDecoratorKind(next.Kind())) {
+		next = next.NextNamedSibling()
+	}
+	return next
+}
+
+// mergeTables 合并重新解析的元素与编辑前范围外的元素，后者按编辑平移位置。存在无法复制的元素时返回 false
+func mergeTables(prev, fresh *FileElementTable, edit *sitter.InputEdit, span *byteSpan) (*FileElementTable, bool) {
+	res := &FileElementTable{
+		Path:     fresh.Path,
+		Package:  fresh.Package,
+		Language: fresh.Language,
+	}
+	seen := make(map[string]struct{})
+	for _, imp := range fresh.Imports {
+		seen[elementKey(imp)] = struct{}{}
+	}
+	for _, e := range fresh.Elements {
+		seen[elementKey(e)] = struct{}{}
+	}
+
+	if res.Package == nil && prev.Package != nil {
+		shifted, keep, ok := shiftElement(prev.Package, edit, span)
+		if !ok {
+			return nil, false
+		}
+		if keep {
+			res.Package = shifted.(*resolver.Package)
+		}
+	}
+	res.Imports = append(res.Imports, fresh.Imports...)
+	for _, imp := range prev.Imports {
+		shifted, keep, ok := shiftElement(imp, edit, span)
+		if !ok {
+			return nil, false
+		}
+		if _, dup := seen[elementKey(shifted)]; keep && !dup {
+			res.Imports = append(res.Imports, shifted.(*resolver.Import))
+		}
+	}
+	res.Elements = append(res.Elements, fresh.Elements...)
+	for _, e := range prev.Elements {
+		shifted, keep, ok := shiftElement(e, edit, span)
+		if !ok {
+			return nil, false
+		}
+		if _, dup := seen[elementKey(shifted)]; keep && !dup {
+			res.Elements = append(res.Elements, shifted)
+		}
+	}
+	sort.SliceStable(res.Imports, func(i, j int) bool {
+		return comparePoint(res.Imports[i].GetRange(), res.Imports[j].GetRange()) < 0
+	})
+	sort.SliceStable(res.Elements, func(i, j int) bool {
+		return comparePoint(res.Elements[i].GetRange(), res.Elements[j].GetRange()) < 0
+	})
+	return res, true
+}
+
+// shiftElement 复制编辑前的元素并平移位置，keep 为 false 表示元素与编辑或重新解析的范围相交，需要丢弃
+func shiftElement(e resolver.Element, edit *sitter.InputEdit, span *byteSpan) (shifted resolver.Element, keep bool, ok bool) {
+	shifted = cloneElement(e)
+	if shifted == nil {
+		return nil, false, false
+	}
+	rng := shiftRange(e.GetRange(), edit)
+	if rng == nil || overlapsSpan(rng, span) {
+		return shifted, false, true
+	}
+	shifted.SetRange(rng)
+	if c, isClass := shifted.(*resolver.Class); isClass {
+		for _, m := range c.Methods {
+			if m != nil && m.BaseElement != nil {
+				m.Range = shiftRange(m.Range, edit)
+			}
+		}
+	}
+	return shifted, true, true
+}
+
+// shiftRange 按编辑平移范围，与编辑相交时返回 nil。起点位于插入点的元素整体后移，终点位于编辑起点的元素不变
+func shiftRange(rng []int32, edit *sitter.InputEdit) []int32 {
+	if len(rng) < 4 {
+		return rng
+	}
+	start := sitter.Point{Row: uint(rng[0]), Column: uint(rng[1])}
+	end := sitter.Point{Row: uint(rng[2]), Column: uint(rng[3])}
+	switch {
+	case pointLE(edit.OldEndPosition, start):
+		start, end = shiftPoint(start, edit), shiftPoint(end, edit)
+	case pointLE(end, edit.StartPosition) && start != edit.StartPosition:
+	default:
+		return nil
+	}
+	return []int32{int32(start.Row), int32(start.Column), int32(end.Row), int32(end.Column)}
+}
+
+// shiftPoint 平移位于编辑之后的位置
+func shiftPoint(p sitter.Point, edit *sitter.InputEdit) sitter.Point {
+	if p.Row == edit.OldEndPosition.Row {
+		p.Column = p.Column - edit.OldEndPosition.Column + edit.NewEndPosition.Column
+	}
+	p.Row = p.Row - edit.OldEndPosition.Row + edit.NewEndPosition.Row
+	return p
+}
+
+func pointLE(a, b sitter.Point) bool {
+	return a.Row < b.Row || (a.Row == b.Row && a.Column <= b.Column)
+}
+
+// overlapsSpan 范围与重新解析的范围相交，与 query 按字节范围过滤节点的规则一致，不含端点
+func overlapsSpan(rng []int32, span *byteSpan) bool {
+	if len(rng) < 4 {
+		return false
+	}
+	start := sitter.Point{Row: uint(rng[0]), Column: uint(rng[1])}
+	end := sitter.Point{Row: uint(rng[2]), Column: uint(rng[3])}
+	return !pointLE(span.endPoint, start) && !pointLE(end, span.startPoint)
+}
+
+func comparePoint(a, b []int32) int {
+	for i := 0; i < 2 && i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return int(a[i] - b[i])
+		}
+	}
+	return 0
+}
+
+func elementKey(e resolver.Element) string {
+	return fmt.Sprintf("%s\x00%s\x00%v", e.GetType(), e.GetName(), e.GetRange())
+}
+
+// clone 复制解析结果，调用方可以修改返回的元素（如预处理导入），不影响缓存
+func (t *FileElementTable) clone() *FileElementTable {
+	res := &FileElementTable{
+		Path:      t.Path,
+		Timestamp: t.Timestamp,
+		Language:  t.Language,
+		Imports:   make([]*resolver.Import, 0, len(t.Imports)),
+		Elements:  make([]resolver.Element, 0, len(t.Elements)),
+	}
+	if t.Package != nil {
+		res.Package = cloneElement(t.Package).(*resolver.Package)
+	}
+	for _, imp := range t.Imports {
+		res.Imports = append(res.Imports, cloneElement(imp).(*resolver.Import))
+	}
+	for _, e := range t.Elements {
+		if c := cloneElement(e); c != nil {
+			res.Elements = append(res.Elements, c)
+		} else {
+			res.Elements = append(res.Elements, e)
+		}
+	}
+	return res
+}
+
+// cloneElement 复制元素及其位置，参数、声明等解析后不再修改的字段共享，未知类型返回 nil
+func cloneElement(e resolver.Element) resolver.Element {
+	switch v := e.(type) {
+	case *resolver.Import:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Package:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Function:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Method:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Call:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Reference:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Interface:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Variable:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		return &c
+	case *resolver.Class:
+		c := *v
+		c.BaseElement = cloneBase(v.BaseElement)
+		if v.Methods != nil {
+			c.Methods = make([]*resolver.Method, len(v.Methods))
+		}
+		for i, m := range v.Methods {
+			if m != nil {
+				mc := *m
+				mc.BaseElement = cloneBase(m.BaseElement)
+				c.Methods[i] = &mc
+			}
+		}
+		return &c
+	}
+	return nil
+}
+
+func cloneBase(b *resolver.BaseElement) *resolver.BaseElement {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	c.Range = append([]int32(nil), b.Range...)
+	return &c
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/textindex"
+	"codebase-indexer/pkg/codegraph/types"
+)
+
+// elementSnapshot 解析结果的可比较形式，与元素顺序无关
+func elementSnapshot(t *testing.T, table *FileElementTable) []string {
+	var res []string
+	for _, imp := range table.Imports {
+		b, err := json.Marshal(imp)
+		assert.NoError(t, err)
+		res = append(res, string(b))
+	}
+	for _, e := range table.Elements {
+		b, err := json.Marshal(e)
+		assert.NoError(t, err)
+		res = append(res, string(b))
+	}
+	if table.Package != nil {
+		b, err := json.Marshal(table.Package)
+		assert.NoError(t, err)
+		res = append(res, string(b))
+	}
+	sort.Strings(res)
+	return res
+}
+
+// lineEdits 对每隔若干行的位置生成编辑：插入空行、插入语句、删除行、修改标识符
+func lineEdits(content []byte, comment string) [][]byte {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	var edits [][]byte
+	for i := 1; i < len(lines); i += 5 {
+		join := func(replace ...[]byte) []byte {
+			var buf bytes.Buffer
+			for _, l := range lines[:i] {
+				buf.Write(l)
+			}
+			for _, r := range replace {
+				buf.Write(r)
+			}
+			for _, l := range lines[i+1:] {
+				buf.Write(l)
+			}
+			return buf.Bytes()
+		}
+		edits = append(edits,
+			join([]byte("\n"), lines[i]),
+			join([]byte(comment+" note\n"), lines[i]),
+			join(),
+			join(bytes.Replace(lines[i], []byte("e"), []byte("ex"), 1)),
+		)
+	}
+	return edits
+}
+
+func TestSourceFileParser_ParseIncremental(t *testing.T) {
+	logger := initLogger()
+	files := []struct {
+		path    string
+		comment string
+	}{
+		{"testdata/test.go", "//"},
+		{"testdata/test.java", "//"},
+		{"testdata/test.py", "#"},
+		{"testdata/test.ts", "//"},
+		{"testdata/test.cpp", "//"},
+		{"testdata/test.c", "//"},
+		{"testdata/test_ts.ts", "//"},
+	}
+	for _, f := range files {
+		t.Run(f.path, func(t *testing.T) {
+			incremental := NewSourceFileParser(logger)
+			full := NewSourceFileParser(logger)
+			original := readFile(f.path)
+			for idx, content := range lineEdits(original, f.comment) {
+				// 先解析原始内容缓存语法树，再增量解析编辑后的内容
+				_, err := incremental.ParseIncremental(context.Background(), &types.SourceFile{Path: f.path, Content: original})
+				assert.NoError(t, err)
+				got, err := incremental.ParseIncremental(context.Background(), &types.SourceFile{Path: f.path, Content: content})
+				assert.NoError(t, err)
+				want, err := full.Parse(context.Background(), &types.SourceFile{Path: f.path, Content: content})
+				assert.NoError(t, err)
+				if !assert.ElementsMatch(t, elementSnapshot(t, want), elementSnapshot(t, got), "edit %d", idx) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestSourceFileParser_CollectTerms(t *testing.T) {
+	p := NewSourceFileParser(initLogger())
+	content := readFile("testdata/test.go")
+	wantTerms, wantCount := textindex.Tokenize("testdata/test.go", content)
+
+	table, err := p.Parse(context.Background(), &types.SourceFile{Path: "testdata/test.go", Content: content})
+	assert.NoError(t, err)
+	assert.Nil(t, table.Terms)
+
+	for i := 0; i < 2; i++ {
+		// 首次解析及内容未变化时复用缓存的语法树
+		table, err = p.ParseIncremental(context.Background(),
+			&types.SourceFile{Path: "testdata/test.go", Content: content, CollectTerms: true})
+		assert.NoError(t, err)
+		assert.Equal(t, wantTerms, table.Terms)
+		assert.Equal(t, wantCount, table.TermCount)
+	}
+}
@@ -130,4 +130,16 @@
 
 (new_expression
   constructor:(member_expression)@call.struct
-)
\ No newline at end of file
+)
+
+;;-----------------------------组件引用--------------------------
+;; JSX 中使用的组件，小写开头的标签为原生元素
+(jsx_opening_element
+  name: [(identifier) (member_expression)] @reference.name
+  (#match? @reference.name "^[A-Z]|\\.")
+) @reference
+
+(jsx_self_closing_element
+  name: [(identifier) (member_expression)] @reference.name
+  (#match? @reference.name "^[A-Z]|\\.")
+) @reference
\ No newline at end of file
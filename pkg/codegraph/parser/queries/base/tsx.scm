@@ -0,0 +1,13 @@
+;; TSX 为 TypeScript 的方言，加载时拼接在 base/typescript.scm 之后，此处只写 JSX 特有的节点
+
+;;-----------------------------组件引用--------------------------
+;; JSX 中使用的组件，小写开头的标签为原生元素
+(jsx_opening_element
+  name: [(identifier) (member_expression)] @reference.name
+  (#match? @reference.name "^[A-Z]|\\.")
+) @reference
+
+(jsx_self_closing_element
+  name: [(identifier) (member_expression)] @reference.name
+  (#match? @reference.name "^[A-Z]|\\.")
+) @reference
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/types"
 	"embed"
 	"fmt"
 	sitter "github.com/tree-sitter/go-tree-sitter"
@@ -63,6 +64,15 @@ func loadLanguageScm(l *lang.TreeSitterParser, scmDir string, sitterLang *sitter
 	if err != nil {
 		return nil, fmt.Errorf("failed to read base query file %s for %s: %w", baseQuery, l.Language, err)
 	}
+	// 方言的查询文件只包含方言特有的节点，与所属语言的查询拼接后使用，如 tsx = typescript + JSX
+	if l.DialectOf != types.EmptyString {
+		parentQuery := makeQueryPath(l.DialectOf, scmDir)
+		parentContent, err := scmFS.ReadFile(parentQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read base query file %s for %s: %w", parentQuery, l.Language, err)
+		}
+		baseQueryContent = append(append(parentContent, '\n'), baseQueryContent...)
+	}
 	query, queryError := sitter.NewQuery(sitterLang, string(baseQueryContent))
 	if queryError != nil && lang.IsRealQueryErr(queryError) {
 		return nil, fmt.Errorf("failed to parse base query file %s: %w", baseQuery, queryError)
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+const testTSXComponent = `import { Button } from './Button'
+import { Layout } from 'antd'
+
+const identity = <T,>(value: T): T => value
+
+export function App(props: { title: string }) {
+  const node = props.title as string
+  return (
+    <Layout.Header>
+      <h1>{identity(node)}</h1>
+      <Button onClick={() => {}} />
+    </Layout.Header>
+  )
+}
+`
+
+// componentRefs 组件引用的名称、Owner 及位置
+func componentRefs(table *FileElementTable) [][]any {
+	var refs [][]any
+	for _, e := range table.Elements {
+		if ref, ok := e.(*resolver.Reference); ok && e.GetType() == types.ElementTypeReference {
+			refs = append(refs, []any{ref.Owner, ref.Name, ref.Range})
+		}
+	}
+	return refs
+}
+
+func TestParseTSX(t *testing.T) {
+	langParser, err := lang.GetSitterParserByFilePath("testdata/App.tsx")
+	assert.NoError(t, err)
+	assert.Equal(t, lang.TSX, langParser.Language)
+	language, err := lang.InferLanguage("testdata/App.tsx")
+	assert.NoError(t, err)
+	assert.Equal(t, lang.TypeScript, language)
+
+	// TSX 语法解析泛型箭头函数与 JSX，没有错误节点
+	sitterParser := sitter.NewParser()
+	defer sitterParser.Close()
+	assert.NoError(t, sitterParser.SetLanguage(langParser.SitterLanguage()))
+	tree := sitterParser.Parse([]byte(testTSXComponent), nil)
+	defer tree.Close()
+	assert.False(t, tree.RootNode().HasError())
+
+	parser := NewSourceFileParser(initLogger())
+	table, err := parser.Parse(context.Background(), &types.SourceFile{
+		Path:    "testdata/App.tsx",
+		Content: []byte(testTSXComponent),
+	})
+	assert.NoError(t, err)
+	// 与 .ts 文件的符号共用 TypeScript 索引
+	assert.Equal(t, lang.TypeScript, table.Language)
+
+	var functions []string
+	for _, e := range table.Elements {
+		if e.GetType() == types.ElementTypeFunction {
+			functions = append(functions, e.GetName())
+		}
+	}
+	assert.Contains(t, functions, "App")
+
+	// 原生元素 <h1> 不是组件
+	assert.Equal(t, [][]any{
+		{"Layout", "Header", []int32{8, 5, 8, 18}},
+		{"", "Button", []int32{10, 7, 10, 13}},
+	}, componentRefs(table))
+}
+
+func TestParseJSX(t *testing.T) {
+	parser := NewSourceFileParser(initLogger())
+	table, err := parser.Parse(context.Background(), &types.SourceFile{
+		Path:    "testdata/App.jsx",
+		Content: []byte("export const App = () => (\n  <Modal.Body>\n    <span><Avatar /></span>\n  </Modal.Body>\n)\n"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, lang.JavaScript, table.Language)
+	assert.Equal(t, [][]any{
+		{"Modal", "Body", []int32{1, 3, 1, 13}},
+		{"", "Avatar", []int32{2, 11, 2, 17}},
+	}, componentRefs(table))
+}
+
+func TestParseTSX_Testdata(t *testing.T) {
+	content, err := os.ReadFile("testdata/test.tsx")
+	assert.NoError(t, err)
+	parser := NewSourceFileParser(initLogger())
+	table, err := parser.ParseIncremental(context.Background(), &types.SourceFile{Path: "testdata/test.tsx", Content: content})
+	assert.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, ref := range componentRefs(table) {
+		names[ref[1].(string)] = true
+	}
+	assert.True(t, names["Navigation"])
+	assert.True(t, names["UserItem"])
+	assert.True(t, names["Link"])
+	assert.False(t, names["div"])
+}
@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"bytes"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+var (
+	// sfcTagPattern 模板中的开始标签
+	sfcTagPattern = regexp.MustCompile(`<([A-Za-z][\w.-]*)`)
+	// sfcCommentPattern 模板中的注释
+	sfcCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// vueBuiltinTags Vue 内置组件及 HTML 规范保留的带连字符的元素，不记录为组件引用
+var vueBuiltinTags = map[string]bool{
+	"template": true, "slot": true, "component": true,
+	"transition": true, "transition-group": true, "keep-alive": true, "teleport": true, "suspense": true,
+	"Transition": true, "TransitionGroup": true, "KeepAlive": true, "Teleport": true, "Suspense": true,
+	"annotation-xml": true, "color-profile": true, "font-face": true, "font-face-src": true,
+	"font-face-uri": true, "font-face-format": true, "font-face-name": true, "missing-glyph": true,
+}
+
+// parseVue 解析单文件组件：每个 <script> 块按其 lang 使用 JavaScript、TypeScript 或 TSX 语法解析，
+// 块外的内容替换为空白（保留换行），解析结果的位置即为在原文件中的位置；<template> 中使用的组件记录为引用
+func (p *SourceFileParser) parseVue(ctx context.Context, sourceFile *types.SourceFile) (*FileElementTable, error) {
+	language, err := lang.InferLanguage(sourceFile.Path)
+	if err != nil {
+		return nil, err
+	}
+	content := sourceFile.Content
+	table := &FileElementTable{
+		Path:     sourceFile.Path,
+		Language: language,
+		Elements: make([]resolver.Element, 0),
+	}
+	var templates []lang.SFCBlock
+	for _, block := range lang.SplitSFCBlocks(content) {
+		switch block.Tag {
+		case "script":
+			blockTable, err := p.parseVueScript(ctx, block, sourceFile)
+			if err != nil {
+				return nil, err
+			}
+			if table.Package == nil {
+				table.Package = blockTable.Package
+			}
+			table.Imports = append(table.Imports, blockTable.Imports...)
+			table.Elements = append(table.Elements, blockTable.Elements...)
+		case "template":
+			templates = append(templates, block)
+		}
+	}
+	// 模板中的组件按脚本中导入或定义的名称引用
+	names := make(map[string]string)
+	for _, imp := range table.Imports {
+		name := imp.Alias
+		if name == types.EmptyString {
+			name = imp.Name
+		}
+		names[componentKey(name)] = name
+	}
+	for _, e := range table.Elements {
+		if isDefinitionElement(e) {
+			names[componentKey(e.GetName())] = e.GetName()
+		}
+	}
+	for _, block := range templates {
+		table.Elements = append(table.Elements, templateComponentRefs(sourceFile.Path, content, block, names)...)
+	}
+	return table, nil
+}
+
+// parseVueScript 解析 <script> 块，<script setup> 与 <script> 分别解析
+func (p *SourceFileParser) parseVueScript(ctx context.Context, block lang.SFCBlock,
+	sourceFile *types.SourceFile) (*FileElementTable, error) {
+	language := block.ScriptLanguage()
+	langParser, err := lang.GetSitterParserByLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	sitterParser := sitter.NewParser()
+	defer sitterParser.Close()
+	if err := sitterParser.SetLanguage(langParser.SitterLanguage()); err != nil {
+		return nil, err
+	}
+	masked := lang.MaskOutside(sourceFile.Content, block.Start, block.End)
+	tree := sitterParser.Parse(masked, nil)
+	if tree == nil {
+		return nil, fmt.Errorf("failed to parse script block of file: %s", sourceFile.Path)
+	}
+	defer tree.Close()
+	return p.resolveTree(ctx, language, tree, &types.SourceFile{Path: sourceFile.Path, Content: masked}, nil)
+}
+
+// templateComponentRefs 模板中使用的组件：PascalCase 或带连字符的标签。同 Vue 的组件解析，<head-top> 匹配
+// 脚本中的 headTop、HeadTop，没有匹配的名称时转为 PascalCase；<Foo.Bar> 记录为 Foo 的成员 Bar
+func templateComponentRefs(path string, content []byte, block lang.SFCBlock, names map[string]string) []resolver.Element {
+	template := content[block.Start:block.End]
+	// 注释中的标签不是组件
+	template = sfcCommentPattern.ReplaceAllFunc(template, func(c []byte) []byte {
+		return bytes.Repeat([]byte(types.Space), len(c))
+	})
+	lineStarts := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	var refs []resolver.Element
+	for _, m := range sfcTagPattern.FindAllSubmatchIndex(template, -1) {
+		name := string(template[m[2]:m[3]])
+		if vueBuiltinTags[name] || !isComponentTag(name) {
+			continue
+		}
+		var owner string
+		if idx := strings.LastIndex(name, types.Dot); idx >= 0 {
+			owner, name = name[:idx], name[idx+1:]
+		}
+		if declared, ok := names[componentKey(name)]; ok {
+			name = declared
+		} else {
+			name = kebabToPascal(name)
+		}
+		start := block.Start + m[2]
+		row, column := offsetPosition(lineStarts, start)
+		refs = append(refs, &resolver.Reference{
+			Owner: owner,
+			BaseElement: &resolver.BaseElement{
+				Name:    name,
+				Path:    path,
+				Type:    types.ElementTypeReference,
+				Content: content[start : block.Start+m[3]],
+				Range:   []int32{int32(row), int32(column), int32(row), int32(column + m[3] - m[2])},
+			},
+		})
+	}
+	return refs
+}
+
+// isComponentTag 组件标签以大写字母开头或带连字符，原生 HTML/SVG 元素均为小写且不带连字符
+func isComponentTag(name string) bool {
+	return name[0] >= 'A' && name[0] <= 'Z' || strings.Contains(name, "-")
+}
+
+// componentKey 忽略大小写及连字符的组件名
+func componentKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", types.EmptyString))
+}
+
+func kebabToPascal(name string) string {
+	if !strings.Contains(name, "-") {
+		return name
+	}
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "-") {
+		if part == types.EmptyString {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	return sb.String()
+}
+
+// offsetPosition 字节偏移对应的行列，均从 0 开始，列为字节偏移，与 tree-sitter 一致
+func offsetPosition(lineStarts []int, offset int) (int, int) {
+	lo, hi := 0, len(lineStarts)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if lineStarts[mid] <= offset {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, offset - lineStarts[lo]
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testVueComponent = `<template>
+  <div>
+    <!-- <OldPanel /> -->
+    <template v-if="ready">
+      <user-card :user="user" />
+    </template>
+    <Form.Item label="名称"><el-input v-model="name" /></Form.Item>
+    <KeepAlive><router-view /></KeepAlive>
+  </div>
+</template>
+
+<script lang="ts">
+export default { name: 'UserList' }
+</script>
+
+<script setup lang="ts">
+import UserCard from './UserCard.vue'
+import { Form } from 'ant-design-vue'
+
+interface User {
+  name: string
+}
+
+function formatUser(user: User): string {
+  return user.name
+}
+</script>
+
+<style scoped>
+.card > .title { color: red; }
+</style>
+`
+
+func TestParseVue(t *testing.T) {
+	parser := NewSourceFileParser(initLogger())
+	table, err := parser.Parse(context.Background(), &types.SourceFile{
+		Path:    "testdata/UserList.vue",
+		Content: []byte(testVueComponent),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, lang.JavaScript, table.Language)
+
+	var imports []string
+	for _, imp := range table.Imports {
+		imports = append(imports, imp.Name)
+	}
+	assert.ElementsMatch(t, []string{"UserCard", "Form"}, imports)
+
+	definitions := make(map[string][]int32)
+	var refs []*resolver.Reference
+	for _, e := range table.Elements {
+		switch e.GetType() {
+		case types.ElementTypeInterface, types.ElementTypeFunction:
+			definitions[e.GetName()] = e.GetRange()
+		case types.ElementTypeReference:
+			// 模板位于前 10 行
+			if ref, ok := e.(*resolver.Reference); ok && ref.Range[0] < 10 {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	// TypeScript 语法的定义，位置为在 .vue 文件中的位置
+	assert.Equal(t, int32(19), definitions["User"][0])
+	assert.Equal(t, []int32{23, 0, 25, 1}, definitions["formatUser"])
+
+	type ref struct {
+		owner, name string
+		rng         []int32
+	}
+	var got []ref
+	for _, r := range refs {
+		got = append(got, ref{owner: r.Owner, name: r.Name, rng: r.Range})
+	}
+	assert.Equal(t, []ref{
+		{name: "UserCard", rng: []int32{4, 7, 4, 16}},
+		{owner: "Form", name: "Item", rng: []int32{6, 5, 6, 14}},
+		{name: "ElInput", rng: []int32{6, 31, 6, 39}},
+		{name: "RouterView", rng: []int32{7, 16, 7, 27}},
+	}, got)
+}
+
+func TestParseVue_Testdata(t *testing.T) {
+	content, err := os.ReadFile("testdata/test.vue")
+	assert.NoError(t, err)
+	parser := NewSourceFileParser(initLogger())
+	table, err := parser.ParseIncremental(context.Background(), &types.SourceFile{Path: "testdata/test.vue", Content: content})
+	assert.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, e := range table.Elements {
+		if e.GetType() == types.ElementTypeReference {
+			names[e.GetName()] = true
+		}
+	}
+	// <head-top> 引用脚本中导入的 headTop
+	assert.True(t, names["headTop"])
+	assert.True(t, names["ElTable"])
+	assert.True(t, names["ElPagination"])
+	assert.False(t, names["HeadTop"])
+}
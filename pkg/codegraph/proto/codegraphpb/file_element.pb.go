@@ -21,15 +21,70 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// FileOrigin 文件来源，非手写的文件在检索结果中降权
+type FileOrigin int32
+
+const (
+	FileOrigin_ORIGIN_SOURCE    FileOrigin = 0
+	FileOrigin_ORIGIN_GENERATED FileOrigin = 1
+	FileOrigin_ORIGIN_VENDORED  FileOrigin = 2
+	FileOrigin_ORIGIN_MINIFIED  FileOrigin = 3
+)
+
+// Enum value maps for FileOrigin.
+var (
+	FileOrigin_name = map[int32]string{
+		0: "ORIGIN_SOURCE",
+		1: "ORIGIN_GENERATED",
+		2: "ORIGIN_VENDORED",
+		3: "ORIGIN_MINIFIED",
+	}
+	FileOrigin_value = map[string]int32{
+		"ORIGIN_SOURCE":    0,
+		"ORIGIN_GENERATED": 1,
+		"ORIGIN_VENDORED":  2,
+		"ORIGIN_MINIFIED":  3,
+	}
+)
+
+func (x FileOrigin) Enum() *FileOrigin {
+	p := new(FileOrigin)
+	*p = x
+	return p
+}
+
+func (x FileOrigin) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileOrigin) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_codegraph_proto_file_element_proto_enumTypes[0].Descriptor()
+}
+
+func (FileOrigin) Type() protoreflect.EnumType {
+	return &file_pkg_codegraph_proto_file_element_proto_enumTypes[0]
+}
+
+func (x FileOrigin) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileOrigin.Descriptor instead.
+func (FileOrigin) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_codegraph_proto_file_element_proto_rawDescGZIP(), []int{0}
+}
+
 // FileElementTable 文件元素表，简化版本
 type FileElementTable struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Imports       []*Import              `protobuf:"bytes,4,rep,name=imports,proto3" json:"imports,omitempty"`
-	Package       *Package               `protobuf:"bytes,5,opt,name=package,proto3" json:"package,omitempty"`
-	Elements      []*Element             `protobuf:"bytes,6,rep,name=elements,proto3" json:"elements,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Path      string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Language  string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Timestamp int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Imports   []*Import              `protobuf:"bytes,4,rep,name=imports,proto3" json:"imports,omitempty"`
+	Package   *Package               `protobuf:"bytes,5,opt,name=package,proto3" json:"package,omitempty"`
+	Elements  []*Element             `protobuf:"bytes,6,rep,name=elements,proto3" json:"elements,omitempty"`
+	// 文件来源，生成、第三方的文件只包含定义
+	Origin        FileOrigin `protobuf:"varint,7,opt,name=origin,proto3,enum=codegraphpb.FileOrigin" json:"origin,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -106,6 +161,13 @@ func (x *FileElementTable) GetElements() []*Element {
 	return nil
 }
 
+func (x *FileElementTable) GetOrigin() FileOrigin {
+	if x != nil {
+		return x.Origin
+	}
+	return FileOrigin_ORIGIN_SOURCE
+}
+
 // 导入
 type Import struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -309,14 +371,15 @@ var File_pkg_codegraph_proto_file_element_proto protoreflect.FileDescriptor
 
 const file_pkg_codegraph_proto_file_element_proto_rawDesc = "" +
 	"\n" +
-	"&pkg/codegraph/proto/file_element.proto\x12\vcodegraphpb\x1a\x1fpkg/codegraph/proto/types.proto\"\xf1\x01\n" +
+	"&pkg/codegraph/proto/file_element.proto\x12\vcodegraphpb\x1a\x1fpkg/codegraph/proto/types.proto\"\xa2\x02\n" +
 	"\x10FileElementTable\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
 	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x1c\n" +
 	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12-\n" +
 	"\aimports\x18\x04 \x03(\v2\x13.codegraphpb.ImportR\aimports\x12.\n" +
 	"\apackage\x18\x05 \x01(\v2\x14.codegraphpb.PackageR\apackage\x120\n" +
-	"\belements\x18\x06 \x03(\v2\x14.codegraphpb.ElementR\belements\"`\n" +
+	"\belements\x18\x06 \x03(\v2\x14.codegraphpb.ElementR\belements\x12/\n" +
+	"\x06origin\x18\a \x01(\x0e2\x17.codegraphpb.FileOriginR\x06origin\"`\n" +
 	"\x06Import\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06source\x18\x02 \x01(\tR\x06source\x12\x14\n" +
@@ -334,7 +397,13 @@ const file_pkg_codegraph_proto_file_element_proto_rawDesc = "" +
 	"extra_data\x18\x06 \x03(\v2#.codegraphpb.Element.ExtraDataEntryR\textraData\x1a<\n" +
 	"\x0eExtraDataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01B-Z+pkg/codegraph/proto/codegraphpb;codegraphpbb\x06proto3"
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01*_\n" +
+	"\n" +
+	"FileOrigin\x12\x11\n" +
+	"\rORIGIN_SOURCE\x10\x00\x12\x14\n" +
+	"\x10ORIGIN_GENERATED\x10\x01\x12\x13\n" +
+	"\x0fORIGIN_VENDORED\x10\x02\x12\x13\n" +
+	"\x0fORIGIN_MINIFIED\x10\x03B-Z+pkg/codegraph/proto/codegraphpb;codegraphpbb\x06proto3"
 
 var (
 	file_pkg_codegraph_proto_file_element_proto_rawDescOnce sync.Once
@@ -348,26 +417,29 @@ func file_pkg_codegraph_proto_file_element_proto_rawDescGZIP() []byte {
 	return file_pkg_codegraph_proto_file_element_proto_rawDescData
 }
 
+var file_pkg_codegraph_proto_file_element_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_pkg_codegraph_proto_file_element_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_pkg_codegraph_proto_file_element_proto_goTypes = []any{
-	(*FileElementTable)(nil), // 0: codegraphpb.FileElementTable
-	(*Import)(nil),           // 1: codegraphpb.Import
-	(*Package)(nil),          // 2: codegraphpb.Package
-	(*Element)(nil),          // 3: codegraphpb.Element
-	nil,                      // 4: codegraphpb.Element.ExtraDataEntry
-	(ElementType)(0),         // 5: codegraphpb.ElementType
+	(FileOrigin)(0),          // 0: codegraphpb.FileOrigin
+	(*FileElementTable)(nil), // 1: codegraphpb.FileElementTable
+	(*Import)(nil),           // 2: codegraphpb.Import
+	(*Package)(nil),          // 3: codegraphpb.Package
+	(*Element)(nil),          // 4: codegraphpb.Element
+	nil,                      // 5: codegraphpb.Element.ExtraDataEntry
+	(ElementType)(0),         // 6: codegraphpb.ElementType
 }
 var file_pkg_codegraph_proto_file_element_proto_depIdxs = []int32{
-	1, // 0: codegraphpb.FileElementTable.imports:type_name -> codegraphpb.Import
-	2, // 1: codegraphpb.FileElementTable.package:type_name -> codegraphpb.Package
-	3, // 2: codegraphpb.FileElementTable.elements:type_name -> codegraphpb.Element
-	5, // 3: codegraphpb.Element.element_type:type_name -> codegraphpb.ElementType
-	4, // 4: codegraphpb.Element.extra_data:type_name -> codegraphpb.Element.ExtraDataEntry
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	2, // 0: codegraphpb.FileElementTable.imports:type_name -> codegraphpb.Import
+	3, // 1: codegraphpb.FileElementTable.package:type_name -> codegraphpb.Package
+	4, // 2: codegraphpb.FileElementTable.elements:type_name -> codegraphpb.Element
+	0, // 3: codegraphpb.FileElementTable.origin:type_name -> codegraphpb.FileOrigin
+	6, // 4: codegraphpb.Element.element_type:type_name -> codegraphpb.ElementType
+	5, // 5: codegraphpb.Element.extra_data:type_name -> codegraphpb.Element.ExtraDataEntry
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_pkg_codegraph_proto_file_element_proto_init() }
@@ -381,13 +453,14 @@ func file_pkg_codegraph_proto_file_element_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_codegraph_proto_file_element_proto_rawDesc), len(file_pkg_codegraph_proto_file_element_proto_rawDesc)),
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_pkg_codegraph_proto_file_element_proto_goTypes,
 		DependencyIndexes: file_pkg_codegraph_proto_file_element_proto_depIdxs,
+		EnumInfos:         file_pkg_codegraph_proto_file_element_proto_enumTypes,
 		MessageInfos:      file_pkg_codegraph_proto_file_element_proto_msgTypes,
 	}.Build()
 	File_pkg_codegraph_proto_file_element_proto = out.File
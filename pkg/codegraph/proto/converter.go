@@ -57,6 +57,34 @@ func ElementTypeFromProto(t codegraphpb.ElementType) types.ElementType {
 	}
 }
 
+// FileOriginToProto 将 types.FileOrigin 转换为 codegraphpb.FileOrigin
+func FileOriginToProto(o types.FileOrigin) codegraphpb.FileOrigin {
+	switch o {
+	case types.FileOriginGenerated:
+		return codegraphpb.FileOrigin_ORIGIN_GENERATED
+	case types.FileOriginVendored:
+		return codegraphpb.FileOrigin_ORIGIN_VENDORED
+	case types.FileOriginMinified:
+		return codegraphpb.FileOrigin_ORIGIN_MINIFIED
+	default:
+		return codegraphpb.FileOrigin_ORIGIN_SOURCE
+	}
+}
+
+// FileOriginFromProto 将 codegraphpb.FileOrigin 转换为 types.FileOrigin
+func FileOriginFromProto(o codegraphpb.FileOrigin) types.FileOrigin {
+	switch o {
+	case codegraphpb.FileOrigin_ORIGIN_GENERATED:
+		return types.FileOriginGenerated
+	case codegraphpb.FileOrigin_ORIGIN_VENDORED:
+		return types.FileOriginVendored
+	case codegraphpb.FileOrigin_ORIGIN_MINIFIED:
+		return types.FileOriginMinified
+	default:
+		return types.FileOriginSource
+	}
+}
+
 // ToDefinitionElementType 转换为定义的类型
 func ToDefinitionElementType(t types.ElementType) types.ElementType {
 	switch t {
@@ -199,6 +227,10 @@ const (
 	keyReturnType      = "returnType"
 	keySuperClasses    = "superClasses"
 	keySuperInterfaces = "superInterfaces"
+	keyDoc             = "doc"
+	keyDecorators      = "decorators"
+	keySignature       = "signature"
+	keyOwner           = "owner"
 )
 
 // FileElementTablesToProto 将 []parser.FileElementTable 转换为 []*codegraphpb.FileElementTable
@@ -214,6 +246,7 @@ func FileElementTablesToProto(fileElementTables []*parser.FileElementTable) []*c
 			Timestamp: ft.Timestamp,
 			Elements:  make([]*codegraphpb.Element, len(ft.Elements)),
 			Imports:   make([]*codegraphpb.Import, len(ft.Imports)),
+			Origin:    FileOriginToProto(ft.Origin),
 		}
 		if ft.Package != nil {
 			pft.Package = &codegraphpb.Package{Name: ft.Package.Name, Range: ft.Package.Range}
@@ -292,14 +325,90 @@ func GetSuperInterfacesFromExtraData(extraData map[string][]byte) (superInterfac
 	return
 }
 
+func GetOwnerFromExtraData(extraData map[string][]byte) (owner string, err error) {
+	ownerBytes, ok := extraData[keyOwner]
+	if !ok {
+		return
+	}
+	err = json.Unmarshal(ownerBytes, &owner)
+	return
+}
+
+// GetDocCommentFromExtraData 获取定义的注释、装饰器及签名，均不存在时返回 nil
+func GetDocCommentFromExtraData(extraData map[string][]byte) (*resolver.DocComment, error) {
+	docBytes, hasDoc := extraData[keyDoc]
+	decoratorsBytes, hasDecorators := extraData[keyDecorators]
+	signatureBytes, hasSignature := extraData[keySignature]
+	if !hasDoc && !hasDecorators && !hasSignature {
+		return nil, nil
+	}
+	var errs []error
+	doc := &resolver.DocComment{}
+	if hasDoc {
+		errs = append(errs, json.Unmarshal(docBytes, &doc.Doc))
+	}
+	if hasDecorators {
+		errs = append(errs, json.Unmarshal(decoratorsBytes, &doc.Decorators))
+	}
+	if hasSignature {
+		errs = append(errs, json.Unmarshal(signatureBytes, &doc.Signature))
+	}
+	return doc, errors.Join(errs...)
+}
+
+// marshalDocComment 注释、装饰器及签名与类型无关，非空时写入 extra_data
+func marshalDocComment(doc *resolver.DocComment, extraData map[string][]byte) error {
+	if doc == nil {
+		return nil
+	}
+	var errs []error
+	put := func(key string, v any) {
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		extraData[key] = bytes
+	}
+	if doc.Doc != "" {
+		put(keyDoc, doc.Doc)
+	}
+	if len(doc.Decorators) > 0 {
+		put(keyDecorators, doc.Decorators)
+	}
+	if doc.Signature != "" {
+		put(keySignature, doc.Signature)
+	}
+	return errors.Join(errs...)
+}
+
+// marshalOwner 记录函数、方法所属的类型或对象，以及调用、引用的限定对象（接收者、包名等）
+func marshalOwner(owner string, extraData map[string][]byte) error {
+	if owner == "" {
+		return nil
+	}
+	ownerBytes, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	extraData[keyOwner] = ownerBytes
+	return nil
+}
+
 func MarshalExtraData(element resolver.Element) (map[string][]byte, error) {
 	var errs []error
 	extraData := make(map[string][]byte)
+	if err := marshalDocComment(element.GetDocComment(), extraData); err != nil {
+		errs = append(errs, err)
+	}
 
 	switch e := element.(type) {
 	case *resolver.Import, *resolver.Package, *resolver.Variable:
 		// 无需处理的类型
 	case *resolver.Function:
+		if err := marshalOwner(e.Owner, extraData); err != nil {
+			errs = append(errs, err)
+		}
 		if len(e.Declaration.Parameters) > 0 {
 			// 处理函数共有的参数和返回类型
 			parametersBytes, err := json.Marshal(e.Declaration.Parameters)
@@ -320,6 +429,9 @@ func MarshalExtraData(element resolver.Element) (map[string][]byte, error) {
 		}
 
 	case *resolver.Method:
+		if err := marshalOwner(e.Owner, extraData); err != nil {
+			errs = append(errs, err)
+		}
 		// 处理方法共有的参数和返回类型
 		if len(e.Declaration.Parameters) > 0 {
 			parametersBytes, err := json.Marshal(e.Declaration.Parameters)
@@ -368,7 +480,15 @@ func MarshalExtraData(element resolver.Element) (map[string][]byte, error) {
 			}
 		}
 
+	case *resolver.Reference:
+		if err := marshalOwner(e.Owner, extraData); err != nil {
+			errs = append(errs, err)
+		}
+
 	case *resolver.Call:
+		if err := marshalOwner(e.Owner, extraData); err != nil {
+			errs = append(errs, err)
+		}
 		if len(e.Parameters) > 0 {
 			parametersBytes, err := json.Marshal(e.Parameters)
 			if err != nil {
@@ -391,10 +511,29 @@ func UnMarshalExtraData(element *codegraphpb.Element) (map[string]any, error) {
 		return extraData, nil
 	}
 
+	if doc, err := GetDocCommentFromExtraData(extraDataRaw); err != nil {
+		errs = append(errs, err)
+	} else if doc != nil {
+		if doc.Doc != "" {
+			extraData[keyDoc] = doc.Doc
+		}
+		if len(doc.Decorators) > 0 {
+			extraData[keyDecorators] = doc.Decorators
+		}
+		if doc.Signature != "" {
+			extraData[keySignature] = doc.Signature
+		}
+	}
+
 	switch element.ElementType {
 	case codegraphpb.ElementType_IMPORT, codegraphpb.ElementType_PACKAGE, codegraphpb.ElementType_VARIABLE:
 		// 无需处理的类型
 	case codegraphpb.ElementType_FUNCTION, codegraphpb.ElementType_METHOD:
+		if owner, err := GetOwnerFromExtraData(extraDataRaw); err != nil {
+			errs = append(errs, err)
+		} else if owner != "" {
+			extraData[keyOwner] = owner
+		}
 		// 处理函数和方法共有的参数和返回类型
 		if parametersBytes, ok := extraDataRaw[keyParameters]; ok {
 			var params []resolver.Parameter
@@ -443,7 +582,19 @@ func UnMarshalExtraData(element *codegraphpb.Element) (map[string]any, error) {
 			}
 		}
 
+	case codegraphpb.ElementType_REFERENCE:
+		if owner, err := GetOwnerFromExtraData(extraDataRaw); err != nil {
+			errs = append(errs, err)
+		} else if owner != "" {
+			extraData[keyOwner] = owner
+		}
+
 	case codegraphpb.ElementType_CALL:
+		if owner, err := GetOwnerFromExtraData(extraDataRaw); err != nil {
+			errs = append(errs, err)
+		} else if owner != "" {
+			extraData[keyOwner] = owner
+		}
 		if parametersBytes, ok := extraDataRaw[keyParameters]; ok {
 			var params []resolver.Parameter
 			if err := json.Unmarshal(parametersBytes, &params); err != nil {
@@ -0,0 +1,354 @@
+package repomap
+
+import (
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultMaxTokens = 1024
+
+	damping          = 0.85
+	maxIterations    = 100
+	convergenceDelta = 1e-6
+	// 没有被引用的定义，按所在文件排名的一小部分计分，预算充足时也能展示
+	unreferencedRankFactor = 1e-3
+	// 被多个文件同时定义的符号（如 String、init）区分度低，降低权重
+	commonDefinitionThreshold = 5
+)
+
+// Options 生成参数
+type Options struct {
+	// MaxTokens 输出内容的 token 上限
+	MaxTokens int
+	// Personalization 当前编辑的文件，PageRank 偏向这些文件
+	Personalization []string
+	// BasePath 输出时文件路径相对于该目录
+	BasePath string
+	// TokenCounter 计算文本的 token 数
+	TokenCounter func(text string) int
+}
+
+// RankedDefinition 带排名的定义
+type RankedDefinition struct {
+	Path      string  `json:"filePath"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Line      int     `json:"line"`
+	Signature string  `json:"signature"`
+	Rank      float64 `json:"rank"`
+}
+
+// RepoMap 生成结果
+type RepoMap struct {
+	Content     string              `json:"content"`
+	Tokens      int                 `json:"tokens"`
+	TotalFiles  int                 `json:"totalFiles"`
+	Definitions []*RankedDefinition `json:"definitions"`
+}
+
+type fileNode struct {
+	path        string
+	definitions []*RankedDefinition
+	references  map[string]int
+}
+
+// Generator 根据存储的元素构建文件间的加权引用图，通过 PageRank 计算符号重要性，生成仓库地图
+type Generator struct {
+	files map[string]*fileNode
+	// 符号名 -> 定义该符号的文件
+	definers map[string]map[string]struct{}
+}
+
+// NewGenerator 创建仓库地图生成器
+func NewGenerator() *Generator {
+	return &Generator{
+		files:    make(map[string]*fileNode),
+		definers: make(map[string]map[string]struct{}),
+	}
+}
+
+// Add 添加一个文件的元素表，只保留定义签名和引用计数
+func (g *Generator) Add(table *codegraphpb.FileElementTable) {
+	if table == nil {
+		return
+	}
+	f, ok := g.files[table.Path]
+	if !ok {
+		f = &fileNode{path: table.Path, references: make(map[string]int)}
+		g.files[table.Path] = f
+	}
+	for _, e := range table.Elements {
+		switch e.ElementType {
+		case codegraphpb.ElementType_CLASS, codegraphpb.ElementType_INTERFACE,
+			codegraphpb.ElementType_FUNCTION, codegraphpb.ElementType_METHOD:
+			if !e.IsDefinition {
+				continue
+			}
+			line := 0
+			if len(e.Range) > 0 {
+				line = int(e.Range[0]) + 1
+			}
+			f.definitions = append(f.definitions, &RankedDefinition{
+				Path:      table.Path,
+				Name:      e.Name,
+				Type:      string(proto.ElementTypeFromProto(e.ElementType)),
+				Line:      line,
+				Signature: Signature(e),
+			})
+			if _, ok := g.definers[e.Name]; !ok {
+				g.definers[e.Name] = make(map[string]struct{})
+			}
+			g.definers[e.Name][table.Path] = struct{}{}
+		case codegraphpb.ElementType_CALL, codegraphpb.ElementType_REFERENCE:
+			if e.IsDefinition {
+				continue
+			}
+			f.references[e.Name]++
+		}
+	}
+}
+
+type edge struct {
+	from, to string
+	name     string
+	weight   float64
+}
+
+// Generate 计算排名并在 token 上限内渲染排名最高的定义签名
+func (g *Generator) Generate(opts Options) *RepoMap {
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = DefaultMaxTokens
+	}
+	if opts.TokenCounter == nil {
+		opts.TokenCounter = func(text string) int { return (len(text) + 3) / 4 }
+	}
+
+	edges := g.buildEdges()
+	ranks := g.pageRank(edges, opts.Personalization)
+	definitions := g.rankDefinitions(edges, ranks)
+
+	// 二分查找在 token 上限内可展示的最多定义数
+	res := &RepoMap{TotalFiles: len(g.files)}
+	lo, hi := 0, len(definitions)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if opts.TokenCounter(render(definitions[:mid], opts.BasePath)) <= opts.MaxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	res.Definitions = definitions[:lo]
+	res.Content = render(res.Definitions, opts.BasePath)
+	res.Tokens = opts.TokenCounter(res.Content)
+	return res
+}
+
+// buildEdges 引用文件 -> 定义文件，权重为引用次数的平方根，并按符号区分度调整
+func (g *Generator) buildEdges() []*edge {
+	var edges []*edge
+	paths := g.sortedPaths()
+	for _, from := range paths {
+		f := g.files[from]
+		names := make([]string, 0, len(f.references))
+		for name := range f.references {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			definers, ok := g.definers[name]
+			if !ok {
+				continue
+			}
+			weight := math.Sqrt(float64(f.references[name])) * nameWeight(name, len(definers))
+			for to := range definers {
+				if to == from {
+					continue
+				}
+				edges = append(edges, &edge{from: from, to: to, name: name, weight: weight})
+			}
+		}
+	}
+	return edges
+}
+
+// nameWeight 长的驼峰/下划线命名更具区分度，私有命名及多处定义的命名权重降低
+func nameWeight(name string, definerCount int) float64 {
+	weight := 1.0
+	if len(name) >= 8 && (strings.Contains(name, "_") || hasInnerUpper(name)) {
+		weight *= 10
+	}
+	if strings.HasPrefix(name, "_") {
+		weight *= 0.1
+	}
+	if definerCount > commonDefinitionThreshold {
+		weight *= 0.1
+	}
+	return weight
+}
+
+func hasInnerUpper(name string) bool {
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// pageRank 加权 PageRank，personalization 非空时随机跳转只落到这些文件
+func (g *Generator) pageRank(edges []*edge, personalization []string) map[string]float64 {
+	paths := g.sortedPaths()
+	n := len(paths)
+	ranks := make(map[string]float64, n)
+	if n == 0 {
+		return ranks
+	}
+
+	jump := make(map[string]float64, n)
+	for _, p := range personalization {
+		if _, ok := g.files[p]; ok {
+			jump[p] = 1
+		}
+	}
+	if len(jump) == 0 {
+		for _, p := range paths {
+			jump[p] = 1
+		}
+	}
+	jumpTotal := float64(len(jump))
+	for p := range jump {
+		jump[p] /= jumpTotal
+	}
+
+	outWeight := make(map[string]float64, n)
+	for _, e := range edges {
+		outWeight[e.from] += e.weight
+	}
+	for _, p := range paths {
+		ranks[p] = 1 / float64(n)
+	}
+
+	for iter := 0; iter < maxIterations; iter++ {
+		next := make(map[string]float64, n)
+		// 没有出边的文件，其排名按跳转分布重新分配
+		dangling := 0.0
+		for _, p := range paths {
+			if outWeight[p] == 0 {
+				dangling += ranks[p]
+			}
+		}
+		for _, e := range edges {
+			next[e.to] += damping * ranks[e.from] * e.weight / outWeight[e.from]
+		}
+		delta := 0.0
+		for _, p := range paths {
+			next[p] += (damping*dangling + 1 - damping) * jump[p]
+			delta += math.Abs(next[p] - ranks[p])
+		}
+		ranks = next
+		if delta < convergenceDelta {
+			break
+		}
+	}
+	return ranks
+}
+
+// rankDefinitions 将文件排名沿出边按权重分配给被引用的定义
+func (g *Generator) rankDefinitions(edges []*edge, ranks map[string]float64) []*RankedDefinition {
+	outWeight := make(map[string]float64)
+	for _, e := range edges {
+		outWeight[e.from] += e.weight
+	}
+	// 定义文件+符号名 -> 排名
+	symbolRanks := make(map[string]float64)
+	for _, e := range edges {
+		symbolRanks[e.to+"\x00"+e.name] += ranks[e.from] * e.weight / outWeight[e.from]
+	}
+
+	var definitions []*RankedDefinition
+	for _, p := range g.sortedPaths() {
+		for _, d := range g.files[p].definitions {
+			rank, ok := symbolRanks[p+"\x00"+d.Name]
+			if !ok {
+				rank = ranks[p] * unreferencedRankFactor
+			}
+			d.Rank = rank
+			definitions = append(definitions, d)
+		}
+	}
+	sort.SliceStable(definitions, func(i, j int) bool {
+		return definitions[i].Rank > definitions[j].Rank
+	})
+	return definitions
+}
+
+func (g *Generator) sortedPaths() []string {
+	paths := make([]string, 0, len(g.files))
+	for p := range g.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// render 按文件分组输出，文件按路径排序，文件内按行号排序
+func render(definitions []*RankedDefinition, basePath string) string {
+	if len(definitions) == 0 {
+		return ""
+	}
+	byFile := make(map[string][]*RankedDefinition)
+	var paths []string
+	for _, d := range definitions {
+		if _, ok := byFile[d.Path]; !ok {
+			paths = append(paths, d.Path)
+		}
+		byFile[d.Path] = append(byFile[d.Path], d)
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	for _, p := range paths {
+		display := p
+		if basePath != "" {
+			if rel, err := filepath.Rel(basePath, p); err == nil {
+				display = filepath.ToSlash(rel)
+			}
+		}
+		sb.WriteString(display)
+		sb.WriteString(":\n")
+		defs := byFile[p]
+		sort.SliceStable(defs, func(i, j int) bool {
+			return defs[i].Line < defs[j].Line
+		})
+		for _, d := range defs {
+			sb.WriteString(fmt.Sprintf("  %d: %s %s\n", d.Line, strings.TrimPrefix(d.Type, "definition."), d.Signature))
+		}
+	}
+	return sb.String()
+}
+
+// Signature 由名称及 extra_data 中的参数、返回值拼接签名，如 Foo(a int, b string) error
+func Signature(e *codegraphpb.Element) string {
+	if e.ElementType != codegraphpb.ElementType_FUNCTION && e.ElementType != codegraphpb.ElementType_METHOD {
+		return e.Name
+	}
+	params, _ := proto.GetParametersFromExtraData(e.ExtraData)
+	returnType, _ := proto.GetReturnTypeFromExtraData(e.ExtraData)
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, strings.TrimSpace(p.Name+" "+strings.Join(p.Type, "|")))
+	}
+	signature := fmt.Sprintf("%s(%s)", e.Name, strings.Join(parts, ", "))
+	if len(returnType) > 0 {
+		signature += " " + strings.Join(returnType, ", ")
+	}
+	return signature
+}
@@ -0,0 +1,111 @@
+package repomap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+)
+
+func definitionElement(t *testing.T, name string, elementType codegraphpb.ElementType, line int32,
+	params []resolver.Parameter, returnType []string) *codegraphpb.Element {
+	extraData := make(map[string][]byte)
+	if params != nil {
+		bytes, err := json.Marshal(params)
+		assert.NoError(t, err)
+		extraData["parameters"] = bytes
+	}
+	if returnType != nil {
+		bytes, err := json.Marshal(returnType)
+		assert.NoError(t, err)
+		extraData["returnType"] = bytes
+	}
+	return &codegraphpb.Element{
+		Name:         name,
+		IsDefinition: true,
+		ElementType:  elementType,
+		Range:        []int32{line, 0, line + 2, 1},
+		ExtraData:    extraData,
+	}
+}
+
+func callElement(name string, line int32) *codegraphpb.Element {
+	return &codegraphpb.Element{
+		Name:        name,
+		ElementType: codegraphpb.ElementType_CALL,
+		Range:       []int32{line, 0, line, 10},
+	}
+}
+
+func newTestGenerator(t *testing.T) *Generator {
+	g := NewGenerator()
+	g.Add(&codegraphpb.FileElementTable{
+		Path: "/repo/core.go",
+		Elements: []*codegraphpb.Element{
+			definitionElement(t, "ParseConfig", codegraphpb.ElementType_FUNCTION, 3,
+				[]resolver.Parameter{{Name: "path", Type: []string{"string"}}}, []string{"*Config", "error"}),
+			definitionElement(t, "Config", codegraphpb.ElementType_CLASS, 10, nil, nil),
+		},
+	})
+	g.Add(&codegraphpb.FileElementTable{
+		Path: "/repo/util.go",
+		Elements: []*codegraphpb.Element{
+			definitionElement(t, "helper", codegraphpb.ElementType_FUNCTION, 1, nil, nil),
+			callElement("ParseConfig", 2),
+		},
+	})
+	g.Add(&codegraphpb.FileElementTable{
+		Path: "/repo/main.go",
+		Elements: []*codegraphpb.Element{
+			definitionElement(t, "main", codegraphpb.ElementType_FUNCTION, 1, nil, nil),
+			callElement("ParseConfig", 2),
+			callElement("ParseConfig", 3),
+			callElement("helper", 4),
+		},
+	})
+	return g
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	g := newTestGenerator(t)
+	res := g.Generate(Options{MaxTokens: 1000, BasePath: "/repo"})
+
+	assert.Equal(t, 3, res.TotalFiles)
+	assert.Len(t, res.Definitions, 4)
+	assert.Equal(t, "ParseConfig", res.Definitions[0].Name)
+	assert.Equal(t, "ParseConfig(path string) *Config, error", res.Definitions[0].Signature)
+	assert.Contains(t, res.Content, "core.go:\n  4: function ParseConfig(path string) *Config, error\n")
+	assert.True(t, strings.Index(res.Content, "core.go") < strings.Index(res.Content, "util.go"))
+}
+
+func TestGenerator_GenerateWithinTokenLimit(t *testing.T) {
+	g := newTestGenerator(t)
+	counter := func(text string) int { return strings.Count(text, "\n") }
+	res := g.Generate(Options{MaxTokens: 2, TokenCounter: counter})
+
+	assert.Len(t, res.Definitions, 1)
+	assert.Equal(t, "ParseConfig", res.Definitions[0].Name)
+	assert.Equal(t, 2, res.Tokens)
+}
+
+func TestGenerator_Personalization(t *testing.T) {
+	g := newTestGenerator(t)
+	plain := g.Generate(Options{MaxTokens: 1000})
+	res := newTestGenerator(t).Generate(Options{MaxTokens: 1000, Personalization: []string{"/repo/util.go"}})
+
+	rankOf := func(m *RepoMap, name string) float64 {
+		for _, d := range m.Definitions {
+			if d.Name == name {
+				return d.Rank
+			}
+		}
+		return 0
+	}
+	// util.go 作为编辑文件时，其引用的 ParseConfig 权重占比提升，helper 只被 main.go 引用
+	assert.Greater(t, rankOf(res, "ParseConfig")/rankOf(res, "helper"),
+		rankOf(plain, "ParseConfig")/rankOf(plain, "helper"))
+}
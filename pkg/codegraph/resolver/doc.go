@@ -0,0 +1,251 @@
+package resolver
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+const (
+	maxDocLength       = 4000
+	maxSignatureLength = 500
+	maxDefinitionDepth = 8
+)
+
+// DocComment 定义的前导注释（docstring、Javadoc、Go doc、JSDoc 等）、装饰器/注解及签名
+type DocComment struct {
+	Doc        string
+	Decorators []string
+	Signature  string
+}
+
+// 定义所在的容器节点，从名称节点向上查找定义节点时到此为止
+var containerKinds = map[string]struct{}{
+	"block":                  {},
+	"statement_block":        {},
+	"compound_statement":     {},
+	"declaration_list":       {},
+	"field_declaration_list": {},
+	"object_type":            {},
+	"body_statement":         {},
+}
+
+var decoratorKinds = map[string]struct{}{
+	"decorator":         {},
+	"attribute_item":    {},
+	"annotation":        {},
+	"marker_annotation": {},
+}
+
+// ExtractDocComment 根据定义的名称节点找到定义节点，提取其前导注释、装饰器/注解，并渲染签名（定义头部，不含函数体）
+func ExtractDocComment(name *sitter.Node, source []byte) *DocComment {
+	if name == nil {
+		return nil
+	}
+	def := definitionNode(name)
+	inner := unwrapDefinition(def)
+	dc := &DocComment{Signature: renderSignature(def, inner, source)}
+
+	// 前导注释及位于定义之前的装饰器（Rust attribute、TS 类成员装饰器）
+	var comments []string
+	var decorators []string
+	nextRow := def.StartPosition().Row
+	for prev := def.PrevSibling(); prev != nil; prev = prev.PrevSibling() {
+		if prev.EndPosition().Row+1 < nextRow {
+			break // 空行隔开的注释不属于该定义
+		}
+		kind := prev.Kind()
+		if IsCommentKind(kind) {
+			// 前一条语句的行尾注释
+			if pp := prev.PrevSibling(); pp != nil && !IsCommentKind(pp.Kind()) &&
+				pp.EndPosition().Row == prev.StartPosition().Row {
+				break
+			}
+			comments = append([]string{cleanComment(prev.Utf8Text(source))}, comments...)
+		} else if _, ok := decoratorKinds[kind]; ok {
+			decorators = append([]string{prev.Utf8Text(source)}, decorators...)
+		} else {
+			break
+		}
+		nextRow = prev.StartPosition().Row
+	}
+	dc.Decorators = append(decorators, childDecorators(def, inner, source)...)
+	dc.Doc = strings.Join(comments, "\n")
+	if dc.Doc == types.EmptyString {
+		dc.Doc = pythonDocstring(inner, source)
+	}
+	if len(dc.Doc) > maxDocLength {
+		dc.Doc = dc.Doc[:maxDocLength]
+	}
+	return dc
+}
+
+// definitionNode 从名称节点向上找到最外层的定义节点（包含 export、装饰器等包装）
+func definitionNode(name *sitter.Node) *sitter.Node {
+	node := name
+	for depth := 0; depth < maxDefinitionDepth; depth++ {
+		parent := node.Parent()
+		if parent == nil || parent.Parent() == nil || IsContainerKind(parent.Kind()) {
+			break
+		}
+		node = parent
+	}
+	return node
+}
+
+// unwrapDefinition 去掉 export、装饰器等包装，返回实际的定义节点
+func unwrapDefinition(def *sitter.Node) *sitter.Node {
+	inner := def
+	for depth := 0; depth < maxDefinitionDepth; depth++ {
+		var next *sitter.Node
+		switch inner.Kind() {
+		case "decorated_definition":
+			next = inner.ChildByFieldName("definition")
+		case "export_statement":
+			next = inner.ChildByFieldName("declaration")
+		}
+		if next == nil {
+			break
+		}
+		inner = next
+	}
+	return inner
+}
+
+// renderSignature 取定义头部：有函数体/类体时截取到体之前，否则取首行，并压缩空白
+func renderSignature(def, inner *sitter.Node, source []byte) string {
+	var header string
+	if body := inner.ChildByFieldName("body"); body != nil && body.StartByte() >= def.StartByte() {
+		header = string(source[def.StartByte():body.StartByte()])
+	} else {
+		header = def.Utf8Text(source)
+		if idx := strings.IndexByte(header, '\n'); idx >= 0 {
+			header = header[:idx]
+		}
+		if idx := strings.IndexByte(header, '{'); idx > 0 {
+			header = header[:idx]
+		}
+	}
+	header = strings.Join(strings.Fields(header), " ")
+	header = strings.TrimSpace(strings.TrimRight(header, "{:;= "))
+	if len(header) > maxSignatureLength {
+		header = header[:maxSignatureLength] + "..."
+	}
+	return header
+}
+
+// childDecorators 定义节点内的装饰器/注解：Python decorated_definition、TS 类装饰器、Java/Kotlin modifiers 中的注解
+func childDecorators(def, inner *sitter.Node, source []byte) []string {
+	var res []string
+	seen := make(map[uint]struct{})
+	collect := func(n *sitter.Node) {
+		for i := uint(0); i < n.NamedChildCount(); i++ {
+			child := n.NamedChild(i)
+			if child == nil {
+				continue
+			}
+			if _, ok := seen[child.StartByte()]; ok {
+				continue
+			}
+			if _, ok := decoratorKinds[child.Kind()]; ok {
+				seen[child.StartByte()] = struct{}{}
+				res = append(res, child.Utf8Text(source))
+			}
+		}
+	}
+	for _, n := range []*sitter.Node{def, inner} {
+		collect(n)
+		for i := uint(0); i < n.NamedChildCount(); i++ {
+			if child := n.NamedChild(i); child != nil && child.Kind() == "modifiers" {
+				collect(child)
+			}
+		}
+	}
+	return res
+}
+
+// pythonDocstring 函数体或类体的第一条字符串语句
+func pythonDocstring(inner *sitter.Node, source []byte) string {
+	if inner.Kind() != "function_definition" && inner.Kind() != "class_definition" {
+		return types.EmptyString
+	}
+	body := inner.ChildByFieldName("body")
+	if body == nil || body.NamedChildCount() == 0 {
+		return types.EmptyString
+	}
+	first := body.NamedChild(0)
+	if first == nil || first.Kind() != "expression_statement" || first.NamedChildCount() == 0 {
+		return types.EmptyString
+	}
+	str := first.NamedChild(0)
+	if str == nil || str.Kind() != "string" {
+		return types.EmptyString
+	}
+	text := strings.TrimLeft(str.Utf8Text(source), "rRbBuUfF")
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`} {
+		if strings.HasPrefix(text, quote) && strings.HasSuffix(text, quote) && len(text) >= 2*len(quote) {
+			text = text[len(quote) : len(text)-len(quote)]
+			break
+		}
+	}
+	return trimLines(strings.Split(text, "\n"))
+}
+
+// cleanComment 去掉注释标记
+func cleanComment(text string) string {
+	var lines []string
+	if strings.HasPrefix(text, "/*") {
+		text = strings.TrimSuffix(strings.TrimLeft(strings.TrimPrefix(text, "/*"), "*!"), "*/")
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "*"), " ")
+			lines = append(lines, line)
+		}
+		return trimLines(lines)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"///", "//!", "//", "#"} {
+			if strings.HasPrefix(line, prefix) {
+				line = strings.TrimPrefix(line[len(prefix):], " ")
+				break
+			}
+		}
+		lines = append(lines, line)
+	}
+	return trimLines(lines)
+}
+
+// trimLines 去掉每行首尾空白及首尾空行
+func trimLines(lines []string) string {
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	for len(lines) > 0 && lines[0] == types.EmptyString {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == types.EmptyString {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsCommentKind 节点为注释
+func IsCommentKind(kind string) bool {
+	return strings.HasSuffix(kind, "comment")
+}
+
+// IsDecoratorKind 节点为装饰器/注解
+func IsDecoratorKind(kind string) bool {
+	_, ok := decoratorKinds[kind]
+	return ok
+}
+
+// IsContainerKind 节点为容器（代码块、类体等），其子节点为语句或成员定义
+func IsContainerKind(kind string) bool {
+	if _, ok := containerKinds[kind]; ok {
+		return true
+	}
+	return strings.HasSuffix(kind, "_body")
+}
@@ -19,6 +19,8 @@ type Element interface {
 	SetRelations(relations []*Relation)
 	GetScope() types.Scope
 	SetScope(scope types.Scope)
+	GetDocComment() *DocComment
+	SetDocComment(doc *DocComment)
 }
 
 // BaseElement 提供接口的基础实现，其他类型嵌入该结构体
@@ -31,6 +33,7 @@ type BaseElement struct {
 	Content          []byte
 	Range            []int32
 	Relations        []*Relation // 与该节点有关的节点
+	DocComment       *DocComment // 定义的前导注释、装饰器及签名
 }
 
 type Relation struct {
@@ -98,6 +101,14 @@ func (e *BaseElement) GetScope() types.Scope {
 	return e.Scope
 }
 
+func (e *BaseElement) SetDocComment(doc *DocComment) {
+	e.DocComment = doc
+}
+
+func (e *BaseElement) GetDocComment() *DocComment {
+	return e.DocComment
+}
+
 // Import 表示导入语句
 type Import struct {
 	*BaseElement
@@ -41,6 +41,8 @@ func resolve(ctx context.Context, b ElementResolver, element Element, rc *Resolv
 		elems, err = b.resolveInterface(ctx, element, rc)
 	case *Call:
 		elems, err = b.resolveCall(ctx, element, rc)
+	case *Reference:
+		elems, err = resolveReference(element, rc)
 	default:
 		rootCap := rc.Match.Captures[0]
 		updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
@@ -51,6 +53,29 @@ func resolve(ctx context.Context, b ElementResolver, element Element, rc *Resolv
 	return FilterValidElems(elems, rc.Logger), err
 }
 
+// resolveReference 解析查询直接捕获的引用，如 JSX 中使用的组件 <Button />、<Form.Item>。
+// 名称为最右侧的部分，其余为 Owner，位置为名称节点的位置
+func resolveReference(element *Reference, rc *ResolveContext) ([]Element, error) {
+	rootCapture := rc.Match.Captures[0]
+	updateRootElement(element, &rootCapture, rc.CaptureNames[rootCapture.Index], rc.SourceFile.Content)
+	for _, capture := range rc.Match.Captures {
+		if types.ToElementType(rc.CaptureNames[capture.Index]) != types.ElementTypeReferenceName {
+			continue
+		}
+		refPath := extractReferencePath(&capture.Node, rc.SourceFile.Content)
+		element.Name = refPath["property"]
+		element.Owner = refPath["object"]
+		element.Range = []int32{
+			int32(capture.Node.StartPosition().Row),
+			int32(capture.Node.StartPosition().Column),
+			int32(capture.Node.EndPosition().Row),
+			int32(capture.Node.EndPosition().Column),
+		}
+	}
+	element.Scope = types.ScopeFunction
+	return []Element{element}, nil
+}
+
 // IsValidElement 检查必须字段
 func IsValidElement(e Element) bool {
 	_, isElement := e.(*Import)
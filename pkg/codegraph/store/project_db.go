@@ -0,0 +1,108 @@
+package store
+
+import (
+	"codebase-indexer/pkg/logger"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ProjectDBs 按项目打开的 LevelDB 集合，每个项目一个数据库，供全文索引、向量存储等附属索引共用
+type ProjectDBs struct {
+	name    string // 用于日志及错误信息，如 text index
+	baseDir string
+	dataDir string
+	logger  logger.Logger
+	clients sync.Map // projectUuid -> *leveldb.DB
+	locks   sync.Map // projectUuid -> *sync.Mutex
+	closed  atomic.Bool
+}
+
+// NewProjectDBs 创建项目数据库集合，数据库位于 baseDir/<projectUuid>/dataDir
+func NewProjectDBs(name string, baseDir string, dataDir string, logger logger.Logger) (*ProjectDBs, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s directory: %w", name, err)
+	}
+	return &ProjectDBs{name: name, baseDir: baseDir, dataDir: dataDir, logger: logger}, nil
+}
+
+// Lock 获取项目锁，返回解锁函数
+func (p *ProjectDBs) Lock(projectUuid string) func() {
+	m, _ := p.locks.LoadOrStore(projectUuid, &sync.Mutex{})
+	mutex := m.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// DB 获取或打开项目数据库，打开失败时删除后重建，调用方需持有项目锁
+func (p *ProjectDBs) DB(projectUuid string) (*leveldb.DB, error) {
+	if p.closed.Load() {
+		return nil, fmt.Errorf("%s is closed", p.name)
+	}
+	if db, ok := p.clients.Load(projectUuid); ok {
+		return db.(*leveldb.DB), nil
+	}
+	dbPath := filepath.Join(p.baseDir, projectUuid, p.dataDir)
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create project directory: %w", err)
+	}
+	dbOptions := &opt.Options{
+		WriteBuffer:        4 * 1024 * 1024,
+		BlockCacheCapacity: 8 * 1024 * 1024,
+	}
+	db, err := leveldb.OpenFile(dbPath, dbOptions)
+	if err != nil {
+		p.logger.Warn("%s open failed, attempting to recreate. project %s err:%v", p.name, projectUuid, err)
+		if removeErr := os.RemoveAll(dbPath); removeErr != nil {
+			return nil, fmt.Errorf("failed to open %s %s: %w", p.name, dbPath, err)
+		}
+		if db, err = leveldb.OpenFile(dbPath, dbOptions); err != nil {
+			return nil, fmt.Errorf("failed to recreate %s %s: %w", p.name, dbPath, err)
+		}
+	}
+	p.clients.Store(projectUuid, db)
+	return db, nil
+}
+
+// Clear 删除项目数据库中的所有数据并压缩，调用方需持有项目锁
+func (p *ProjectDBs) Clear(projectUuid string) error {
+	db, err := p.DB(projectUuid)
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		batch.Delete(append([]byte(nil), iter.Key()...))
+	}
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		return err
+	}
+	if err = db.Write(batch, nil); err != nil {
+		return err
+	}
+	return db.CompactRange(util.Range{})
+}
+
+// Close 关闭所有项目数据库，重复调用直接返回
+func (p *ProjectDBs) Close() error {
+	if !p.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	var errs []error
+	p.clients.Range(func(key, value any) bool {
+		if err := value.(*leveldb.DB).Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close project %s %s: %w", key, p.name, err))
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
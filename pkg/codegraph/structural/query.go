@@ -0,0 +1,176 @@
+package structural
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"fmt"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// DefaultMaxTextLength 单个捕获返回文本的最大字节数，超出部分截断
+const DefaultMaxTextLength = 2000
+
+var queryErrorKinds = map[sitter.QueryErrorKind]string{
+	sitter.QueryErrorSyntax:    "syntax",
+	sitter.QueryErrorNodeType:  "node_type",
+	sitter.QueryErrorField:     "field",
+	sitter.QueryErrorCapture:   "capture",
+	sitter.QueryErrorPredicate: "predicate",
+	sitter.QueryErrorStructure: "structure",
+	sitter.QueryErrorLanguage:  "language",
+}
+
+// QueryError 查询语句编译错误，行列从 1 开始
+type QueryError struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    uint   `json:"line"`
+	Column  uint   `json:"column"`
+	Offset  uint   `json:"offset"`
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query: %s error at line %d column %d: %s", e.Kind, e.Line, e.Column, e.Message)
+}
+
+// Capture 查询命中的单个捕获
+type Capture struct {
+	FilePath string `json:"filePath"`
+	// Name 捕获名，不含 @
+	Name string `json:"name"`
+	// Pattern 命中的模式序号
+	Pattern uint `json:"pattern"`
+	// Match 文件内的匹配序号，同一次匹配的捕获序号相同
+	Match int `json:"match"`
+	// Range startLine, startColumn, endLine, endColumn，从 0 开始
+	Range     []int32 `json:"range"`
+	Text      string  `json:"text"`
+	Truncated bool    `json:"truncated,omitempty"`
+}
+
+// Query 编译后的结构化查询，可在多个协程间共享，每次匹配使用独立的 QueryCursor
+type Query struct {
+	language       lang.Language
+	sitterLanguage *sitter.Language
+	query          *sitter.Query
+	maxTextLength  int
+}
+
+// Compile 按语言编译 tree-sitter 查询语句，语法错误返回 *QueryError
+func Compile(language lang.Language, source string) (*Query, error) {
+	langParser, err := lang.GetSitterParserByLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	sitterLanguage := langParser.SitterLanguage()
+	query, queryErr := sitter.NewQuery(sitterLanguage, source)
+	if queryErr != nil && lang.IsRealQueryErr(queryErr) {
+		return nil, toQueryError(queryErr)
+	}
+	if query.PatternCount() == 0 {
+		query.Close()
+		return nil, &QueryError{Kind: queryErrorKinds[sitter.QueryErrorStructure], Message: "query has no pattern",
+			Line: 1, Column: 1}
+	}
+	return &Query{
+		language:       language,
+		sitterLanguage: sitterLanguage,
+		query:          query,
+		maxTextLength:  DefaultMaxTextLength,
+	}, nil
+}
+
+func toQueryError(err *sitter.QueryError) *QueryError {
+	kind, ok := queryErrorKinds[err.Kind]
+	if !ok {
+		kind = "unknown"
+	}
+	return &QueryError{
+		Kind:    kind,
+		Message: err.Error(),
+		Line:    err.Row + 1,
+		Column:  err.Column + 1,
+		Offset:  err.Offset,
+	}
+}
+
+// Language 查询对应的语言
+func (q *Query) Language() lang.Language {
+	return q.language
+}
+
+// Close 释放查询
+func (q *Query) Close() {
+	q.query.Close()
+}
+
+// Match 解析文件并执行查询，按匹配顺序回调每个捕获，回调返回错误时终止。
+// 单文件组件（.vue）只匹配 lang 与查询语言一致的 <script> 块
+func (q *Query) Match(ctx context.Context, path string, content []byte, emit func(*Capture) error) error {
+	sources, err := lang.ScriptSources(path, content)
+	if err != nil {
+		return err
+	}
+	matchId := 0
+	for _, source := range sources {
+		if lang.IsSFCFile(path) && source.Parser.Language != q.language {
+			continue
+		}
+		if err = q.matchSource(ctx, path, source.Content, &matchId, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchSource 解析内容并执行查询，matchId 为文件内的匹配序号，多个 <script> 块之间连续编号
+func (q *Query) matchSource(ctx context.Context, path string, content []byte, matchId *int,
+	emit func(*Capture) error) error {
+	parser := sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(q.sitterLanguage); err != nil {
+		return err
+	}
+	tree := parser.Parse(content, nil)
+	if tree == nil {
+		return fmt.Errorf("failed to parse file %s", path)
+	}
+	defer tree.Close()
+
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	captureNames := q.query.CaptureNames()
+	matches := qc.Matches(q.query, tree.RootNode(), content)
+	for ; ; *matchId++ {
+		if err := utils.CheckContextCanceled(ctx); err != nil {
+			return err
+		}
+		match := matches.Next()
+		if match == nil {
+			break
+		}
+		for _, capture := range match.Captures {
+			node := capture.Node
+			text := node.Utf8Text(content)
+			truncated := false
+			if q.maxTextLength > 0 && len(text) > q.maxTextLength {
+				text, truncated = text[:q.maxTextLength], true
+			}
+			start, end := node.StartPosition(), node.EndPosition()
+			if err := emit(&Capture{
+				FilePath:  path,
+				Name:      captureNames[capture.Index],
+				Pattern:   match.PatternIndex,
+				Match:     *matchId,
+				Range:     []int32{int32(start.Row), int32(start.Column), int32(end.Row), int32(end.Column)},
+				Text:      text,
+				Truncated: truncated,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package structural
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/lang"
+)
+
+const goSource = `package main
+
+import "sync"
+
+var mu sync.Mutex
+
+func update() {
+	mu.Lock()
+	defer mu.Unlock()
+	defer close()
+}
+`
+
+func TestQuery_Match(t *testing.T) {
+	q, err := Compile(lang.Go, `(defer_statement
+  (call_expression
+    function: (selector_expression field: (field_identifier) @method)) @call
+  (#eq? @method "Unlock"))`)
+	assert.NoError(t, err)
+	defer q.Close()
+
+	var captures []*Capture
+	err = q.Match(context.Background(), "/repo/main.go", []byte(goSource), func(c *Capture) error {
+		captures = append(captures, c)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, captures, 2)
+	texts := map[string]string{}
+	for _, c := range captures {
+		texts[c.Name] = c.Text
+		assert.Equal(t, "/repo/main.go", c.FilePath)
+		assert.Equal(t, 0, c.Match)
+	}
+	assert.Equal(t, "mu.Unlock()", texts["call"])
+	assert.Equal(t, "Unlock", texts["method"])
+	assert.Equal(t, []int32{8, 7, 8, 18}, captures[0].Range)
+}
+
+func TestCompile_InvalidQuery(t *testing.T) {
+	_, err := Compile(lang.Go, "(defer_statement\n  (no_such_node) @x)")
+	var qe *QueryError
+	assert.True(t, errors.As(err, &qe))
+	assert.Equal(t, "node_type", qe.Kind)
+	assert.Equal(t, uint(2), qe.Line)
+	assert.Equal(t, uint(4), qe.Column)
+
+	_, err = Compile(lang.Go, "(defer_statement")
+	assert.True(t, errors.As(err, &qe))
+	assert.Equal(t, "syntax", qe.Kind)
+
+	_, err = Compile(lang.Go, "")
+	assert.True(t, errors.As(err, &qe))
+}
+
+func TestQuery_MatchVue(t *testing.T) {
+	source := []byte(`<template>
+  <div>{{ total }}</div>
+</template>
+
+<script lang="ts">
+interface Props {
+  total: number
+}
+</script>
+`)
+	q, err := Compile(lang.TypeScript, `(interface_declaration name: (type_identifier) @name)`)
+	assert.NoError(t, err)
+	defer q.Close()
+	var captures []*Capture
+	err = q.Match(context.Background(), "/repo/Total.vue", source, func(c *Capture) error {
+		captures = append(captures, c)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, captures, 1) {
+		assert.Equal(t, "Props", captures[0].Text)
+		// 位置为在原文件中的位置
+		assert.Equal(t, []int32{5, 10, 5, 15}, captures[0].Range)
+	}
+
+	// lang 与查询语言不一致的块不匹配
+	js, err := Compile(lang.JavaScript, `(identifier) @id`)
+	assert.NoError(t, err)
+	defer js.Close()
+	err = js.Match(context.Background(), "/repo/Total.vue", source, func(c *Capture) error {
+		t.Errorf("unexpected capture %s", c.Text)
+		return nil
+	})
+	assert.NoError(t, err)
+}
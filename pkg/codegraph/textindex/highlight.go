@@ -0,0 +1,175 @@
+package textindex
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultMaxSnippets = 3
+	maxSnippetLineLen  = 300
+)
+
+// Snippet 命中行，Highlights 为行内命中词的字节区间 [start, end)
+type Snippet struct {
+	Line       int      `json:"line"`
+	Content    string   `json:"content"`
+	Highlights [][2]int `json:"highlights"`
+}
+
+// Highlight 找出命中查询词项最多的行并标注命中区间，结果按行号排序，行号从 1 开始
+func Highlight(content []byte, terms []string, maxSnippets int) []*Snippet {
+	if maxSnippets <= 0 {
+		maxSnippets = DefaultMaxSnippets
+	}
+	termSet := make(map[string]struct{}, len(terms))
+	for _, t := range terms {
+		termSet[t] = struct{}{}
+	}
+
+	type candidate struct {
+		snippet *Snippet
+		matched int
+	}
+	var candidates []*candidate
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) > maxSnippetLineLen {
+			end := maxSnippetLineLen
+			for end > 0 && !utf8.RuneStart(line[end]) {
+				end--
+			}
+			line = line[:end]
+		}
+		var ranges [][2]int
+		matched := make(map[string]struct{})
+		for _, t := range SplitTerms(line) {
+			if _, ok := termSet[t.Text]; ok {
+				ranges = append(ranges, [2]int{t.Start, t.End})
+				matched[t.Text] = struct{}{}
+			}
+		}
+		if len(ranges) == 0 {
+			continue
+		}
+		candidates = append(candidates, &candidate{
+			snippet: &Snippet{Line: i + 1, Content: line, Highlights: mergeRanges(ranges)},
+			matched: len(matched),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].matched > candidates[j].matched
+	})
+	if len(candidates) > maxSnippets {
+		candidates = candidates[:maxSnippets]
+	}
+	snippets := make([]*Snippet, 0, len(candidates))
+	for _, c := range candidates {
+		snippets = append(snippets, c.snippet)
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Line < snippets[j].Line
+	})
+	return snippets
+}
+
+// mergeRanges 合并重叠区间，整词与子词同时命中时只保留整词
+func mergeRanges(ranges [][2]int) [][2]int {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+	merged := [][2]int{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] < last[1] {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// PathFilter 根据 glob 构造路径过滤器，路径先转换为相对 basePath 的形式再匹配。
+// 支持 *、?、**，不含通配符的模式按目录前缀匹配
+func PathFilter(basePath string, includes, excludes []string) func(path string) bool {
+	includeRes := compileGlobs(includes)
+	excludeRes := compileGlobs(excludes)
+	if len(includeRes) == 0 && len(excludeRes) == 0 {
+		return nil
+	}
+	return func(path string) bool {
+		rel := path
+		if basePath != "" {
+			if r, err := filepath.Rel(basePath, path); err == nil {
+				rel = r
+			}
+		}
+		rel = filepath.ToSlash(rel)
+		for _, re := range excludeRes {
+			if re.MatchString(rel) {
+				return false
+			}
+		}
+		if len(includeRes) == 0 {
+			return true
+		}
+		for _, re := range includeRes {
+			if re.MatchString(rel) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func compileGlobs(globs []string) []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, g := range globs {
+		g = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(g)), "./")
+		if g == "" {
+			continue
+		}
+		if !strings.ContainsAny(g, "*?") {
+			g = strings.TrimSuffix(g, "/") + "/**"
+		}
+		res = append(res, globToRegexp(g))
+	}
+	return res
+}
+
+// globToRegexp 将 glob 转换为正则，** 匹配任意层目录，不含 / 的模式匹配任意层级的文件名
+func globToRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		sb.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				sb.WriteString("(.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
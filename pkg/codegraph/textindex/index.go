@@ -0,0 +1,427 @@
+package textindex
+
+import (
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/logger"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	dataDir = "data"
+
+	docKeyPrefix     = "@tdoc:"
+	postingKeyPrefix = "@tpost:"
+	statsKey         = "@tstat"
+	postingSeparator = "\x00"
+
+	// BM25 参数
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	DefaultSearchLimit = 20
+)
+
+// Document 待索引的文档
+type Document struct {
+	Path    string
+	Content []byte
+	// Terms 解析时已从语法树提取的词项及词频，为空时按 Content 分词
+	Terms  map[string]int
+	Length int
+}
+
+// SearchOptions 检索参数
+type SearchOptions struct {
+	Limit int
+	// Filter 返回 false 的路径将被过滤
+	Filter func(path string) bool
+}
+
+// Hit 检索结果
+type Hit struct {
+	Path         string
+	Score        float64
+	MatchedTerms []string
+}
+
+type docRecord struct {
+	Length int            `json:"length"`
+	Terms  map[string]int `json:"terms"`
+}
+
+type indexStats struct {
+	Docs   int `json:"docs"`
+	Length int `json:"length"`
+}
+
+// Index 项目级本地倒排索引，与图存储并列，每个项目一个 LevelDB
+type Index struct {
+	dbs *store.ProjectDBs
+}
+
+// NewIndex 创建全文索引
+func NewIndex(baseDir string, logger logger.Logger) (*Index, error) {
+	dbs, err := store.NewProjectDBs("text index", baseDir, dataDir, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Index{dbs: dbs}, nil
+}
+
+func docKey(path string) []byte {
+	return []byte(docKeyPrefix + path)
+}
+
+func postingKey(term, path string) []byte {
+	return []byte(postingKeyPrefix + term + postingSeparator + path)
+}
+
+func encodePosting(tf, length int) []byte {
+	buf := make([]byte, 2*binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(tf))
+	n += binary.PutUvarint(buf[n:], uint64(length))
+	return buf[:n]
+}
+
+func decodePosting(value []byte) (int, int) {
+	tf, n := binary.Uvarint(value)
+	if n <= 0 {
+		return 0, 0
+	}
+	length, m := binary.Uvarint(value[n:])
+	if m <= 0 {
+		return int(tf), 0
+	}
+	return int(tf), int(length)
+}
+
+func readStats(db *leveldb.DB) (*indexStats, error) {
+	stats := &indexStats{}
+	value, err := db.Get([]byte(statsKey), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(value, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func readDoc(db *leveldb.DB, path string) (*docRecord, error) {
+	value, err := db.Get(docKey(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	doc := new(docRecord)
+	if err = json.Unmarshal(value, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// writeDoc 写入文档及倒排项，old 非空时先清除旧的倒排项
+func writeDoc(batch *leveldb.Batch, stats *indexStats, path string, old, doc *docRecord) error {
+	if old != nil {
+		removeDoc(batch, stats, path, old)
+	}
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	batch.Put(docKey(path), value)
+	for term, tf := range doc.Terms {
+		batch.Put(postingKey(term, path), encodePosting(tf, doc.Length))
+	}
+	stats.Docs++
+	stats.Length += doc.Length
+	return nil
+}
+
+func removeDoc(batch *leveldb.Batch, stats *indexStats, path string, doc *docRecord) {
+	batch.Delete(docKey(path))
+	for term := range doc.Terms {
+		batch.Delete(postingKey(term, path))
+	}
+	stats.Docs--
+	stats.Length -= doc.Length
+}
+
+func commit(db *leveldb.DB, batch *leveldb.Batch, stats *indexStats) error {
+	value, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	batch.Put([]byte(statsKey), value)
+	return db.Write(batch, nil)
+}
+
+// IndexFiles 分词并写入文档，已存在的文档将被替换
+func (x *Index) IndexFiles(ctx context.Context, projectUuid string, docs []*Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	unlock := x.dbs.Lock(projectUuid)
+	defer unlock()
+	db, err := x.dbs.DB(projectUuid)
+	if err != nil {
+		return err
+	}
+	stats, err := readStats(db)
+	if err != nil {
+		return fmt.Errorf("read text index stats err:%w", err)
+	}
+	// 同一批次中重复的路径只保留最后一次，避免重复计入统计
+	latest := make(map[string]int, len(docs))
+	for idx, d := range docs {
+		latest[d.Path] = idx
+	}
+	batch := new(leveldb.Batch)
+	for idx, d := range docs {
+		if latest[d.Path] != idx {
+			continue
+		}
+		if err = utils.CheckContext(ctx); err != nil {
+			return err
+		}
+		terms, length := d.Terms, d.Length
+		if terms == nil {
+			terms, length = Tokenize(d.Path, d.Content)
+		}
+		old, err := readDoc(db, d.Path)
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			return fmt.Errorf("read text index doc %s err:%w", d.Path, err)
+		}
+		if err = writeDoc(batch, stats, d.Path, old, &docRecord{Length: length, Terms: terms}); err != nil {
+			return err
+		}
+	}
+	return commit(db, batch, stats)
+}
+
+// collectDocs 按文件路径精确匹配，匹配不到时按目录前缀匹配
+func collectDocs(db *leveldb.DB, path string) (map[string]*docRecord, error) {
+	docs := make(map[string]*docRecord)
+	doc, err := readDoc(db, path)
+	if err == nil {
+		docs[path] = doc
+		return docs, nil
+	}
+	if !errors.Is(err, leveldb.ErrNotFound) {
+		return nil, err
+	}
+	iter := db.NewIterator(util.BytesPrefix(docKey(utils.EnsureTrailingSeparator(path))), nil)
+	defer iter.Release()
+	for iter.Next() {
+		doc := new(docRecord)
+		if err = json.Unmarshal(iter.Value(), doc); err != nil {
+			return nil, err
+		}
+		docs[strings.TrimPrefix(string(iter.Key()), docKeyPrefix)] = doc
+	}
+	return docs, iter.Error()
+}
+
+// RemoveFiles 删除文件（或目录下所有文件）的索引
+func (x *Index) RemoveFiles(ctx context.Context, projectUuid string, paths []string) error {
+	unlock := x.dbs.Lock(projectUuid)
+	defer unlock()
+	db, err := x.dbs.DB(projectUuid)
+	if err != nil {
+		return err
+	}
+	stats, err := readStats(db)
+	if err != nil {
+		return fmt.Errorf("read text index stats err:%w", err)
+	}
+	batch := new(leveldb.Batch)
+	for _, p := range paths {
+		docs, err := collectDocs(db, p)
+		if err != nil {
+			return fmt.Errorf("collect text index docs %s err:%w", p, err)
+		}
+		for path, doc := range docs {
+			removeDoc(batch, stats, path, doc)
+		}
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return commit(db, batch, stats)
+}
+
+// RenameFiles 将文件（或目录）的索引由 sourcePath 重命名为 targetPath，支持跨项目
+func (x *Index) RenameFiles(ctx context.Context, sourceProjectUuid, targetProjectUuid, sourcePath, targetPath string) error {
+	sourcePath, targetPath = utils.TrimLastSeparator(sourcePath), utils.TrimLastSeparator(targetPath)
+	unlock := x.dbs.Lock(sourceProjectUuid)
+	db, err := x.dbs.DB(sourceProjectUuid)
+	if err != nil {
+		unlock()
+		return err
+	}
+	docs, err := collectDocs(db, sourcePath)
+	if err != nil || len(docs) == 0 {
+		unlock()
+		return err
+	}
+	stats, err := readStats(db)
+	if err != nil {
+		unlock()
+		return fmt.Errorf("read text index stats err:%w", err)
+	}
+	batch := new(leveldb.Batch)
+	for path, doc := range docs {
+		removeDoc(batch, stats, path, doc)
+	}
+	err = commit(db, batch, stats)
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	unlock = x.dbs.Lock(targetProjectUuid)
+	defer unlock()
+	if db, err = x.dbs.DB(targetProjectUuid); err != nil {
+		return err
+	}
+	if stats, err = readStats(db); err != nil {
+		return fmt.Errorf("read text index stats err:%w", err)
+	}
+	batch = new(leveldb.Batch)
+	for path, doc := range docs {
+		newPath := targetPath + strings.TrimPrefix(path, sourcePath)
+		old, err := readDoc(db, newPath)
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			return err
+		}
+		if err = writeDoc(batch, stats, newPath, old, doc); err != nil {
+			return err
+		}
+	}
+	return commit(db, batch, stats)
+}
+
+// DeleteAll 删除项目的所有全文索引
+func (x *Index) DeleteAll(ctx context.Context, projectUuid string) error {
+	unlock := x.dbs.Lock(projectUuid)
+	defer unlock()
+	return x.dbs.Clear(projectUuid)
+}
+
+// Search 按 BM25 对查询词项打分，返回得分最高的文件
+func (x *Index) Search(ctx context.Context, projectUuid string, query string, opts SearchOptions) ([]*Hit, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSearchLimit
+	}
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("query %q contains no searchable terms", query)
+	}
+
+	unlock := x.dbs.Lock(projectUuid)
+	defer unlock()
+	db, err := x.dbs.DB(projectUuid)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := readStats(db)
+	if err != nil {
+		return nil, fmt.Errorf("read text index stats err:%w", err)
+	}
+	if stats.Docs == 0 {
+		return []*Hit{}, nil
+	}
+	avgLength := float64(stats.Length) / float64(stats.Docs)
+
+	hits := make(map[string]*Hit)
+	for _, term := range terms {
+		if err = utils.CheckContext(ctx); err != nil {
+			return nil, err
+		}
+		type posting struct {
+			path       string
+			tf, length int
+		}
+		var postings []posting
+		// 文档频率按全部文档统计，idf 不随路径过滤变化
+		docFreq := 0
+		prefix := []byte(postingKeyPrefix + term + postingSeparator)
+		iter := db.NewIterator(util.BytesPrefix(prefix), nil)
+		for iter.Next() {
+			docFreq++
+			path := string(iter.Key()[len(prefix):])
+			if opts.Filter != nil && !opts.Filter(path) {
+				continue
+			}
+			tf, length := decodePosting(iter.Value())
+			postings = append(postings, posting{path: path, tf: tf, length: length})
+		}
+		iter.Release()
+		if err = iter.Error(); err != nil {
+			return nil, err
+		}
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(docFreq)
+		idf := math.Log(1 + (float64(stats.Docs)-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.tf)
+			score := idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(p.length)/avgLength))
+			hit, ok := hits[p.path]
+			if !ok {
+				hit = &Hit{Path: p.path}
+				hits[p.path] = hit
+			}
+			hit.Score += score
+			hit.MatchedTerms = append(hit.MatchedTerms, term)
+		}
+	}
+
+	res := make([]*Hit, 0, len(hits))
+	for _, h := range hits {
+		res = append(res, h)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Path < res[j].Path
+	})
+	if len(res) > opts.Limit {
+		res = res[:opts.Limit]
+	}
+	return res, nil
+}
+
+// QueryTerms 将查询语句拆分为去重后的词项
+func QueryTerms(query string) []string {
+	seen := make(map[string]struct{})
+	var terms []string
+	for _, t := range SplitTerms(query) {
+		if _, ok := seen[t.Text]; ok {
+			continue
+		}
+		seen[t.Text] = struct{}{}
+		terms = append(terms, t.Text)
+	}
+	return terms
+}
+
+// Close 关闭所有项目数据库
+func (x *Index) Close() error {
+	return x.dbs.Close()
+}
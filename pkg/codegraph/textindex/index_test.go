@@ -0,0 +1,176 @@
+package textindex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/logger"
+)
+
+func termTexts(terms []Term) []string {
+	res := make([]string, 0, len(terms))
+	for _, t := range terms {
+		res = append(res, t.Text)
+	}
+	return res
+}
+
+func TestSplitTerms(t *testing.T) {
+	assert.Equal(t, []string{"parsehttprequest", "parse", "http", "request"}, termTexts(SplitTerms("parseHTTPRequest")))
+	assert.Equal(t, []string{"max_batch_size", "max", "batch", "size"}, termTexts(SplitTerms("MAX_BATCH_SIZE")))
+	assert.Equal(t, []string{"load", "config", "file"}, termTexts(SplitTerms("load config: file 42 a")))
+
+	terms := SplitTerms("x := getUserName()")
+	assert.Equal(t, "getusername", terms[0].Text)
+	assert.Equal(t, 5, terms[0].Start)
+	assert.Equal(t, 16, terms[0].End)
+}
+
+func TestTokenize(t *testing.T) {
+	content := []byte(`package main
+
+// loadConfig reads the settings file
+func loadConfig() string {
+	return "default_path"
+}
+`)
+	terms, length := Tokenize("/repo/main.go", content)
+	assert.Equal(t, 2, terms["loadconfig"])
+	assert.Equal(t, 2, terms["config"])
+	assert.Equal(t, 1, terms["settings"])
+	assert.Equal(t, 1, terms["default_path"])
+	// 关键字不参与索引
+	assert.Zero(t, terms["func"])
+	assert.Zero(t, terms["return"])
+	assert.Greater(t, length, 0)
+}
+
+func TestTokenize_SFC(t *testing.T) {
+	content := []byte(`<template>
+  <UserCard :title="pageTitle" />
+</template>
+
+<script setup lang="ts">
+// loadUser fetches the profile
+const pageTitle: string = "user_profile"
+</script>
+`)
+	terms, length := Tokenize("/repo/UserList.vue", content)
+	// 脚本块按 TypeScript 语法分词，类型关键字不参与索引
+	assert.Equal(t, 1, terms["loaduser"])
+	assert.Equal(t, 1, terms["user_profile"])
+	assert.Zero(t, terms["const"])
+	// 模板按纯文本分词
+	assert.Equal(t, 1, terms["usercard"])
+	assert.Equal(t, 2, terms["pagetitle"])
+	assert.Greater(t, length, 0)
+}
+
+func newTestIndex(t *testing.T) *Index {
+	l, err := logger.NewLogger("/tmp/logs", "info", "codebase-indexer")
+	assert.NoError(t, err)
+	idx, err := NewIndex(t.TempDir(), l)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = idx.Close() })
+	return idx
+}
+
+func TestIndex_Search(t *testing.T) {
+	ctx := context.Background()
+	idx := newTestIndex(t)
+	err := idx.IndexFiles(ctx, "p1", []*Document{
+		{Path: "/repo/config/loader.go", Content: []byte("package config\n\n// LoadConfig loads config\nfunc LoadConfig() {}\n")},
+		{Path: "/repo/server/server.go", Content: []byte("package server\n\nfunc Start() { config.LoadConfig() }\n")},
+		{Path: "/repo/util/strings.go", Content: []byte("package util\n\nfunc Trim(s string) string { return s }\n")},
+	})
+	assert.NoError(t, err)
+
+	hits, err := idx.Search(ctx, "p1", "loadConfig", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+	assert.Equal(t, "/repo/config/loader.go", hits[0].Path)
+	assert.Contains(t, hits[0].MatchedTerms, "loadconfig")
+
+	hits, err = idx.Search(ctx, "p1", "loadConfig", SearchOptions{Filter: PathFilter("/repo", []string{"server"}, nil)})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "/repo/server/server.go", hits[0].Path)
+
+	// 删除目录
+	assert.NoError(t, idx.RemoveFiles(ctx, "p1", []string{"/repo/config"}))
+	hits, err = idx.Search(ctx, "p1", "loadConfig", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	// 重命名文件
+	assert.NoError(t, idx.RenameFiles(ctx, "p1", "p1", "/repo/server/server.go", "/repo/server/main.go"))
+	hits, err = idx.Search(ctx, "p1", "start", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "/repo/server/main.go", hits[0].Path)
+
+	assert.NoError(t, idx.DeleteAll(ctx, "p1"))
+	hits, err = idx.Search(ctx, "p1", "trim", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestIndex_SearchScoring(t *testing.T) {
+	ctx := context.Background()
+	idx := newTestIndex(t)
+	err := idx.IndexFiles(ctx, "p1", []*Document{
+		{Path: "/repo/a/loader.go", Content: []byte("package a\n\nfunc LoadConfig() {}\n")},
+		{Path: "/repo/b/loader.go", Content: []byte("package b\n\nfunc LoadConfig() {}\n")},
+		// 同一批次中的重复路径只计一次，以最后一次为准
+		{Path: "/repo/c/util.go", Content: []byte("package c\n\nfunc LoadConfig() {}\n")},
+		{Path: "/repo/c/util.go", Content: []byte("package c\n\nfunc Trim() {}\n")},
+		// 预先分词的结果直接写入
+		{Path: "/repo/d/doc.md", Terms: map[string]int{"readme": 1}, Length: 1},
+	})
+	assert.NoError(t, err)
+
+	hits, err := idx.Search(ctx, "p1", "loadConfig", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+	all := hits[0].Score
+
+	// 路径过滤不影响 idf，得分保持不变
+	hits, err = idx.Search(ctx, "p1", "loadConfig", SearchOptions{Filter: PathFilter("/repo", []string{"a"}, nil)})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.InDelta(t, all, hits[0].Score, 1e-9)
+	}
+
+	hits, err = idx.Search(ctx, "p1", "readme", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	// 删除全部文档后统计归零
+	assert.NoError(t, idx.RemoveFiles(ctx, "p1", []string{"/repo"}))
+	db, err := idx.dbs.DB("p1")
+	assert.NoError(t, err)
+	stats, err := readStats(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Docs)
+	assert.Equal(t, 0, stats.Length)
+}
+
+func TestHighlight(t *testing.T) {
+	content := []byte("package config\n\n// LoadConfig loads config\nfunc LoadConfig() {}\n")
+	snippets := Highlight(content, QueryTerms("loadConfig"), 1)
+	assert.Len(t, snippets, 1)
+	assert.Equal(t, 3, snippets[0].Line)
+	assert.Equal(t, [][2]int{{3, 13}, {20, 26}}, snippets[0].Highlights)
+}
+
+func TestPathFilter(t *testing.T) {
+	filter := PathFilter("/repo", []string{"**/*.go"}, []string{"vendor", "*_test.go"})
+	assert.True(t, filter("/repo/a/b.go"))
+	assert.True(t, filter("/repo/b.go"))
+	assert.False(t, filter("/repo/a/b_test.go"))
+	assert.False(t, filter("/repo/vendor/x/y.go"))
+	assert.False(t, filter("/repo/a/b.py"))
+	assert.Nil(t, PathFilter("/repo", nil, nil))
+}
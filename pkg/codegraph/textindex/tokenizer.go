@@ -0,0 +1,231 @@
+package textindex
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+const minTermLength = 2
+
+// Term 分词结果，Start/End 为在原文本中的字节偏移
+type Term struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// Tokenize 使用 tree-sitter 提取源码中的标识符、注释与字符串字面量并分词，
+// 返回词项到词频的映射及文档长度。不支持的语言按纯文本分词。已有语法树时使用 TokenizeTree，避免重复解析
+func Tokenize(path string, content []byte) (map[string]int, int) {
+	if lang.IsSFCFile(path) {
+		return tokenizeSFC(content)
+	}
+	langParser, err := lang.GetSitterParserByFilePath(path)
+	if err != nil {
+		return tokenizeText(content)
+	}
+	parser := sitter.NewParser()
+	defer parser.Close()
+	if err = parser.SetLanguage(langParser.SitterLanguage()); err != nil {
+		return tokenizeText(content)
+	}
+	tree := parser.Parse(content, nil)
+	if tree == nil {
+		return tokenizeText(content)
+	}
+	defer tree.Close()
+	return TokenizeTree(tree.RootNode(), content)
+}
+
+// tokenizeSFC 单文件组件的 <script> 块按其 lang 解析后分词，<template>、<style> 等其余内容按纯文本分词
+func tokenizeSFC(content []byte) (map[string]int, int) {
+	rest := append([]byte(nil), content...)
+	freqs := make(map[string]int)
+	length := 0
+	parser := sitter.NewParser()
+	defer parser.Close()
+	for _, block := range lang.SplitSFCBlocks(content) {
+		if block.Tag != "script" {
+			continue
+		}
+		langParser, err := lang.GetSitterParserByLanguage(block.ScriptLanguage())
+		if err != nil || parser.SetLanguage(langParser.SitterLanguage()) != nil {
+			continue
+		}
+		masked := lang.MaskOutside(content, block.Start, block.End)
+		tree := parser.Parse(masked, nil)
+		if tree == nil {
+			continue
+		}
+		blockFreqs, blockLength := TokenizeTree(tree.RootNode(), masked)
+		tree.Close()
+		for term, n := range blockFreqs {
+			freqs[term] += n
+		}
+		length += blockLength
+		// 已分词的脚本内容不再按纯文本重复计数
+		for i := block.Start; i < block.End; i++ {
+			rest[i] = ' '
+		}
+	}
+	restFreqs, restLength := tokenizeText(rest)
+	for term, n := range restFreqs {
+		freqs[term] += n
+	}
+	return freqs, length + restLength
+}
+
+// TokenizeTree 从已解析的语法树中提取标识符、注释与字符串字面量并分词
+func TokenizeTree(root *sitter.Node, content []byte) (map[string]int, int) {
+	freqs := make(map[string]int)
+	length := 0
+	add := func(text string) {
+		for _, t := range SplitTerms(text) {
+			freqs[t.Text]++
+			length++
+		}
+	}
+
+	cursor := root.Walk()
+	defer cursor.Close()
+	for {
+		node := cursor.Node()
+		kind := node.Kind()
+		// 注释、字符串整体取文本，不再下探子节点
+		leaf := isTextNode(kind) || (node.ChildCount() == 0 && isIdentifierNode(kind))
+		if leaf {
+			add(string(content[node.StartByte():node.EndByte()]))
+		}
+		if !leaf && cursor.GotoFirstChild() {
+			continue
+		}
+		for !cursor.GotoNextSibling() {
+			if !cursor.GotoParent() {
+				return freqs, length
+			}
+		}
+	}
+}
+
+// tokenizeText 按纯文本分词
+func tokenizeText(content []byte) (map[string]int, int) {
+	freqs := make(map[string]int)
+	terms := SplitTerms(string(content))
+	for _, t := range terms {
+		freqs[t.Text]++
+	}
+	return freqs, len(terms)
+}
+
+func isTextNode(kind string) bool {
+	return strings.Contains(kind, "comment") || strings.Contains(kind, "string") ||
+		kind == "char_literal" || kind == "character_literal"
+}
+
+func isIdentifierNode(kind string) bool {
+	return strings.Contains(kind, "identifier") || kind == "name" || kind == "constant"
+}
+
+// SplitTerms 将文本拆分为小写词项。标识符保留整体，并按 camelCase、snake_case 拆分出子词，
+// 如 parseHTTPRequest -> parsehttprequest, parse, http, request
+func SplitTerms(text string) []Term {
+	var terms []Term
+	start := -1
+	for i := 0; i <= len(text); {
+		r, size := utf8.RuneError, 1
+		if i < len(text) {
+			r, size = utf8.DecodeRuneInString(text[i:])
+		}
+		isWord := i < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			terms = appendWordTerms(terms, text[start:i], start)
+			start = -1
+		}
+		i += size
+	}
+	return terms
+}
+
+// appendWordTerms 添加单个单词及其子词
+func appendWordTerms(terms []Term, word string, offset int) []Term {
+	parts := splitWord(word)
+	whole := strings.Trim(word, "_")
+	if len(parts) != 1 && validTerm(whole) {
+		trimmed := offset + strings.Index(word, whole)
+		terms = append(terms, Term{Text: strings.ToLower(whole), Start: trimmed, End: trimmed + len(whole)})
+	}
+	for _, p := range parts {
+		text := word[p[0]:p[1]]
+		if !validTerm(text) {
+			continue
+		}
+		terms = append(terms, Term{Text: strings.ToLower(text), Start: offset + p[0], End: offset + p[1]})
+	}
+	return terms
+}
+
+func validTerm(text string) bool {
+	if utf8.RuneCountInString(text) < minTermLength {
+		return false
+	}
+	for _, r := range text {
+		if !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWord 按下划线及大小写边界拆分单词，返回子词的字节区间
+func splitWord(word string) [][2]int {
+	var parts [][2]int
+	runes := []rune(word)
+	offsets := make([]int, len(runes)+1)
+	for i, pos := 0, 0; i < len(runes); i++ {
+		offsets[i] = pos
+		pos += utf8.RuneLen(runes[i])
+		offsets[i+1] = pos
+	}
+
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			parts = append(parts, [2]int{offsets[start], offsets[end]})
+		}
+		start = -1
+	}
+	for i, r := range runes {
+		if r == '_' {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		// fooBar
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush(i)
+			start = i
+		// HTTPServer -> HTTP, Server
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			flush(i)
+			start = i
+		// v2Api -> v2, Api
+		case unicode.IsLetter(r) && unicode.IsDigit(prev) && unicode.IsUpper(r):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return parts
+}
@@ -138,6 +138,7 @@ const (
 	ElementTypeComment                  ElementType = "comment"
 	ElementTypeAnnotation               ElementType = "annotation"
 	ElementTypeReference                ElementType = "reference"
+	ElementTypeReferenceName            ElementType = "reference.name"
 )
 
 // TypeMappings 类型映射表 - captureName -> ElementType（使用ElementType字符串值作为键）
@@ -224,6 +225,8 @@ var TypeMappings = map[string]ElementType{
 	string(ElementTypeParameter):                ElementTypeParameter,
 	string(ElementTypeComment):                  ElementTypeComment,
 	string(ElementTypeAnnotation):               ElementTypeAnnotation,
+	string(ElementTypeReference):                ElementTypeReference,
+	string(ElementTypeReferenceName):            ElementTypeReferenceName,
 	string(ElementTypeStructCall):               ElementTypeStructCall,
 	string(ElementTypeStructCallType):           ElementTypeStructCallType,
 	string(ElementTypeNewExpression):            ElementTypeNewExpression,
@@ -259,6 +262,8 @@ const (
 type SourceFile struct {
 	Path    string
 	Content []byte
+	// CollectTerms 解析时同时从语法树提取全文索引词项
+	CollectTerms bool
 }
 type NodeKind string
 
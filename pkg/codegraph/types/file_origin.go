@@ -0,0 +1,21 @@
+package types
+
+// FileOrigin 文件来源，非手写的文件在检索结果中降权
+type FileOrigin string
+
+const (
+	FileOriginSource    FileOrigin = ""          // 手写的源码
+	FileOriginGenerated FileOrigin = "generated" // 生成的代码，如 *.pb.go、带 "Code generated ... DO NOT EDIT." 头的文件
+	FileOriginVendored  FileOrigin = "vendored"  // 复制进仓库的第三方代码
+	FileOriginMinified  FileOrigin = "minified"  // 压缩、打包后的代码
+)
+
+// DefinitionOnly 只索引定义，不索引调用与引用
+func (o FileOrigin) DefinitionOnly() bool {
+	return o == FileOriginGenerated || o == FileOriginVendored
+}
+
+// Excluded 不索引
+func (o FileOrigin) Excluded() bool {
+	return o == FileOriginMinified
+}
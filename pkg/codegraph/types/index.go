@@ -32,11 +32,19 @@ type CodeDefinition struct {
 }
 
 type Definition struct {
-	Name    string
-	Type    string
-	Path    string
-	Range   []int32
-	Content []byte
+	Name       string
+	Type       string
+	Path       string
+	Range      []int32
+	Content    []byte
+	Doc        string     // 前导注释
+	Decorators []string   // 装饰器/注解
+	Signature  string     // 定义头部，不含函数体
+	Container  string     // 所属的类、接口或方法接收者
+	Confidence float64    // 候选定义的置信度，0~1
+	Reason     string     // 置信度依据
+	Ambiguous  bool       // 存在多个同样匹配的重载
+	Origin     FileOrigin // 定义所在文件的来源，生成、第三方的文件降权
 }
 
 type QueryDefinitionOptions struct {
@@ -45,6 +53,95 @@ type QueryDefinitionOptions struct {
 	Workspace   string
 	FilePath    string
 	CodeSnippet []byte
+	// MinConfidence 过滤置信度低于该值的候选定义
+	MinConfidence float64
+}
+
+type QueryTextOptions struct {
+	Workspace    string
+	Query        string
+	Limit        int
+	IncludePaths []string
+	ExcludePaths []string
+}
+
+type QuerySemanticOptions struct {
+	Workspace    string
+	Query        string
+	Limit        int
+	Hybrid       bool
+	IncludePaths []string
+	ExcludePaths []string
+}
+
+type QueryHoverOptions struct {
+	Workspace string
+	FilePath  string
+	Line      int // 从 1 开始
+	Column    int // 从 1 开始，按字符计
+}
+
+// SymbolHover 光标处的标识符及其定义
+type SymbolHover struct {
+	Name        string
+	Range       []int32 // 标识符位置，列为字节偏移
+	Definitions []*Definition
+}
+
+type QueryRenameOptions struct {
+	Workspace string
+	FilePath  string
+	Line      int // 从 1 开始
+	Column    int // 从 1 开始，按字符计
+	NewName   string
+}
+
+const (
+	RenameConflictAmbiguous  = "ambiguous"  // 符号解析到多个定义
+	RenameConflictExists     = "exists"     // 定义所在包中已存在同名定义
+	RenameConflictShadowing  = "shadowing"  // 引用所在文件中已存在同名符号，重命名后会被遮蔽或遮蔽它
+	RenameConflictUnresolved = "unresolved" // 同名引用无法确定是否指向该定义，未重命名
+)
+
+// TextEdit 将 Range 处的文本替换为 NewText
+type TextEdit struct {
+	Range   []int32
+	NewText string
+}
+
+// RenameConflict 重命名冲突
+type RenameConflict struct {
+	Kind    string
+	Path    string
+	Name    string
+	Range   []int32
+	Message string
+}
+
+// RenameResult 重命名预览：文件路径 -> 编辑列表
+type RenameResult struct {
+	Name       string
+	NewName    string
+	Definition *Definition
+	Edits      map[string][]*TextEdit
+	Conflicts  []*RenameConflict
+}
+
+type QueryStructuralOptions struct {
+	Workspace    string
+	Language     string
+	Query        string
+	Limit        int // 最多返回的捕获数，0 不限制
+	IncludePaths []string
+	ExcludePaths []string
+}
+
+// StructuralSearchStats 结构化检索统计
+type StructuralSearchStats struct {
+	Files     int  // 扫描的文件数
+	Matched   int  // 有命中的文件数
+	Captures  int  // 返回的捕获数
+	Truncated bool // 达到 Limit 后提前结束
 }
 
 type QueryReferenceOptions struct {
@@ -61,13 +158,31 @@ type RelationNode struct {
 	Position   Position        `json:"position,omitempty"`
 	Content    string          `json:"content,omitempty"`
 	NodeType   string          `json:"nodeType,omitempty"`
+	Ambiguous  bool            `json:"ambiguous,omitempty"` // 调用同样匹配多个重载
 	Children   []*RelationNode `json:"children,omitempty"`
 }
 
+// ReferenceVisitor 引用遍历回调：先对每个根定义回调一次（reference 为 nil），再随索引遍历对每个引用回调，返回错误时终止遍历
+type ReferenceVisitor func(definition *RelationNode, reference *RelationNode) error
+
 type CodeGraphSummary struct {
 	TotalFiles int `json:"totalFiles"`
 }
 
+// GovernorState 后台索引资源调节器的状态，系统指标不可用（非 Linux）时为零值
+type GovernorState struct {
+	Concurrency    int     `json:"concurrency"`      // 当前并发批次数
+	MaxConcurrency int     `json:"maxConcurrency"`   // 并发批次数上限
+	Paused         bool    `json:"paused"`           // 批次之间是否暂停
+	PauseMs        int64   `json:"pauseMs"`          // 暂停时长
+	Reason         string  `json:"reason,omitempty"` // 最近一次调整的原因
+	Load1          float64 `json:"load1"`            // 1 分钟平均负载
+	CPUs           int     `json:"cpus"`
+	RSSMB          int     `json:"rssMB"`       // 进程常驻内存
+	AvailableMB    int     `json:"availableMB"` // 系统可用内存
+	UpdatedAt      int64   `json:"updatedAt"`   // 最近一次采样时间（Unix 秒）
+}
+
 type Position struct {
 	StartLine   int `json:"startLine"`   // 开始行（从1开始）
 	StartColumn int `json:"startColumn"` // 开始列（从1开始）
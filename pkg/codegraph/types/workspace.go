@@ -67,6 +67,13 @@ type VisitPattern struct {
 	ExcludeDirs     []string
 	IncludeDirs     []string
 	SkipFunc        SkipFunc
+	// KeepDirFunc 隐藏目录下仍有需要访问的路径时返回 true，如 include 规则指向其中的文件，为空时跳过隐藏目录
+	KeepDirFunc func(dirPath string) bool
+}
+
+// KeepDir 默认跳过的目录是否仍需进入
+func (v *VisitPattern) KeepDir(dirPath string) bool {
+	return v != nil && v.KeepDirFunc != nil && v.KeepDirFunc(dirPath)
 }
 
 func (v *VisitPattern) ShouldSkip(fileInfo *FileInfo) (bool, error) {
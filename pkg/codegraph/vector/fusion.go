@@ -0,0 +1,35 @@
+package vector
+
+import "sort"
+
+// DefaultRRFConstant 倒数排名融合的平滑常数
+const DefaultRRFConstant = 60
+
+// FuseRRF 倒数排名融合多路检索结果，同一分块的得分为各路 1/(k+rank) 之和
+func FuseRRF(k int, lists ...[]*Hit) []*Hit {
+	if k <= 0 {
+		k = DefaultRRFConstant
+	}
+	fused := make(map[string]*Hit)
+	var order []string
+	for _, list := range lists {
+		for rank, h := range list {
+			key := string(recordKey(h.Chunk))
+			hit, ok := fused[key]
+			if !ok {
+				hit = &Hit{Chunk: h.Chunk}
+				fused[key] = hit
+				order = append(order, key)
+			}
+			hit.Score += 1 / float64(k+rank+1)
+		}
+	}
+	res := make([]*Hit, 0, len(order))
+	for _, key := range order {
+		res = append(res, fused[key])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res
+}
@@ -0,0 +1,198 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"codebase-indexer/pkg/codegraph/textindex"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+
+	defaultFakeDimensions = 256
+	defaultBatchSize      = 16
+	defaultRequestTimeout = 60 * time.Second
+)
+
+// Provider 向量化服务
+type Provider interface {
+	// Embed 返回与 texts 一一对应的向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ProviderConfig 向量化服务配置
+type ProviderConfig struct {
+	// Provider openai 或 fake，为空时不启用本地向量检索
+	Provider string
+	// BaseURL OpenAI 兼容接口地址，如 http://localhost:11434/v1
+	BaseURL    string
+	Model      string
+	ApiKey     string
+	Dimensions int
+	BatchSize  int
+}
+
+// NewProvider 根据配置创建向量化服务
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg)
+	case ProviderFake:
+		return NewFakeProvider(cfg.Dimensions), nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider %q", cfg.Provider)
+	}
+}
+
+// OpenAIProvider 调用 OpenAI 兼容的 /embeddings 接口，可对接 llama.cpp、Ollama 等本地服务
+type OpenAIProvider struct {
+	baseURL   string
+	model     string
+	apiKey    string
+	batchSize int
+	client    *http.Client
+}
+
+// NewOpenAIProvider 创建 OpenAI 兼容的向量化服务
+func NewOpenAIProvider(cfg ProviderConfig) (*OpenAIProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("embedding base url is required")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("embedding model is required")
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &OpenAIProvider{
+		baseURL:   strings.TrimSuffix(cfg.BaseURL, "/"),
+		model:     cfg.Model,
+		apiKey:    cfg.ApiKey,
+		batchSize: batchSize,
+		client:    &http.Client{Timeout: defaultRequestTimeout},
+	}, nil
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed 按批次请求向量
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += p.batchSize {
+		end := min(start+p.batchSize, len(texts))
+		vectors, err := p.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, vectors...)
+	}
+	return res, nil
+}
+
+func (p *OpenAIProvider) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(&embeddingRequest{Model: p.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request embeddings err:%w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request embeddings failed, status: %d, body: %s", resp.StatusCode, string(respBody))
+	}
+	var data embeddingResponse
+	if err = json.Unmarshal(respBody, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal embeddings response err:%w", err)
+	}
+	if len(data.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings response size %d not match input size %d", len(data.Data), len(texts))
+	}
+	sort.Slice(data.Data, func(i, j int) bool {
+		return data.Data[i].Index < data.Data[j].Index
+	})
+	vectors := make([][]float32, len(data.Data))
+	for i, d := range data.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// FakeProvider 将词项哈希到固定维度的词袋向量，结果确定，用于测试及离线调试
+type FakeProvider struct {
+	dimensions int
+}
+
+// NewFakeProvider 创建测试用向量化服务
+func NewFakeProvider(dimensions int) *FakeProvider {
+	if dimensions <= 0 {
+		dimensions = defaultFakeDimensions
+	}
+	return &FakeProvider{dimensions: dimensions}
+}
+
+// Embed 词项哈希计数后归一化
+func (p *FakeProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, p.dimensions)
+		for _, t := range textindex.SplitTerms(text) {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(t.Text))
+			vector[h.Sum32()%uint32(p.dimensions)]++
+		}
+		res[i] = normalize(vector)
+	}
+	return res, nil
+}
+
+// normalize 归一化为单位向量，余弦相似度即为点积
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
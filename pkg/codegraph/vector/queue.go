@@ -0,0 +1,223 @@
+package vector
+
+import (
+	"codebase-indexer/pkg/codegraph/chunker"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// embedQueueSize 等待向量化的批次上限，队列满时入队阻塞
+const embedQueueSize = 64
+
+// embedJob 一批待向量化的文件
+type embedJob struct {
+	projectUuid string
+	paths       []string
+	chunks      []*chunker.Chunk
+	seq         uint64
+}
+
+// embedQueue 后台向量化队列，单个 worker 按入队顺序处理。
+// latest 记录每个文件最近一次入队的序号，只有序号仍为最新的文件才会写入，
+// 文件在向量化期间被删除、重命名或再次入队时，旧批次的结果被丢弃
+type embedQueue struct {
+	store  *Store
+	jobs   chan *embedJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	idle     *sync.Cond
+	inflight int
+	seq      uint64
+	latest   map[string]map[string]uint64 // projectUuid -> path -> seq
+	closed   bool
+}
+
+func newEmbedQueue(s *Store) *embedQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &embedQueue{
+		store:  s,
+		jobs:   make(chan *embedJob, embedQueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		latest: make(map[string]map[string]uint64),
+	}
+	q.idle = sync.NewCond(&q.mu)
+	q.wg.Add(1)
+	go q.run()
+	return q
+}
+
+// Enqueue 将文件的分块加入后台向量化队列，完成后替换 paths 中文件已有的分块
+func (s *Store) Enqueue(ctx context.Context, projectUuid string, paths []string, chunks []*chunker.Chunk) error {
+	if len(paths) == 0 && len(chunks) == 0 {
+		return nil
+	}
+	q := s.queue
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return fmt.Errorf("vector store is closed")
+	}
+	q.seq++
+	job := &embedJob{projectUuid: projectUuid, paths: paths, chunks: chunks, seq: q.seq}
+	files := q.latest[projectUuid]
+	if files == nil {
+		files = make(map[string]uint64)
+		q.latest[projectUuid] = files
+	}
+	for _, p := range jobPaths(job) {
+		files[p] = job.seq
+	}
+	q.inflight++
+	q.mu.Unlock()
+
+	select {
+	case q.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		q.take(job)
+		q.finish()
+		return ctx.Err()
+	case <-q.ctx.Done():
+		q.finish()
+		return fmt.Errorf("vector store is closed")
+	}
+}
+
+// WaitIdle 等待队列中的批次全部处理完成
+func (s *Store) WaitIdle() {
+	q := s.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.inflight > 0 {
+		q.idle.Wait()
+	}
+}
+
+func (q *embedQueue) run() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case job := <-q.jobs:
+			q.process(job)
+			q.finish()
+		}
+	}
+}
+
+func (q *embedQueue) process(job *embedJob) {
+	s := q.store
+	vectors, err := s.embed(q.ctx, job.chunks)
+	unlock := s.dbs.Lock(job.projectUuid)
+	defer unlock()
+	current := q.take(job)
+	if err != nil {
+		s.logger.Error("vector store embed %d files of project %s err:%v", len(current), job.projectUuid, err)
+		return
+	}
+	if len(current) == 0 {
+		return
+	}
+	var paths []string
+	for _, p := range job.paths {
+		if current[p] {
+			paths = append(paths, p)
+		}
+	}
+	var chunks []*chunker.Chunk
+	var chunkVectors [][]float32
+	for i, c := range job.chunks {
+		if current[c.FilePath] {
+			chunks = append(chunks, c)
+			chunkVectors = append(chunkVectors, vectors[i])
+		}
+	}
+	if err = s.write(job.projectUuid, paths, chunks, chunkVectors); err != nil {
+		s.logger.Error("vector store write %d chunks of project %s err:%v", len(chunks), job.projectUuid, err)
+	}
+}
+
+// take 取出批次中序号仍为最新的文件，并清除其记录
+func (q *embedQueue) take(job *embedJob) map[string]bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	current := make(map[string]bool)
+	files := q.latest[job.projectUuid]
+	for _, p := range jobPaths(job) {
+		if seq, ok := files[p]; ok && seq == job.seq {
+			current[p] = true
+			delete(files, p)
+		}
+	}
+	if len(files) == 0 {
+		delete(q.latest, job.projectUuid)
+	}
+	return current
+}
+
+// discard 丢弃文件（或目录下所有文件）尚未写入的批次结果，paths 为空时丢弃整个项目，调用方需持有项目锁
+func (q *embedQueue) discard(projectUuid string, paths ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(paths) == 0 {
+		delete(q.latest, projectUuid)
+		return
+	}
+	files := q.latest[projectUuid]
+	for f := range files {
+		for _, p := range paths {
+			if f == p || strings.HasPrefix(f, utils.EnsureTrailingSeparator(p)) {
+				delete(files, f)
+				break
+			}
+		}
+	}
+}
+
+func (q *embedQueue) finish() {
+	q.mu.Lock()
+	// 关闭时计数已清零
+	if q.inflight > 0 {
+		q.inflight--
+	}
+	if q.inflight == 0 {
+		q.idle.Broadcast()
+	}
+	q.mu.Unlock()
+}
+
+// close 停止 worker，未处理的批次被丢弃
+func (q *embedQueue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	q.mu.Unlock()
+	q.cancel()
+	q.wg.Wait()
+
+	q.mu.Lock()
+	q.inflight = 0
+	q.latest = make(map[string]map[string]uint64)
+	q.idle.Broadcast()
+	q.mu.Unlock()
+}
+
+// jobPaths 批次涉及的所有文件
+func jobPaths(job *embedJob) []string {
+	paths := append([]string(nil), job.paths...)
+	for _, c := range job.chunks {
+		paths = append(paths, c.FilePath)
+	}
+	return paths
+}
@@ -0,0 +1,393 @@
+package vector
+
+import (
+	"codebase-indexer/pkg/codegraph/chunker"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/logger"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	dataDir = "vectors"
+
+	vectorKeyPrefix = "@vec:"
+	keySeparator    = "\x00"
+
+	DefaultSearchLimit = 10
+)
+
+// SearchOptions 检索参数
+type SearchOptions struct {
+	Limit int
+	// Filter 返回 false 的路径将被过滤
+	Filter func(path string) bool
+}
+
+// Hit 检索结果，Score 为余弦相似度
+type Hit struct {
+	Chunk *chunker.Chunk
+	Score float64
+}
+
+type record struct {
+	chunk  *chunker.Chunk
+	vector []float32
+}
+
+// Store 项目级本地向量存储，存放在 EmbeddingDir 下，每个项目一个 LevelDB。
+// 索引时分块进入后台队列异步向量化；检索时在项目向量的内存副本上暴力计算余弦相似度，写入后副本失效
+type Store struct {
+	dbs      *store.ProjectDBs
+	provider Provider
+	logger   logger.Logger
+	vectors  sync.Map // projectUuid -> []*record
+	queue    *embedQueue
+}
+
+// NewStore 创建向量存储并启动后台向量化队列
+func NewStore(baseDir string, provider Provider, logger logger.Logger) (*Store, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("embedding provider is required")
+	}
+	dbs, err := store.NewProjectDBs("vector store", baseDir, dataDir, logger)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{dbs: dbs, provider: provider, logger: logger}
+	s.queue = newEmbedQueue(s)
+	return s, nil
+}
+
+func fileKeyPrefix(path string) []byte {
+	return []byte(vectorKeyPrefix + path + keySeparator)
+}
+
+func recordKey(c *chunker.Chunk) []byte {
+	return []byte(fmt.Sprintf("%s%s%s%010d", vectorKeyPrefix, c.FilePath, keySeparator, c.StartByte))
+}
+
+// encodeRecord 编码为 uvarint(元数据长度) + 元数据 JSON + 小端 float32 向量
+func encodeRecord(r *record) ([]byte, error) {
+	meta, err := json.Marshal(r.chunk)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(meta)+4*len(r.vector))
+	n := binary.PutUvarint(buf, uint64(len(meta)))
+	buf = append(buf[:n], meta...)
+	for _, v := range r.vector {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+	}
+	return buf, nil
+}
+
+func decodeRecord(value []byte, withVector bool) (*record, error) {
+	size, n := binary.Uvarint(value)
+	if n <= 0 || n+int(size) > len(value) {
+		return nil, fmt.Errorf("invalid vector record")
+	}
+	r := &record{chunk: new(chunker.Chunk)}
+	if err := json.Unmarshal(value[n:n+int(size)], r.chunk); err != nil {
+		return nil, err
+	}
+	if withVector {
+		raw := value[n+int(size):]
+		r.vector = make([]float32, len(raw)/4)
+		for i := range r.vector {
+			r.vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+	}
+	return r, nil
+}
+
+// collectKeys 收集文件（或目录下所有文件）的记录
+func collectKeys(db *leveldb.DB, path string) ([][]byte, [][]byte, error) {
+	var keys, values [][]byte
+	for _, prefix := range [][]byte{fileKeyPrefix(path),
+		[]byte(vectorKeyPrefix + utils.EnsureTrailingSeparator(path))} {
+		iter := db.NewIterator(util.BytesPrefix(prefix), nil)
+		for iter.Next() {
+			keys = append(keys, append([]byte(nil), iter.Key()...))
+			values = append(values, append([]byte(nil), iter.Value()...))
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return keys, values, nil
+}
+
+// IndexChunks 同步向量化分块并写入。paths 中文件已有的分块先被删除，没有分块的文件也会清除旧向量
+func (s *Store) IndexChunks(ctx context.Context, projectUuid string, paths []string, chunks []*chunker.Chunk) error {
+	vectors, err := s.embed(ctx, chunks)
+	if err != nil {
+		return err
+	}
+	unlock := s.dbs.Lock(projectUuid)
+	defer unlock()
+	return s.write(projectUuid, paths, chunks, vectors)
+}
+
+// embed 向量化分块
+func (s *Store) embed(ctx context.Context, chunks []*chunker.Chunk) ([][]float32, error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = embeddingText(c)
+	}
+	vectors, err := s.provider.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed %d chunks err:%w", len(chunks), err)
+	}
+	if len(vectors) != len(chunks) {
+		return nil, fmt.Errorf("embedding size %d not match chunk size %d", len(vectors), len(chunks))
+	}
+	return vectors, nil
+}
+
+// write 按文件删除旧分块后写入新分块，调用方需持有项目锁
+func (s *Store) write(projectUuid string, paths []string, chunks []*chunker.Chunk, vectors [][]float32) error {
+	db, err := s.dbs.DB(projectUuid)
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	replaced := make(map[string]struct{})
+	replace := func(path string) {
+		if _, ok := replaced[path]; ok {
+			return
+		}
+		replaced[path] = struct{}{}
+		iter := db.NewIterator(util.BytesPrefix(fileKeyPrefix(path)), nil)
+		for iter.Next() {
+			batch.Delete(append([]byte(nil), iter.Key()...))
+		}
+		iter.Release()
+	}
+	for _, p := range paths {
+		replace(p)
+	}
+	for i, c := range chunks {
+		replace(c.FilePath)
+		value, err := encodeRecord(&record{chunk: c, vector: normalize(vectors[i])})
+		if err != nil {
+			return err
+		}
+		batch.Put(recordKey(c), value)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	s.vectors.Delete(projectUuid)
+	return db.Write(batch, nil)
+}
+
+// embeddingText 向量化的文本，带上符号路径以提升召回
+func embeddingText(c *chunker.Chunk) string {
+	if c.SymbolPath == "" {
+		return c.Content
+	}
+	return c.SymbolPath + "\n" + c.Content
+}
+
+// ChunksByPath 获取文件的所有分块（不含内容），按位置排序
+func (s *Store) ChunksByPath(ctx context.Context, projectUuid string, path string) ([]*chunker.Chunk, error) {
+	unlock := s.dbs.Lock(projectUuid)
+	defer unlock()
+	db, err := s.dbs.DB(projectUuid)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []*chunker.Chunk
+	iter := db.NewIterator(util.BytesPrefix(fileKeyPrefix(path)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		r, err := decodeRecord(iter.Value(), false)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, r.chunk)
+	}
+	return chunks, iter.Error()
+}
+
+// RemoveFiles 删除文件（或目录下所有文件）的向量
+func (s *Store) RemoveFiles(ctx context.Context, projectUuid string, paths []string) error {
+	unlock := s.dbs.Lock(projectUuid)
+	defer unlock()
+	db, err := s.dbs.DB(projectUuid)
+	if err != nil {
+		return err
+	}
+	// 尚未完成向量化的批次不再写入这些文件
+	s.queue.discard(projectUuid, paths...)
+	batch := new(leveldb.Batch)
+	for _, p := range paths {
+		keys, _, err := collectKeys(db, p)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			batch.Delete(k)
+		}
+	}
+	s.vectors.Delete(projectUuid)
+	return db.Write(batch, nil)
+}
+
+// RenameFiles 将文件（或目录）的向量由 sourcePath 重命名为 targetPath，支持跨项目
+func (s *Store) RenameFiles(ctx context.Context, sourceProjectUuid, targetProjectUuid, sourcePath, targetPath string) error {
+	sourcePath, targetPath = utils.TrimLastSeparator(sourcePath), utils.TrimLastSeparator(targetPath)
+	unlock := s.dbs.Lock(sourceProjectUuid)
+	db, err := s.dbs.DB(sourceProjectUuid)
+	if err != nil {
+		unlock()
+		return err
+	}
+	s.queue.discard(sourceProjectUuid, sourcePath)
+	keys, values, err := collectKeys(db, sourcePath)
+	if err == nil && len(keys) > 0 {
+		s.vectors.Delete(sourceProjectUuid)
+		batch := new(leveldb.Batch)
+		for _, k := range keys {
+			batch.Delete(k)
+		}
+		err = db.Write(batch, nil)
+	}
+	unlock()
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	unlock = s.dbs.Lock(targetProjectUuid)
+	defer unlock()
+	if db, err = s.dbs.DB(targetProjectUuid); err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	for _, v := range values {
+		r, err := decodeRecord(v, true)
+		if err != nil {
+			return err
+		}
+		r.chunk.FilePath = targetPath + strings.TrimPrefix(r.chunk.FilePath, sourcePath)
+		value, err := encodeRecord(r)
+		if err != nil {
+			return err
+		}
+		batch.Put(recordKey(r.chunk), value)
+	}
+	s.vectors.Delete(targetProjectUuid)
+	return db.Write(batch, nil)
+}
+
+// DeleteAll 删除项目的所有向量
+func (s *Store) DeleteAll(ctx context.Context, projectUuid string) error {
+	unlock := s.dbs.Lock(projectUuid)
+	defer unlock()
+	s.queue.discard(projectUuid)
+	s.vectors.Delete(projectUuid)
+	return s.dbs.Clear(projectUuid)
+}
+
+// Search 向量化查询语句，返回余弦相似度最高的分块
+func (s *Store) Search(ctx context.Context, projectUuid string, query string, opts SearchOptions) ([]*Hit, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSearchLimit
+	}
+	vectors, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query err:%w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query returned %d vectors", len(vectors))
+	}
+	queryVector := normalize(vectors[0])
+
+	records, err := s.loadVectors(projectUuid)
+	if err != nil {
+		return nil, err
+	}
+	var hits []*Hit
+	for idx, r := range records {
+		if idx%1024 == 0 {
+			if err = utils.CheckContext(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Filter != nil && !opts.Filter(r.chunk.FilePath) {
+			continue
+		}
+		if len(r.vector) != len(queryVector) {
+			continue
+		}
+		hits = append(hits, &Hit{Chunk: r.chunk, Score: dot(queryVector, r.vector)})
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if len(hits) > opts.Limit {
+		hits = hits[:opts.Limit]
+	}
+	return hits, nil
+}
+
+// loadVectors 获取项目向量的内存副本，不存在时从数据库加载。副本只会整体替换，调用方可在锁外读取
+func (s *Store) loadVectors(projectUuid string) ([]*record, error) {
+	if v, ok := s.vectors.Load(projectUuid); ok {
+		return v.([]*record), nil
+	}
+	unlock := s.dbs.Lock(projectUuid)
+	defer unlock()
+	if v, ok := s.vectors.Load(projectUuid); ok {
+		return v.([]*record), nil
+	}
+	db, err := s.dbs.DB(projectUuid)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*record, 0)
+	iter := db.NewIterator(util.BytesPrefix([]byte(vectorKeyPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		r, err := decodeRecord(iter.Value(), true)
+		if err != nil {
+			s.logger.Debug("decode vector record %s err:%v", string(iter.Key()), err)
+			continue
+		}
+		records = append(records, r)
+	}
+	if err = iter.Error(); err != nil {
+		return nil, err
+	}
+	s.vectors.Store(projectUuid, records)
+	return records, nil
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// Close 停止后台向量化队列并关闭所有项目数据库
+func (s *Store) Close() error {
+	s.queue.close()
+	return s.dbs.Close()
+}
@@ -0,0 +1,164 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codebase-indexer/pkg/codegraph/chunker"
+	"codebase-indexer/pkg/logger"
+)
+
+func newTestStore(t *testing.T) *Store {
+	l, err := logger.NewLogger("/tmp/logs", "info", "codebase-indexer")
+	assert.NoError(t, err)
+	s, err := NewStore(t.TempDir(), NewFakeProvider(0), l)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func testChunk(path, symbol, content string, start uint) *chunker.Chunk {
+	return &chunker.Chunk{
+		FilePath:   path,
+		Language:   "go",
+		SymbolPath: symbol,
+		Kind:       "definition.function",
+		Range:      []int32{int32(start), 0, int32(start) + 2, 1},
+		StartByte:  start,
+		EndByte:    start + uint(len(content)),
+		Content:    content,
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	err := s.IndexChunks(ctx, "p1", nil, []*chunker.Chunk{
+		testChunk("/repo/config.go", "LoadConfig", "func LoadConfig(path string) (*Config, error) { return readYaml(path) }", 0),
+		testChunk("/repo/config.go", "SaveConfig", "func SaveConfig(cfg *Config) error { return writeYaml(cfg) }", 100),
+		testChunk("/repo/http.go", "StartServer", "func StartServer(addr string) error { return http.ListenAndServe(addr, nil) }", 0),
+	})
+	assert.NoError(t, err)
+
+	hits, err := s.Search(ctx, "p1", "load config yaml", SearchOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+	assert.Equal(t, "LoadConfig", hits[0].Chunk.SymbolPath)
+
+	// 重新索引文件时替换旧分块
+	err = s.IndexChunks(ctx, "p1", nil, []*chunker.Chunk{
+		testChunk("/repo/config.go", "LoadConfig", "func LoadConfig() {}", 0),
+	})
+	assert.NoError(t, err)
+	chunks, err := s.ChunksByPath(ctx, "p1", "/repo/config.go")
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	assert.NoError(t, s.RenameFiles(ctx, "p1", "p1", "/repo/http.go", "/repo/server/http.go"))
+	hits, err = s.Search(ctx, "p1", "start server", SearchOptions{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "/repo/server/http.go", hits[0].Chunk.FilePath)
+
+	assert.NoError(t, s.RemoveFiles(ctx, "p1", []string{"/repo/server"}))
+	hits, err = s.Search(ctx, "p1", "start server", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "/repo/config.go", hits[0].Chunk.FilePath)
+}
+
+// blockingProvider 在 release 关闭前阻塞向量化
+type blockingProvider struct {
+	Provider
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return p.Provider.Embed(ctx, texts)
+}
+
+func TestStore_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	assert.NoError(t, s.Enqueue(ctx, "p1", []string{"/repo/a.go", "/repo/b.go"}, []*chunker.Chunk{
+		testChunk("/repo/a.go", "A", "func A() {}", 0),
+		testChunk("/repo/b.go", "B", "func B() {}", 0),
+	}))
+	s.WaitIdle()
+	hits, err := s.Search(ctx, "p1", "func", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+
+	// 没有分块的文件清除旧向量
+	assert.NoError(t, s.Enqueue(ctx, "p1", []string{"/repo/b.go"}, nil))
+	s.WaitIdle()
+	chunks, err := s.ChunksByPath(ctx, "p1", "/repo/b.go")
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+	hits, err = s.Search(ctx, "p1", "func", SearchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+}
+
+func TestStore_EnqueueDiscardRemoved(t *testing.T) {
+	ctx := context.Background()
+	l, err := logger.NewLogger("/tmp/logs", "info", "codebase-indexer")
+	assert.NoError(t, err)
+	provider := &blockingProvider{Provider: NewFakeProvider(0), started: make(chan struct{}),
+		release: make(chan struct{})}
+	s, err := NewStore(t.TempDir(), provider, l)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	assert.NoError(t, s.Enqueue(ctx, "p1", []string{"/repo/a.go"}, []*chunker.Chunk{
+		testChunk("/repo/a.go", "A", "func A() {}", 0),
+	}))
+	// 向量化期间文件被删除，结果不再写入
+	<-provider.started
+	assert.NoError(t, s.RemoveFiles(ctx, "p1", []string{"/repo"}))
+	close(provider.release)
+	s.WaitIdle()
+	chunks, err := s.ChunksByPath(ctx, "p1", "/repo/a.go")
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestFuseRRF(t *testing.T) {
+	a := testChunk("/a.go", "A", "a", 0)
+	b := testChunk("/b.go", "B", "b", 0)
+	c := testChunk("/c.go", "C", "c", 0)
+	res := FuseRRF(0, []*Hit{{Chunk: a}, {Chunk: b}}, []*Hit{{Chunk: b}, {Chunk: c}})
+	assert.Len(t, res, 3)
+	assert.Equal(t, "B", res[0].Chunk.SymbolPath)
+}
+
+func TestOpenAIProvider_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		var req embeddingRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		// 乱序返回，按 index 还原
+		resp := map[string]any{"data": []map[string]any{}}
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			resp["data"] = append(resp["data"].([]map[string]any),
+				map[string]any{"index": i, "embedding": []float32{float32(len(req.Input[i])), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(ProviderConfig{Provider: ProviderOpenAI, BaseURL: server.URL + "/v1/", Model: "m",
+		ApiKey: "key", BatchSize: 2})
+	assert.NoError(t, err)
+	vectors, err := p.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 1}, {2, 1}, {3, 1}}, vectors)
+}
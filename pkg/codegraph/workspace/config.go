@@ -0,0 +1,391 @@
+package workspace
+
+import (
+	"bytes"
+	"codebase-indexer/pkg/codegraph/lang"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName 工作区根目录下的索引配置文件
+const ConfigFileName = ".codebase-indexer.yaml"
+
+const (
+	configVersion        = 1
+	maxConfigFiles       = 1_000_000
+	maxConfigFileSizeKB  = 10 * 1024
+	maxConfigConcurrency = 64
+	maxConfigBatchSize   = 1000
+)
+
+// Config 随仓库提交的索引配置，优先于环境变量及服务端下发的配置
+type Config struct {
+	Version int `yaml:"version"`
+	// Languages 启用的语言，为空时启用全部支持的语言
+	Languages []string `yaml:"languages"`
+	// Include 额外索引的路径，gitignore 语法，优先于默认及 .gitignore 的忽略规则
+	Include []string `yaml:"include"`
+	// Exclude 额外忽略的路径，gitignore 语法
+	Exclude []string `yaml:"exclude"`
+	// Projects 项目根目录（相对工作区），配置后不再按 git 仓库自动识别项目
+	Projects []string    `yaml:"projects"`
+	Modules  ModuleHints `yaml:"modules"`
+	Limits   Limits      `yaml:"limits"`
+}
+
+// ModuleHints 各语言的模块信息，补充自动解析的结果，作用于工作区的所有项目
+type ModuleHints struct {
+	GoModules           []string `yaml:"goModules"`
+	JavaPackagePrefixes []string `yaml:"javaPackagePrefixes"`
+	PythonPackages      []string `yaml:"pythonPackages"`
+	JsPackages          []string `yaml:"jsPackages"`
+	// TsPathAliases 同 tsconfig 的 compilerOptions.paths，如 "@app/*": ["src/app/*"]，目标相对工作区
+	TsPathAliases map[string][]string `yaml:"tsPathAliases"`
+	// CIncludeDirs C/C++ 头文件目录（相对工作区）
+	CIncludeDirs []string `yaml:"cIncludeDirs"`
+}
+
+// Limits 工作区的索引上限，0 表示使用默认值
+type Limits struct {
+	MaxFiles      int `yaml:"maxFiles"`
+	MaxFileSizeKB int `yaml:"maxFileSizeKB"`
+	// MaxConcurrency 工作区索引的并发批次数上限，不超过资源调节器的上限
+	MaxConcurrency int `yaml:"maxConcurrency"`
+	// BatchSize 每个批次索引的文件数
+	BatchSize int `yaml:"batchSize"`
+}
+
+// ConfigError 配置文件的校验错误
+type ConfigError struct {
+	Field   string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+type cachedConfig struct {
+	modTime time.Time
+	size    int64
+	config  *Config
+	errs    []error
+}
+
+var configCache = struct {
+	sync.Mutex
+	entries map[string]*cachedConfig
+}{entries: make(map[string]*cachedConfig)}
+
+// LoadConfig 加载工作区的配置文件，文件未变化时使用缓存。没有配置文件时返回 nil；
+// 语法或字段错误时整个文件不生效；取值错误时忽略错误的项，其余配置生效
+func LoadConfig(workspacePath string) (*Config, []error) {
+	path := filepath.Join(workspacePath, ConfigFileName)
+	info, err := os.Stat(path)
+
+	configCache.Lock()
+	defer configCache.Unlock()
+	if err != nil {
+		delete(configCache.entries, path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []error{&ConfigError{Message: err.Error()}}
+	}
+	if cached, ok := configCache.entries[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.config, cached.errs
+	}
+	content, err := os.ReadFile(path)
+	var config *Config
+	var errs []error
+	if err != nil {
+		errs = []error{&ConfigError{Message: err.Error()}}
+	} else {
+		config, errs = ParseConfig(workspacePath, content)
+	}
+	configCache.entries[path] = &cachedConfig{modTime: info.ModTime(), size: info.Size(), config: config, errs: errs}
+	return config, errs
+}
+
+// ParseConfig 解析并校验配置
+func ParseConfig(workspacePath string, content []byte) (*Config, []error) {
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, []error{&ConfigError{Message: err.Error()}}
+	}
+	errs := config.validate(workspacePath)
+	return config, errs
+}
+
+// validate 校验取值，移除错误的项
+func (c *Config) validate(workspacePath string) []error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, &ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Version != 0 && c.Version != configVersion {
+		fail("version", "unsupported version %d, expected %d", c.Version, configVersion)
+	}
+
+	languages := c.Languages[:0]
+	for n, l := range c.Languages {
+		if _, err := lang.GetSitterParserByLanguage(lang.Language(l)); err != nil {
+			fail(fmt.Sprintf("languages[%d]", n), "unsupported language %q", l)
+			continue
+		}
+		languages = append(languages, l)
+	}
+	c.Languages = languages
+
+	c.Include = validPatterns("include", c.Include, fail)
+	c.Exclude = validPatterns("exclude", c.Exclude, fail)
+
+	projects := c.Projects[:0]
+projects:
+	for n, p := range c.Projects {
+		field := fmt.Sprintf("projects[%d]", n)
+		if !validRelativePath(field, p, fail) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(workspacePath, p)); err != nil || !info.IsDir() {
+			fail(field, "project root %q is not a directory", p)
+			continue
+		}
+		// 项目不能重叠，否则同一文件会被多个项目索引
+		for _, kept := range projects {
+			if pathOverlaps(kept, p) {
+				fail(field, "project root %q overlaps %q", p, kept)
+				continue projects
+			}
+		}
+		projects = append(projects, p)
+	}
+	c.Projects = projects
+
+	includeDirs := c.Modules.CIncludeDirs[:0]
+	for n, d := range c.Modules.CIncludeDirs {
+		if validRelativePath(fmt.Sprintf("modules.cIncludeDirs[%d]", n), d, fail) {
+			includeDirs = append(includeDirs, d)
+		}
+	}
+	c.Modules.CIncludeDirs = includeDirs
+
+	aliases := make([]string, 0, len(c.Modules.TsPathAliases))
+	for alias := range c.Modules.TsPathAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		field := fmt.Sprintf("modules.tsPathAliases[%q]", alias)
+		targets := c.Modules.TsPathAliases[alias]
+		if strings.TrimSpace(alias) == "" || strings.Count(alias, "*") > 1 {
+			fail(field, "alias must be non-empty with at most one '*'")
+			delete(c.Modules.TsPathAliases, alias)
+			continue
+		}
+		valid := targets[:0]
+		for n, target := range targets {
+			if strings.Count(target, "*") != strings.Count(alias, "*") {
+				fail(fmt.Sprintf("%s[%d]", field, n), "target %q must have as many '*' as the alias", target)
+				continue
+			}
+			if validRelativePath(fmt.Sprintf("%s[%d]", field, n), target, fail) {
+				valid = append(valid, target)
+			}
+		}
+		if len(valid) == 0 {
+			delete(c.Modules.TsPathAliases, alias)
+			continue
+		}
+		c.Modules.TsPathAliases[alias] = valid
+	}
+
+	validLimit("limits.maxFiles", &c.Limits.MaxFiles, maxConfigFiles, fail)
+	validLimit("limits.maxFileSizeKB", &c.Limits.MaxFileSizeKB, maxConfigFileSizeKB, fail)
+	validLimit("limits.maxConcurrency", &c.Limits.MaxConcurrency, maxConfigConcurrency, fail)
+	validLimit("limits.batchSize", &c.Limits.BatchSize, maxConfigBatchSize, fail)
+	return errs
+}
+
+// validLimit 上限须在 0 到 upper 之间，0 表示使用默认值，超出范围时使用默认值
+func validLimit(field string, value *int, upper int, fail func(field, format string, args ...any)) {
+	if *value < 0 || *value > upper {
+		fail(field, "must be between 0 and %d, 0 uses the default", upper)
+		*value = 0
+	}
+}
+
+// pathOverlaps 两个相对路径是否相同或互为上下级
+func pathOverlaps(a, b string) bool {
+	a, b = filepath.Clean(filepath.FromSlash(a)), filepath.Clean(filepath.FromSlash(b))
+	if a == b || a == "." || b == "." {
+		return true
+	}
+	sep := string(filepath.Separator)
+	return strings.HasPrefix(a, b+sep) || strings.HasPrefix(b, a+sep)
+}
+
+func validPatterns(field string, patterns []string, fail func(field, format string, args ...any)) []string {
+	valid := patterns[:0]
+	for n, p := range patterns {
+		if strings.TrimSpace(p) == "" || strings.HasPrefix(p, "!") {
+			fail(fmt.Sprintf("%s[%d]", field, n), "pattern %q must be non-empty and not negated", p)
+			continue
+		}
+		valid = append(valid, p)
+	}
+	return valid
+}
+
+// validRelativePath 路径须为工作区内的相对路径
+func validRelativePath(field, path string, fail func(field, format string, args ...any)) bool {
+	cleaned := filepath.Clean(filepath.FromSlash(path))
+	if path == "" || filepath.IsAbs(cleaned) || cleaned == ".." ||
+		strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		fail(field, "path %q must be relative to the workspace root", path)
+		return false
+	}
+	return true
+}
+
+// LanguageEnabled 语言是否启用
+func (c *Config) LanguageEnabled(language lang.Language) bool {
+	if c == nil || len(c.Languages) == 0 {
+		return true
+	}
+	// 所属语言启用时其方言同样启用，如 typescript 包含 tsx
+	for _, l := range c.Languages {
+		if lang.Language(l) == language || lang.Language(l) == lang.IndexLanguage(language) {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreLines 追加到忽略规则之后的规则：exclude 为忽略，include 取反以覆盖之前的忽略
+func (c *Config) IgnoreLines() []string {
+	if c == nil {
+		return nil
+	}
+	lines := make([]string, 0, len(c.Exclude)+len(c.Include))
+	lines = append(lines, c.Exclude...)
+	for _, p := range c.Include {
+		lines = append(lines, "!"+p)
+	}
+	return lines
+}
+
+// IncludesUnder 是否有 include 规则可能匹配目录 dir（相对工作区）下的路径。
+// 被忽略的目录中有 include 的路径时不能整个跳过，需进入目录逐个判断。
+// 不含目录的规则（如 *.pb.go）只在文件级的忽略判断中生效，不会保留被忽略的目录
+func (c *Config) IncludesUnder(dir string) bool {
+	if c == nil || len(c.Include) == 0 {
+		return false
+	}
+	dir = strings.Trim(filepath.ToSlash(dir), "/")
+	if dir == "" || dir == "." {
+		return true
+	}
+	for _, p := range c.Include {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+		anchored := strings.HasPrefix(p, "/")
+		p = strings.TrimPrefix(p, "/")
+		if !anchored && !strings.Contains(p, "/") {
+			continue
+		}
+		// **/ 开头的规则匹配任意层级，目录路径中含其后的首个目录时保留
+		if rest, ok := strings.CutPrefix(p, "**/"); ok {
+			first, _, _ := strings.Cut(rest, "/")
+			if includesDirName(dir, first) {
+				return true
+			}
+			continue
+		}
+		literal := p
+		glob := strings.IndexAny(p, "*?[")
+		if glob >= 0 {
+			literal = p[:glob]
+		}
+		// 规则的字面前缀位于目录内，或目录位于规则的通配部分之下
+		if literal == dir || strings.HasPrefix(literal, dir+"/") || (glob >= 0 && strings.HasPrefix(dir+"/", literal)) {
+			return true
+		}
+	}
+	return false
+}
+
+// includesDirName 目录路径中是否有一级目录匹配 pattern
+func includesDirName(dir, pattern string) bool {
+	if pattern == "" || strings.Contains(pattern, "**") {
+		return false
+	}
+	for _, name := range strings.Split(dir, "/") {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyModuleHints 将配置的模块信息补充到项目中
+func (c *Config) applyModuleHints(workspacePath string, project *Project) {
+	if c == nil {
+		return
+	}
+	hints := c.Modules
+	project.GoModules = appendUnique(project.GoModules, hints.GoModules...)
+	project.JavaPackagePrefix = appendUnique(project.JavaPackagePrefix, hints.JavaPackagePrefixes...)
+	project.PythonPackages = appendUnique(project.PythonPackages, hints.PythonPackages...)
+	project.JsPackages = appendUnique(project.JsPackages, hints.JsPackages...)
+	for _, dir := range hints.CIncludeDirs {
+		// 头文件目录相对项目，项目外的目录不适用
+		rel, err := filepath.Rel(project.Path, filepath.Join(workspacePath, dir))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		project.CppIncludes = appendUnique(project.CppIncludes, filepath.ToSlash(rel))
+	}
+	if len(hints.TsPathAliases) > 0 {
+		if project.JsPathAliases == nil {
+			project.JsPathAliases = make(map[string][]string, len(hints.TsPathAliases))
+		}
+		for alias, targets := range hints.TsPathAliases {
+			abs := make([]string, 0, len(targets))
+			for _, target := range targets {
+				abs = append(abs, filepath.Join(workspacePath, target))
+			}
+			project.JsPathAliases[alias] = abs
+		}
+	}
+}
+
+func appendUnique(values []string, more ...string) []string {
+	for _, m := range more {
+		found := false
+		for _, v := range values {
+			if v == m {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, m)
+		}
+	}
+	return values
+}
@@ -0,0 +1,172 @@
+package workspace
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	dir := createTestDir(t, map[string]bool{"backend": true, "frontend": true})
+
+	content := `
+version: 1
+languages: [go, typescript, cobol]
+include: ["vendor/internal/"]
+exclude: ["generated/", "!keep/"]
+projects: [backend, frontend, missing, ../outside]
+modules:
+  goModules: [example.com/backend]
+  tsPathAliases:
+    "@app/*": ["frontend/src/app/*", "frontend/src/app"]
+    "@a/*/*": ["x/*/*"]
+  cIncludeDirs: [include, /usr/include]
+limits:
+  maxFiles: 20000
+  maxFileSizeKB: -1
+`
+	config, errs := ParseConfig(dir, []byte(content))
+	assert.NotNil(t, config)
+	assert.Len(t, errs, 8)
+	assert.Equal(t, []string{"go", "typescript"}, config.Languages)
+	assert.Equal(t, []string{"vendor/internal/"}, config.Include)
+	assert.Equal(t, []string{"generated/"}, config.Exclude)
+	assert.Equal(t, []string{"backend", "frontend"}, config.Projects)
+	assert.Equal(t, []string{"example.com/backend"}, config.Modules.GoModules)
+	assert.Equal(t, map[string][]string{"@app/*": {"frontend/src/app/*"}}, config.Modules.TsPathAliases)
+	assert.Equal(t, []string{"include"}, config.Modules.CIncludeDirs)
+	assert.Equal(t, 20000, config.Limits.MaxFiles)
+	assert.Equal(t, 0, config.Limits.MaxFileSizeKB)
+	assert.True(t, config.LanguageEnabled(lang.Go))
+	assert.False(t, config.LanguageEnabled(lang.Java))
+	// typescript 包含其方言 tsx
+	assert.True(t, config.LanguageEnabled(lang.TSX))
+
+	// 未知字段、语法错误时整个文件不生效
+	config, errs = ParseConfig(dir, []byte("exclud: [a]"))
+	assert.Nil(t, config)
+	assert.Len(t, errs, 1)
+	config, errs = ParseConfig(dir, []byte("languages: [go"))
+	assert.Nil(t, config)
+	assert.Len(t, errs, 1)
+
+	// 空文件等同于默认配置
+	config, errs = ParseConfig(dir, nil)
+	assert.Empty(t, errs)
+	assert.True(t, config.LanguageEnabled(lang.Java))
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	config, errs := LoadConfig(dir)
+	assert.Nil(t, config)
+	assert.Empty(t, errs)
+
+	path := filepath.Join(dir, ConfigFileName)
+	assert.NoError(t, os.WriteFile(path, []byte("exclude: [dist/]\n"), 0644))
+	config, errs = LoadConfig(dir)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"dist/"}, config.Exclude)
+
+	// 文件变化后重新加载
+	assert.NoError(t, os.WriteFile(path, []byte("exclude: [dist/, build/]\nversion: 2\n"), 0644))
+	config, errs = LoadConfig(dir)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []string{"dist/", "build/"}, config.Exclude)
+
+	assert.NoError(t, os.Remove(path))
+	config, errs = LoadConfig(dir)
+	assert.Nil(t, config)
+	assert.Empty(t, errs)
+}
+
+func TestConfig_IgnoreLines(t *testing.T) {
+	var config *Config
+	assert.Empty(t, config.IgnoreLines())
+	assert.True(t, config.LanguageEnabled(lang.Go))
+
+	config = &Config{Include: []string{"vendor/lib/"}, Exclude: []string{"gen/", "*.pb.go"}}
+	assert.Equal(t, []string{"gen/", "*.pb.go", "!vendor/lib/"}, config.IgnoreLines())
+}
+
+func TestConfig_IncludesUnder(t *testing.T) {
+	var config *Config
+	assert.False(t, config.IncludesUnder("vendor"))
+
+	config = &Config{Include: []string{"vendor/internal/", "third_party/*/gen"}}
+	assert.True(t, config.IncludesUnder("vendor"))
+	assert.True(t, config.IncludesUnder("vendor/internal"))
+	assert.False(t, config.IncludesUnder("vendor/other"))
+	assert.True(t, config.IncludesUnder("third_party/proto"))
+	assert.False(t, config.IncludesUnder("node_modules"))
+
+	// 不含目录的规则只作用于文件，不保留被忽略的目录
+	config = &Config{Include: []string{"*.pb.go"}}
+	assert.False(t, config.IncludesUnder("node_modules"))
+	assert.False(t, config.IncludesUnder(".git"))
+
+	config = &Config{Include: []string{"**/gen/*.go"}}
+	assert.True(t, config.IncludesUnder("vendor/gen"))
+	assert.False(t, config.IncludesUnder("node_modules"))
+}
+
+func TestParseConfig_ProjectsAndLimits(t *testing.T) {
+	dir := createTestDir(t, map[string]bool{"services/api": true, "services/web": true})
+
+	content := `
+projects: [services, services/api, services/web, ./services]
+limits:
+  maxFiles: 0
+  maxConcurrency: 2
+  batchSize: 5000
+`
+	config, errs := ParseConfig(dir, []byte(content))
+	assert.NotNil(t, config)
+	// 重叠的项目及超出范围的上限
+	assert.Len(t, errs, 4)
+	assert.Equal(t, []string{"services"}, config.Projects)
+	assert.Contains(t, errs[0].Error(), "overlaps")
+	assert.Equal(t, 2, config.Limits.MaxConcurrency)
+	assert.Equal(t, 0, config.Limits.BatchSize)
+	assert.EqualError(t, errs[3], "limits.batchSize: must be between 0 and 1000, 0 uses the default")
+}
+
+func TestConfig_applyModuleHints(t *testing.T) {
+	workspacePath := filepath.Join(string(filepath.Separator), "w")
+	config := &Config{Modules: ModuleHints{
+		GoModules:     []string{"example.com/a"},
+		TsPathAliases: map[string][]string{"@/*": {"web/src/*"}},
+		CIncludeDirs:  []string{"native/include", "other/include"},
+	}}
+	project := &Project{Path: filepath.Join(workspacePath, "native"), GoModules: []string{"example.com/a"}}
+	config.applyModuleHints(workspacePath, project)
+
+	assert.Equal(t, []string{"example.com/a"}, project.GoModules)
+	assert.Equal(t, []string{"include"}, project.CppIncludes)
+	assert.Equal(t, map[string][]string{"@/*": {filepath.Join(workspacePath, "web", "src", "*")}}, project.JsPathAliases)
+}
+
+func TestFindProjectsWithConfig(t *testing.T) {
+	dir := createTestDir(t, map[string]bool{
+		"services/api":  true,
+		"services/web":  true,
+		"libs/.git":     true,
+		"services/.git": true,
+	})
+	content := "projects: [services/api, services/web]\nmodules:\n  goModules: [example.com/api]\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644))
+
+	wr := NewWorkSpaceReader(NewMockLogger())
+	projects := wr.FindProjects(context.Background(), dir, true, &types.VisitPattern{})
+	var paths []string
+	for _, p := range projects {
+		paths = append(paths, p.Path)
+		assert.Contains(t, p.GoModules, "example.com/api")
+	}
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "services", "api"), filepath.Join(dir, "services", "web")}, paths)
+}
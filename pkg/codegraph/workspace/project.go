@@ -26,6 +26,8 @@ type Project struct {
 	CppIncludes []string
 	// JsPackages JavaScript/TypeScript 项目包列表（如 myapp, @myapp/utils）
 	JsPackages []string
+	// JsPathAliases TypeScript 路径别名（如 @app/* -> /workspace/src/app/*），目标为绝对路径
+	JsPathAliases map[string][]string
 }
 
 func NewProject(name, path string) *Project {
@@ -73,6 +73,9 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 	entryCount := 0
 	foundGit := false
 
+	// 工作区配置文件，可指定项目根目录及模块信息
+	config, _ := LoadConfig(workspacePath)
+
 	// 辅助函数：判断目录下是否有 .git 目录
 	hasGitDir := func(dir string) bool {
 		gitPath := filepath.Join(dir, ".git")
@@ -80,8 +83,26 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 		return err == nil && info.IsDir()
 	}
 
-	// 1. 当前目录是 git 仓库
-	if hasGitDir(workspacePath) {
+	if config != nil && len(config.Projects) > 0 {
+		// 0. 配置文件中指定了项目根目录，不再自动识别
+		for _, root := range config.Projects {
+			projectPath := filepath.Join(workspacePath, root)
+			projectName := filepath.Base(projectPath)
+			project := &Project{
+				Path: projectPath,
+				Name: projectName,
+				Uuid: generateUuid(projectName, projectPath),
+			}
+			projects = append(projects, project)
+			if resolveModule {
+				if err := moduleResolver.ResolveProjectModules(ctx, project, project.Path, 2); err != nil {
+					w.logger.Error("resolve project modules err:%v", err)
+				}
+			}
+		}
+		foundGit = true
+	} else if hasGitDir(workspacePath) {
+		// 1. 当前目录是 git 仓库
 		projectName := filepath.Base(workspacePath)
 		project := &Project{
 			Path: workspacePath,
@@ -180,6 +201,12 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 		}
 	}
 
+	if resolveModule {
+		for _, p := range projects {
+			config.applyModuleHints(workspacePath, p)
+		}
+	}
+
 	var projectNames string
 	var goModules []string
 	for _, p := range projects {
@@ -310,8 +337,8 @@ func (w *workspaceReader) WalkFile(ctx context.Context, dir string, walkFn types
 			return err
 		}
 
-		// 跳过隐藏文件和目录
-		if utils.IsHiddenFile(info.Name()) {
+		// 跳过隐藏文件和目录，include 规则指向其中的隐藏目录仍需进入
+		if utils.IsHiddenFile(info.Name()) && !(info.IsDir() && walkOpts.VisitPattern.KeepDir(filePath)) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -400,25 +427,26 @@ func (l *workspaceReader) Tree(ctx context.Context, workspacePath string, subDir
 	// 使用 map 来构建目录树
 	nodeMap := make(map[string]*types.TreeNode)
 	walkBasePath := filepath.Join(workspacePath, subDir)
+	config, _ := LoadConfig(workspacePath)
 
 	err = filepath.WalkDir(walkBasePath, func(absFilePath string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// 跳过隐藏文件和目录
-		if utils.IsHiddenFile(info.Name()) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
 		// 获取相对路径，相对workspacePath
 		codeBaseRelativePath, err := filepath.Rel(workspacePath, absFilePath)
 		if err != nil {
 			return err
 		}
+
+		// 跳过隐藏文件和目录，include 规则指向其中的隐藏目录仍需进入
+		if utils.IsHiddenFile(info.Name()) && !(info.IsDir() && config.IncludesUnder(codeBaseRelativePath)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		// 获取相对路径，相对workspacePath + subdir
 		walkBaseRelativePath, err := filepath.Rel(walkBasePath, absFilePath)
 		if err != nil {
@@ -41,7 +41,8 @@ func (d *Downloader) Write(data []byte) error {
 		d.isFirst = false
 	}
 
-	// 写入当前分片数据
+	// 写入当前分片数据，顺延写入超时，大文件下载不受服务端 WriteTimeout 限制
+	extendWriteDeadline(d.c.Writer)
 	if _, err := d.c.Writer.Write(data); err != nil {
 		return err
 	}
@@ -43,6 +43,13 @@ func Error(c *gin.Context, httpStatusCode int, e error) {
 	c.JSON(httpStatusCode, wrapResponse(e))
 }
 
+// ErrorWithData 返回错误，data 为结构化的错误详情
+func ErrorWithData(c *gin.Context, httpStatusCode int, e error, data any) {
+	resp := wrapResponse(e)
+	resp.Data = data
+	c.JSON(httpStatusCode, resp)
+}
+
 func Bytes(c *gin.Context, v []byte) {
 	c.Header("Content-Type", "application/octet-stream")
 	c.Writer.WriteHeader(http.StatusOK)
@@ -0,0 +1,135 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StreamModeJsonLines = "ndjson"
+	StreamModeSSE       = "sse"
+)
+
+// StreamWriteTimeout 流式输出每次写入的超时，写入前顺延，避免长时间的流被服务端 WriteTimeout 中断
+const StreamWriteTimeout = 30 * time.Second
+
+// EventWriter 流式输出事件，首次写入时才发送响应头，之前仍可返回普通错误响应
+type EventWriter interface {
+	// WriteEvent 写入一个事件并刷新，event 为事件类型
+	WriteEvent(event string, v any) error
+	// Started 是否已开始输出
+	Started() bool
+}
+
+// NewEventWriter 按模式创建流式输出器，mode 为 ndjson 或 sse
+func NewEventWriter(c *gin.Context, mode string) (EventWriter, error) {
+	switch mode {
+	case StreamModeJsonLines:
+		return NewJsonLinesWriter(c), nil
+	case StreamModeSSE:
+		return NewSSEWriter(c), nil
+	default:
+		return nil, fmt.Errorf("unsupported stream mode %s", mode)
+	}
+}
+
+// extendWriteDeadline 顺延写入超时，底层不支持时忽略
+func extendWriteDeadline(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(StreamWriteTimeout))
+}
+
+// JsonLinesWriter 以 NDJSON 格式分块输出，每个对象一行
+type JsonLinesWriter struct {
+	c       *gin.Context
+	started bool
+	mu      sync.Mutex
+}
+
+// NewJsonLinesWriter 创建 NDJSON 输出器，首次写入时才发送响应头，之前仍可返回普通错误响应
+func NewJsonLinesWriter(c *gin.Context) *JsonLinesWriter {
+	return &JsonLinesWriter{c: c}
+}
+
+// Write 写入一行并刷新
+func (w *JsonLinesWriter) Write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		w.c.Header("Content-Type", "application/x-ndjson")
+		w.c.Header("Cache-Control", "no-cache")
+		w.c.Header("Transfer-Encoding", "chunked")
+		w.c.Writer.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+	extendWriteDeadline(w.c.Writer)
+	if _, err = w.c.Writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// WriteEvent 写入一行，事件类型由对象自身的字段表示
+func (w *JsonLinesWriter) WriteEvent(_ string, v any) error {
+	return w.Write(v)
+}
+
+// Started 是否已开始输出
+func (w *JsonLinesWriter) Started() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.started
+}
+
+// SSEWriter 以 Server-Sent Events 格式输出
+type SSEWriter struct {
+	c       *gin.Context
+	started bool
+	mu      sync.Mutex
+}
+
+// NewSSEWriter 创建 SSE 输出器，首次写入时才发送响应头
+func NewSSEWriter(c *gin.Context) *SSEWriter {
+	return &SSEWriter{c: c}
+}
+
+// WriteEvent 写入一个事件并刷新
+func (w *SSEWriter) WriteEvent(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		w.c.Header("Content-Type", "text/event-stream")
+		w.c.Header("Cache-Control", "no-cache")
+		w.c.Header("Connection", "keep-alive")
+		w.c.Writer.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+	extendWriteDeadline(w.c.Writer)
+	if _, err = fmt.Fprintf(w.c.Writer, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// Started 是否已开始输出
+func (w *SSEWriter) Started() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.started
+}
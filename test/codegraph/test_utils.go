@@ -114,6 +114,8 @@ func createTestIndexer(env *testEnvironment, visitPattern *types.VisitPattern) s
 		env.dependencyAnalyzer,
 		env.workspaceReader,
 		env.storage,
+		nil,
+		nil,
 		env.repository,
 		service.IndexerConfig{VisitPattern: visitPattern, MaxBatchSize: 50, MaxConcurrency: 1},
 		// 2,2, 300s， 20% cpu ,500MB内存占用；
@@ -6,9 +6,13 @@ package mocks
 
 import (
 	store "codebase-indexer/pkg/codegraph/store"
+	structural "codebase-indexer/pkg/codegraph/structural"
+	textindex "codebase-indexer/pkg/codegraph/textindex"
 	types "codebase-indexer/pkg/codegraph/types"
+	vector "codebase-indexer/pkg/codegraph/vector"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -36,6 +40,78 @@ func (m *MockIndexer) EXPECT() *MockIndexerMockRecorder {
 	return m.recorder
 }
 
+// CloseBuffer mocks base method.
+func (m *MockIndexer) CloseBuffer(ctx context.Context, workspacePath, filePath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseBuffer", ctx, workspacePath, filePath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseBuffer indicates an expected call of CloseBuffer.
+func (mr *MockIndexerMockRecorder) CloseBuffer(ctx, workspacePath, filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseBuffer", reflect.TypeOf((*MockIndexer)(nil).CloseBuffer), ctx, workspacePath, filePath)
+}
+
+// GetBuffer mocks base method.
+func (m *MockIndexer) GetBuffer(ctx context.Context, workspacePath, filePath string) ([]byte, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBuffer", ctx, workspacePath, filePath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetBuffer indicates an expected call of GetBuffer.
+func (mr *MockIndexerMockRecorder) GetBuffer(ctx, workspacePath, filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBuffer", reflect.TypeOf((*MockIndexer)(nil).GetBuffer), ctx, workspacePath, filePath)
+}
+
+// GetDroppedFiles mocks base method.
+func (m *MockIndexer) GetDroppedFiles(workspacePath string) (int, []string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDroppedFiles", workspacePath)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([]string)
+	return ret0, ret1
+}
+
+// GetDroppedFiles indicates an expected call of GetDroppedFiles.
+func (mr *MockIndexerMockRecorder) GetDroppedFiles(workspacePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDroppedFiles", reflect.TypeOf((*MockIndexer)(nil).GetDroppedFiles), workspacePath)
+}
+
+// GetGovernorState mocks base method.
+func (m *MockIndexer) GetGovernorState() *types.GovernorState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGovernorState")
+	ret0, _ := ret[0].(*types.GovernorState)
+	return ret0
+}
+
+// GetGovernorState indicates an expected call of GetGovernorState.
+func (mr *MockIndexerMockRecorder) GetGovernorState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGovernorState", reflect.TypeOf((*MockIndexer)(nil).GetGovernorState))
+}
+
+// GetOpenBuffers mocks base method.
+func (m *MockIndexer) GetOpenBuffers() map[string]time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenBuffers")
+	ret0, _ := ret[0].(map[string]time.Time)
+	return ret0
+}
+
+// GetOpenBuffers indicates an expected call of GetOpenBuffers.
+func (mr *MockIndexerMockRecorder) GetOpenBuffers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenBuffers", reflect.TypeOf((*MockIndexer)(nil).GetOpenBuffers))
+}
+
 // GetSummary mocks base method.
 func (m *MockIndexer) GetSummary(ctx context.Context, workspacePath string) (*types.CodeGraphSummary, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +185,36 @@ func (mr *MockIndexerMockRecorder) QueryDefinitions(ctx, options interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryDefinitions", reflect.TypeOf((*MockIndexer)(nil).QueryDefinitions), ctx, options)
 }
 
+// QueryHover mocks base method.
+func (m *MockIndexer) QueryHover(ctx context.Context, options *types.QueryHoverOptions) (*types.SymbolHover, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryHover", ctx, options)
+	ret0, _ := ret[0].(*types.SymbolHover)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryHover indicates an expected call of QueryHover.
+func (mr *MockIndexerMockRecorder) QueryHover(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryHover", reflect.TypeOf((*MockIndexer)(nil).QueryHover), ctx, options)
+}
+
+// QueryImportedFiles mocks base method.
+func (m *MockIndexer) QueryImportedFiles(ctx context.Context, workspacePath, filePath string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryImportedFiles", ctx, workspacePath, filePath)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryImportedFiles indicates an expected call of QueryImportedFiles.
+func (mr *MockIndexerMockRecorder) QueryImportedFiles(ctx, workspacePath, filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryImportedFiles", reflect.TypeOf((*MockIndexer)(nil).QueryImportedFiles), ctx, workspacePath, filePath)
+}
+
 // QueryReferences mocks base method.
 func (m *MockIndexer) QueryReferences(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +230,66 @@ func (mr *MockIndexerMockRecorder) QueryReferences(ctx, opts interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryReferences", reflect.TypeOf((*MockIndexer)(nil).QueryReferences), ctx, opts)
 }
 
+// QueryRename mocks base method.
+func (m *MockIndexer) QueryRename(ctx context.Context, opts *types.QueryRenameOptions) (*types.RenameResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryRename", ctx, opts)
+	ret0, _ := ret[0].(*types.RenameResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryRename indicates an expected call of QueryRename.
+func (mr *MockIndexerMockRecorder) QueryRename(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRename", reflect.TypeOf((*MockIndexer)(nil).QueryRename), ctx, opts)
+}
+
+// QuerySemantic mocks base method.
+func (m *MockIndexer) QuerySemantic(ctx context.Context, options *types.QuerySemanticOptions) ([]*vector.Hit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuerySemantic", ctx, options)
+	ret0, _ := ret[0].([]*vector.Hit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuerySemantic indicates an expected call of QuerySemantic.
+func (mr *MockIndexerMockRecorder) QuerySemantic(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuerySemantic", reflect.TypeOf((*MockIndexer)(nil).QuerySemantic), ctx, options)
+}
+
+// QueryStructural mocks base method.
+func (m *MockIndexer) QueryStructural(ctx context.Context, options *types.QueryStructuralOptions, emit func(*structural.Capture) error) (*types.StructuralSearchStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryStructural", ctx, options, emit)
+	ret0, _ := ret[0].(*types.StructuralSearchStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryStructural indicates an expected call of QueryStructural.
+func (mr *MockIndexerMockRecorder) QueryStructural(ctx, options, emit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryStructural", reflect.TypeOf((*MockIndexer)(nil).QueryStructural), ctx, options, emit)
+}
+
+// QueryText mocks base method.
+func (m *MockIndexer) QueryText(ctx context.Context, options *types.QueryTextOptions) ([]*textindex.Hit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryText", ctx, options)
+	ret0, _ := ret[0].([]*textindex.Hit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryText indicates an expected call of QueryText.
+func (mr *MockIndexerMockRecorder) QueryText(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryText", reflect.TypeOf((*MockIndexer)(nil).QueryText), ctx, options)
+}
+
 // RemoveAllIndexes mocks base method.
 func (m *MockIndexer) RemoveAllIndexes(ctx context.Context, workspacePath string) error {
 	m.ctrl.T.Helper()
@@ -165,3 +331,32 @@ func (mr *MockIndexerMockRecorder) RenameIndexes(ctx, workspacePath, sourceFileP
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameIndexes", reflect.TypeOf((*MockIndexer)(nil).RenameIndexes), ctx, workspacePath, sourceFilePath, targetFilePath)
 }
+
+// UpdateBuffer mocks base method.
+func (m *MockIndexer) UpdateBuffer(ctx context.Context, workspacePath, filePath string, version int64, content []byte) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBuffer", ctx, workspacePath, filePath, version, content)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBuffer indicates an expected call of UpdateBuffer.
+func (mr *MockIndexerMockRecorder) UpdateBuffer(ctx, workspacePath, filePath, version, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBuffer", reflect.TypeOf((*MockIndexer)(nil).UpdateBuffer), ctx, workspacePath, filePath, version, content)
+}
+
+// WalkReferences mocks base method.
+func (m *MockIndexer) WalkReferences(ctx context.Context, opts *types.QueryReferenceOptions, visit types.ReferenceVisitor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalkReferences", ctx, opts, visit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WalkReferences indicates an expected call of WalkReferences.
+func (mr *MockIndexerMockRecorder) WalkReferences(ctx, opts, visit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalkReferences", reflect.TypeOf((*MockIndexer)(nil).WalkReferences), ctx, opts, visit)
+}
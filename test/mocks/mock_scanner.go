@@ -107,3 +107,11 @@ func (m *MockScanner) CheckIgnoreFile(ignoreConfig *config.IgnoreConfig, codebas
 	}
 	return false, nil
 }
+
+func (m *MockScanner) DroppedFiles(codebasePath string) (int, []string) {
+	args := m.Called(codebasePath)
+	if args.Get(1) != nil {
+		return args.Int(0), args.Get(1).([]string)
+	}
+	return args.Int(0), nil
+}